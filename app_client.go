@@ -1,36 +1,112 @@
 package rest_client
 
 import (
+	"bytes"
 	"context"
 	"crypto/md5"
-	"encoding/json"
 	"fmt"
 	"github.com/tidwall/gjson"
 	"io"
 	"net/http"
 	"net/url"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
 // AppRestConfig 回收宝内部服务配置
 type AppRestConfig struct {
-	Name        string
-	AppKey      string
-	AppSecret   string
-	AppUrl      string
-	EventCreate func(ctx context.Context) RestEvent
+	Name         string
+	AppKey       string
+	AppSecret    string
+	AppUrl       string
+	EventCreate  func(ctx context.Context) RestEvent
+	Balancer     RestBalancer       //多地址负载均衡,设置后忽略AppUrl
+	ParamEncoder ParamEncoder       //参数编码,默认JSON,接口上配置的优先
+	Background   *AppRestBackground //通过 WithBackground 标记的后台请求使用的配置
+	//默认参数,如 channel source 等每个请求都需要的字段,调用参数中已存在时不覆盖
+	DefaultParams map[string]interface{}
+	//默认请求HEADER,可通过 WithHeader 对单次请求覆盖
+	Headers map[string]string
+	Mirror  *AppRestMirror //镜像流量配置
+	//独立的连接池配置,为nil时使用管理器公共的 Transport
+	Transport *RestTransportConfig
+	//设置后将ctx剩余时间(毫秒)通过此HEADER传递给服务端,如 X-Timeout-Ms
+	DeadlineHeader string
+	//ctx剩余时间小于此值时不发起请求直接返回错误
+	MinBudget time.Duration
+	//请求内容发送进度回调间隔,默认200ms,RestEvent 实现 RestUploadEvent 时有效
+	ProgressInterval time.Duration
+	//事件采样,为nil时每个请求都调用 EventCreate
+	EventSample *AppRestEventSample
+	//按 WithTenant 设置的租户获取密钥及地址,未设置租户的请求使用本配置
+	Tenants TenantCredentialProvider
+	//请求调度,限制并发并按优先级排队
+	Scheduler *AppRestScheduler
+	//低分配模式,签名及参数编码使用池化缓冲按固定顺序拼接,请求HEADER预分配,用于高频调用的服务
+	LowAlloc bool
+	//失败重试,为nil时不重试
+	Retry *AppRestRetry
+	//主动健康检查,设置后由管理器在后台定时检查,Balancer 实现 RestHealthBalancer 时跳过不健康的地址
+	HealthCheck *AppRestHealthCheck
+	//灰度路由,按比例或 WithCanary 标记将请求发送到灰度地址
+	Canary *AppRestCanary
+	//签名缓存,同一秒内相同参数重复请求(如重试)时复用已生成的签名参数,为nil时不缓存
+	SignCache *AppRestSignCache
+	//审计日志,每次请求脱敏后异步按批写入,为nil时不记录
+	Audit *RestAuditor
+	//调用方标识HEADER,为nil时使用默认的 User-Agent 及 X-Client-Name
+	Identity *RestClientIdentity
+	//网关接口版本,签名参数中的 version,为空时为1.0,接口上配置的优先
+	Version string
+	//签名参数发送方式,如 AppSignHeader,接口上配置的优先
+	SignMode AppSignMode
+	//签名串生成规则,如 NewAppRestCanonical 修改后的规则,为nil时使用 AppRestParamSign,设置后 io.Reader 参数读取全部内容后签名
+	Canonical AppRestCanonicalizer
+	//时钟偏差修正,网关因 timestamp 偏差拒绝请求时按服务端时间修正之后的签名,为nil时不修正
+	ClockSkew *AppRestClockSkew
+	//网关因 timestamp 过期或 nonce 已使用拒绝时重新签名重试一次,为nil时不重试
+	Resign *AppRestResign
+	//带请求信息的事件创建,设置后忽略 EventCreate
+	EventCreateV2 func(ctx context.Context) RestEventV2
+	//附加的事件创建,如指标及链路追踪,与 EventCreate 一起回调
+	EventCreates []func(ctx context.Context) RestEvent
+	//慢请求检测及统计,为nil时仅检测设置了 SlowThreshold 的接口
+	Slow *AppRestSlow
+	//租户的TOKEN获取,WithTenant 的租户设置了 Token 时替换 RestTokenApi
+	tenantToken func(ctx context.Context) (string, error)
+	//租户设置了地址,优先于负载均衡
+	tenantUrl bool
 }
 
 func (clf *AppRestConfig) GetName() string {
 	return clf.Name
 }
 
+// AppRestBackground 后台批量请求配置
+type AppRestBackground struct {
+	Timeout time.Duration //后台请求超时时间,大于0时替换接口配置的超时
+	Rate    float64       //后台请求每秒最大请求数,0不限制
+	Burst   int           //后台请求允许突发的请求数
+	once    sync.Once
+	limiter *RestRateLimiter
+}
+
+// Wait 按后台限流等待
+func (bg *AppRestBackground) Wait(ctx context.Context) error {
+	bg.once.Do(func() {
+		bg.limiter = NewRestRateLimiter(bg.Rate, bg.Burst)
+	})
+	return bg.limiter.Wait(ctx)
+}
+
 type AppClientError struct {
 	Msg     string
 	Code    string
 	SubCode string
+	caller  *RestCallerInfo //发起请求的调用方,通过 ErrorCaller 获取
 }
 
 func (err *AppClientError) Error() string {
@@ -48,10 +124,47 @@ func NewAppClientError(code string, subCode string, msg string) *AppClientError
 
 // AppRestBuild 内部接口配置
 type AppRestBuild struct {
-	Timeout    time.Duration //指定接口超时时间,默认0,跟全局一致
-	Path       string        //接口路径
-	HttpMethod string
-	Method     string
+	Timeout      time.Duration //等待返回HEADER超时,已由 ResponseHeaderTimeout 替代,未设置 ResponseHeaderTimeout 时使用
+	Path         string        //接口路径
+	HttpMethod   string        //请求方式,GET HEAD OPTIONS 参数在URL上,其他参数在内容中,默认POST
+	Method       string
+	Version      string       //网关接口版本,为空时使用服务配置,如新接口使用 2.0
+	SignMode     AppSignMode  //签名参数发送方式,为空时使用服务配置
+	ParamEncoder ParamEncoder //参数编码,为nil时使用服务配置
+	Raw          bool         //不使用签名格式,参数为 []byte string io.Reader 时原样作为请求内容,其他类型编码后发送
+	ContentType  string       //Raw 时请求的 Content-Type,默认 application/json
+	//Raw 时参数为 QueryParams 的数组编码方式,GET等请求编码到URL,其他请求编码为表单内容
+	QueryArrayStyle QueryArrayStyle
+	//并发隔离,按参数生成KEY,同KEY的请求在进程内串行执行,用于有并发问题的服务,可使用 AppRestFenceParam
+	Fence func(param interface{}) string
+	//参数默认值及规范化规则,KEY为参数名,不通过时返回 *AppParamError 且不发送请求
+	ParamRules map[string]*AppParamRule
+	//按HTTP状态码及返回内容分类结果,设置后替代默认的 result.code 检测,如 AppEnvelopeClassifier
+	Classifier RestClassifier
+	//条件请求缓存,GET HEAD请求时发送 If-None-Match/If-Modified-Since,返回304时使用缓存的内容
+	Conditional RestConditionalStore
+	//返回内容的编码,如 gbk,为空时从 Content-Type 获取,非UTF-8时转为UTF-8
+	Charset string
+	//检测及解析JSON前处理返回内容,如 SanitizeJsonBody
+	Sanitizer RestSanitizer
+	//建立连接超时,0时使用连接配置,仅对 NewRestTransport 创建的连接有效
+	DialTimeout time.Duration
+	//等待返回HEADER超时,0时使用 Timeout,均为0时使用连接配置
+	ResponseHeaderTimeout time.Duration
+	//整个请求的超时,包含排队及读取返回内容,0不限制
+	TotalTimeout time.Duration
+	//请求参数及返回内容的编解码,如 restcodec.Msgpack,为nil时使用JSON
+	Codec RestCodec
+	//业务内容加解密,如 NewAppAesGcm,返回内容检测成功后解密,为nil时不处理
+	Crypto *AppRestCrypto
+	//请求内容校验HEADER及返回内容校验,为nil时不处理
+	Checksum *AppRestChecksum
+	//返回内容转换,在 Sanitizer 之后及检测返回结果前按顺序执行,如 UnwrapJsonString RenameJsonField FlattenJsonEnvelope
+	Transformers []RestTransformer
+	//慢请求阈值,大于0时替换服务配置 Slow 的阈值,事件实现 RestSlowEvent 时回调
+	SlowThreshold time.Duration
+	//接口幂等,服务配置了重试时 POST PATCH 请求在超时及服务端错误时也重试,未设置时仅在请求未发送(如建立连接失败)时重试
+	Idempotent bool
 }
 
 func NewAppRestEvent(logger func(method string, url string, httpCode int, httpHeader map[string][]string, request []byte, response []byte, err error)) *AppRestEvent {
@@ -81,6 +194,10 @@ func (event *AppRestEvent) RequestRead(data []byte) {
 func (event *AppRestEvent) ResponseHeader(httpCode int, httpHeader map[string][]string) {
 	event.httpCode = httpCode
 	event.httpHeader = httpHeader
+	//按返回长度预分配,避免读取时多次扩容
+	if length, err := strconv.Atoi(http.Header(httpHeader).Get("Content-Length")); err == nil && length > 0 && length <= maxPoolBuffer && event.response == nil {
+		event.response = make([]byte, 0, length)
+	}
 }
 func (event *AppRestEvent) ResponseRead(data []byte) {
 	event.response = append(event.response, data...)
@@ -127,110 +244,433 @@ func AppRestParamSign(version, appKey, method, timestamp, content, appSecret str
 }
 
 // BuildRequest 执行请求
-func (clt *AppRestBuild) BuildRequest(ctx context.Context, client *RestClient, _ int, param interface{}, _ *RestCallerInfo) *RestResult {
+func (clt *AppRestBuild) BuildRequest(ctx context.Context, client *RestClient, key int, param interface{}, _ *RestCallerInfo) *RestResult {
+	timing := newRestTiming()
+	attempt := 0
+	result := clt.withRetry(ctx, client, param, func() *RestResult {
+		return clt.withResign(ctx, client, param, func(ctx context.Context) *RestResult {
+			attempt++
+			return clt.buildRequest(withAttempt(ctx, attempt), client, key, param, timing)
+		})
+	})
+	timing.finish()
+	result.timing = timing
+	result.err = timing.timeoutError(result.err)
+	timing.checkSlow(result.err)
+	clt.mirrorResult(ctx, client, key, param, result)
+	return result
+}
+
+// buildRequest 执行请求并记录各阶段耗时
+func (clt *AppRestBuild) buildRequest(ctx context.Context, client *RestClient, key int, param interface{}, timing *restTiming) (result *RestResult) {
 	tConfig, err := client.GetConfig(ctx)
 	if err != nil {
 		return NewRestResultFromError(err, &RestEventNoop{})
 	}
 	config, ok := tConfig.(*AppRestConfig)
 	if !ok {
-		return NewRestResultFromError(NewRestClientError("11", "build config is wrong"), &RestEventNoop{})
+		return NewRestResultFromError(NewRestClientError(ErrConfigType, "build config is wrong"), &RestEventNoop{})
 	}
-
-	var event RestEvent
-	if config.EventCreate != nil {
-		event = config.EventCreate(ctx)
-	} else {
-		event = &RestEventNoop{}
+	config, err = config.forTenant(ctx)
+	if err != nil {
+		return NewRestResultFromError(err, &RestEventNoop{})
 	}
 
-	transport := client.GetTransport()
-	headerTime := transport.ResponseHeaderTimeout
-	apiUrl := config.AppUrl
-	appid := config.AppKey
-	keyConfig := config.AppSecret
+	ctx = withCallInfo(ctx, config.Name, key)
+	info := &restRequestInfo{configName: config.Name, key: key}
+	defer func() {
+		if result != nil && result.info == nil {
+			result.info = info
+		}
+	}()
+	event := contextEvent(ctx)
+	if event == nil {
+		create := eventCreate(config.EventCreate, config.EventCreateV2, config.EventCreates)
+		if create != nil && config.EventSample != nil {
+			event = config.EventSample.event(ctx, create)
+		} else if create != nil {
+			event = create(ctx)
+		} else {
+			event = &RestEventNoop{}
+		}
+	}
+	timing.withSlow(ctx, config, clt.slowThreshold(config), event)
+	if config.Audit != nil {
+		event = config.Audit.event(ctx, event)
+	}
+	if config.ClockSkew != nil {
+		event = config.ClockSkew.event(event)
+	}
 
-	jsonParam, err := json.Marshal(param)
-	if err != nil {
+	if err := checkDeadlineBudget(ctx, config.MinBudget); err != nil {
 		return NewRestResultFromError(err, event)
 	}
 
-	var token *string
-	if token_, find := client.Api.(RestTokenApi); find {
-		tokenTmp, err := token_.Token(ctx)
+	headerTimeout := clt.ResponseHeaderTimeout
+	if headerTimeout <= 0 {
+		headerTimeout = clt.Timeout
+	}
+	if config.Background != nil && IsBackground(ctx) {
+		queued := timing.queue()
+		err := config.Background.Wait(ctx)
+		queued()
 		if err != nil {
-			return NewRestResultFromError(err, event)
+			return NewRestResultFromError(cancelError(err), event)
+		}
+		if config.Background.Timeout > 0 {
+			headerTimeout = config.Background.Timeout
 		}
-		token = &tokenTmp
 	}
 
-	timestamp := time.Now().Format("2006-01-02 15:04:05")
-	dataSign := AppRestParamSign("1.0", appid, clt.Method, timestamp, string(jsonParam), keyConfig, token)
-	reqParam := map[string]string{
-		"app":       appid,
-		"version":   "1.0",
-		"timestamp": timestamp,
-		"content":   string(jsonParam),
-		"sign":      dataSign,
+	var roundTripper http.RoundTripper = client.GetTransport()
+	if config.Transport != nil && client.manager != nil {
+		roundTripper = client.manager.transports.get(config.Name, config.Transport).roundTripper
 	}
-	if len(clt.Method) > 0 {
-		reqParam["method"] = clt.Method
+	apiUrl := config.AppUrl
+	override := contextOverride(ctx)
+	canary, balanced, sent := false, false, false
+	if override != nil && len(override.AppUrl) > 0 {
+		apiUrl = override.AppUrl
+	} else if config.tenantUrl {
+		apiUrl = config.AppUrl
+	} else if canary = config.Canary.route(ctx); canary {
+		apiUrl = config.Canary.Url
+	} else if config.Balancer != nil {
+		apiUrl = config.Balancer.Next()
+		balanced = true
+		node := apiUrl
+		defer func() {
+			//请求未发出时释放选择的地址
+			if !sent && result != nil {
+				releaseBalancer(config.Balancer, node, result.err)
+			}
+		}()
 	}
-	if token != nil {
-		reqParam["token"] = *token
+	baseUrl := apiUrl
+	apiUrl += clt.Path
+	httpMethod := appHttpMethod(clt.HttpMethod)
+	info.method, info.url = httpMethod, apiUrl
+	//按类型编码的结构体参数不合并默认参数,参数规则只校验
+	if !clt.typedParam(config) || isMapParam(param) {
+		param, err = mergeDefaultParams(config.DefaultParams, param)
+		if err != nil {
+			return NewRestResultFromError(err, event)
+		}
+		param, err = applyParamRules(clt.ParamRules, param)
+	} else {
+		err = checkParamRules(clt.ParamRules, param)
 	}
-
-	pData := url.Values{}
-	for key, val := range reqParam {
-		pData.Set(key, val)
+	if err != nil {
+		return NewRestResultFromError(err, event)
 	}
-	paramStr := pData.Encode()
-	apiUrl += clt.Path
+	conditional := newRestConditional(ctx, clt.Conditional, config.Name, key, httpMethod, param, clt.paramEncoder(), config.ParamEncoder)
 	var ioRead io.Reader
-	if clt.HttpMethod == http.MethodGet {
-		if strings.Index(apiUrl, "?") == -1 {
-			apiUrl += "?" + paramStr
+	var signHeader http.Header
+	contentType := ""
+	if clt.Raw {
+		defContentType := "application/json"
+		if clt.Codec != nil {
+			defContentType = clt.Codec.ContentType()
+		}
+		rawParam := param
+		if query, ok := param.(QueryParams); ok {
+			if appMethodHasBody(httpMethod) {
+				rawParam = query.Encode(clt.QueryArrayStyle)
+				defContentType = "application/x-www-form-urlencoded"
+			} else {
+				apiUrl = urlAppendQuery(apiUrl, query.Encode(clt.QueryArrayStyle))
+				rawParam = nil
+			}
+		}
+		body, err := rawParamBody(rawParam, clt.paramEncoder(), config.ParamEncoder)
+		if err != nil {
+			return NewRestResultFromError(err, event)
+		}
+		if body != nil {
+			ioRead = NewRestRequestReader(body, event)
+			contentType = clt.ContentType
+			if len(contentType) == 0 {
+				contentType = defContentType
+			}
+		}
+	} else if clt.signMode(config) == AppSignHeader {
+		var content string
+		content, signHeader, err = clt.signHeader(ctx, client, config, param, appMethodHasBody(httpMethod))
+		if err != nil {
+			return NewRestResultFromError(err, event)
+		}
+		info.signHeader, info.signContent = signHeader, content
+		if !appMethodHasBody(httpMethod) {
+			apiUrl = urlAppendQuery(apiUrl, "content="+url.QueryEscape(content))
 		} else {
-			apiUrl += "&" + paramStr
+			ioRead = NewRestRequestReader(strings.NewReader(content), event)
+			contentType = "application/json"
+			if clt.Codec != nil {
+				contentType = clt.Codec.ContentType()
+			}
 		}
-		ioRead = nil
+	} else if src, ok := param.(io.Reader); ok && appMethodHasBody(httpMethod) && config.Canonical == nil && !clt.encryptRequest() {
+		body, err := clt.newAppStreamBody(ctx, client, config, src)
+		if err != nil {
+			return NewRestResultFromError(err, event)
+		}
+		ioRead = NewRestRequestReader(body, event)
+		contentType = "application/x-www-form-urlencoded"
 	} else {
-		ioRead = NewRestRequestReader(strings.NewReader(paramStr), event)
+		signParam := clt.signParam
+		if config.LowAlloc {
+			signParam = clt.signParamLowAlloc
+		}
+		paramStr, err := signParam(ctx, client, config, param)
+		if err != nil {
+			return NewRestResultFromError(err, event)
+		}
+		info.signForm = paramStr
+		if !appMethodHasBody(httpMethod) {
+			apiUrl = urlAppendQuery(apiUrl, paramStr)
+		} else {
+			ioRead = NewRestRequestReader(strings.NewReader(paramStr), event)
+			contentType = "application/x-www-form-urlencoded"
+		}
+	}
+	if reader, ok := ioRead.(*RestRequestReader); ok && config.ProgressInterval > 0 {
+		reader.interval = config.ProgressInterval
+	}
+	checksumHeader, err := clt.Checksum.requestHeader(ioRead)
+	if err != nil {
+		return NewRestResultFromError(err, event)
+	}
+	info.url = apiUrl
+	event.RequestStart(httpMethod, apiUrl)
+	totalTimeout := clt.TotalTimeout
+	if override != nil && override.Timeout > 0 {
+		totalTimeout = override.Timeout
+	}
+	reqCtx, timeout := newRestTimeout(timing.withTrace(ctx), clt.DialTimeout, headerTimeout, totalTimeout)
+	req, err := http.NewRequestWithContext(reqCtx, httpMethod, apiUrl, ioRead)
+	if err != nil {
+		timeout.release()
+		return NewRestResultFromError(err, event)
 	}
-	event.RequestStart(clt.HttpMethod, apiUrl)
-	var req *http.Request
-	req, err = http.NewRequest(clt.HttpMethod, apiUrl, ioRead)
 
+	ctxHeader := contextHeader(ctx)
+	if config.LowAlloc {
+		req.Header = appRequestHeader(config, ctxHeader)
+	}
+	setIdentityHeader(config.Identity, req.Header)
+	for key, val := range config.Headers {
+		req.Header.Set(key, val)
+	}
+	for key, val := range ctxHeader {
+		req.Header[key] = val
+	}
+	if override != nil {
+		for key, val := range override.Headers {
+			req.Header.Set(key, val)
+		}
+	}
+	if conditional != nil {
+		conditional.setHeader(req.Header)
+	}
+	if client.manager != nil {
+		injectTrace(ctx, client.manager.propagators, req.Header)
+	}
+	for key, val := range signHeader {
+		req.Header[key] = val
+	}
+	for key, val := range checksumHeader {
+		req.Header[key] = val
+	}
 	if rid, find := client.Api.(AppRestRequestId); find {
 		tmp := rid.RequestId(ctx)
 		req.Header["X-Request-ID"] = []string{tmp}
 	}
-
-	if clt.HttpMethod == http.MethodPost {
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if len(contentType) > 0 {
+		req.Header.Set("Content-Type", contentType)
 	}
-	if err != nil {
-		return NewRestResultFromError(err, event)
+	if clt.Codec != nil && len(req.Header.Get("Accept")) == 0 {
+		req.Header.Set("Accept", clt.Codec.ContentType())
 	}
 
-	if clt.Timeout > 0 {
-		transport.ResponseHeaderTimeout = clt.Timeout
+	release := timeout.release
+	queued := timing.queue()
+	//先获取隔离锁,等待隔离锁时不占用调度的并发数
+	if fence := clt.fenceName(config.Name, key, param); len(fence) > 0 {
+		fenceRelease, err := restFences.acquire(reqCtx, fence)
+		if err != nil {
+			release()
+			return NewRestResultFromError(cancelError(err), event)
+		}
+		release = joinRelease(release, fenceRelease)
 	}
+	if config.Scheduler != nil {
+		scheduleRelease, err := config.Scheduler.acquire(reqCtx)
+		if err != nil {
+			release()
+			return NewRestResultFromError(cancelError(err), event)
+		}
+		release = joinRelease(release, scheduleRelease)
+	}
+	queued()
+
 	httpClient := &http.Client{
-		Transport: transport,
+		Transport: client.wrapRoundTripper(roundTripper),
 	}
+	setDeadlineHeader(reqCtx, req.Header, config.DeadlineHeader)
+	start := time.Now()
+	timeout.start()
+	sent = true
 	res, err := httpClient.Do(req)
-	if clt.Timeout > 0 {
-		transport.ResponseHeaderTimeout = headerTime
+	timeout.gotHeader()
+	//单次请求指定地址时不计入负载均衡及灰度统计
+	routed := override == nil || len(override.AppUrl) == 0
+	if balanced || (routed && config.Canary != nil) {
+		httpCode := 0
+		if res != nil {
+			httpCode = res.StatusCode
+		}
+		latency, balanceErr := time.Since(start), balanceError(httpCode, err)
+		if balanced {
+			config.Balancer.Report(baseUrl, latency, balanceErr)
+		}
+		if routed {
+			config.Canary.report(canary, latency, balanceErr)
+		}
 	}
 	if err != nil {
-		return NewRestResultFromError(err, event)
+		release()
+		checkPinError(err, event)
+		return NewRestResultFromError(timeout.error(err), event)
 	} else {
+		res.Body = newFenceBody(res.Body, release)
+		clt.Checksum.verifyBody(res)
+		charsetBody(res, clt.Charset)
+		checkDeprecation(client, config.Name, key, apiUrl, res.Header, event)
+		if conditional != nil {
+			if result := conditional.response(clt, res, event); result != nil {
+				return result
+			}
+		}
 		return NewRestResult(clt, res, event)
 	}
 }
 
+// AppRestCheckSign 校验请求参数签名,用于模拟网关或服务端校验
+func AppRestCheckSign(form url.Values, appSecret string) bool {
+	var token *string
+	if _, ok := form["token"]; ok {
+		tmp := form.Get("token")
+		token = &tmp
+	}
+	sign := AppRestParamSign(form.Get("version"), form.Get("app"), form.Get("method"), form.Get("timestamp"), form.Get("content"), appSecret, token)
+	return len(form.Get("sign")) > 0 && sign == form.Get("sign")
+}
+
+// appHttpMethod 统一请求方式,未设置时为POST
+func appHttpMethod(method string) string {
+	if len(method) == 0 {
+		return http.MethodPost
+	}
+	return strings.ToUpper(method)
+}
+
+// appMethodHasBody 请求方式是否在内容中发送参数
+func appMethodHasBody(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+// appVersion 接口使用的网关版本
+func (clt *AppRestBuild) appVersion(config *AppRestConfig) string {
+	if len(clt.Version) > 0 {
+		return clt.Version
+	}
+	if len(config.Version) > 0 {
+		return config.Version
+	}
+	return "1.0"
+}
+
+// signParam 生成带签名的请求参数
+func (clt *AppRestBuild) signParam(ctx context.Context, client *RestClient, config *AppRestConfig, param interface{}) (string, error) {
+	param, err := readStreamParam(param)
+	if err != nil {
+		return "", err
+	}
+	jsonParam, err := encodeParam(param, clt.paramEncoder(), config.ParamEncoder)
+	if err != nil {
+		return "", err
+	}
+	if jsonParam, err = clt.encryptContent(jsonParam); err != nil {
+		return "", err
+	}
+
+	token, err := appToken(ctx, client, config)
+	if err != nil {
+		return "", err
+	}
+
+	timestamp := appTimestamp(config)
+	if config.SignCache != nil {
+		load := config.SignCache.load
+		if isResigned(ctx) {
+			load = config.SignCache.refresh
+		}
+		return load(config, clt.appVersion(config), clt.Method, timestamp, jsonParam, token, appSignedParams), nil
+	}
+	return appSignedParams(config, clt.appVersion(config), clt.Method, timestamp, jsonParam, token), nil
+}
+
+// appSignedParams 生成签名并编码全部请求参数
+func appSignedParams(config *AppRestConfig, version, method, timestamp, jsonParam string, token *string) string {
+	dataSign := appConfigSign(config, version, method, timestamp, jsonParam, token)
+	reqParam := map[string]string{
+		"app":       config.AppKey,
+		"version":   version,
+		"timestamp": timestamp,
+		"content":   jsonParam,
+		"sign":      dataSign,
+	}
+	if len(method) > 0 {
+		reqParam["method"] = method
+	}
+	if token != nil {
+		reqParam["token"] = *token
+	}
+
+	pData := url.Values{}
+	for key, val := range reqParam {
+		pData.Set(key, val)
+	}
+	return pData.Encode()
+}
+
+// rawParamBody 原样发送的请求内容,[]byte string io.Reader 原样发送,其他类型编码后发送,nil不发送内容
+func rawParamBody(param interface{}, encoders ...ParamEncoder) (io.Reader, error) {
+	switch body := param.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return bytes.NewReader(body), nil
+	case string:
+		return strings.NewReader(body), nil
+	case io.Reader:
+		return body, nil
+	}
+	body, err := encodeParam(param, encoders...)
+	if err != nil {
+		return nil, err
+	}
+	return strings.NewReader(body), nil
+}
+
 func (clt *AppRestBuild) CheckJsonResult(body string) error {
+	if clt.Raw {
+		return nil
+	}
 	code := gjson.Get(body, "result.code").String()
 	state := gjson.Get(body, "result.state").String()
 	if code != "200" || state != "ok" {
@@ -2,7 +2,13 @@ package rest_client
 
 import (
 	"context"
+	"errors"
+	"github.com/tidwall/gjson"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -77,3 +83,205 @@ func TestAppClient(t *testing.T) {
 		t.Error("test fail")
 	}
 }
+
+// newTestAppServer 创建测试网关,handler 参数为接口名及content内容,返回data节点内容
+func newTestAppServer(handler func(method string, content gjson.Result) string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		data := handler(r.Form.Get("method"), gjson.Parse(r.Form.Get("content")))
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":` + data + `}`))
+	}))
+}
+
+// testBuildApi 测试用接口定义
+type testBuildApi struct {
+	name   string
+	builds map[int]RestBuild
+}
+
+func (res *testBuildApi) ConfigBuilds(_ context.Context) (map[int]RestBuild, error) {
+	return res.builds, nil
+}
+func (res *testBuildApi) ConfigName(_ context.Context) (string, error) {
+	return res.name, nil
+}
+
+func newTestAppClient(url string, builds map[int]RestBuild) *RestClient {
+	client := NewRestClientManager()
+	client.SetRestConfig(&AppRestConfig{
+		Name:      "test",
+		AppKey:    "dome1",
+		AppSecret: "dome111111",
+		AppUrl:    url,
+	})
+	return client.NewApi(&testBuildApi{name: "test", builds: builds})
+}
+
+func TestAppRawBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", r.Header.Get("Content-Type"))
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodPost, Raw: true},
+		2: &AppRestBuild{HttpMethod: http.MethodPut, Raw: true, ContentType: "text/plain"},
+	})
+	res := (<-client.Do(context.Background(), 1, map[string]string{"a": "b"})).JsonResult()
+	if res.MustString("a") != "b" {
+		t.Error("raw json body error")
+	}
+	res = (<-client.Do(context.Background(), 1, strings.NewReader(`{"c":"d"}`))).JsonResult()
+	if res.MustString("c") != "d" {
+		t.Error("raw reader body error")
+	}
+	result := <-client.Do(context.Background(), 2, "text")
+	_, header := result.Header()
+	body, _ := ioutil.ReadAll(result)
+	if string(body) != "text" || header.Get("Content-Type") != "text/plain" {
+		t.Error("raw text body error")
+	}
+}
+
+func TestAppHttpMethod(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		inBody := strings.Contains(string(body), "sign=")
+		inQuery := r.URL.Query().Get("sign") != ""
+		w.Header().Set("X-Sign-Body", strconv.FormatBool(inBody))
+		w.Header().Set("X-Sign-Query", strconv.FormatBool(inQuery))
+		w.Header().Set("X-Method", r.Method)
+	}))
+	defer server.Close()
+	methods := []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, ""}
+	builds := map[int]RestBuild{}
+	for i, method := range methods {
+		builds[i] = &AppRestBuild{HttpMethod: method}
+	}
+	client := newTestAppClient(server.URL, builds)
+	for i, method := range methods {
+		err, header := (<-client.Do(context.Background(), i, nil)).Header()
+		if err != nil {
+			t.Fatal(err)
+		}
+		hasBody := appMethodHasBody(appHttpMethod(method))
+		if header.Get("X-Sign-Body") != strconv.FormatBool(hasBody) || header.Get("X-Sign-Query") != strconv.FormatBool(!hasBody) {
+			t.Error("sign place error:" + method)
+		}
+		if header.Get("X-Method") != appHttpMethod(method) {
+			t.Error("http method error:" + method)
+		}
+	}
+}
+
+type testStartEvent struct {
+	RestEventNoop
+	url string
+}
+
+func (event *testStartEvent) RequestStart(_, url string) {
+	event.url = url
+}
+
+func TestAppEventOverride(t *testing.T) {
+	server := newTestAppServer(func(_ string, _ gjson.Result) string {
+		return `{}`
+	})
+	defer server.Close()
+	global := &testStartEvent{}
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:   "test",
+		AppUrl: server.URL,
+		EventCreate: func(_ context.Context) RestEvent {
+			return global
+		},
+	})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{Path: "/call"},
+	}})
+	event := &testStartEvent{}
+	if err := (<-client.Do(WithEvent(context.Background(), event), 1, nil)).JsonResult().Err(); err != nil {
+		t.Fatal(err)
+	}
+	if event.url != server.URL+"/call" || len(global.url) > 0 {
+		t.Error("call event not use")
+	}
+	_ = (<-client.Do(context.Background(), 1, nil)).JsonResult()
+	if len(global.url) == 0 {
+		t.Error("config event not use")
+	}
+}
+
+func TestAppContextCancel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = ioutil.ReadAll(r.Body)
+		if r.URL.Path == "/body" {
+			w.(http.Flusher).Flush()
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{Path: "/header"},
+		2: &AppRestBuild{Path: "/body"},
+	})
+	for key := 1; key <= 2; key++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(20*time.Millisecond, cancel)
+		start := time.Now()
+		err := (<-client.Do(ctx, key, nil)).JsonResult().Err()
+		var rErr *RestClientError
+		if !errors.As(err, &rErr) || rErr.Code != ErrCanceled || !errors.Is(err, context.Canceled) {
+			t.Errorf("cancel error wrong:%v", err)
+		}
+		if time.Since(start) > 500*time.Millisecond {
+			t.Error("request not abort")
+		}
+		cancel()
+	}
+}
+
+func TestAppVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		code := "200"
+		if !AppRestCheckSign(r.Form, "dome111111") {
+			code = "500"
+		}
+		_, _ = w.Write([]byte(`{"result":{"code":"` + code + `","state":"ok"},"data":{"version":"` + r.Form.Get("version") + `"}}`))
+	}))
+	defer server.Close()
+	builds := map[int]RestBuild{
+		1: &AppRestBuild{Method: "a.b"},
+		2: &AppRestBuild{Method: "a.c", Version: "2.0"},
+	}
+	for _, lowAlloc := range []bool{false, true} {
+		client := NewRestClientManager()
+		client.SetRestConfig(&AppRestConfig{
+			Name:      "test",
+			AppKey:    "dome1",
+			AppSecret: "dome111111",
+			AppUrl:    server.URL,
+			Version:   "1.1",
+			LowAlloc:  lowAlloc,
+		})
+		api := client.NewApi(&testBuildApi{name: "test", builds: builds})
+		res := (<-api.Do(context.Background(), 1, map[string]string{"a": "b"})).JsonResult()
+		if res.Err() != nil || res.MustString("data.version") != "1.1" {
+			t.Error("config version error", res.Err())
+		}
+		res = (<-api.Do(context.Background(), 2, map[string]string{"a": "b"})).JsonResult()
+		if res.Err() != nil || res.MustString("data.version") != "2.0" {
+			t.Error("build version error", res.Err())
+		}
+	}
+	res := (<-newTestAppClient(server.URL, builds).Do(context.Background(), 1, nil)).JsonResult()
+	if res.MustString("data.version") != "1.0" {
+		t.Error("default version error")
+	}
+}
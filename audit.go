@@ -0,0 +1,376 @@
+package rest_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RestAuditRecord 审计记录,请求及返回内容已脱敏及截断
+type RestAuditRecord struct {
+	Time       time.Time         `json:"time"` //开始请求时间
+	ConfigName string            `json:"config_name"`
+	Key        int               `json:"key"`
+	Method     string            `json:"method"`
+	Url        string            `json:"url"`
+	HttpCode   int               `json:"http_code"`
+	Request    string            `json:"request"`
+	Response   string            `json:"response"`
+	Duration   time.Duration     `json:"duration"`
+	Error      string            `json:"error,omitempty"`
+	Caller     string            `json:"caller,omitempty"`      //发起请求的调用方,文件:行 函数
+	CallerTags map[string]string `json:"caller_tags,omitempty"` //WithCallerTag 设置的标签
+}
+
+// RestAuditSink 审计记录写入目标,如文件,Kafka,日志收集服务
+type RestAuditSink interface {
+	WriteAudit(ctx context.Context, records []*RestAuditRecord) error
+}
+
+// RestAuditSinkFunc 函数形式的写入目标,如封装 Kafka producer 的批量发送
+type RestAuditSinkFunc func(ctx context.Context, records []*RestAuditRecord) error
+
+func (fn RestAuditSinkFunc) WriteAudit(ctx context.Context, records []*RestAuditRecord) error {
+	return fn(ctx, records)
+}
+
+// RestAuditDrop 队列已满时的处理方式
+type RestAuditDrop int
+
+const (
+	AuditDropNewest RestAuditDrop = iota //丢弃新记录,默认
+	AuditDropOldest                      //丢弃队列中最早的记录
+	AuditBlock                           //等待队列空闲,写入慢时会阻塞请求
+)
+
+// RestAuditStats 审计统计
+type RestAuditStats struct {
+	Written int64 //已写入的记录数
+	Dropped int64 //队列已满或已关闭时丢弃的记录数
+	Failed  int64 //写入目标失败的记录数
+}
+
+// RestAuditor 审计日志,每次请求(含重试)在读取完返回内容或失败时生成记录,异步按批写入 Sink
+// 设置到服务配置的 Audit,进程退出前调用 Close 写入剩余记录
+type RestAuditor struct {
+	Sink          RestAuditSink
+	Redact        func(body []byte) []byte //内容脱敏,为nil时使用 RedactBody()
+	MaxBody       int                      //记录内容最大长度,默认4096
+	QueueSize     int                      //队列长度,默认1024
+	BatchSize     int                      //每批最多记录数,默认100
+	FlushInterval time.Duration            //未满一批时的写入间隔,默认1秒
+	WriteTimeout  time.Duration            //每批写入超时,默认5秒
+	Drop          RestAuditDrop            //队列已满时的处理方式
+	OnError       func(err error)          //写入失败回调,可以为nil
+	once          sync.Once
+	lock          sync.RWMutex
+	closed        bool
+	queue         chan *RestAuditRecord
+	done          chan struct{}
+	written       int64
+	dropped       int64
+	failed        int64
+}
+
+// NewRestAuditor 创建审计日志
+func NewRestAuditor(sink RestAuditSink) *RestAuditor {
+	return &RestAuditor{Sink: sink}
+}
+
+func (auditor *RestAuditor) init() {
+	auditor.once.Do(func() {
+		if auditor.Redact == nil {
+			auditor.Redact = RedactBody()
+		}
+		if auditor.MaxBody <= 0 {
+			auditor.MaxBody = 4096
+		}
+		if auditor.QueueSize <= 0 {
+			auditor.QueueSize = 1024
+		}
+		if auditor.BatchSize <= 0 {
+			auditor.BatchSize = 100
+		}
+		if auditor.FlushInterval <= 0 {
+			auditor.FlushInterval = time.Second
+		}
+		if auditor.WriteTimeout <= 0 {
+			auditor.WriteTimeout = 5 * time.Second
+		}
+		auditor.queue = make(chan *RestAuditRecord, auditor.QueueSize)
+		auditor.done = make(chan struct{})
+		go auditor.run()
+	})
+}
+
+// Stats 获取审计统计
+func (auditor *RestAuditor) Stats() RestAuditStats {
+	return RestAuditStats{
+		Written: atomic.LoadInt64(&auditor.written),
+		Dropped: atomic.LoadInt64(&auditor.dropped),
+		Failed:  atomic.LoadInt64(&auditor.failed),
+	}
+}
+
+// Close 停止接收记录并等待队列中的记录写入完成
+func (auditor *RestAuditor) Close() {
+	auditor.init()
+	auditor.lock.Lock()
+	if !auditor.closed {
+		auditor.closed = true
+		close(auditor.queue)
+	}
+	auditor.lock.Unlock()
+	<-auditor.done
+}
+
+// add 按队列已满时的处理方式加入记录
+func (auditor *RestAuditor) add(record *RestAuditRecord) {
+	auditor.init()
+	auditor.lock.RLock()
+	defer auditor.lock.RUnlock()
+	if auditor.closed {
+		atomic.AddInt64(&auditor.dropped, 1)
+		return
+	}
+	if auditor.Drop == AuditBlock {
+		auditor.queue <- record
+		return
+	}
+	for {
+		select {
+		case auditor.queue <- record:
+			return
+		default:
+		}
+		if auditor.Drop != AuditDropOldest {
+			atomic.AddInt64(&auditor.dropped, 1)
+			return
+		}
+		select {
+		case <-auditor.queue:
+			atomic.AddInt64(&auditor.dropped, 1)
+		default:
+		}
+	}
+}
+
+func (auditor *RestAuditor) run() {
+	defer close(auditor.done)
+	ticker := time.NewTicker(auditor.FlushInterval)
+	defer ticker.Stop()
+	batch := make([]*RestAuditRecord, 0, auditor.BatchSize)
+	for {
+		select {
+		case record, ok := <-auditor.queue:
+			if !ok {
+				auditor.write(batch)
+				return
+			}
+			batch = append(batch, record)
+			if len(batch) >= auditor.BatchSize {
+				auditor.write(batch)
+				batch = make([]*RestAuditRecord, 0, auditor.BatchSize)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				auditor.write(batch)
+				batch = make([]*RestAuditRecord, 0, auditor.BatchSize)
+			}
+		}
+	}
+}
+
+func (auditor *RestAuditor) write(batch []*RestAuditRecord) {
+	if len(batch) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), auditor.WriteTimeout)
+	defer cancel()
+	if err := auditor.Sink.WriteAudit(ctx, batch); err != nil {
+		atomic.AddInt64(&auditor.failed, int64(len(batch)))
+		if auditor.OnError != nil {
+			auditor.OnError(err)
+		}
+		return
+	}
+	atomic.AddInt64(&auditor.written, int64(len(batch)))
+}
+
+// url 脱敏地址中的查询参数
+func (auditor *RestAuditor) url(rawUrl string) string {
+	if i := strings.IndexByte(rawUrl, '?'); i >= 0 {
+		return rawUrl[:i+1] + string(auditor.Redact([]byte(rawUrl[i+1:])))
+	}
+	return rawUrl
+}
+
+// event 创建记录单次请求的事件,同时回调 next
+func (auditor *RestAuditor) event(ctx context.Context, next RestEvent) RestEvent {
+	auditor.init()
+	return &restAuditEvent{restEventForward: restEventForward{next}, ctx: ctx, auditor: auditor, next: next}
+}
+
+// RestCloseEvent 可选事件接口,调用 RestResult.Close 时回调,如只检查状态码未读取返回内容时,可重复回调
+type RestCloseEvent interface {
+	ResponseClose()
+}
+
+// restAuditEvent 记录单次请求的事件,未读取完返回内容时在关闭时记录
+type restAuditEvent struct {
+	restEventForward
+	restBodyCapture
+	ctx     context.Context
+	auditor *RestAuditor
+	next    RestEvent
+	once    sync.Once
+	start   time.Time
+}
+
+func (event *restAuditEvent) RequestStart(method, url string) {
+	event.start = time.Now()
+	event.captureStart(method, url)
+	event.next.RequestStart(method, url)
+}
+func (event *restAuditEvent) RequestRead(p []byte) {
+	event.captureRequest(p, event.auditor.MaxBody)
+	event.next.RequestRead(p)
+}
+func (event *restAuditEvent) ResponseHeader(httpCode int, header map[string][]string) {
+	event.captureHeader(httpCode, header)
+	event.next.ResponseHeader(httpCode, header)
+}
+func (event *restAuditEvent) ResponseRead(p []byte) {
+	event.captureResponse(p, event.auditor.MaxBody)
+	event.next.ResponseRead(p)
+}
+func (event *restAuditEvent) ResponseFinish(err error) {
+	event.finish(err)
+	event.next.ResponseFinish(err)
+}
+func (event *restAuditEvent) ResponseCheck(err error) {
+	event.finish(err)
+	event.next.ResponseCheck(err)
+}
+func (event *restAuditEvent) ResponseClose() {
+	event.finish(nil)
+	event.restEventForward.ResponseClose()
+}
+
+func (event *restAuditEvent) finish(err error) {
+	event.once.Do(func() {
+		record := &RestAuditRecord{
+			Time:     event.start,
+			Method:   event.method,
+			Url:      event.auditor.url(event.url),
+			HttpCode: event.httpCode,
+			Request:  redactBody(event.request, event.auditor.Redact, event.auditor.MaxBody),
+			Response: redactBody(event.response, event.auditor.Redact, event.auditor.MaxBody),
+		}
+		if event.start.IsZero() {
+			record.Time = time.Now()
+		} else {
+			record.Duration = time.Since(event.start)
+		}
+		if err != nil {
+			record.Error = err.Error()
+		}
+		record.ConfigName, record.Key, _ = CallInfo(event.ctx)
+		if caller := Caller(event.ctx); caller != nil {
+			record.Caller, record.CallerTags = caller.String(), caller.Tags
+		}
+		event.auditor.add(record)
+	})
+}
+
+// RestAuditWriterSink 按行写入JSON记录,用于文件或标准输出
+type RestAuditWriterSink struct {
+	lock sync.Mutex
+	w    io.Writer
+}
+
+// NewRestAuditWriterSink 创建按行写入JSON记录的目标
+func NewRestAuditWriterSink(w io.Writer) *RestAuditWriterSink {
+	return &RestAuditWriterSink{w: w}
+}
+
+// NewRestAuditFileSink 创建追加写入文件的目标,不存在时创建
+func NewRestAuditFileSink(path string) (*RestAuditWriterSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return NewRestAuditWriterSink(file), nil
+}
+
+func (sink *RestAuditWriterSink) WriteAudit(_ context.Context, records []*RestAuditRecord) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, record := range records {
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+	}
+	sink.lock.Lock()
+	defer sink.lock.Unlock()
+	_, err := sink.w.Write(buf.Bytes())
+	return err
+}
+
+// Close 关闭写入的文件,非 io.Closer 时不处理
+func (sink *RestAuditWriterSink) Close() error {
+	if closer, ok := sink.w.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// RestAuditHttpSink 以JSON数组POST到日志收集服务,非2xx状态码为写入失败
+type RestAuditHttpSink struct {
+	Url     string
+	Headers map[string]string
+	Client  *http.Client //为nil时使用 http.DefaultClient
+}
+
+// NewRestAuditHttpSink 创建发送到日志收集服务的目标
+func NewRestAuditHttpSink(url string) *RestAuditHttpSink {
+	return &RestAuditHttpSink{Url: url}
+}
+
+func (sink *RestAuditHttpSink) WriteAudit(ctx context.Context, records []*RestAuditRecord) error {
+	body, err := json.Marshal(records)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sink.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, val := range sink.Headers {
+		req.Header.Set(key, val)
+	}
+	client := sink.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("audit collector http code:%d", res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package rest_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRestAuditor(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":{"token":"abc"}}`))
+	}))
+	defer server.Close()
+	var lock sync.Mutex
+	var records []*RestAuditRecord
+	batches := 0
+	auditor := NewRestAuditor(RestAuditSinkFunc(func(ctx context.Context, batch []*RestAuditRecord) error {
+		lock.Lock()
+		defer lock.Unlock()
+		records = append(records, batch...)
+		batches++
+		return nil
+	}))
+	auditor.BatchSize = 2
+	auditor.FlushInterval = time.Hour
+	manager := NewRestClientManager()
+	defer manager.Close()
+	manager.SetRestConfig(&AppRestConfig{Name: "test", AppUrl: server.URL, AppSecret: "secret", Audit: auditor})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{Method: "user.login"},
+		2: &AppRestBuild{HttpMethod: http.MethodGet, Path: "/raw", Raw: true},
+	}})
+	for i := 0; i < 2; i++ {
+		res, _ := client.DoSync(context.Background(), 1, map[string]interface{}{"user": "test", "password": "123456"})
+		if err := res.JsonResult().Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, _ := client.DoSync(context.Background(), 2, QueryParams{"token": "abc"})
+	_, _ = ioutil.ReadAll(res)
+	auditor.Close()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(records) != 3 || batches != 2 {
+		t.Fatal("audit records error", len(records), batches)
+	}
+	record := records[0]
+	if record.ConfigName != "test" || record.Key != 1 || record.Method != http.MethodPost || record.HttpCode != http.StatusOK || record.Duration <= 0 {
+		t.Error("audit record error", record)
+	}
+	if strings.Contains(record.Request, "123456") || strings.Contains(record.Response, "abc") || !strings.Contains(record.Request, "user.login") {
+		t.Error("audit record should redact", record.Request, record.Response)
+	}
+	if records[2].Url != server.URL+"/raw?token=%2A%2A%2A" {
+		t.Error("audit url should redact", records[2].Url)
+	}
+	if stats := auditor.Stats(); stats.Written != 3 || stats.Dropped != 0 {
+		t.Error("audit stats error", stats)
+	}
+	res, _ = client.DoSync(context.Background(), 2, nil)
+	_, _ = ioutil.ReadAll(res)
+	if stats := auditor.Stats(); stats.Dropped != 1 {
+		t.Error("closed auditor should drop", stats)
+	}
+}
+
+func TestRestAuditorClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+	var records []*RestAuditRecord
+	auditor := NewRestAuditor(RestAuditSinkFunc(func(ctx context.Context, batch []*RestAuditRecord) error {
+		records = append(records, batch...)
+		return nil
+	}))
+	manager := NewRestClientManager()
+	defer manager.Close()
+	manager.SetRestConfig(&AppRestConfig{Name: "test", AppUrl: server.URL, Audit: auditor, ClockSkew: &AppRestClockSkew{}})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true},
+	}})
+	//只检查状态码后关闭,不读取返回内容
+	res := <-client.Do(context.Background(), 1, nil)
+	if res.Err() != nil || res.response.StatusCode != http.StatusAccepted {
+		t.Fatal("request fail", res.Err())
+	}
+	_ = res.Close()
+	_ = res.Close()
+	auditor.Close()
+	if len(records) != 1 || records[0].HttpCode != http.StatusAccepted || len(records[0].Error) > 0 {
+		t.Error("closed result must write audit record", len(records))
+	}
+}
+
+func TestRestAuditorDrop(t *testing.T) {
+	for _, drop := range []RestAuditDrop{AuditDropNewest, AuditDropOldest} {
+		release := make(chan struct{})
+		var lock sync.Mutex
+		var urls []string
+		auditor := &RestAuditor{QueueSize: 1, BatchSize: 1, Drop: drop, Sink: RestAuditSinkFunc(func(ctx context.Context, batch []*RestAuditRecord) error {
+			<-release
+			lock.Lock()
+			defer lock.Unlock()
+			urls = append(urls, batch[0].Url)
+			return nil
+		})}
+		auditor.add(&RestAuditRecord{Url: "1"})
+		time.Sleep(20 * time.Millisecond) //等待第一条被取出写入
+		auditor.add(&RestAuditRecord{Url: "2"})
+		auditor.add(&RestAuditRecord{Url: "3"})
+		close(release)
+		auditor.Close()
+		expect := "1,2"
+		if drop == AuditDropOldest {
+			expect = "1,3"
+		}
+		if strings.Join(urls, ",") != expect || auditor.Stats().Dropped != 1 {
+			t.Error("audit drop error", drop, urls)
+		}
+	}
+}
+
+func TestRestAuditSink(t *testing.T) {
+	records := []*RestAuditRecord{{ConfigName: "test", Key: 1}, {ConfigName: "test", Key: 2}}
+	var buf bytes.Buffer
+	if err := NewRestAuditWriterSink(&buf).WriteAudit(context.Background(), records); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 2 || !strings.Contains(lines[1], `"key":2`) {
+		t.Error("writer sink error", buf.String())
+	}
+
+	var got []*RestAuditRecord
+	code := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(code)
+	}))
+	defer server.Close()
+	sink := NewRestAuditHttpSink(server.URL)
+	if err := sink.WriteAudit(context.Background(), records); err != nil || len(got) != 2 {
+		t.Error("http sink error", err)
+	}
+	code = http.StatusInternalServerError
+	if err := sink.WriteAudit(context.Background(), records); err == nil {
+		t.Error("http sink should fail on 500")
+	}
+}
@@ -0,0 +1,222 @@
+package rest_client
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RestBalancer 多地址负载均衡
+type RestBalancer interface {
+	Next() string                                        //选择本次请求的地址
+	Report(url string, latency time.Duration, err error) //请求完成后回报结果,err为nil表示成功
+}
+
+// RestBalancerRelease 可选接口,Next 选择地址后请求未发出(如参数校验失败)时释放占用,不计入统计
+type RestBalancerRelease interface {
+	Release(url string)
+}
+
+// releaseBalancer 请求未发出时释放选择的地址,未实现 RestBalancerRelease 时按失败回报
+func releaseBalancer(balancer RestBalancer, url string, err error) {
+	if release, ok := balancer.(RestBalancerRelease); ok {
+		release.Release(url)
+		return
+	}
+	balancer.Report(url, 0, err)
+}
+
+// EwmaBalancerNode 节点当前状态
+type EwmaBalancerNode struct {
+	Url       string
+	Latency   time.Duration //延迟EWMA
+	ErrorRate float64       //错误率EWMA
+	Inflight  int64         //请求中数量
+	Down      bool          //健康检查不通过
+}
+
+type ewmaNode struct {
+	url      string
+	latency  float64
+	errRate  float64
+	inflight int64
+	last     time.Time
+	down     bool //健康检查不通过
+}
+
+// score 分值越低越优先,未有统计的节点优先探测
+func (node *ewmaNode) score() float64 {
+	return node.latency * (1 + node.errRate*10) * float64(node.inflight+1)
+}
+
+// defaultEwmaPenalty 失败请求统计的默认延迟
+const defaultEwmaPenalty = time.Second
+
+// EwmaBalancer 基于延迟及错误率EWMA的P2C负载均衡
+// 每次随机取两个节点选分值低的,慢节点或出错节点自动减少流量
+type EwmaBalancer struct {
+	Decay time.Duration //统计衰减时间,默认10s
+	//失败请求按此延迟统计,实际延迟更大时使用实际延迟,避免快速失败(如拒绝连接)的节点分值低于正常节点,默认1秒
+	Penalty time.Duration
+	lock    sync.Mutex
+	nodes   []*ewmaNode
+	rand    *rand.Rand
+}
+
+// NewEwmaBalancer 创建负载均衡
+func NewEwmaBalancer(urls ...string) *EwmaBalancer {
+	nodes := make([]*ewmaNode, 0, len(urls))
+	for _, url := range urls {
+		nodes = append(nodes, &ewmaNode{url: url})
+	}
+	return &EwmaBalancer{
+		Decay: 10 * time.Second,
+		nodes: nodes,
+		rand:  rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (bal *EwmaBalancer) Next() string {
+	bal.lock.Lock()
+	defer bal.lock.Unlock()
+	nodes := bal.upNodes()
+	var node *ewmaNode
+	switch len(nodes) {
+	case 0:
+		return ""
+	case 1:
+		node = nodes[0]
+	default:
+		i := bal.rand.Intn(len(nodes))
+		j := bal.rand.Intn(len(nodes) - 1)
+		if j >= i {
+			j++
+		}
+		node = nodes[i]
+		if nodes[j].score() < node.score() {
+			node = nodes[j]
+		}
+	}
+	node.inflight++
+	return node.url
+}
+
+func (bal *EwmaBalancer) Report(url string, latency time.Duration, err error) {
+	bal.lock.Lock()
+	defer bal.lock.Unlock()
+	for _, node := range bal.nodes {
+		if node.url != url {
+			continue
+		}
+		if node.inflight > 0 {
+			node.inflight--
+		}
+		now := time.Now()
+		fail := 0.0
+		if err != nil {
+			fail = 1
+			penalty := bal.Penalty
+			if penalty <= 0 {
+				penalty = defaultEwmaPenalty
+			}
+			if latency < penalty {
+				latency = penalty
+			}
+		}
+		if node.last.IsZero() {
+			node.latency = float64(latency)
+			node.errRate = fail
+		} else {
+			//密集请求时也保证每次结果有一定权重
+			w := math.Min(math.Exp(-float64(now.Sub(node.last))/float64(bal.Decay)), 0.9)
+			node.latency = node.latency*w + float64(latency)*(1-w)
+			node.errRate = node.errRate*w + fail*(1-w)
+		}
+		node.last = now
+		return
+	}
+}
+
+// Release 释放 Next 占用的请求中数量,不计入延迟及错误率
+func (bal *EwmaBalancer) Release(url string) {
+	bal.lock.Lock()
+	defer bal.lock.Unlock()
+	for _, node := range bal.nodes {
+		if node.url == url && node.inflight > 0 {
+			node.inflight--
+			return
+		}
+	}
+}
+
+// upNodes 健康检查通过的节点,全部不通过时返回所有节点
+func (bal *EwmaBalancer) upNodes() []*ewmaNode {
+	down := 0
+	for _, node := range bal.nodes {
+		if node.down {
+			down++
+		}
+	}
+	if down == 0 || down == len(bal.nodes) {
+		return bal.nodes
+	}
+	nodes := make([]*ewmaNode, 0, len(bal.nodes)-down)
+	for _, node := range bal.nodes {
+		if !node.down {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// Urls 所有节点地址,用于健康检查
+func (bal *EwmaBalancer) Urls() []string {
+	bal.lock.Lock()
+	defer bal.lock.Unlock()
+	out := make([]string, 0, len(bal.nodes))
+	for _, node := range bal.nodes {
+		out = append(out, node.url)
+	}
+	return out
+}
+
+// SetHealthy 设置节点健康状态,不健康的节点不再分配请求
+func (bal *EwmaBalancer) SetHealthy(url string, healthy bool) {
+	bal.lock.Lock()
+	defer bal.lock.Unlock()
+	for _, node := range bal.nodes {
+		if node.url == url {
+			node.down = !healthy
+		}
+	}
+}
+
+// Nodes 获取所有节点状态
+func (bal *EwmaBalancer) Nodes() []EwmaBalancerNode {
+	bal.lock.Lock()
+	defer bal.lock.Unlock()
+	out := make([]EwmaBalancerNode, 0, len(bal.nodes))
+	for _, node := range bal.nodes {
+		out = append(out, EwmaBalancerNode{
+			Url:       node.url,
+			Latency:   time.Duration(node.latency),
+			ErrorRate: node.errRate,
+			Inflight:  node.inflight,
+			Down:      node.down,
+		})
+	}
+	return out
+}
+
+// balanceError 转换请求结果为负载回报错误,服务端5xx也视为错误
+func balanceError(httpCode int, err error) error {
+	if err != nil {
+		return err
+	}
+	if httpCode >= 500 {
+		return NewRestClientError(ErrServerHttp, fmt.Sprintf("server http code:%d", httpCode))
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package rest_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestEwmaBalancer(t *testing.T) {
+	bal := NewEwmaBalancer("fast", "slow")
+	for i := 0; i < 10; i++ {
+		url := bal.Next()
+		if url == "slow" {
+			bal.Report(url, time.Second, errors.New("timeout"))
+		} else {
+			bal.Report(url, time.Millisecond, nil)
+		}
+	}
+	fast := 0
+	for i := 0; i < 100; i++ {
+		url := bal.Next()
+		if url == "fast" {
+			fast++
+		}
+		bal.Report(url, time.Millisecond, nil)
+	}
+	if fast < 90 {
+		t.Errorf("slow node get too much traffic:%d", 100-fast)
+	}
+	for _, node := range bal.Nodes() {
+		if node.Inflight != 0 {
+			t.Error("balancer inflight error")
+		}
+	}
+}
+
+func TestEwmaBalancerNotSent(t *testing.T) {
+	bal := NewEwmaBalancer("http://127.0.0.1:1")
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{Name: "test", Balancer: bal})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{ParamRules: map[string]*AppParamRule{"name": {Required: true}}},
+	}})
+	for i := 0; i < 5; i++ {
+		if err := (<-client.Do(context.Background(), 1, map[string]interface{}{})).Err(); err == nil {
+			t.Fatal("param rule must fail")
+		}
+	}
+	if node := bal.Nodes()[0]; node.Inflight != 0 || node.ErrorRate != 0 {
+		t.Error("balancer not release", node)
+	}
+}
+
+func TestEwmaBalancerFailFast(t *testing.T) {
+	healthy := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		healthy++
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	refused := httptest.NewServer(http.NotFoundHandler())
+	refused.Close()
+	bal := NewEwmaBalancer(refused.URL, server.URL)
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{Name: "test", Balancer: bal})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true},
+	}})
+	for i := 0; i < 20; i++ {
+		_ = (<-client.Do(context.Background(), 1, nil)).Close()
+	}
+	//拒绝连接的节点失败很快,但不能因延迟低获得流量
+	if healthy < 17 {
+		t.Error("refused node get too much traffic", 20-healthy)
+	}
+}
@@ -0,0 +1,187 @@
+package rest_client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AppBatchBuild 批量接口聚合,将多次调用的参数合并为数组通过下游批量接口一次发送
+// 收集到 MaxSize 个参数或等待 Wait 时间后发送,按下标将返回数组中的元素分发给每次调用
+// 每次调用的结果内容为对应的数组元素,需在 ConfigBuilds 中返回同一个实例才能合并
+type AppBatchBuild struct {
+	Build     *AppRestBuild //下游批量接口,参数为各次调用参数组成的数组
+	MaxSize   int           //单次批量最大数量,默认100
+	Wait      time.Duration //等待合并的时间,默认10ms
+	ItemsPath string        //返回数组的路径,默认 data
+	Timeout   time.Duration //批量请求超时,默认使用 Build 的 TotalTimeout,均未设置时为30秒
+	lock      sync.Mutex
+	queues    map[string]*batchQueue
+}
+
+type batchItem struct {
+	ctx    context.Context
+	client *RestClient
+	param  interface{}
+	result chan *RestResult
+}
+
+// fail 以调用自身的ctx返回错误,调用已取消时返回取消错误
+func (item *batchItem) fail(err error) {
+	if ctxErr := item.ctx.Err(); ctxErr != nil {
+		err = cancelError(ctxErr)
+	}
+	item.result <- NewRestResultFromError(err, nil)
+}
+
+type batchQueue struct {
+	items []*batchItem
+	timer *time.Timer
+}
+
+// NewAppBatchBuild 创建批量聚合
+func NewAppBatchBuild(build *AppRestBuild) *AppBatchBuild {
+	return &AppBatchBuild{
+		Build:     build,
+		MaxSize:   100,
+		Wait:      10 * time.Millisecond,
+		ItemsPath: "data",
+	}
+}
+
+// batchQueueName 合并队列名,不同服务配置,租户,HEADER及单次请求配置的调用不合并,避免使用其他调用的密钥发送
+func batchQueueName(ctx context.Context, configName string, key int) string {
+	identity, _ := json.Marshal(struct {
+		Tenant   string
+		Header   http.Header
+		Override *RestOverride
+	}{TenantFrom(ctx), contextHeader(ctx), contextOverride(ctx)})
+	return configName + "|" + strconv.Itoa(key) + "|" + string(identity)
+}
+
+// BuildRequest 加入批量队列并等待结果
+func (batch *AppBatchBuild) BuildRequest(ctx context.Context, client *RestClient, key int, param interface{}, _ *RestCallerInfo) *RestResult {
+	config, err := client.GetConfig(ctx)
+	if err != nil {
+		return NewRestResultFromError(err, &RestEventNoop{})
+	}
+	item := &batchItem{ctx: ctx, client: client, param: param, result: make(chan *RestResult, 1)}
+	name := batchQueueName(ctx, config.GetName(), key)
+	batch.lock.Lock()
+	if batch.queues == nil {
+		batch.queues = map[string]*batchQueue{}
+	}
+	queue, ok := batch.queues[name]
+	if !ok {
+		queue = &batchQueue{}
+		batch.queues[name] = queue
+	}
+	queue.items = append(queue.items, item)
+	if len(queue.items) >= batch.maxSize() {
+		if queue.timer != nil {
+			queue.timer.Stop()
+		}
+		delete(batch.queues, name)
+		go batch.send(key, queue.items)
+	} else if queue.timer == nil {
+		wait := batch.Wait
+		if wait <= 0 {
+			wait = 10 * time.Millisecond
+		}
+		queue.timer = time.AfterFunc(wait, func() {
+			batch.lock.Lock()
+			if batch.queues[name] != queue {
+				batch.lock.Unlock()
+				return
+			}
+			delete(batch.queues, name)
+			batch.lock.Unlock()
+			batch.send(key, queue.items)
+		})
+	}
+	batch.lock.Unlock()
+	select {
+	case res := <-item.result:
+		return res
+	case <-ctx.Done():
+		return NewRestResultFromError(cancelError(ctx.Err()), &RestEventNoop{})
+	}
+}
+
+func (batch *AppBatchBuild) maxSize() int {
+	if batch.MaxSize <= 0 {
+		return 100
+	}
+	return batch.MaxSize
+}
+
+// batchContext 批量请求的ctx,只保留合并队列的租户,HEADER及单次请求配置,不继承调用的事件,调用方等信息
+func (batch *AppBatchBuild) batchContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	batchCtx := context.Background()
+	if name, ok := ctx.Value(configNameKey{}).(string); ok {
+		batchCtx = withConfigName(batchCtx, name)
+	}
+	if tenant := TenantFrom(ctx); len(tenant) > 0 {
+		batchCtx = WithTenant(batchCtx, tenant)
+	}
+	if header := contextHeader(ctx); header != nil {
+		batchCtx = context.WithValue(batchCtx, headerKey{}, header)
+	}
+	if override := contextOverride(ctx); override != nil {
+		batchCtx = context.WithValue(batchCtx, overrideKey{}, override)
+	}
+	timeout := batch.Timeout
+	if timeout <= 0 && batch.Build != nil {
+		timeout = batch.Build.TotalTimeout
+	}
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return context.WithTimeout(batchCtx, timeout)
+}
+
+// send 发送批量请求并分发结果,请求不随单次调用的ctx取消,发送前已取消的调用不发送
+func (batch *AppBatchBuild) send(key int, items []*batchItem) {
+	active := items[:0:0]
+	for _, item := range items {
+		if item.ctx.Err() == nil {
+			active = append(active, item)
+		}
+	}
+	if len(active) == 0 {
+		return
+	}
+	items = active
+	params := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		params = append(params, item.param)
+	}
+	first := items[0]
+	ctx, cancel := batch.batchContext(first.ctx)
+	defer cancel()
+	res := batch.Build.BuildRequest(ctx, first.client, key, params, nil).JsonResult()
+	if err := res.Err(); err != nil {
+		for _, item := range items {
+			item.fail(err)
+		}
+		return
+	}
+	path := batch.ItemsPath
+	if len(path) == 0 {
+		path = "data"
+	}
+	list, err := res.GetArray(path)
+	for i, item := range items {
+		if err != nil {
+			item.fail(err)
+		} else if i >= len(list) {
+			item.fail(NewRestClientError(ErrPathNotExists, fmt.Sprintf("batch result index %d not exists", i)))
+		} else {
+			item.result <- NewRestBodyResult(batch, list[i].Raw, nil, nil)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package rest_client
+
+import (
+	"context"
+	"github.com/tidwall/gjson"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAppBatchBuild(t *testing.T) {
+	var lock sync.Mutex
+	var sizes []int
+	server := newTestAppServer(func(_ string, content gjson.Result) string {
+		items := content.Array()
+		lock.Lock()
+		sizes = append(sizes, len(items))
+		lock.Unlock()
+		out := "["
+		for i, item := range items {
+			if i > 0 {
+				out += ","
+			}
+			out += `{"id":` + item.Get("id").Raw + `}`
+		}
+		return out + "]"
+	})
+	defer server.Close()
+	batch := NewAppBatchBuild(&AppRestBuild{Method: "bulk"})
+	batch.MaxSize = 3
+	batch.Wait = 20 * time.Millisecond
+	client := newTestAppClient(server.URL, map[int]RestBuild{1: batch})
+	futures := make([]*RestFuture, 0, 5)
+	for i := 0; i < 5; i++ {
+		futures = append(futures, client.DoFuture(context.Background(), 1, map[string]int{"id": i}))
+	}
+	results, err := WaitAll(context.Background(), futures...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, res := range results {
+		if id := res.JsonResult().MustInt("id"); id != int64(i) {
+			t.Error("batch result wrong:" + strconv.Itoa(int(id)))
+		}
+	}
+	if len(sizes) != 2 || sizes[0]+sizes[1] != 5 {
+		t.Error("batch size wrong", sizes)
+	}
+}
+
+func TestAppBatchBuildIdentity(t *testing.T) {
+	var lock sync.Mutex
+	var sizes []int
+	server := newTestAppServer(func(_ string, content gjson.Result) string {
+		lock.Lock()
+		sizes = append(sizes, len(content.Array()))
+		lock.Unlock()
+		return content.Raw
+	})
+	defer server.Close()
+	batch := NewAppBatchBuild(&AppRestBuild{Method: "bulk"})
+	batch.Wait = 20 * time.Millisecond
+	client := newTestAppClient(server.URL, map[int]RestBuild{1: batch})
+	canceled, cancel := context.WithCancel(context.Background())
+	contexts := []context.Context{
+		context.Background(),
+		WithTenant(context.Background(), "t1"),
+		WithHeader(context.Background(), "X-Source", "a"),
+		WithHeader(context.Background(), "X-Source", "a"),
+		canceled,
+	}
+	futures := make([]*RestFuture, 0, len(contexts))
+	for i, ctx := range contexts {
+		futures = append(futures, client.DoFuture(ctx, 1, map[string]int{"id": i}))
+	}
+	cancel()
+	for i, future := range futures {
+		res, err := future.Get(context.Background())
+		if err == nil {
+			err = res.Err()
+		}
+		if i == len(futures)-1 {
+			if err == nil {
+				t.Error("canceled call must fail")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.JsonResult().MustInt("id") != int64(i) {
+			t.Error("batch result wrong", i)
+		}
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	//未设置租户的调用与已取消的调用在同一队列,已取消的不发送
+	if len(sizes) != 3 || sizes[0]+sizes[1]+sizes[2] != 4 {
+		t.Error("batch queue identity wrong", sizes)
+	}
+}
+
+type testBatchEvent struct {
+	RestEventNoop
+	starts int32
+}
+
+func (event *testBatchEvent) RequestStart(_, _ string) {
+	atomic.AddInt32(&event.starts, 1)
+}
+
+func TestAppBatchBuildContext(t *testing.T) {
+	server := newTestAppServer(func(_ string, content gjson.Result) string {
+		if content.Get("0.id").Int() == 1 {
+			time.Sleep(200 * time.Millisecond)
+		}
+		return content.Raw
+	})
+	defer server.Close()
+	batch := NewAppBatchBuild(&AppRestBuild{Method: "bulk"})
+	batch.Timeout = 50 * time.Millisecond
+	client := newTestAppClient(server.URL, map[int]RestBuild{1: batch})
+	//批量请求不使用第一个调用设置的事件
+	event := &testBatchEvent{}
+	res := <-client.Do(WithEvent(context.Background(), event), 1, map[string]int{"id": 0})
+	if err := res.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt32(&event.starts) != 0 {
+		t.Error("batch request must not use caller event")
+	}
+	//批量请求超过超时时间后失败
+	start := time.Now()
+	res = <-client.Do(context.Background(), 1, map[string]int{"id": 1})
+	if err, ok := res.Err().(*RestClientError); !ok || err.Code != ErrCanceled {
+		t.Error("batch timeout must fail", res.Err())
+	}
+	if time.Since(start) > 150*time.Millisecond {
+		t.Error("batch timeout not applied")
+	}
+}
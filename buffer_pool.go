@@ -0,0 +1,30 @@
+package rest_client
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPoolBuffer 超过此容量的缓冲不放回池中,避免长期占用大块内存
+const maxPoolBuffer = 1024 * 1024
+
+var restBufferPool = sync.Pool{
+	New: func() interface{} {
+		return new(bytes.Buffer)
+	},
+}
+
+// getBuffer 从池中获取缓冲
+func getBuffer() *bytes.Buffer {
+	buf := restBufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	return buf
+}
+
+// putBuffer 缓冲使用完放回池中,放回后不能再使用其内容
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPoolBuffer {
+		return
+	}
+	restBufferPool.Put(buf)
+}
@@ -0,0 +1,14 @@
+package rest_client
+
+import (
+	"testing"
+)
+
+func TestBufferPool(t *testing.T) {
+	buf := getBuffer()
+	buf.WriteString("data")
+	putBuffer(buf)
+	if get := getBuffer(); get.Len() != 0 {
+		t.Error("pool buffer not reset")
+	}
+}
@@ -0,0 +1,85 @@
+package rest_client
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// restBuildsCache 注册的接口定义缓存
+type restBuildsCache struct {
+	value atomic.Value //map[int]RestBuild,失效后为nil
+	lock  sync.Mutex
+}
+
+// restApiRegistry 按 RestApi 类型缓存的 RestClient
+type restApiRegistry struct {
+	lock    sync.RWMutex
+	clients map[reflect.Type]*RestClient
+}
+
+// RegisterApi 创建缓存接口定义的 RestClient 并按 api 的类型注册到管理器,已注册的同类型被替换
+// ConfigBuilds 仅在首次请求及 InvalidateBuilds 后调用,适用于接口定义不随ctx变化的服务
+func (c *RestClientManager) RegisterApi(api RestApi) *RestClient {
+	return c.apis.register(c, api, true)
+}
+
+// GetApi 获取 api 类型已注册的 RestClient,未注册时按 api 注册
+// 同类型共用首次注册的 api,TOKEN等随实例变化的接口需使用 NewApi
+func (c *RestClientManager) GetApi(api RestApi) *RestClient {
+	typ := reflect.TypeOf(api)
+	c.apis.lock.RLock()
+	client, ok := c.apis.clients[typ]
+	c.apis.lock.RUnlock()
+	if ok {
+		return client
+	}
+	return c.apis.register(c, api, false)
+}
+
+// register 注册 api,replace 为false时已注册的类型返回原有的 RestClient
+func (registry *restApiRegistry) register(manager *RestClientManager, api RestApi, replace bool) *RestClient {
+	typ := reflect.TypeOf(api)
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+	if client, ok := registry.clients[typ]; ok && !replace {
+		return client
+	}
+	if registry.clients == nil {
+		registry.clients = map[reflect.Type]*RestClient{}
+	}
+	client := manager.NewApi(api)
+	client.builds = &restBuildsCache{}
+	registry.clients[typ] = client
+	return client
+}
+
+// InvalidateBuilds 清除缓存的接口定义,下次请求时重新调用 ConfigBuilds,未通过 RegisterApi 创建时无作用
+func (client *RestClient) InvalidateBuilds() {
+	if client.builds != nil {
+		client.builds.value.Store(map[int]RestBuild(nil))
+	}
+}
+
+// configBuilds 获取接口定义,获取失败时不缓存
+func (client *RestClient) configBuilds(ctx context.Context) (map[int]RestBuild, error) {
+	cache := client.builds
+	if cache == nil {
+		return client.Api.ConfigBuilds(ctx)
+	}
+	if builds, _ := cache.value.Load().(map[int]RestBuild); builds != nil {
+		return builds, nil
+	}
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	if builds, _ := cache.value.Load().(map[int]RestBuild); builds != nil {
+		return builds, nil
+	}
+	builds, err := client.Api.ConfigBuilds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cache.value.Store(builds)
+	return builds, nil
+}
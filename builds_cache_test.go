@@ -0,0 +1,109 @@
+package rest_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testCountApi 记录 ConfigBuilds 调用次数
+type testCountApi struct {
+	calls int32
+	fail  bool
+}
+
+func (api *testCountApi) ConfigBuilds(_ context.Context) (map[int]RestBuild, error) {
+	atomic.AddInt32(&api.calls, 1)
+	if api.fail {
+		return nil, errors.New("builds error")
+	}
+	builds := make(map[int]RestBuild, 10)
+	for i := 0; i < 10; i++ {
+		builds[i] = &AppRestBuild{HttpMethod: http.MethodPost, Path: "/product", Method: "detail", Timeout: time.Second}
+	}
+	return builds, nil
+}
+
+func (api *testCountApi) ConfigName(_ context.Context) (string, error) {
+	return "test", nil
+}
+
+func TestRegisterApi(t *testing.T) {
+	manager := NewRestClientManager()
+	api := &testCountApi{fail: true}
+	client := manager.RegisterApi(api)
+	if _, err := client.configBuilds(context.Background()); err == nil {
+		t.Error("builds error not return")
+	}
+	api.fail = false
+	for i := 0; i < 3; i++ {
+		if builds, err := client.configBuilds(context.Background()); err != nil || len(builds) != 10 {
+			t.Error("cached builds error")
+		}
+	}
+	if api.calls != 2 {
+		t.Error("builds not cached", api.calls)
+	}
+	client.InvalidateBuilds()
+	_, _ = client.configBuilds(context.Background())
+	if api.calls != 3 {
+		t.Error("builds not invalidated", api.calls)
+	}
+
+	plain := manager.NewApi(api)
+	plain.InvalidateBuilds()
+	_, _ = plain.configBuilds(context.Background())
+	_, _ = plain.configBuilds(context.Background())
+	if api.calls != 5 {
+		t.Error("NewApi should not cache builds", api.calls)
+	}
+}
+
+func BenchmarkRestClientBuilds(b *testing.B) {
+	manager := NewRestClientManager()
+	for name, client := range map[string]*RestClient{
+		"NewApi":      manager.NewApi(&testCountApi{}),
+		"RegisterApi": manager.RegisterApi(&testCountApi{}),
+	} {
+		b.Run(name, func(b *testing.B) {
+			ctx := context.Background()
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_, _ = client.configBuilds(ctx)
+				}
+			})
+		})
+	}
+}
+
+func TestGetApi(t *testing.T) {
+	manager := NewRestClientManager()
+	first := &testCountApi{}
+	var wg sync.WaitGroup
+	clients := make([]*RestClient, 10)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i] = manager.GetApi(&testCountApi{})
+		}(i)
+	}
+	wg.Wait()
+	for _, client := range clients {
+		if client != clients[0] || client.builds == nil {
+			t.Fatal("GetApi not cached")
+		}
+	}
+	if manager.GetApi(&testBuildApi{}) == clients[0] {
+		t.Error("GetApi type key error")
+	}
+	registered := manager.RegisterApi(first)
+	if registered == clients[0] || manager.GetApi(&testCountApi{}) != registered || registered.Api != first {
+		t.Error("RegisterApi not replace")
+	}
+}
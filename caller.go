@@ -0,0 +1,88 @@
+package rest_client
+
+import (
+	"context"
+	"errors"
+)
+
+type callerKey struct{}
+
+type callerTagsKey struct{}
+
+// WithCallerTag 设置调用方标签,如功能名称,请求ID,随调用方信息传递到事件,审计记录及错误,用于按业务功能归集对外请求
+func WithCallerTag(ctx context.Context, key, value string) context.Context {
+	prev, _ := ctx.Value(callerTagsKey{}).(map[string]string)
+	tags := make(map[string]string, len(prev)+1)
+	for k, v := range prev {
+		tags[k] = v
+	}
+	tags[key] = value
+	return context.WithValue(ctx, callerTagsKey{}, tags)
+}
+
+// contextCallerTags 获取设置的调用方标签,返回的map不可修改
+func contextCallerTags(ctx context.Context) map[string]string {
+	tags, _ := ctx.Value(callerTagsKey{}).(map[string]string)
+	return tags
+}
+
+func withCaller(ctx context.Context, caller *RestCallerInfo) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// Caller 获取发起请求的调用方信息,可在 EventCreate 中使用,非 Do 发起的请求返回nil
+func Caller(ctx context.Context) *RestCallerInfo {
+	caller, _ := ctx.Value(callerKey{}).(*RestCallerInfo)
+	return caller
+}
+
+// ErrorCaller 获取请求错误的调用方信息,非请求返回的错误返回nil
+func ErrorCaller(err error) *RestCallerInfo {
+	var aErr *AppClientError
+	if errors.As(err, &aErr) {
+		return aErr.caller
+	}
+	var rErr *RestClientError
+	if errors.As(err, &rErr) {
+		return rErr.caller
+	}
+	return nil
+}
+
+// withCallerError 复制错误并附加调用方信息,错误可能在多个请求间共用,不修改原错误
+func withCallerError(err error, caller *RestCallerInfo) error {
+	if caller == nil {
+		return err
+	}
+	switch tErr := err.(type) {
+	case *RestClientError:
+		if tErr.caller == nil {
+			copied := *tErr
+			copied.caller = caller
+			return &copied
+		}
+	case *AppClientError:
+		if tErr.caller == nil {
+			copied := *tErr
+			copied.caller = caller
+			return &copied
+		}
+	}
+	return err
+}
+
+// Caller 请求的调用方信息,非 Do 发起的请求返回nil
+func (res *RestResult) Caller() *RestCallerInfo {
+	if res == nil {
+		return nil
+	}
+	return res.caller
+}
+
+// jsonError 记录 JsonResult 的错误并附加调用方信息及诊断信息
+func (res *RestResult) jsonError(err error) *JsonResult {
+	res.err = withCallerError(err, res.caller)
+	result := NewJsonResultFromError(res.err)
+	result.diagnostics = res.Diagnostics()
+	return result
+}
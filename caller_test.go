@@ -0,0 +1,57 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRestCallerInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"result":{"code":"500","state":"fail"}}`))
+	}))
+	defer server.Close()
+	var eventCaller *RestCallerInfo
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:      "test",
+		AppKey:    "dome1",
+		AppSecret: "dome111111",
+		AppUrl:    server.URL,
+		EventCreate: func(ctx context.Context) RestEvent {
+			eventCaller = Caller(ctx)
+			return &RestEventNoop{}
+		},
+	})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{1: &AppRestBuild{}}})
+	ctx := WithCallerTag(WithCallerTag(context.Background(), "feature", "order"), "request_id", "r1")
+	res := <-client.Do(ctx, 1, nil)
+	caller := res.Caller()
+	if caller == nil || !strings.HasSuffix(caller.FileName, "caller_test.go") || !strings.Contains(caller.FuncName, "TestRestCallerInfo") {
+		t.Fatal("caller wrong", caller)
+	}
+	if caller.Tags["feature"] != "order" || caller.Tags["request_id"] != "r1" {
+		t.Error("caller tags wrong", caller.Tags)
+	}
+	if eventCaller != caller || !strings.Contains(caller.String(), "caller_test.go:") {
+		t.Error("event caller wrong", eventCaller)
+	}
+	err := res.JsonResult().Err()
+	if err == nil || ErrorCaller(err) != caller {
+		t.Error("error caller wrong", err)
+	}
+	if err := (<-client.Do(ctx, 2, nil)).Err(); ErrorCode(err) != ErrApiNotFound || ErrorCaller(err) == nil {
+		t.Error("not found caller wrong", err)
+	}
+	if res, _ := client.DoFuture(ctx, 1, nil).Get(ctx); res == nil || !strings.HasSuffix(res.Caller().FileName, "caller_test.go") {
+		t.Error("future caller must skip package frames", res.Caller())
+	}
+	if res, _ := client.DoSync(ctx, 1, nil); res == nil || !strings.Contains(res.Caller().FuncName, "TestRestCallerInfo") {
+		t.Error("sync caller must skip package frames", res.Caller())
+	}
+	if ErrorCaller(NewRestClientError(ErrApiNotFound, "a")) != nil || Caller(context.Background()) != nil {
+		t.Error("caller without request")
+	}
+}
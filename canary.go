@@ -0,0 +1,73 @@
+package rest_client
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// AppRestCanary 灰度路由配置,按比例或请求标记将请求发送到灰度地址,用于网关迁移等逐步切换流量
+type AppRestCanary struct {
+	Url     string  //灰度地址,路由到灰度时替代 AppUrl 及 Balancer
+	Percent float64 //路由到灰度地址的比例,0-100
+	lock    sync.Mutex
+	stable  RestCanaryVariant
+	canary  RestCanaryVariant
+}
+
+// RestCanaryVariant 灰度或正常地址的请求统计
+type RestCanaryVariant struct {
+	Requests int64         //请求数
+	Errors   int64         //失败数,包含服务端5xx
+	Latency  time.Duration //累计耗时,除以请求数为平均耗时
+}
+
+// RestCanaryStats 灰度路由统计
+type RestCanaryStats struct {
+	Stable RestCanaryVariant
+	Canary RestCanaryVariant
+}
+
+type canaryKey struct{}
+
+// WithCanary 标记请求是否发送到灰度地址,优先于按比例路由
+func WithCanary(ctx context.Context, canary bool) context.Context {
+	return context.WithValue(ctx, canaryKey{}, canary)
+}
+
+// route 本次请求是否路由到灰度地址
+func (canary *AppRestCanary) route(ctx context.Context) bool {
+	if canary == nil || len(canary.Url) == 0 {
+		return false
+	}
+	if flag, ok := ctx.Value(canaryKey{}).(bool); ok {
+		return flag
+	}
+	return canary.Percent > 0 && rand.Float64()*100 < canary.Percent
+}
+
+// report 记录请求结果
+func (canary *AppRestCanary) report(isCanary bool, latency time.Duration, err error) {
+	if canary == nil {
+		return
+	}
+	canary.lock.Lock()
+	defer canary.lock.Unlock()
+	variant := &canary.stable
+	if isCanary {
+		variant = &canary.canary
+	}
+	variant.Requests++
+	variant.Latency += latency
+	if err != nil {
+		variant.Errors++
+	}
+}
+
+// Stats 获取灰度及正常地址的请求统计
+func (canary *AppRestCanary) Stats() RestCanaryStats {
+	canary.lock.Lock()
+	defer canary.lock.Unlock()
+	return RestCanaryStats{Stable: canary.stable, Canary: canary.canary}
+}
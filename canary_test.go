@@ -0,0 +1,41 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAppRestCanary(t *testing.T) {
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"name":"` + name + `"}`))
+		}))
+	}
+	stable := newServer("stable")
+	defer stable.Close()
+	canaryServer := newServer("canary")
+	defer canaryServer.Close()
+	canary := &AppRestCanary{Url: canaryServer.URL, Percent: 30}
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{Name: "test", AppUrl: stable.URL, Canary: canary})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true},
+	}})
+	count := map[string]int{}
+	for i := 0; i < 200; i++ {
+		count[(<-client.Do(context.Background(), 1, nil)).JsonResult().MustString("name")]++
+	}
+	if count["canary"] < 20 || count["canary"] > 100 || count["stable"]+count["canary"] != 200 {
+		t.Error("canary percent error", count)
+	}
+	if (<-client.Do(WithCanary(context.Background(), true), 1, nil)).JsonResult().MustString("name") != "canary" ||
+		(<-client.Do(WithCanary(context.Background(), false), 1, nil)).JsonResult().MustString("name") != "stable" {
+		t.Error("canary context flag error")
+	}
+	stats := canary.Stats()
+	if stats.Canary.Requests != int64(count["canary"]+1) || stats.Stable.Requests != int64(count["stable"]+1) || stats.Stable.Errors != 0 {
+		t.Error("canary stats error", stats)
+	}
+}
@@ -0,0 +1,92 @@
+package rest_client
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+// RestCertPins SPKI证书固定,用于CA可能被攻破的高安全合作方,证书链中任一证书的公钥与任一 Pin 一致时通过
+// 轮换证书时先同时配置新旧证书的 Pin,对方更换证书后再移除旧 Pin
+type RestCertPins struct {
+	//base64编码的证书公钥SHA-256,可带 sha256/ 前缀
+	//openssl x509 -in cert.pem -pubkey -noout | openssl pkey -pubin -outform der | openssl dgst -sha256 -binary | base64
+	Pins []string
+	//校验失败回调,如发送告警,可以为nil,建立连接时回调,不是每个请求都回调
+	OnFail func(err *RestPinError)
+	//只回调 OnFail 不阻断连接,用于上线前验证配置的 Pin
+	ReportOnly bool
+}
+
+// RestPinError 证书固定校验失败
+type RestPinError struct {
+	Host string
+	Pins []string //对方证书链中各证书的 Pin,确认为对方新证书后可加入配置
+}
+
+func (err *RestPinError) Error() string {
+	return "certificate pin mismatch:" + err.Host
+}
+
+// RestPinEvent 可选事件接口,请求因证书固定校验失败而失败时回调,ReportOnly 时不回调
+type RestPinEvent interface {
+	PinFailed(err *RestPinError)
+}
+
+// CertPin 计算证书的 Pin
+func CertPin(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// verify 校验连接的证书链,已校验证书链时只使用校验通过的证书,避免对方附带无关证书绕过
+func (pins *RestCertPins) verify(state tls.ConnectionState) error {
+	accept := make(map[string]bool, len(pins.Pins))
+	for _, pin := range pins.Pins {
+		accept[strings.TrimPrefix(pin, "sha256/")] = true
+	}
+	certs := state.PeerCertificates
+	if len(state.VerifiedChains) > 0 {
+		certs = nil
+		for _, chain := range state.VerifiedChains {
+			certs = append(certs, chain...)
+		}
+	}
+	pinErr := &RestPinError{Host: state.ServerName}
+	for _, cert := range certs {
+		pin := CertPin(cert)
+		if accept[pin] {
+			return nil
+		}
+		pinErr.Pins = append(pinErr.Pins, pin)
+	}
+	if pins.OnFail != nil {
+		pins.OnFail(pinErr)
+	}
+	if pins.ReportOnly {
+		return nil
+	}
+	return pinErr
+}
+
+// tlsConfig 校验证书固定的TLS配置
+func (pins *RestCertPins) tlsConfig() *tls.Config {
+	return &tls.Config{
+		VerifyConnection: pins.verify,
+	}
+}
+
+// checkPinError 请求因证书固定校验失败时回调事件
+func checkPinError(err error, event RestEvent) {
+	pEvent, ok := event.(RestPinEvent)
+	if !ok {
+		return
+	}
+	var pinErr *RestPinError
+	if errors.As(err, &pinErr) {
+		pEvent.PinFailed(pinErr)
+	}
+}
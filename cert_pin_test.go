@@ -0,0 +1,71 @@
+package rest_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testPinEvent struct {
+	RestEventNoop
+	errs []*RestPinError
+}
+
+func (event *testPinEvent) PinFailed(err *RestPinError) {
+	event.errs = append(event.errs, err)
+}
+
+func TestRestCertPins(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	pin := CertPin(server.Certificate())
+	for _, item := range []struct {
+		pins       []string
+		reportOnly bool
+		ok         bool
+		fails      int
+	}{
+		{[]string{"old", "sha256/" + pin}, false, true, 0},
+		{[]string{"old"}, false, false, 1},
+		{[]string{"old"}, true, true, 1},
+	} {
+		var fails []*RestPinError
+		transport := NewRestTransport(&RestTransportConfig{
+			DisableKeepAlives: true,
+			CertPins: &RestCertPins{
+				Pins:       item.pins,
+				ReportOnly: item.reportOnly,
+				OnFail: func(err *RestPinError) {
+					fails = append(fails, err)
+				},
+			},
+		})
+		transport.TLSClientConfig.RootCAs = server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+		event := &testPinEvent{}
+		manager := NewRestClientManager(transport)
+		manager.SetRestConfig(&AppRestConfig{Name: "test", AppUrl: server.URL, EventCreate: func(_ context.Context) RestEvent {
+			return NewRestCompositeEvent(event, &RestEventNoop{})
+		}})
+		client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+			1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true},
+		}})
+		err := (<-client.Do(context.Background(), 1, nil)).JsonResult().Err()
+		if (err == nil) != item.ok || len(fails) != item.fails {
+			t.Error("cert pin wrong", item.pins, err, len(fails))
+		}
+		if item.fails > 0 && (len(fails[0].Pins) == 0 || fails[0].Pins[0] != pin) {
+			t.Error("pin error must contain server pin", fails[0].Pins)
+		}
+		var pinErr *RestPinError
+		if !item.ok && (!errors.As(err, &pinErr) || len(event.errs) != 1 || event.errs[0] != pinErr) {
+			t.Error("pin event wrong", err, len(event.errs))
+		}
+		if item.ok && len(event.errs) != 0 {
+			t.Error("pin event must not call")
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package rest_client
+
+import (
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/transform"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// charsetBody 返回内容非UTF-8时转为UTF-8,并修改 Content-Type 中的 charset
+// @param charset 指定的编码,为空时从 Content-Type 获取,无法识别的编码不转换
+func charsetBody(res *http.Response, charset string) {
+	contentType := res.Header.Get("Content-Type")
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if len(charset) == 0 {
+		if err != nil {
+			return
+		}
+		charset = params["charset"]
+	}
+	charset = strings.ToLower(strings.TrimSpace(charset))
+	if len(charset) == 0 || charset == "utf-8" || charset == "utf8" {
+		return
+	}
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return
+	}
+	if name, _ := htmlindex.Name(enc); name == "utf-8" {
+		return
+	}
+	res.Body = &charsetReader{
+		Reader: transform.NewReader(res.Body, enc.NewDecoder()),
+		body:   res.Body,
+	}
+	//长度已变化
+	res.ContentLength = -1
+	res.Header.Del("Content-Length")
+	if len(mediaType) > 0 {
+		if params == nil {
+			params = map[string]string{}
+		}
+		params["charset"] = "utf-8"
+		res.Header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+	}
+}
+
+// charsetReader 转码后的返回内容
+type charsetReader struct {
+	*transform.Reader
+	body interface{ Close() error }
+}
+
+func (read *charsetReader) Close() error {
+	return read.body.Close()
+}
@@ -0,0 +1,34 @@
+package rest_client
+
+import (
+	"context"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAppRestCharset(t *testing.T) {
+	body, _ := simplifiedchinese.GBK.NewEncoder().String(`{"result":{"code":"200","state":"ok"},"data":{"name":"回收宝"}}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/header" {
+			w.Header().Set("Content-Type", "application/json; charset=GBK")
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{Path: "/header"},
+		2: &AppRestBuild{Path: "/none", Charset: "gb2312"},
+	})
+	for key := 1; key <= 2; key++ {
+		res := <-client.Do(context.Background(), key, nil)
+		_, header := res.Header()
+		if name := res.JsonResult().MustString("data.name"); name != "回收宝" {
+			t.Error("charset convert wrong:" + name)
+		}
+		if key == 1 && header.Get("Content-Type") != "application/json; charset=utf-8" {
+			t.Error("content type not change:" + header.Get("Content-Type"))
+		}
+	}
+}
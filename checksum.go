@@ -0,0 +1,123 @@
+package rest_client
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+// AppChecksumAlgorithm 请求内容校验算法
+type AppChecksumAlgorithm int
+
+const (
+	ChecksumMD5    AppChecksumAlgorithm = iota //Content-MD5: base64(MD5),默认
+	ChecksumSHA256                             //Digest: SHA-256=base64(SHA-256)
+)
+
+// AppRestChecksum 请求及返回内容校验,用于文件及结算等对完整性要求高的接口
+// 请求内容为 io.Reader 时先读取到内存计算校验
+type AppRestChecksum struct {
+	Algorithm AppChecksumAlgorithm //请求内容校验算法
+	Request   bool                 //请求内容设置校验HEADER
+	//返回内容存在 Content-MD5,Digest(MD5 SHA-256) 或MD5形式的 ETag 时校验,不匹配时读取结束返回 ErrChecksum
+	Response bool
+}
+
+// requestHeader 计算请求内容校验HEADER,读取后的内容重新放回 reader
+func (checksum *AppRestChecksum) requestHeader(body io.Reader) (http.Header, error) {
+	reader, ok := body.(*RestRequestReader)
+	if checksum == nil || !checksum.Request || !ok || reader.reader == nil {
+		return nil, nil
+	}
+	data, err := ioutil.ReadAll(reader.reader)
+	if err != nil {
+		return nil, err
+	}
+	reader.reader = bytes.NewReader(data)
+	reader.total = int64(len(data))
+	header := http.Header{}
+	if checksum.Algorithm == ChecksumSHA256 {
+		sum := sha256.Sum256(data)
+		header.Set("Digest", "SHA-256="+base64.StdEncoding.EncodeToString(sum[:]))
+	} else {
+		sum := md5.Sum(data)
+		header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+	}
+	return header, nil
+}
+
+// responseChecksum 返回HEADER中的校验算法及十六进制期望值,无可用校验时返回nil
+func responseChecksum(res *http.Response) (hash.Hash, string) {
+	if val := res.Header.Get("Content-MD5"); len(val) > 0 {
+		if b, err := base64.StdEncoding.DecodeString(val); err == nil {
+			return md5.New(), hex.EncodeToString(b)
+		}
+	}
+	for _, digest := range strings.Split(res.Header.Get("Digest"), ",") {
+		i := strings.IndexByte(digest, '=')
+		if i < 0 {
+			continue
+		}
+		b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(digest[i+1:]))
+		if err != nil {
+			continue
+		}
+		switch strings.ToUpper(strings.TrimSpace(digest[:i])) {
+		case "SHA-256":
+			return sha256.New(), hex.EncodeToString(b)
+		case "MD5":
+			return md5.New(), hex.EncodeToString(b)
+		}
+	}
+	//部分内容的 ETag 为完整内容的校验
+	if res.StatusCode == http.StatusOK {
+		if match := restEtagMd5.FindStringSubmatch(res.Header.Get("ETag")); match != nil {
+			return md5.New(), strings.ToLower(match[1])
+		}
+	}
+	return nil, ""
+}
+
+// verifyBody 按返回HEADER校验返回内容,需在转换编码前调用
+func (checksum *AppRestChecksum) verifyBody(res *http.Response) {
+	if checksum == nil || !checksum.Response || res.Uncompressed {
+		return
+	}
+	if res.StatusCode == http.StatusNotModified || res.StatusCode == http.StatusNoContent ||
+		(res.Request != nil && res.Request.Method == http.MethodHead) {
+		return
+	}
+	sum, expect := responseChecksum(res)
+	if sum == nil {
+		return
+	}
+	res.Body = &restChecksumBody{ReadCloser: res.Body, sum: sum, expect: expect}
+}
+
+// restChecksumBody 读取时计算校验,读取完毕时不匹配返回错误
+type restChecksumBody struct {
+	io.ReadCloser
+	sum    hash.Hash
+	expect string
+}
+
+func (body *restChecksumBody) Read(p []byte) (int, error) {
+	n, err := body.ReadCloser.Read(p)
+	if n > 0 {
+		body.sum.Write(p[:n])
+	}
+	if err == io.EOF {
+		if get := hex.EncodeToString(body.sum.Sum(nil)); get != body.expect {
+			return n, NewRestClientError(ErrChecksum, fmt.Sprintf("response checksum mismatch,expect:%s get:%s", body.expect, get))
+		}
+	}
+	return n, err
+}
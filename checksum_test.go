@@ -0,0 +1,76 @@
+package rest_client
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAppRestChecksum(t *testing.T) {
+	body := `{"result":{"code":"200","state":"ok"},"data":{"amount":100}}`
+	md5Sum := md5.Sum([]byte(body))
+	sha256Sum := sha256.Sum256([]byte(body))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		//校验请求内容
+		reqMd5, reqSha256 := md5.Sum(data), sha256.Sum256(data)
+		if val := r.Header.Get("Content-MD5"); len(val) > 0 && val != base64.StdEncoding.EncodeToString(reqMd5[:]) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if val := r.Header.Get("Digest"); len(val) > 0 && val != "SHA-256="+base64.StdEncoding.EncodeToString(reqSha256[:]) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.URL.Path != "/bad" && len(r.Header.Get("Content-MD5"))+len(r.Header.Get("Digest")) == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/md5":
+			w.Header().Set("Content-MD5", base64.StdEncoding.EncodeToString(md5Sum[:]))
+		case "/digest":
+			w.Header().Set("Digest", "SHA-256="+base64.StdEncoding.EncodeToString(sha256Sum[:]))
+		case "/etag":
+			w.Header().Set("ETag", `"`+hex.EncodeToString(md5Sum[:])+`"`)
+		case "/bad":
+			w.Header().Set("Content-MD5", base64.StdEncoding.EncodeToString(sha256Sum[:16]))
+		case "/bad_digest":
+			w.Header().Set("Digest", "SHA-256="+base64.StdEncoding.EncodeToString(md5Sum[:]))
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	checksum := &AppRestChecksum{Request: true, Response: true}
+	sha256Checksum := &AppRestChecksum{Algorithm: ChecksumSHA256, Request: true, Response: true}
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{Path: "/md5", Method: "a", Checksum: checksum},
+		2: &AppRestBuild{Path: "/digest", Method: "a", Checksum: sha256Checksum},
+		3: &AppRestBuild{Path: "/etag", Raw: true, Checksum: checksum},
+		4: &AppRestBuild{Path: "/bad", Method: "a", Checksum: checksum},
+		5: &AppRestBuild{Path: "/bad_digest", Raw: true, Checksum: sha256Checksum},
+		6: &AppRestBuild{Path: "/bad", Method: "a"},
+		7: &AppRestBuild{Path: "/none", Raw: true, Checksum: checksum},
+	})
+	for _, key := range []int{1, 2, 3, 6, 7} {
+		res := (<-client.Do(context.Background(), key, strings.NewReader(`{"a":"b"}`))).JsonResult()
+		if res.Err() != nil || res.MustInt("data.amount") != 100 {
+			t.Error("checksum should pass", key, res.Err())
+		}
+	}
+	for _, key := range []int{4, 5} {
+		err := (<-client.Do(context.Background(), key, map[string]string{"a": "b"})).JsonResult().Err()
+		var restErr *RestClientError
+		if !errors.As(err, &restErr) || restErr.Code != ErrChecksum {
+			t.Error("checksum mismatch error", key, err)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package rest_client
+
+import (
+	"errors"
+	"fmt"
+	"github.com/tidwall/gjson"
+	"net/http"
+)
+
+// RestResultClass 请求结果分类,用于判断是否可重试等
+type RestResultClass int
+
+const (
+	ResultSuccess   RestResultClass = iota //成功
+	ResultRetryable                        //可重试的错误,如服务端5xx,限流
+	ResultFatal                            //不可重试的错误,如参数错误
+	ResultAuth                             //鉴权错误,如令牌过期,可刷新令牌后重试
+)
+
+func (class RestResultClass) String() string {
+	switch class {
+	case ResultSuccess:
+		return "success"
+	case ResultRetryable:
+		return "retryable"
+	case ResultFatal:
+		return "fatal"
+	case ResultAuth:
+		return "auth"
+	}
+	return fmt.Sprintf("class(%d)", int(class))
+}
+
+// RestClassifier 按HTTP状态码及返回内容对结果分类,非成功时返回的错误可以为nil
+type RestClassifier func(httpCode int, body string) (RestResultClass, error)
+
+// RestResultClassifier 实现该接口的 RestBuild 检测结果时替代 CheckJsonResult
+type RestResultClassifier interface {
+	ClassifyResult(httpCode int, body string) error
+}
+
+// RestClassError 带分类的结果错误
+type RestClassError struct {
+	Class RestResultClass
+	Err   error
+}
+
+func (err *RestClassError) Error() string {
+	return fmt.Sprintf("%s: %s", err.Class, err.Err.Error())
+}
+
+func (err *RestClassError) Unwrap() error {
+	return err.Err
+}
+
+// ClassifyError 获取错误分类
+// 未分类的错误中,ctx取消及参数错误为不可重试,其他客户端错误(如网络错误,超时)及服务端5xx为可重试
+func ClassifyError(err error) RestResultClass {
+	if err == nil {
+		return ResultSuccess
+	}
+	var cErr *RestClassError
+	if errors.As(err, &cErr) {
+		return cErr.Class
+	}
+	var appErr *AppClientError
+	if errors.As(err, &appErr) {
+		return ResultFatal
+	}
+	var paramErr *AppParamError
+	if errors.As(err, &paramErr) {
+		return ResultFatal
+	}
+	var rErr *RestClientError
+	if errors.As(err, &rErr) {
+		switch rErr.Code {
+		case ErrDownload, ErrServerHttp, ErrFaultDrop, ErrQueueFull, ErrTimeout:
+			return ResultRetryable
+		}
+		return ResultFatal
+	}
+	return ResultRetryable
+}
+
+// ClassifyHttpCode 按HTTP状态码分类,401 403 为鉴权错误,408 429 及5xx为可重试
+func ClassifyHttpCode(httpCode int) RestResultClass {
+	switch {
+	case httpCode >= 200 && httpCode < 300:
+		return ResultSuccess
+	case httpCode == http.StatusUnauthorized || httpCode == http.StatusForbidden:
+		return ResultAuth
+	case httpCode == http.StatusRequestTimeout || httpCode == http.StatusTooManyRequests || httpCode >= 500:
+		return ResultRetryable
+	}
+	return ResultFatal
+}
+
+// AppEnvelopeClassifier 内部网关返回格式的分类
+// 先按HTTP状态码分类,再检测 result.code,其中 401 403 为鉴权错误,5xx为可重试
+func AppEnvelopeClassifier(httpCode int, body string) (RestResultClass, error) {
+	if class := ClassifyHttpCode(httpCode); class != ResultSuccess {
+		return class, NewRestClientError(ErrServerHttp, fmt.Sprintf("server http code:%d", httpCode))
+	}
+	code := gjson.Get(body, "result.code").String()
+	state := gjson.Get(body, "result.state").String()
+	if code == "200" && state == "ok" {
+		return ResultSuccess, nil
+	}
+	msg := gjson.Get(body, "result.message").String()
+	if len(msg) == 0 {
+		msg = body
+	}
+	err := NewAppClientError(code, state, "server return fail:"+msg)
+	switch {
+	case code == "401" || code == "403":
+		return ResultAuth, err
+	case len(code) == 3 && code[0] == '5':
+		return ResultRetryable, err
+	}
+	return ResultFatal, err
+}
+
+// ClassifyResult 设置了 Classifier 时按其分类,否则使用 CheckJsonResult
+func (clt *AppRestBuild) ClassifyResult(httpCode int, body string) error {
+	if clt.Classifier == nil {
+		return clt.CheckJsonResult(body)
+	}
+	class, err := clt.Classifier(httpCode, body)
+	if class == ResultSuccess {
+		return nil
+	}
+	if err == nil {
+		err = NewRestClientError(ErrServerHttp, fmt.Sprintf("server http code:%d", httpCode))
+	}
+	return &RestClassError{Class: class, Err: err}
+}
+
+// checkResult 检测返回内容
+func (res *RestResult) checkResult(body string) error {
+	if classify, ok := res.build.(RestResultClassifier); ok {
+		httpCode := http.StatusOK
+		if res.response != nil {
+			httpCode = res.response.StatusCode
+		}
+		return classify.ClassifyResult(httpCode, body)
+	}
+	if check, ok := res.build.(RestJsonResult); ok {
+		return check.CheckJsonResult(body)
+	}
+	return nil
+}
+
+// needCheck 是否需要检测返回内容
+func (res *RestResult) needCheck() bool {
+	if _, ok := res.build.(RestResultClassifier); ok {
+		return true
+	}
+	_, ok := res.build.(RestJsonResult)
+	return ok
+}
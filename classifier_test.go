@@ -0,0 +1,60 @@
+package rest_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAppEnvelopeClassifier(t *testing.T) {
+	cases := []struct {
+		httpCode int
+		body     string
+		class    RestResultClass
+	}{
+		{200, `{"result":{"code":"200","state":"ok"}}`, ResultSuccess},
+		{200, `{"result":{"code":"401","state":"token"}}`, ResultAuth},
+		{200, `{"result":{"code":"503","state":"busy"}}`, ResultRetryable},
+		{200, `{"result":{"code":"400","state":"param"}}`, ResultFatal},
+		{429, ``, ResultRetryable},
+		{403, ``, ResultAuth},
+		{404, ``, ResultFatal},
+	}
+	for _, item := range cases {
+		if class, _ := AppEnvelopeClassifier(item.httpCode, item.body); class != item.class {
+			t.Errorf("classify error:%d %s %s", item.httpCode, item.body, class)
+		}
+	}
+	if ClassifyError(nil) != ResultSuccess || ClassifyError(errors.New("net")) != ResultRetryable ||
+		ClassifyError(cancelError(context.Canceled)) != ResultFatal || ClassifyError(NewAppClientError("400", "", "")) != ResultFatal {
+		t.Error("classify error wrong")
+	}
+}
+
+func TestAppRestBuildClassifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/busy" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_, _ = w.Write([]byte(`{"result":{"code":"401","state":"token"}}`))
+	}))
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{Path: "/auth", Classifier: AppEnvelopeClassifier},
+		2: &AppRestBuild{Path: "/busy", Classifier: AppEnvelopeClassifier},
+		3: &AppRestBuild{Path: "/auth"},
+	})
+	err := (<-client.Do(context.Background(), 1, nil)).JsonResult().Err()
+	var appErr *AppClientError
+	if ClassifyError(err) != ResultAuth || !errors.As(err, &appErr) || appErr.Code != "401" {
+		t.Error("auth class error")
+	}
+	if err = (<-client.Do(context.Background(), 2, nil)).JsonResult().Err(); ClassifyError(err) != ResultRetryable {
+		t.Error("retryable class error")
+	}
+	if err = (<-client.Do(context.Background(), 3, nil)).JsonResult().Err(); !errors.As(err, &appErr) {
+		t.Error("default check error")
+	}
+}
@@ -0,0 +1,153 @@
+package rest_client
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// AppRestClockSkew 时钟偏差修正,网关因 timestamp 偏差拒绝请求时,从错误内容或返回的 Date HEADER 获取服务端时间,
+// 之后的签名使用修正后的时间,偏差小于 MinOffset 时取消修正
+type AppRestClockSkew struct {
+	MaxOffset time.Duration //最大修正,超过时按此值修正,默认10分钟
+	MinOffset time.Duration //偏差小于此值时不修正,Date HEADER 精度为秒,默认2秒
+	//是否为时间偏差导致的失败,为nil时请求失败即检测偏差
+	Detect func(err error, body []byte) bool
+	//从错误内容获取服务端时间,为nil或获取失败时使用 Date HEADER
+	ServerTime func(body []byte) (time.Time, bool)
+	//检测到偏差时回调,用于上报监控,offset 为服务端时间减本地时间,可以为nil
+	OnSkew      func(offset time.Duration)
+	offset      int64
+	observed    int64
+	maxObserved int64
+}
+
+// AppClockSkewStats 时钟偏差统计
+type AppClockSkewStats struct {
+	Offset      time.Duration //当前使用的修正
+	Observed    int64         //检测到偏差的次数
+	MaxObserved time.Duration //检测到的最大偏差,绝对值
+}
+
+// Offset 当前使用的修正,为nil时为0
+func (skew *AppRestClockSkew) Offset() time.Duration {
+	if skew == nil {
+		return 0
+	}
+	return time.Duration(atomic.LoadInt64(&skew.offset))
+}
+
+// Stats 获取偏差统计
+func (skew *AppRestClockSkew) Stats() AppClockSkewStats {
+	return AppClockSkewStats{
+		Offset:      skew.Offset(),
+		Observed:    atomic.LoadInt64(&skew.observed),
+		MaxObserved: time.Duration(atomic.LoadInt64(&skew.maxObserved)),
+	}
+}
+
+// observe 记录服务端时间,按偏差更新修正
+func (skew *AppRestClockSkew) observe(serverTime, localTime time.Time) {
+	offset := serverTime.Sub(localTime)
+	abs := offset
+	if abs < 0 {
+		abs = -abs
+	}
+	minOffset := skew.MinOffset
+	if minOffset <= 0 {
+		minOffset = 2 * time.Second
+	}
+	maxOffset := skew.MaxOffset
+	if maxOffset <= 0 {
+		maxOffset = 10 * time.Minute
+	}
+	if abs < minOffset {
+		atomic.StoreInt64(&skew.offset, 0)
+		return
+	}
+	atomic.AddInt64(&skew.observed, 1)
+	for {
+		max := atomic.LoadInt64(&skew.maxObserved)
+		if int64(abs) <= max || atomic.CompareAndSwapInt64(&skew.maxObserved, max, int64(abs)) {
+			break
+		}
+	}
+	if skew.OnSkew != nil {
+		skew.OnSkew(offset)
+	}
+	if offset > maxOffset {
+		offset = maxOffset
+	} else if offset < -maxOffset {
+		offset = -maxOffset
+	}
+	atomic.StoreInt64(&skew.offset, int64(offset))
+}
+
+// event 创建检测偏差的事件,同时回调 next
+func (skew *AppRestClockSkew) event(next RestEvent) RestEvent {
+	return &restClockSkewEvent{restEventForward: restEventForward{next}, skew: skew, next: next}
+}
+
+// appTimestamp 签名使用的时间,设置时钟偏差修正时加上修正
+func appTimestamp(config *AppRestConfig) string {
+	return time.Now().Add(config.ClockSkew.Offset()).Format("2006-01-02 15:04:05")
+}
+
+// restClockSkewEvent 记录返回时间及内容,检测返回失败时的时钟偏差
+type restClockSkewEvent struct {
+	restEventForward
+	skew     *AppRestClockSkew
+	next     RestEvent
+	received time.Time
+	date     string
+	body     []byte
+	checked  bool
+}
+
+func (event *restClockSkewEvent) RequestStart(method, url string) {
+	event.next.RequestStart(method, url)
+}
+func (event *restClockSkewEvent) RequestRead(p []byte) {
+	event.next.RequestRead(p)
+}
+func (event *restClockSkewEvent) ResponseHeader(httpCode int, header map[string][]string) {
+	event.received = time.Now()
+	event.date = http.Header(header).Get("Date")
+	event.next.ResponseHeader(httpCode, header)
+}
+func (event *restClockSkewEvent) ResponseRead(p []byte) {
+	if (event.skew.Detect != nil || event.skew.ServerTime != nil) && len(event.body) < 4096 {
+		event.body = append(event.body, p...)
+	}
+	event.next.ResponseRead(p)
+}
+func (event *restClockSkewEvent) ResponseFinish(err error) {
+	event.next.ResponseFinish(err)
+}
+func (event *restClockSkewEvent) ResponseCheck(err error) {
+	if err != nil && !event.checked && !event.received.IsZero() {
+		event.checked = true
+		event.detect(err)
+	}
+	event.next.ResponseCheck(err)
+}
+
+func (event *restClockSkewEvent) detect(err error) {
+	if event.skew.Detect != nil && !event.skew.Detect(err, event.body) {
+		return
+	}
+	event.skew.observeResponse(event.date, event.body, event.received)
+}
+
+// observeResponse 从错误内容或 Date HEADER 获取服务端时间并更新修正
+func (skew *AppRestClockSkew) observeResponse(date string, body []byte, received time.Time) {
+	if skew.ServerTime != nil {
+		if serverTime, ok := skew.ServerTime(body); ok {
+			skew.observe(serverTime, received)
+			return
+		}
+	}
+	if serverTime, err := http.ParseTime(date); err == nil {
+		skew.observe(serverTime, received)
+	}
+}
@@ -0,0 +1,84 @@
+package rest_client
+
+import (
+	"context"
+	"github.com/tidwall/gjson"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestAppRestClockSkew(t *testing.T) {
+	//服务端时间快5分钟,时间偏差超过1分钟时拒绝
+	serverOffset := 5 * time.Minute
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		now := time.Now().Add(serverOffset)
+		w.Header().Set("Date", now.UTC().Format(http.TimeFormat))
+		timestamp, _ := time.ParseInLocation("2006-01-02 15:04:05", r.Form.Get("timestamp"), time.Local)
+		if diff := now.Sub(timestamp); diff > time.Minute || diff < -time.Minute {
+			_, _ = w.Write([]byte(`{"result":{"code":"401","message":"timestamp expired"},"data":{"server_time":` + strconv.FormatInt(now.Unix(), 10) + `}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":{}}`))
+	}))
+	defer server.Close()
+	call := func(skew *AppRestClockSkew) error {
+		manager := NewRestClientManager()
+		manager.SetRestConfig(&AppRestConfig{
+			Name:      "test",
+			AppKey:    "dome1",
+			AppSecret: "dome111111",
+			AppUrl:    server.URL,
+			ClockSkew: skew,
+		})
+		client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{1: &AppRestBuild{Method: "a.b"}}})
+		return (<-client.Do(context.Background(), 1, map[string]string{"a": "b"})).JsonResult().Err()
+	}
+
+	//Date HEADER
+	var observed time.Duration
+	skew := &AppRestClockSkew{OnSkew: func(offset time.Duration) {
+		observed = offset
+	}}
+	if call(skew) == nil {
+		t.Fatal("first request should reject")
+	}
+	if err := call(skew); err != nil {
+		t.Error("skew should apply to next request", err)
+	}
+	if observed < 4*time.Minute || skew.Stats().Observed != 1 || skew.Stats().MaxObserved != observed {
+		t.Error("skew stats error", observed, skew.Stats())
+	}
+
+	//错误内容中的服务端时间,只处理偏差错误
+	skew = &AppRestClockSkew{
+		Detect: func(_ error, body []byte) bool {
+			return gjson.GetBytes(body, "result.code").String() == "401"
+		},
+		ServerTime: func(body []byte) (time.Time, bool) {
+			sec := gjson.GetBytes(body, "data.server_time").Int()
+			return time.Unix(sec, 0), sec > 0
+		},
+	}
+	_ = call(skew)
+	if err := call(skew); err != nil || skew.Offset() < 4*time.Minute {
+		t.Error("skew from body error", err, skew.Offset())
+	}
+
+	//超过最大修正
+	skew = &AppRestClockSkew{MaxOffset: 2 * time.Minute}
+	_ = call(skew)
+	if skew.Offset() != 2*time.Minute || call(skew) == nil {
+		t.Error("skew max offset error", skew.Offset())
+	}
+
+	//服务端时间恢复后取消修正
+	serverOffset = 0
+	_ = call(skew)
+	if skew.Offset() != 0 || call(skew) != nil {
+		t.Error("skew should reset", skew.Offset())
+	}
+}
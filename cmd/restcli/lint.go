@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"github.com/hsbteam/rest_client"
+	"io"
+	"os"
+	"sort"
+)
+
+// cliLintApi 配置文件中一个服务配置的全部接口,KEY按接口名称排序
+type cliLintApi struct {
+	name   string
+	builds map[int]rest_client.RestBuild
+}
+
+func (api *cliLintApi) ConfigBuilds(_ context.Context) (map[int]rest_client.RestBuild, error) {
+	return api.builds, nil
+}
+
+func (api *cliLintApi) ConfigName(_ context.Context) (string, error) {
+	return api.name, nil
+}
+
+// runLint 检查配置文件中的服务配置及接口,存在错误级别的问题时返回错误
+func runLint(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("restcli lint", flag.ContinueOnError)
+	file := flags.String("f", os.Getenv("RESTCLI_CONFIG"), "配置文件,YAML或JSON,默认读取环境变量 RESTCLI_CONFIG")
+	name := flags.String("config", "", "只检查该服务配置,默认检查全部")
+	dial := flags.Duration("dial", 0, "大于0时检测服务地址是否可连接,适用于开发环境")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if len(*file) == 0 {
+		return fmt.Errorf("-f is required")
+	}
+	configs, err := loadConfigs(*file)
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(configs))
+	for configName := range configs {
+		if len(*name) == 0 || configName == *name {
+			names = append(names, configName)
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("config %s not found", *name)
+	}
+	sort.Strings(names)
+
+	manager := rest_client.NewRestClientManager()
+	defer manager.Close()
+	apis := make([]rest_client.RestApi, 0, len(names))
+	methods := map[string][]string{}
+	for _, configName := range names {
+		config := configs[configName]
+		applyEnv(configName, &config)
+		manager.SetRestConfig(&rest_client.AppRestConfig{
+			Name:      configName,
+			AppKey:    config.AppKey,
+			AppSecret: config.AppSecret,
+			AppUrl:    config.AppUrl,
+		})
+		for method := range config.Apis {
+			methods[configName] = append(methods[configName], method)
+		}
+		sort.Strings(methods[configName])
+		api := &cliLintApi{name: configName, builds: map[int]rest_client.RestBuild{}}
+		for key, method := range methods[configName] {
+			build, err := config.Apis[method].restBuild(method)
+			if err != nil {
+				return err
+			}
+			api.builds[key] = build
+		}
+		apis = append(apis, api)
+	}
+
+	findings := manager.Lint(context.Background(), &rest_client.RestLintOption{DialTimeout: *dial}, apis...)
+	for _, find := range findings {
+		api := ""
+		if find.Key >= 0 && find.Key < len(methods[find.ConfigName]) {
+			api = " api:" + methods[find.ConfigName][find.Key]
+		}
+		fmt.Fprintf(stdout, "[%s] %s config:%s%s %s\n", find.Level, find.Rule, find.ConfigName, api, find.Message)
+	}
+	if rest_client.HasLintError(findings) {
+		return fmt.Errorf("lint found errors")
+	}
+	fmt.Fprintf(stdout, "lint: %d configs, %d findings\n", len(names), len(findings))
+	return nil
+}
@@ -0,0 +1,302 @@
+// Command restcli 从终端调用接口,打印签名后的请求及解析后的返回,用于排查签名及返回格式问题
+//
+//	restcli -f rest.yaml -config product -method detail '{"id":"111"}'
+//	REST_PRODUCT_APP_URL=http://127.0.0.1:8080 REST_PRODUCT_APP_KEY=hjx REST_PRODUCT_APP_SECRET=xxx \
+//		restcli -config product -http GET -path /jp/product -method detail -d @param.json
+//
+// 配置文件为YAML或JSON,KEY为服务配置名,apis 中按接口名称设置路径等,命令行参数优先:
+//
+//	product:
+//	  app_url: http://127.0.0.1:8080
+//	  app_key: hjx
+//	  app_secret: f4dea3417a2f52ae29a635be00537395
+//	  headers: {X-Env: dev}
+//	  default_params: {channel: cli}
+//	  apis:
+//	    detail: {path: /jp/product, http_method: GET, timeout: 3s}
+//
+// 环境变量 REST_<配置名>_APP_URL REST_<配置名>_APP_KEY REST_<配置名>_APP_SECRET REST_<配置名>_TOKEN 覆盖配置文件
+//
+// lint 子命令检查配置文件中的服务配置及接口,存在错误级别的问题时退出码非0,用于CI:
+//
+//	restcli lint -f rest.yaml -dial 1s
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/hsbteam/rest_client"
+	"gopkg.in/yaml.v3"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// cliConfig 配置文件中的服务配置
+type cliConfig struct {
+	AppUrl        string                 `yaml:"app_url"`
+	AppKey        string                 `yaml:"app_key"`
+	AppSecret     string                 `yaml:"app_secret"`
+	Token         *string                `yaml:"token"`
+	Headers       map[string]string      `yaml:"headers"`
+	DefaultParams map[string]interface{} `yaml:"default_params"`
+	Apis          map[string]cliBuild    `yaml:"apis"`
+}
+
+// cliBuild 配置文件中的接口
+type cliBuild struct {
+	Path       string `yaml:"path"`
+	HttpMethod string `yaml:"http_method"`
+	Raw        bool   `yaml:"raw"`
+	Timeout    string `yaml:"timeout"`
+}
+
+// restBuild 转为接口定义
+func (def cliBuild) restBuild(method string) (*rest_client.AppRestBuild, error) {
+	build := &rest_client.AppRestBuild{Method: method, Path: def.Path, HttpMethod: def.HttpMethod, Raw: def.Raw}
+	if len(def.Timeout) > 0 {
+		buildTimeout, err := time.ParseDuration(def.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("api %s timeout error:%w", method, err)
+		}
+		build.ResponseHeaderTimeout = buildTimeout
+	}
+	return build, nil
+}
+
+// cliApi 按命令行参数生成的接口定义
+type cliApi struct {
+	name  string
+	build *rest_client.AppRestBuild
+}
+
+func (api *cliApi) ConfigBuilds(_ context.Context) (map[int]rest_client.RestBuild, error) {
+	return map[int]rest_client.RestBuild{0: api.build}, nil
+}
+
+func (api *cliApi) ConfigName(_ context.Context) (string, error) {
+	return api.name, nil
+}
+
+// cliTokenApi 设置了TOKEN的接口定义
+type cliTokenApi struct {
+	cliApi
+	token string
+}
+
+func (api *cliTokenApi) Token(_ context.Context) (string, error) {
+	return api.token, nil
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "restcli:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) > 0 && args[0] == "lint" {
+		return runLint(args[1:], stdout)
+	}
+	flags := flag.NewFlagSet("restcli", flag.ContinueOnError)
+	file := flags.String("f", os.Getenv("RESTCLI_CONFIG"), "配置文件,YAML或JSON,默认读取环境变量 RESTCLI_CONFIG")
+	name := flags.String("config", "", "服务配置名")
+	method := flags.String("method", "", "接口名称,签名参数中的 method")
+	path := flags.String("path", "", "接口路径,未设置时使用配置文件中该接口的路径")
+	httpMethod := flags.String("http", "", "请求方式,默认POST")
+	raw := flags.Bool("raw", false, "不使用签名格式")
+	token := flags.String("token", "", "TOKEN,设置后参与签名")
+	timeout := flags.Duration("timeout", 30*time.Second, "请求超时")
+	data := flags.String("d", "", "JSON参数,@文件 从文件读取,- 从标准输入读取,也可作为最后一个参数传入")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if len(*name) == 0 {
+		return fmt.Errorf("-config is required")
+	}
+	if len(*data) == 0 && flags.NArg() > 0 {
+		*data = flags.Arg(0)
+	}
+
+	config := cliConfig{}
+	if len(*file) > 0 {
+		configs, err := loadConfigs(*file)
+		if err != nil {
+			return err
+		}
+		config = configs[*name]
+	}
+	applyEnv(*name, &config)
+	if len(*token) > 0 {
+		config.Token = token
+	}
+	if len(config.AppUrl) == 0 {
+		return fmt.Errorf("app url of config %s is empty", *name)
+	}
+
+	build, err := config.Apis[*method].restBuild(*method)
+	if err != nil {
+		return err
+	}
+	if len(*path) > 0 {
+		build.Path = *path
+	}
+	if len(*httpMethod) > 0 {
+		build.HttpMethod = *httpMethod
+	}
+	build.Raw = build.Raw || *raw
+
+	param, err := readParam(*data, stdin)
+	if err != nil {
+		return err
+	}
+
+	manager := rest_client.NewRestClientManager()
+	defer manager.Close()
+	manager.SetRestConfig(&rest_client.AppRestConfig{
+		Name:          *name,
+		AppKey:        config.AppKey,
+		AppSecret:     config.AppSecret,
+		AppUrl:        config.AppUrl,
+		Headers:       config.Headers,
+		DefaultParams: config.DefaultParams,
+		EventCreate: func(_ context.Context) rest_client.RestEvent {
+			return rest_client.NewAppRestEvent(func(method string, reqUrl string, httpCode int, _ map[string][]string, request []byte, response []byte, err error) {
+				printRequest(stdout, method, reqUrl, request, config.AppSecret, build.Raw)
+				if httpCode > 0 {
+					fmt.Fprintf(stdout, "< %d\n< %s\n", httpCode, response)
+				}
+				if err != nil {
+					fmt.Fprintf(stdout, "< error: %s\n", err)
+				}
+			})
+		},
+	})
+	var api rest_client.RestApi = &cliApi{name: *name, build: build}
+	if config.Token != nil {
+		api = &cliTokenApi{cliApi: cliApi{name: *name, build: build}, token: *config.Token}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	res, err := manager.NewApi(api).DoSync(ctx, 0, param)
+	if err != nil {
+		return err
+	}
+	result := res.JsonResult()
+	if err = result.Err(); err != nil {
+		fmt.Fprintf(stdout, "result: %s\n", err)
+		return err
+	}
+	fmt.Fprintln(stdout, "result: ok")
+	fmt.Fprintf(stdout, "timings: %s\n", res.Timings())
+	if !build.Raw {
+		if data := result.GetData("data"); data.Err() == nil && data.Exists() {
+			printJson(stdout, "data:", []byte(data.Raw))
+		}
+	}
+	return nil
+}
+
+// loadConfigs 读取配置文件,KEY为服务配置名
+func loadConfigs(file string) (map[string]cliConfig, error) {
+	body, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	configs := map[string]cliConfig{}
+	if err = yaml.Unmarshal(body, &configs); err != nil {
+		return nil, err
+	}
+	return configs, nil
+}
+
+// applyEnv 环境变量覆盖配置文件
+func applyEnv(name string, config *cliConfig) {
+	prefix := "REST_" + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name)) + "_"
+	for env, val := range map[string]*string{
+		"APP_URL":    &config.AppUrl,
+		"APP_KEY":    &config.AppKey,
+		"APP_SECRET": &config.AppSecret,
+	} {
+		if set, ok := os.LookupEnv(prefix + env); ok {
+			*val = set
+		}
+	}
+	if token, ok := os.LookupEnv(prefix + "TOKEN"); ok {
+		config.Token = &token
+	}
+}
+
+// readParam 读取JSON参数,为空时不传参数
+func readParam(data string, stdin io.Reader) (interface{}, error) {
+	var body []byte
+	var err error
+	switch {
+	case len(data) == 0:
+		return nil, nil
+	case data == "-":
+		body, err = ioutil.ReadAll(stdin)
+	case strings.HasPrefix(data, "@"):
+		body, err = ioutil.ReadFile(data[1:])
+	default:
+		body = []byte(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var param interface{}
+	if err = json.Unmarshal(body, &param); err != nil {
+		return nil, fmt.Errorf("param is not json:%w", err)
+	}
+	return param, nil
+}
+
+// printRequest 打印请求,签名格式时逐项打印签名参数及签名原文
+func printRequest(out io.Writer, method, reqUrl string, request []byte, secret string, raw bool) {
+	fmt.Fprintf(out, "> %s %s\n", method, reqUrl)
+	if raw {
+		if len(request) > 0 {
+			fmt.Fprintf(out, "> %s\n", request)
+		}
+		return
+	}
+	form := url.Values{}
+	if parsed, err := url.Parse(reqUrl); err == nil {
+		form = parsed.Query()
+	}
+	if body, err := url.ParseQuery(string(request)); err == nil {
+		for key, val := range body {
+			form[key] = val
+		}
+	}
+	for _, key := range []string{"app", "method", "version", "timestamp", "token", "content", "sign"} {
+		if _, ok := form[key]; ok {
+			fmt.Fprintf(out, ">   %s: %s\n", key, form.Get(key))
+		}
+	}
+	sign := form.Get("sign")
+	form.Del("sign")
+	//签名原文为按KEY排序的参数拼接密钥后MD5
+	fmt.Fprintf(out, "> sign base: %s + <app_secret>\n", form.Encode())
+	form.Set("sign", sign)
+	if len(secret) > 0 && !rest_client.AppRestCheckSign(form, secret) {
+		fmt.Fprintln(out, "> sign check: fail")
+	}
+}
+
+func printJson(out io.Writer, title string, data []byte) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		buf.Reset()
+		buf.Write(data)
+	}
+	fmt.Fprintf(out, "%s\n%s\n", title, buf.String())
+}
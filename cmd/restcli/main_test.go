@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"github.com/hsbteam/rest_client"
+	"github.com/hsbteam/rest_client/resttest"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRestCli(t *testing.T) {
+	api := &cliApi{name: "product", build: &rest_client.AppRestBuild{HttpMethod: http.MethodGet, Path: "/jp/product", Method: "detail"}}
+	server, err := resttest.NewApiServer(context.Background(), api, &rest_client.AppRestConfig{Name: "product", AppKey: "hjx", AppSecret: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	server.SetFixture(0, &resttest.Fixture{Data: map[string]interface{}{"id": "111"}})
+
+	file := filepath.Join(t.TempDir(), "rest.yaml")
+	config := "product:\n  app_url: " + server.URL + "\n  app_key: hjx\n  app_secret: wrong\n  apis:\n    detail: {path: /jp/product, http_method: GET}\n"
+	if err = ioutil.WriteFile(file, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("REST_PRODUCT_APP_SECRET", "secret")
+	defer os.Unsetenv("REST_PRODUCT_APP_SECRET")
+
+	var out bytes.Buffer
+	if err = run([]string{"-f", file, "-config", "product", "-method", "detail", `{"id":"111"}`}, nil, &out); err != nil {
+		t.Fatal(err, out.String())
+	}
+	for _, find := range []string{"> GET " + server.URL + "/jp/product?", `>   content: {"id":"111"}`, "> sign base: app=hjx&content=", "< 200", "result: ok", `"id": "111"`} {
+		if !strings.Contains(out.String(), find) {
+			t.Error("output miss:" + find + "\n" + out.String())
+		}
+	}
+	if strings.Contains(out.String(), "sign check: fail") {
+		t.Error("env secret not used")
+	}
+
+	out.Reset()
+	os.Setenv("REST_PRODUCT_APP_SECRET", "wrong")
+	if err = run([]string{"-f", file, "-config", "product", "-method", "detail", "-d", "-"}, strings.NewReader(`{"id":"1"}`), &out); err == nil {
+		t.Error("sign error not return")
+	}
+	if !strings.Contains(out.String(), "result:") || !strings.Contains(out.String(), "sign error") {
+		t.Error("sign error output wrong:" + out.String())
+	}
+
+	if err = run([]string{"-config", "none"}, nil, &out); err == nil {
+		t.Error("empty url not error")
+	}
+	if err = run([]string{"-f", file, "-config", "product", "-method", "detail", "{"}, nil, &out); err == nil {
+		t.Error("bad json not error")
+	}
+}
+
+func TestRestCliLint(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rest.yaml")
+	config := "product:\n  app_url: http://127.0.0.1:8080\n  app_key: hjx\n  app_secret: secret\n  apis:\n" +
+		"    detail: {path: /jp/product, http_method: GET, timeout: 3s}\n    list: {http_method: GET, timeout: 3s}\n" +
+		"order:\n  app_url: http://127.0.0.1:8080\n  apis:\n    create: {path: /order, timeout: 3s}\n"
+	if err := ioutil.WriteFile(file, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := run([]string{"lint", "-f", file, "-config", "product"}, nil, &out); err != nil {
+		t.Fatal(err, out.String())
+	}
+	if !strings.Contains(out.String(), "empty-path config:product api:list") {
+		t.Error("lint warning output wrong:" + out.String())
+	}
+	out.Reset()
+	if err := run([]string{"lint", "-f", file}, nil, &out); err == nil || !strings.Contains(out.String(), "empty-secret config:order") {
+		t.Error("lint error not return:" + out.String())
+	}
+	if err := run([]string{"lint", "-f", file, "-config", "none"}, nil, &out); err == nil {
+		t.Error("missing config not error")
+	}
+}
@@ -0,0 +1,64 @@
+// Command restgen 按 OpenAPI 文档或YAML服务定义生成 RestApi 实现代码,可用于 go:generate
+//
+//	//go:generate go run github.com/hsbteam/rest_client/cmd/restgen -openapi product.yaml -package product -name ProductApi -config product -o product_gen.go
+//	//go:generate go run github.com/hsbteam/rest_client/cmd/restgen -yaml product_api.yaml -o product_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/hsbteam/rest_client/restgen"
+	"io/ioutil"
+	"os"
+)
+
+func main() {
+	openApi := flag.String("openapi", "", "OpenAPI 3 文档路径,YAML或JSON")
+	yamlDef := flag.String("yaml", "", "YAML服务定义路径,与 -openapi 二选一")
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "生成代码的包名,go:generate 时默认为当前包")
+	name := flag.String("name", "", "服务结构名,如 ProductApi")
+	config := flag.String("config", "", "服务配置名")
+	timeout := flag.Duration("timeout", 0, "接口未设置 x-timeout 时的超时时间")
+	out := flag.String("o", "", "输出文件,为空时输出到标准输出")
+	flag.Parse()
+
+	if err := run(*openApi, *yamlDef, *out, &restgen.OpenApiOption{Package: *pkg, Name: *name, ConfigName: *config, Timeout: *timeout}); err != nil {
+		fmt.Fprintln(os.Stderr, "restgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(openApi, yamlDef, out string, option *restgen.OpenApiOption) error {
+	var api *restgen.Api
+	switch {
+	case len(openApi) > 0 && len(yamlDef) > 0:
+		return fmt.Errorf("-openapi and -yaml can not be used together")
+	case len(openApi) > 0:
+		data, err := ioutil.ReadFile(openApi)
+		if err != nil {
+			return err
+		}
+		if api, err = restgen.ParseOpenApi(data, option); err != nil {
+			return err
+		}
+	case len(yamlDef) > 0:
+		data, err := ioutil.ReadFile(yamlDef)
+		if err != nil {
+			return err
+		}
+		if api, err = restgen.ParseYaml(data, option.Package); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("-openapi or -yaml is required")
+	}
+	code, err := restgen.Generate(api)
+	if err != nil {
+		return err
+	}
+	if len(out) == 0 {
+		_, err = os.Stdout.Write(code)
+		return err
+	}
+	return ioutil.WriteFile(out, code, 0644)
+}
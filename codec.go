@@ -0,0 +1,129 @@
+package rest_client
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"mime"
+	"strings"
+)
+
+// RestCodec 请求参数及返回内容的编解码,如 msgpack protobuf,实现见 restcodec 包
+type RestCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	ContentType() string
+}
+
+// JsonCodec JSON编解码,未设置 Codec 时的默认方式
+type JsonCodec struct{}
+
+func (JsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (JsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (JsonCodec) ContentType() string {
+	return "application/json"
+}
+
+// isJsonCodec 编码结果是否为JSON文本
+func isJsonCodec(codec RestCodec) bool {
+	return strings.Contains(codec.ContentType(), "json")
+}
+
+// codecParamEncoder 使用 Codec 编码参数,signed 时非JSON的二进制内容以base64放入 content
+type codecParamEncoder struct {
+	codec  RestCodec
+	signed bool
+}
+
+func (enc *codecParamEncoder) EncodeParam(param interface{}) (string, error) {
+	data, err := enc.codec.Marshal(param)
+	if err != nil {
+		return "", err
+	}
+	if enc.signed && !isJsonCodec(enc.codec) {
+		return base64.StdEncoding.EncodeToString(data), nil
+	}
+	return string(data), nil
+}
+
+// paramEncoder 接口使用的参数编码,ParamEncoder 优先于 Codec
+func (clt *AppRestBuild) paramEncoder() ParamEncoder {
+	if clt.ParamEncoder != nil || clt.Codec == nil {
+		return clt.ParamEncoder
+	}
+	return &codecParamEncoder{codec: clt.Codec, signed: !clt.Raw}
+}
+
+// RestCodec 返回内容解码使用的编解码,实现 restCodecBuild
+func (clt *AppRestBuild) RestCodec() RestCodec {
+	return clt.Codec
+}
+
+// restCodecBuild 设置了编解码的接口
+type restCodecBuild interface {
+	RestCodec() RestCodec
+}
+
+// resultCodec 返回内容对应的编解码,返回的 Content-Type 与接口 Codec 一致时使用该 Codec,否则为nil
+func (res *RestResult) resultCodec() RestCodec {
+	build, ok := res.build.(restCodecBuild)
+	if !ok || res.response == nil {
+		return nil
+	}
+	codec := build.RestCodec()
+	if codec == nil || isJsonCodec(codec) {
+		return nil
+	}
+	resType, _, _ := mime.ParseMediaType(res.response.Header.Get("Content-Type"))
+	codecType, _, _ := mime.ParseMediaType(codec.ContentType())
+	if resType != codecType {
+		return nil
+	}
+	return codec
+}
+
+// transcode 非JSON的返回内容转为JSON,使 JsonResult 及返回检测可直接使用
+func (res *RestResult) transcode(body string) (string, error) {
+	codec := res.resultCodec()
+	if codec == nil {
+		return body, nil
+	}
+	var data interface{}
+	if err := codec.Unmarshal([]byte(body), &data); err != nil {
+		return "", &RestClientError{Code: ErrCodec, Msg: "decode result fail:" + err.Error(), err: err}
+	}
+	jsonBody, err := json.Marshal(data)
+	if err != nil {
+		return "", &RestClientError{Code: ErrCodec, Msg: "transcode result fail:" + err.Error(), err: err}
+	}
+	return string(jsonBody), nil
+}
+
+// Decode 读取全部返回内容并解码到 out,返回的 Content-Type 与接口 Codec 一致时使用该 Codec,否则按JSON解码
+// 不检测签名格式的返回结果,需检测时使用 JsonResult
+func (res *RestResult) Decode(out interface{}) error {
+	if res == nil {
+		return errResultClosed()
+	}
+	if res.err != nil {
+		return res.err
+	}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if _, err := buf.ReadFrom(res); err != nil {
+		return err
+	}
+	codec := res.resultCodec()
+	if codec == nil {
+		codec = JsonCodec{}
+	}
+	if err := codec.Unmarshal(buf.Bytes(), out); err != nil {
+		return &RestClientError{Code: ErrCodec, Msg: "decode result fail:" + err.Error(), err: err}
+	}
+	return nil
+}
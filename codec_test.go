@@ -0,0 +1,110 @@
+package rest_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"github.com/vmihailenco/msgpack/v5"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// testMsgpackCodec 测试用msgpack编解码,restcodec 依赖本包,测试中不能引用
+type testMsgpackCodec struct{}
+
+func (testMsgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	return msgpack.Marshal(v)
+}
+
+func (testMsgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+func (testMsgpackCodec) ContentType() string {
+	return "application/msgpack"
+}
+
+func TestRestCodec(t *testing.T) {
+	var body []byte
+	var content, accept string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		if r.URL.Path == "/raw" {
+			body, _ = ioutil.ReadAll(r.Body)
+		} else {
+			_ = r.ParseForm()
+			content = r.Form.Get("content")
+		}
+		if r.URL.Path == "/json" {
+			_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":{}}`))
+			return
+		}
+		if r.URL.Path == "/bad" {
+			w.Header().Set("Content-Type", "application/msgpack")
+			_, _ = w.Write([]byte{0xc1})
+			return
+		}
+		data, _ := msgpack.Marshal(map[string]interface{}{
+			"result": map[string]interface{}{"code": "200", "state": "ok"},
+			"data":   map[string]interface{}{"id": 1, "name": "goods"},
+		})
+		w.Header().Set("Content-Type", "application/msgpack; charset=binary")
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+	manager := NewRestClientManager()
+	defer manager.Close()
+	manager.SetRestConfig(&AppRestConfig{Name: "test", AppUrl: server.URL})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: NewAppRestBuild("/sign", "product.detail", BuildWithCodec(testMsgpackCodec{})),
+		2: NewAppRestBuild("/raw", "", BuildWithRaw(""), BuildWithCodec(testMsgpackCodec{})),
+		3: NewAppRestBuild("/bad", "product.bad", BuildWithCodec(testMsgpackCodec{})),
+		4: NewAppRestBuild("/json", "product.json"),
+	}})
+	param := map[string]interface{}{"id": 1}
+	expect, _ := msgpack.Marshal(param)
+
+	res, err := client.DoSync(context.Background(), 1, param)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := res.JsonResult()
+	if result.Err() != nil || result.MustString("data.name") != "goods" {
+		t.Error("codec json result error", result.Err())
+	}
+	if data, _ := base64.StdEncoding.DecodeString(content); !bytes.Equal(data, expect) {
+		t.Error("signed content should be base64 codec data")
+	}
+	if accept != "application/msgpack" {
+		t.Error("codec accept header error")
+	}
+
+	res, _ = client.DoSync(context.Background(), 2, param)
+	var out struct {
+		Data struct {
+			Id   int    `msgpack:"id"`
+			Name string `msgpack:"name"`
+		} `msgpack:"data"`
+	}
+	if err = res.Decode(&out); err != nil || out.Data.Id != 1 || out.Data.Name != "goods" {
+		t.Error("codec decode error", err)
+	}
+	if !bytes.Equal(body, expect) {
+		t.Error("raw body should be codec data")
+	}
+
+	res, _ = client.DoSync(context.Background(), 3, param)
+	if err = res.JsonResult().Err(); err == nil || err.(*RestClientError).Code != ErrCodec {
+		t.Error("codec decode fail should return ErrCodec", err)
+	}
+
+	res, _ = client.DoSync(context.Background(), 4, param)
+	if res.JsonResult().Err() != nil || accept != "" {
+		t.Error("json build should not use codec")
+	}
+	if content != `{"id":1}` {
+		t.Error("json build content error", content)
+	}
+}
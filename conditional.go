@@ -0,0 +1,182 @@
+package rest_client
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// RestConditionalEntry 条件请求缓存的返回
+type RestConditionalEntry struct {
+	ETag         string
+	LastModified string
+	Header       http.Header
+	Body         []byte
+}
+
+// RestConditionalStore 条件请求缓存存储,可实现为Redis等共享存储,ctx 为请求的ctx,存储操作需在ctx结束时返回
+type RestConditionalStore interface {
+	Get(ctx context.Context, key string) (*RestConditionalEntry, bool)
+	Set(ctx context.Context, key string, entry *RestConditionalEntry)
+}
+
+// RestConditionalLimit 可选接口,存储可缓存的最大内容长度,未实现时使用默认的1M
+type RestConditionalLimit interface {
+	MaxBody() int64
+}
+
+// defaultConditionalMaxBody 可缓存的最大内容长度,存储未限制时使用
+const defaultConditionalMaxBody = 1024 * 1024
+
+// conditionalMaxBody 可缓存的最大内容长度,超过时不缓存,读取时不再记录内容
+func conditionalMaxBody(store RestConditionalStore) int64 {
+	if limit, ok := store.(RestConditionalLimit); ok && limit.MaxBody() > 0 {
+		return limit.MaxBody()
+	}
+	return defaultConditionalMaxBody
+}
+
+// RestMemoryConditionalStore 进程内的条件请求缓存
+type RestMemoryConditionalStore struct {
+	MaxEntries  int   //最大缓存数,超过时随机淘汰,默认1000
+	MaxBodySize int64 //可缓存的最大内容长度,默认1M
+	lock        sync.RWMutex
+	data        map[string]*RestConditionalEntry
+}
+
+// NewRestMemoryConditionalStore 创建进程内缓存
+func NewRestMemoryConditionalStore() *RestMemoryConditionalStore {
+	return &RestMemoryConditionalStore{
+		MaxEntries:  1000,
+		MaxBodySize: 1024 * 1024,
+	}
+}
+
+func (store *RestMemoryConditionalStore) MaxBody() int64 {
+	return store.MaxBodySize
+}
+
+func (store *RestMemoryConditionalStore) Get(_ context.Context, key string) (*RestConditionalEntry, bool) {
+	store.lock.RLock()
+	defer store.lock.RUnlock()
+	entry, ok := store.data[key]
+	return entry, ok
+}
+
+func (store *RestMemoryConditionalStore) Set(_ context.Context, key string, entry *RestConditionalEntry) {
+	if store.MaxBodySize > 0 && int64(len(entry.Body)) > store.MaxBodySize {
+		return
+	}
+	store.lock.Lock()
+	defer store.lock.Unlock()
+	if store.data == nil {
+		store.data = map[string]*RestConditionalEntry{}
+	}
+	if _, ok := store.data[key]; !ok && store.MaxEntries > 0 && len(store.data) >= store.MaxEntries {
+		for old := range store.data {
+			delete(store.data, old)
+			break
+		}
+	}
+	store.data[key] = entry
+}
+
+// restConditional 单次请求的条件请求信息
+type restConditional struct {
+	ctx   context.Context
+	store RestConditionalStore
+	key   string
+	entry *RestConditionalEntry
+}
+
+// newRestConditional 仅GET HEAD请求使用条件请求,KEY为服务配置,租户,接口及参数,不含签名
+func newRestConditional(ctx context.Context, store RestConditionalStore, configName string, key int, httpMethod string, param interface{}, encoders ...ParamEncoder) *restConditional {
+	if store == nil || (httpMethod != http.MethodGet && httpMethod != http.MethodHead) {
+		return nil
+	}
+	var paramStr string
+	switch body := param.(type) {
+	case QueryParams:
+		paramStr = body.Encode(QueryArrayRepeat)
+	default:
+		var err error
+		if paramStr, err = encodeParam(param, encoders...); err != nil {
+			return nil
+		}
+	}
+	cond := &restConditional{
+		ctx:   ctx,
+		store: store,
+		key:   configName + "|" + TenantFrom(ctx) + "|" + strconv.Itoa(key) + "|" + httpMethod + "|" + paramStr,
+	}
+	cond.entry, _ = store.Get(ctx, cond.key)
+	return cond
+}
+
+// setHeader 设置条件请求HEADER,已通过 WithHeader 设置的不覆盖
+func (cond *restConditional) setHeader(header http.Header) {
+	if cond.entry == nil {
+		return
+	}
+	if len(cond.entry.ETag) > 0 && len(header.Get("If-None-Match")) == 0 {
+		header.Set("If-None-Match", cond.entry.ETag)
+	}
+	if len(cond.entry.LastModified) > 0 && len(header.Get("If-Modified-Since")) == 0 {
+		header.Set("If-Modified-Since", cond.entry.LastModified)
+	}
+}
+
+// response 返回304时使用缓存的内容,返回成功时读取完成后保存
+func (cond *restConditional) response(build RestBuild, res *http.Response, event RestEvent) *RestResult {
+	if res.StatusCode == http.StatusNotModified && cond.entry != nil {
+		_ = res.Body.Close()
+		cached := *res
+		cached.StatusCode = http.StatusOK
+		cached.Status = "200 OK"
+		cached.Header = cond.entry.Header.Clone()
+		cached.Body = http.NoBody
+		return NewRestBodyResult(build, string(cond.entry.Body), &cached, event)
+	}
+	etag, modified := res.Header.Get("ETag"), res.Header.Get("Last-Modified")
+	maxBody := conditionalMaxBody(cond.store)
+	if res.StatusCode == http.StatusOK && (len(etag) > 0 || len(modified) > 0) && res.ContentLength <= maxBody {
+		res.Body = &conditionalBody{
+			ReadCloser: res.Body,
+			cond:       cond,
+			entry:      &RestConditionalEntry{ETag: etag, LastModified: modified, Header: res.Header.Clone()},
+			maxBody:    maxBody,
+		}
+	}
+	return nil
+}
+
+// conditionalBody 读取完成时保存返回内容,超过 maxBody 时放弃缓存
+type conditionalBody struct {
+	io.ReadCloser
+	cond    *restConditional
+	entry   *RestConditionalEntry
+	buf     bytes.Buffer
+	maxBody int64
+}
+
+func (body *conditionalBody) Read(p []byte) (int, error) {
+	n, err := body.ReadCloser.Read(p)
+	if body.entry == nil {
+		return n, err
+	}
+	if int64(body.buf.Len()+n) > body.maxBody {
+		body.entry = nil
+		body.buf = bytes.Buffer{}
+		return n, err
+	}
+	body.buf.Write(p[:n])
+	if err == io.EOF && body.entry != nil {
+		body.entry.Body = body.buf.Bytes()
+		body.cond.store.Set(body.cond.ctx, body.cond.key, body.entry)
+		body.entry = nil
+	}
+	return n, err
+}
@@ -0,0 +1,120 @@
+package rest_client
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// RestRedisClient 使用的 Redis 命令,可适配 go-redis 等客户端
+type RestRedisClient interface {
+	Get(ctx context.Context, key string) ([]byte, error) //KEY不存在时返回nil及nil错误
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// RestRedisConditionalStore Redis 条件请求缓存,多个实例共享下游返回
+// KEY为 Namespace:服务配置:请求摘要,过期时间在 TTL 上随机增加 Jitter 比例,避免同时过期
+type RestRedisConditionalStore struct {
+	Client    RestRedisClient
+	Codec     RestCodec     //缓存内容编码,为nil时使用JSON
+	Namespace string        //KEY前缀,默认 rest_client:conditional
+	TTL       time.Duration //过期时间,默认10分钟
+	Jitter    float64       //过期时间随机增加的比例,如0.1为增加0-10%
+	//可缓存的最大内容长度,默认1M
+	MaxBodySize int64
+	Timeout     time.Duration   //单次 Redis 操作超时,请求ctx剩余时间更短时使用ctx的,默认100ms
+	OnError     func(err error) //Redis 或编码错误回调,错误时视为未缓存,可以为nil
+}
+
+// NewRestRedisConditionalStore 创建 Redis 条件请求缓存
+func NewRestRedisConditionalStore(client RestRedisClient) *RestRedisConditionalStore {
+	return &RestRedisConditionalStore{
+		Client:      client,
+		Namespace:   "rest_client:conditional",
+		TTL:         10 * time.Minute,
+		MaxBodySize: 1024 * 1024,
+		Timeout:     100 * time.Millisecond,
+	}
+}
+
+// redisKey 按服务配置分组的KEY,请求部分使用摘要避免KEY过长
+func (store *RestRedisConditionalStore) redisKey(key string) string {
+	configName := key
+	if i := strings.IndexByte(key, '|'); i >= 0 {
+		configName = key[:i]
+	}
+	sum := sha1.Sum([]byte(key))
+	return store.Namespace + ":" + configName + ":" + hex.EncodeToString(sum[:])
+}
+
+func (store *RestRedisConditionalStore) codec() RestCodec {
+	if store.Codec == nil {
+		return JsonCodec{}
+	}
+	return store.Codec
+}
+
+func (store *RestRedisConditionalStore) MaxBody() int64 {
+	return store.MaxBodySize
+}
+
+// redisContext 单次 Redis 操作的ctx,请求ctx结束时同时结束
+func (store *RestRedisConditionalStore) redisContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if store.Timeout > 0 {
+		return context.WithTimeout(ctx, store.Timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+func (store *RestRedisConditionalStore) onError(err error) {
+	if store.OnError != nil {
+		store.OnError(err)
+	}
+}
+
+// ttl 增加随机比例后的过期时间
+func (store *RestRedisConditionalStore) ttl() time.Duration {
+	ttl := store.TTL
+	if store.Jitter > 0 && ttl > 0 {
+		ttl += time.Duration(rand.Float64() * store.Jitter * float64(ttl))
+	}
+	return ttl
+}
+
+func (store *RestRedisConditionalStore) Get(ctx context.Context, key string) (*RestConditionalEntry, bool) {
+	ctx, cancel := store.redisContext(ctx)
+	defer cancel()
+	data, err := store.Client.Get(ctx, store.redisKey(key))
+	if err != nil {
+		store.onError(err)
+		return nil, false
+	}
+	if data == nil {
+		return nil, false
+	}
+	entry := &RestConditionalEntry{}
+	if err = store.codec().Unmarshal(data, entry); err != nil {
+		store.onError(err)
+		return nil, false
+	}
+	return entry, true
+}
+
+func (store *RestRedisConditionalStore) Set(ctx context.Context, key string, entry *RestConditionalEntry) {
+	if store.MaxBodySize > 0 && int64(len(entry.Body)) > store.MaxBodySize {
+		return
+	}
+	data, err := store.codec().Marshal(entry)
+	if err != nil {
+		store.onError(err)
+		return
+	}
+	ctx, cancel := store.redisContext(ctx)
+	defer cancel()
+	if err = store.Client.Set(ctx, store.redisKey(key), data, store.ttl()); err != nil {
+		store.onError(err)
+	}
+}
@@ -0,0 +1,138 @@
+package rest_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testRedisClient 测试用的 Redis 客户端
+type testRedisClient struct {
+	lock sync.Mutex
+	data map[string][]byte
+	ttl  map[string]time.Duration
+	err  error
+}
+
+func newTestRedisClient() *testRedisClient {
+	return &testRedisClient{data: map[string][]byte{}, ttl: map[string]time.Duration{}}
+}
+
+func (client *testRedisClient) Get(ctx context.Context, key string) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	return client.data[key], client.err
+}
+
+func (client *testRedisClient) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	client.data[key] = value
+	client.ttl[key] = ttl
+	return client.err
+}
+
+func TestRestRedisConditionalStore(t *testing.T) {
+	var full, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":{"id":"a"}}`))
+	}))
+	defer server.Close()
+	redis := newTestRedisClient()
+	newStore := func() *RestRedisConditionalStore {
+		store := NewRestRedisConditionalStore(redis)
+		store.Codec = testMsgpackCodec{}
+		store.Jitter = 0.1
+		return store
+	}
+	//两个实例共享缓存
+	for i := 0; i < 2; i++ {
+		client := newTestAppClient(server.URL, map[int]RestBuild{
+			1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true, Conditional: newStore()},
+		})
+		res := (<-client.Do(context.Background(), 1, QueryParams{"id": "a"})).JsonResult()
+		if res.Err() != nil || res.MustString("data.id") != "a" {
+			t.Error("redis conditional result wrong", res.Err())
+		}
+	}
+	if full != 1 || notModified != 1 {
+		t.Error("redis conditional not shared", full, notModified)
+	}
+	if len(redis.data) != 1 {
+		t.Fatal("redis conditional key error", len(redis.data))
+	}
+	for key, ttl := range redis.ttl {
+		if !strings.HasPrefix(key, "rest_client:conditional:test:") {
+			t.Error("redis conditional key namespace error", key)
+		}
+		if ttl < 10*time.Minute || ttl > 11*time.Minute {
+			t.Error("redis conditional ttl jitter error", ttl)
+		}
+	}
+
+	var errs []error
+	store := newStore()
+	store.OnError = func(err error) {
+		errs = append(errs, err)
+	}
+	redis.err = errors.New("redis down")
+	if _, ok := store.Get(context.Background(), "test|a"); ok || len(errs) != 1 {
+		t.Error("redis error should be miss", errs)
+	}
+	redis.err = nil
+	redis.data[store.redisKey("test|b")] = []byte("bad")
+	if _, ok := store.Get(context.Background(), "test|b"); ok || len(errs) != 2 {
+		t.Error("decode error should be miss", errs)
+	}
+	//使用请求的ctx
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, ok := store.Get(ctx, "test|c"); ok || len(errs) != 3 || !errors.Is(errs[2], context.Canceled) {
+		t.Error("redis get should use request ctx", errs)
+	}
+	if conditionalMaxBody(store) != store.MaxBodySize || conditionalMaxBody(&testConditionalStore{}) != defaultConditionalMaxBody {
+		t.Error("conditional max body accessor wrong")
+	}
+}
+
+func (client *testRedisClient) SetNX(_ context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	if _, ok := client.data[key]; ok {
+		return false, client.err
+	}
+	client.data[key] = value
+	client.ttl[key] = ttl
+	return true, client.err
+}
+
+func (client *testRedisClient) Del(_ context.Context, key string) error {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	delete(client.data, key)
+	return client.err
+}
+
+// testConditionalStore 未实现 RestConditionalLimit 的存储
+type testConditionalStore struct{}
+
+func (store *testConditionalStore) Get(_ context.Context, _ string) (*RestConditionalEntry, bool) {
+	return nil, false
+}
+
+func (store *testConditionalStore) Set(_ context.Context, _ string, _ *RestConditionalEntry) {}
@@ -0,0 +1,68 @@
+package rest_client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAppRestConditional(t *testing.T) {
+	var full, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":{"id":"` + r.URL.Query().Get("id") + `"}}`))
+	}))
+	defer server.Close()
+	store := NewRestMemoryConditionalStore()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true, Conditional: store},
+	})
+	for i := 0; i < 3; i++ {
+		res := (<-client.Do(context.Background(), 1, QueryParams{"id": "a"})).JsonResult()
+		if res.Err() != nil || res.MustString("data.id") != "a" {
+			t.Error("conditional result wrong", res.Err())
+		}
+	}
+	if full != 1 || notModified != 2 {
+		t.Error("conditional request not use", full, notModified)
+	}
+	_ = (<-client.Do(context.Background(), 1, QueryParams{"id": "b"})).JsonResult()
+	if full != 2 {
+		t.Error("conditional key wrong")
+	}
+}
+
+func TestConditionalMaxBody(t *testing.T) {
+	store := NewRestMemoryConditionalStore()
+	store.MaxBodySize = 8
+	cond := &restConditional{ctx: context.Background(), store: store, key: "k"}
+	for _, item := range []struct {
+		body   string
+		length int64
+		cached bool
+	}{
+		{"small", -1, true},
+		{"large content", -1, false},
+		{"large content", 13, false},
+	} {
+		store.data = nil
+		res := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Etag": {`"v1"`}},
+			ContentLength: item.length, Body: ioutil.NopCloser(strings.NewReader(item.body))}
+		cond.response(nil, res, nil)
+		if data, _ := ioutil.ReadAll(res.Body); string(data) != item.body {
+			t.Error("conditional body changed", string(data))
+		}
+		if _, ok := store.Get(context.Background(), "k"); ok != item.cached {
+			t.Error("conditional max body wrong", item.body, item.length)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package rest_client
+
+import (
+	"context"
+)
+
+// AppRestConfigOption 派生配置时的修改项
+type AppRestConfigOption func(config *AppRestConfig)
+
+// ConfigWithName 修改配置名
+func ConfigWithName(name string) AppRestConfigOption {
+	return func(config *AppRestConfig) {
+		config.Name = name
+	}
+}
+
+// ConfigWithUrl 修改服务地址
+func ConfigWithUrl(url string) AppRestConfigOption {
+	return func(config *AppRestConfig) {
+		config.AppUrl = url
+	}
+}
+
+// ConfigWithEvent 修改事件创建
+func ConfigWithEvent(create func(ctx context.Context) RestEvent) AppRestConfigOption {
+	return func(config *AppRestConfig) {
+		config.EventCreate = create
+	}
+}
+
+// ConfigWithHeaders 增加或覆盖默认请求HEADER
+func ConfigWithHeaders(headers map[string]string) AppRestConfigOption {
+	return func(config *AppRestConfig) {
+		for key, val := range headers {
+			config.Headers[key] = val
+		}
+	}
+}
+
+// ConfigWithTransport 使用独立的连接池配置,可设置不同的超时
+func ConfigWithTransport(transport *RestTransportConfig) AppRestConfigOption {
+	return func(config *AppRestConfig) {
+		config.Transport = transport
+	}
+}
+
+// With 复制配置并应用修改,用于按租户或地区创建不同地址等的配置而无需复制密钥
+// 默认参数及HEADER会复制一份,修改不影响原配置,其他指针字段(如 Balancer)与原配置共用
+func (clf *AppRestConfig) With(opts ...AppRestConfigOption) *AppRestConfig {
+	config := *clf
+	config.DefaultParams = make(map[string]interface{}, len(clf.DefaultParams))
+	for key, val := range clf.DefaultParams {
+		config.DefaultParams[key] = val
+	}
+	config.Headers = make(map[string]string, len(clf.Headers))
+	for key, val := range clf.Headers {
+		config.Headers[key] = val
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return &config
+}
+
+// DeriveConfig 从已设置的配置派生新配置并设置
+// @param from 已设置的配置名,需为 *AppRestConfig
+// @param name 新配置名
+func (c *RestClientManager) DeriveConfig(from, name string, opts ...AppRestConfigOption) (*AppRestConfig, error) {
+	config, ok := c.restConfig[from]
+	if !ok {
+		return nil, NewRestClientError(ErrConfigMissing, "rest config is exits:"+from)
+	}
+	appConfig, ok := config.(*AppRestConfig)
+	if !ok {
+		return nil, NewRestClientError(ErrConfigType, "derive config is wrong")
+	}
+	derive := appConfig.With(append([]AppRestConfigOption{ConfigWithName(name)}, opts...)...)
+	c.SetRestConfig(derive)
+	return derive, nil
+}
@@ -0,0 +1,37 @@
+package rest_client
+
+import (
+	"context"
+	"github.com/tidwall/gjson"
+	"testing"
+)
+
+func TestDeriveConfig(t *testing.T) {
+	server := newTestAppServer(func(method string, _ gjson.Result) string {
+		return `{}`
+	})
+	defer server.Close()
+	manager := NewRestClientManager()
+	base := &AppRestConfig{
+		Name:      "base",
+		AppKey:    "dome1",
+		AppSecret: "dome111111",
+		AppUrl:    "http://127.0.0.1:1",
+		Headers:   map[string]string{"X-Region": "cn"},
+	}
+	manager.SetRestConfig(base)
+	derive, err := manager.DeriveConfig("base", "test", ConfigWithUrl(server.URL), ConfigWithHeaders(map[string]string{"X-Region": "us"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if derive.AppSecret != base.AppSecret || derive.Name != "test" || base.Headers["X-Region"] != "cn" || derive.Headers["X-Region"] != "us" {
+		t.Error("derive config wrong")
+	}
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{1: &AppRestBuild{}}})
+	if err = (<-client.Do(context.Background(), 1, nil)).JsonResult().Err(); err != nil {
+		t.Error(err)
+	}
+	if _, err = manager.DeriveConfig("none", "test2"); err == nil {
+		t.Error("derive missing config not return error")
+	}
+}
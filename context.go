@@ -0,0 +1,88 @@
+package rest_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+type backgroundKey struct{}
+
+// WithBackground 标记为后台批量任务的请求
+// 服务配置了 Background 时,此类请求使用更严格的限流及更长的超时
+func WithBackground(ctx context.Context) context.Context {
+	return context.WithValue(ctx, backgroundKey{}, true)
+}
+
+// IsBackground 是否为后台批量任务的请求
+func IsBackground(ctx context.Context) bool {
+	background, _ := ctx.Value(backgroundKey{}).(bool)
+	return background
+}
+
+type headerKey struct{}
+
+// WithHeader 设置单次请求的HEADER,覆盖服务配置中的同名HEADER
+func WithHeader(ctx context.Context, key, value string) context.Context {
+	header := http.Header{}
+	if prev, ok := ctx.Value(headerKey{}).(http.Header); ok {
+		header = prev.Clone()
+	}
+	header.Set(key, value)
+	return context.WithValue(ctx, headerKey{}, header)
+}
+
+// contextHeader 获取单次请求设置的HEADER
+func contextHeader(ctx context.Context) http.Header {
+	header, _ := ctx.Value(headerKey{}).(http.Header)
+	return header
+}
+
+type configNameKey struct{}
+
+// withConfigName 指定本次请求使用的服务配置,替换 RestApi.ConfigName 的返回
+func withConfigName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, configNameKey{}, name)
+}
+
+type eventKey struct{}
+
+// WithEvent 设置单次请求使用的事件,替换服务配置的 EventCreate,用于临时跟踪某次调用
+func WithEvent(ctx context.Context, event RestEvent) context.Context {
+	return context.WithValue(ctx, eventKey{}, event)
+}
+
+type eventV2Key struct{}
+
+// WithEventV2 设置单次请求使用的带请求信息的事件,替换服务配置的事件创建
+func WithEventV2(ctx context.Context, event RestEventV2) context.Context {
+	return context.WithValue(ctx, eventV2Key{}, event)
+}
+
+// withoutEvent 去除单次请求设置的事件,用于镜像等派生请求,避免回调到调用方的事件
+func withoutEvent(ctx context.Context) context.Context {
+	if ctx.Value(eventKey{}) != nil {
+		ctx = context.WithValue(ctx, eventKey{}, nil)
+	}
+	if ctx.Value(eventV2Key{}) != nil {
+		ctx = context.WithValue(ctx, eventV2Key{}, nil)
+	}
+	return ctx
+}
+
+// contextEvent 获取单次请求设置的事件,未设置时返回nil
+func contextEvent(ctx context.Context) RestEvent {
+	if event, ok := ctx.Value(eventV2Key{}).(RestEventV2); ok {
+		return NewRestEventV2Adapter(ctx, event)
+	}
+	event, _ := ctx.Value(eventKey{}).(RestEvent)
+	return event
+}
+
+// cancelError 因ctx取消或超时导致的错误转为统一错误码,便于与其他网络错误区分
+func cancelError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return &RestClientError{Code: ErrCanceled, Msg: "request canceled:" + err.Error(), err: err}
+	}
+	return err
+}
@@ -0,0 +1,136 @@
+package rest_client
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/tidwall/gjson"
+)
+
+// AppRestCrypto 业务内容加解密,用于返回内容中 data 为密文的合作方
+// 返回内容检测成功后解密 DataPath 字段的base64密文,明文为JSON时替换为该JSON,否则替换为字符串,之后按路径获取
+type AppRestCrypto struct {
+	AEAD           cipher.AEAD //加密算法,如 NewAppAesGcm 创建的 AES-GCM
+	DataPath       string      //返回内容中密文字段的路径,默认 data
+	IvPath         string      //返回内容中IV(nonce)字段的路径,值为base64,为空时IV在密文前
+	AdditionalData []byte      //附加认证数据,可以为nil
+	EncryptRequest bool        //加密签名格式请求的 content,加密后为 base64(IV+密文)
+}
+
+// NewAppAesGcm 创建 AES-GCM 加解密,key 长度为16 24 32,对应 AES-128 AES-192 AES-256
+func NewAppAesGcm(key []byte) (*AppRestCrypto, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AppRestCrypto{AEAD: aead}, nil
+}
+
+func cryptoError(msg string, err error) error {
+	if err != nil {
+		msg += ":" + err.Error()
+	}
+	return &RestClientError{Code: ErrCrypto, Msg: msg, err: err}
+}
+
+// Encrypt 加密内容,返回 base64(IV+密文)
+func (crypto *AppRestCrypto) Encrypt(plain []byte) (string, error) {
+	nonce := make([]byte, crypto.AEAD.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", cryptoError("generate iv fail", err)
+	}
+	sealed := crypto.AEAD.Seal(nonce, nonce, plain, crypto.AdditionalData)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密base64密文,iv 为nil时IV在密文前
+func (crypto *AppRestCrypto) Decrypt(data string, iv []byte) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, cryptoError("decode cipher text fail", err)
+	}
+	nonceSize := crypto.AEAD.NonceSize()
+	if iv == nil {
+		if len(sealed) < nonceSize {
+			return nil, cryptoError("cipher text too short", nil)
+		}
+		iv, sealed = sealed[:nonceSize], sealed[nonceSize:]
+	}
+	if len(iv) != nonceSize {
+		return nil, cryptoError("iv size error", nil)
+	}
+	plain, err := crypto.AEAD.Open(nil, iv, sealed, crypto.AdditionalData)
+	if err != nil {
+		return nil, cryptoError("decrypt fail", err)
+	}
+	return plain, nil
+}
+
+// decryptBody 解密返回内容中的密文字段,字段不存在或不是字符串时原样返回
+func (crypto *AppRestCrypto) decryptBody(body string) (string, error) {
+	path := crypto.DataPath
+	if len(path) == 0 {
+		path = "data"
+	}
+	data := gjson.Get(body, path)
+	if data.Type != gjson.String {
+		return body, nil
+	}
+	var iv []byte
+	if len(crypto.IvPath) > 0 {
+		var err error
+		if iv, err = base64.StdEncoding.DecodeString(gjson.Get(body, crypto.IvPath).String()); err != nil {
+			return "", cryptoError("decode iv fail", err)
+		}
+	}
+	plain, err := crypto.Decrypt(data.String(), iv)
+	if err != nil {
+		return "", err
+	}
+	if !json.Valid(plain) {
+		if plain, err = json.Marshal(string(plain)); err != nil {
+			return "", cryptoError("encode plain text fail", err)
+		}
+	}
+	if data.Index <= 0 {
+		return "", cryptoError("data path not support decrypt:"+path, nil)
+	}
+	return body[:data.Index] + string(plain) + body[data.Index+len(data.Raw):], nil
+}
+
+// encryptRequest 是否加密请求参数
+func (clt *AppRestBuild) encryptRequest() bool {
+	return clt.Crypto != nil && clt.Crypto.EncryptRequest
+}
+
+// encryptContent 按接口配置加密签名格式的 content
+func (clt *AppRestBuild) encryptContent(content string) (string, error) {
+	if !clt.encryptRequest() {
+		return content, nil
+	}
+	return clt.Crypto.Encrypt([]byte(content))
+}
+
+// restCryptoBuild 配置了返回内容解密的接口
+type restCryptoBuild interface {
+	restCrypto() *AppRestCrypto
+}
+
+func (clt *AppRestBuild) restCrypto() *AppRestCrypto {
+	return clt.Crypto
+}
+
+// decrypt 按接口配置解密返回内容
+func (res *RestResult) decrypt(body string) (string, error) {
+	build, ok := res.build.(restCryptoBuild)
+	if !ok || build.restCrypto() == nil {
+		return body, nil
+	}
+	return build.restCrypto().decryptBody(body)
+}
@@ -0,0 +1,81 @@
+package rest_client
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAppRestCrypto(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	serverCrypto, _ := NewAppAesGcm(key)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		//请求内容为密文,解密后加密返回
+		plain, err := serverCrypto.Decrypt(r.Form.Get("content"), nil)
+		if err != nil {
+			_, _ = w.Write([]byte(`{"result":{"code":"500","state":"fail","message":"decrypt fail"}}`))
+			return
+		}
+		switch r.Form.Get("method") {
+		case "iv":
+			//IV单独返回
+			sealed, _ := serverCrypto.Encrypt(plain)
+			raw, _ := base64.StdEncoding.DecodeString(sealed)
+			iv := base64.StdEncoding.EncodeToString(raw[:12])
+			data := base64.StdEncoding.EncodeToString(raw[12:])
+			_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"iv":"` + iv + `","data":"` + data + `"}`))
+		case "text":
+			data, _ := serverCrypto.Encrypt([]byte("hello"))
+			_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":"` + data + `"}`))
+		default:
+			data, _ := serverCrypto.Encrypt(plain)
+			_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":"` + data + `","other":1}`))
+		}
+	}))
+	defer server.Close()
+
+	crypto, err := NewAppAesGcm(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crypto.EncryptRequest = true
+	ivCrypto := *crypto
+	ivCrypto.IvPath = "iv"
+	wrongCrypto, _ := NewAppAesGcm([]byte("fedcba9876543210"))
+	wrongCrypto.EncryptRequest = true
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{Method: "echo", Crypto: crypto},
+		2: &AppRestBuild{Method: "iv", Crypto: &ivCrypto},
+		3: &AppRestBuild{Method: "text", Crypto: crypto},
+		4: &AppRestBuild{Method: "echo", Crypto: wrongCrypto},
+	})
+	res := (<-client.Do(context.Background(), 1, map[string]string{"name": "phone"})).JsonResult()
+	if res.Err() != nil || res.MustString("data.name") != "phone" || res.MustInt("other") != 1 {
+		t.Error("crypto echo error", res.Err())
+	}
+	res = (<-client.Do(context.Background(), 2, map[string]string{"name": "iv"})).JsonResult("data")
+	if res.Err() != nil || res.MustString("name") != "iv" {
+		t.Error("crypto iv path error", res.Err())
+	}
+	res = (<-client.Do(context.Background(), 3, map[string]string{"name": "text"})).JsonResult()
+	if res.Err() != nil || res.MustString("data") != "hello" {
+		t.Error("crypto text error", res.Err())
+	}
+	res = (<-client.Do(context.Background(), 4, map[string]string{"name": "phone"})).JsonResult()
+	if res.Err() == nil {
+		t.Error("wrong key should fail")
+	}
+
+	_, err = crypto.Decrypt(base64.StdEncoding.EncodeToString([]byte("short")), nil)
+	var restErr *RestClientError
+	if !errors.As(err, &restErr) || restErr.Code != ErrCrypto {
+		t.Error("crypto error code error", err)
+	}
+	if _, err = NewAppAesGcm([]byte("short")); err == nil {
+		t.Error("aes key size should fail")
+	}
+}
@@ -0,0 +1,40 @@
+package rest_client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// checkDeadlineBudget 检测ctx剩余时间是否足够发起请求,未设置截止时间时不检测
+func checkDeadlineBudget(ctx context.Context, minBudget time.Duration) error {
+	if minBudget <= 0 {
+		return nil
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+	if remain := time.Until(deadline); remain < minBudget {
+		return NewRestClientError(ErrDeadlineBudget, fmt.Sprintf("deadline budget not enough,remain:%s min:%s", remain, minBudget))
+	}
+	return nil
+}
+
+// setDeadlineHeader 将ctx剩余时间按毫秒写入HEADER,未设置截止时间时不写入
+func setDeadlineHeader(ctx context.Context, header http.Header, name string) {
+	if len(name) == 0 {
+		return
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return
+	}
+	remain := time.Until(deadline).Milliseconds()
+	if remain < 0 {
+		remain = 0
+	}
+	header.Set(name, strconv.FormatInt(remain, 10))
+}
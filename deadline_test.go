@@ -0,0 +1,42 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDeadlineBudget(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"timeout":"` + r.Header.Get("X-Timeout-Ms") + `"}`))
+	}))
+	defer server.Close()
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:           "test",
+		AppUrl:         server.URL,
+		DeadlineHeader: "X-Timeout-Ms",
+		MinBudget:      100 * time.Millisecond,
+	})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true},
+	}})
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	res := (<-client.Do(ctx, 1, nil)).JsonResult()
+	remain, _ := strconv.Atoi(res.MustString("timeout"))
+	if remain <= 1000 || remain > 2000 {
+		t.Error("deadline header error")
+	}
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel2()
+	if (<-client.Do(ctx2, 1, nil)).Err() == nil {
+		t.Error("deadline budget not check")
+	}
+	if (<-client.Do(context.Background(), 1, nil)).JsonResult().MustString("timeout") != "" {
+		t.Error("no deadline should not set header")
+	}
+}
@@ -0,0 +1,141 @@
+package rest_client
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RestDeprecation 服务端返回的接口废弃信息
+// 解析 Deprecation, Sunset 及 Link rel=deprecation HEADER
+type RestDeprecation struct {
+	ConfigName   string
+	Key          int
+	Url          string
+	Deprecation  string    //Deprecation HEADER原始内容
+	DeprecatedAt time.Time //废弃时间,未指定时间时为零值
+	Sunset       time.Time //下线时间,未返回时为零值
+	Link         string    //废弃说明链接
+	Count        int64     //请求到此废弃接口的次数
+	LastSeen     time.Time //最后一次请求时间
+}
+
+// RestDeprecationEvent 可选事件接口,请求的接口被服务端标记废弃时回调
+type RestDeprecationEvent interface {
+	Deprecated(info *RestDeprecation)
+}
+
+// parseHttpDate 解析HTTP时间或 @unix 格式时间
+func parseHttpDate(val string) time.Time {
+	val = strings.TrimSpace(val)
+	if strings.HasPrefix(val, "@") {
+		if sec, err := strconv.ParseInt(val[1:], 10, 64); err == nil {
+			return time.Unix(sec, 0)
+		}
+		return time.Time{}
+	}
+	if tm, err := http.ParseTime(val); err == nil {
+		return tm
+	}
+	return time.Time{}
+}
+
+// parseDeprecationLink 从Link HEADER中获取 rel=deprecation 的链接
+func parseDeprecationLink(links []string) string {
+	for _, link := range links {
+		for _, item := range strings.Split(link, ",") {
+			parts := strings.Split(item, ";")
+			if len(parts) < 2 {
+				continue
+			}
+			for _, attr := range parts[1:] {
+				attr = strings.ReplaceAll(strings.TrimSpace(attr), " ", "")
+				if attr == `rel="deprecation"` || attr == "rel=deprecation" {
+					return strings.Trim(strings.TrimSpace(parts[0]), "<>")
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// ParseDeprecation 从返回HEADER中解析废弃信息,未废弃时返回nil
+func ParseDeprecation(header http.Header) *RestDeprecation {
+	deprecation := header.Get("Deprecation")
+	sunset := header.Get("Sunset")
+	link := parseDeprecationLink(header.Values("Link"))
+	if len(deprecation) == 0 && len(sunset) == 0 && len(link) == 0 {
+		return nil
+	}
+	info := &RestDeprecation{
+		Deprecation: deprecation,
+		Link:        link,
+	}
+	if len(deprecation) > 0 && deprecation != "true" {
+		info.DeprecatedAt = parseHttpDate(deprecation)
+	}
+	if len(sunset) > 0 {
+		info.Sunset = parseHttpDate(sunset)
+	}
+	return info
+}
+
+// restDeprecationRegistry 记录请求过的废弃接口
+type restDeprecationRegistry struct {
+	lock sync.Mutex
+	data map[string]*RestDeprecation
+}
+
+func (reg *restDeprecationRegistry) add(info *RestDeprecation) {
+	reg.lock.Lock()
+	defer reg.lock.Unlock()
+	if reg.data == nil {
+		reg.data = make(map[string]*RestDeprecation)
+	}
+	key := info.ConfigName + "|" + strconv.Itoa(info.Key)
+	save, ok := reg.data[key]
+	if !ok {
+		save = &RestDeprecation{}
+		reg.data[key] = save
+	}
+	count := save.Count
+	*save = *info
+	save.Count = count + 1
+	save.LastSeen = time.Now()
+}
+
+func (reg *restDeprecationRegistry) list() []RestDeprecation {
+	reg.lock.Lock()
+	defer reg.lock.Unlock()
+	out := make([]RestDeprecation, 0, len(reg.data))
+	for _, info := range reg.data {
+		out = append(out, *info)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ConfigName != out[j].ConfigName {
+			return out[i].ConfigName < out[j].ConfigName
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out
+}
+
+// checkDeprecation 检测返回是否标记废弃,记录到管理器并回调事件
+func checkDeprecation(client *RestClient, configName string, key int, url string, header http.Header, event RestEvent) {
+	info := ParseDeprecation(header)
+	if info == nil {
+		return
+	}
+	info.ConfigName = configName
+	info.Key = key
+	info.Url = url
+	if client.manager != nil {
+		client.manager.deprecations.add(info)
+	}
+	if dEvent, ok := event.(RestDeprecationEvent); ok {
+		dEvent.Deprecated(info)
+	}
+}
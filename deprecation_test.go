@@ -0,0 +1,48 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseDeprecation(t *testing.T) {
+	if ParseDeprecation(http.Header{}) != nil {
+		t.Error("not deprecation header parse error")
+	}
+	header := http.Header{}
+	header.Set("Deprecation", "@1688169599")
+	header.Set("Sunset", "Wed, 11 Nov 2026 23:59:59 GMT")
+	header.Add("Link", `<https://api.example.com/v2>; rel="successor-version", <https://api.example.com/deprecation>; rel="deprecation"`)
+	info := ParseDeprecation(header)
+	if info == nil {
+		t.Fatal("deprecation header parse error")
+	}
+	if info.DeprecatedAt.Unix() != 1688169599 || info.Sunset.Year() != 2026 {
+		t.Error("deprecation time parse error")
+	}
+	if info.Link != "https://api.example.com/deprecation" {
+		t.Error("deprecation link parse error:" + info.Link)
+	}
+}
+
+func TestDeprecationReport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"}}`))
+	}))
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodPost, Method: "old"},
+	})
+	for i := 0; i < 2; i++ {
+		if err := (<-client.Do(context.Background(), 1, nil)).JsonResult().Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	report := client.manager.Deprecations()
+	if len(report) != 1 || report[0].Key != 1 || report[0].Count != 2 {
+		t.Error("deprecation report error")
+	}
+}
@@ -0,0 +1,158 @@
+package rest_client
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// restDiagnosticsMaxBody 诊断信息中返回内容及签名内容的最大长度
+const restDiagnosticsMaxBody = 1024
+
+// RestDiagnostics 失败请求的诊断信息,不需要开启事件记录全部内容即可在错误日志中排查问题
+type RestDiagnostics struct {
+	ConfigName string
+	Key        int
+	Method     string
+	Url        string            //参数已脱敏
+	HttpCode   int               //未收到返回时为0
+	Body       string            //返回内容片段,已脱敏及截断
+	SignInputs map[string]string //参与签名的参数,已脱敏,未签名的请求为nil
+	Caller     *RestCallerInfo   //发起请求的调用方,非 Do 发起的请求为nil
+	Err        error
+}
+
+// String 用于错误日志输出
+func (diag *RestDiagnostics) String() string {
+	var build strings.Builder
+	build.WriteString(diag.ConfigName)
+	build.WriteString(":")
+	build.WriteString(strconv.Itoa(diag.Key))
+	build.WriteString(" ")
+	build.WriteString(diag.Method)
+	build.WriteString(" ")
+	build.WriteString(diag.Url)
+	build.WriteString(" http:")
+	build.WriteString(strconv.Itoa(diag.HttpCode))
+	if diag.Err != nil {
+		build.WriteString(" err:")
+		build.WriteString(diag.Err.Error())
+	}
+	if len(diag.SignInputs) > 0 {
+		keys := make(url.Values, len(diag.SignInputs))
+		for key, val := range diag.SignInputs {
+			keys.Set(key, val)
+		}
+		build.WriteString(" sign:")
+		build.WriteString(keys.Encode())
+	}
+	if len(diag.Body) > 0 {
+		build.WriteString(" body:")
+		build.WriteString(diag.Body)
+	}
+	if diag.Caller != nil {
+		build.WriteString(" caller:")
+		build.WriteString(diag.Caller.String())
+	}
+	return build.String()
+}
+
+// restRequestInfo 请求时记录的诊断信息,出错时才生成 RestDiagnostics
+type restRequestInfo struct {
+	configName  string
+	key         int
+	method      string
+	url         string
+	signForm    string      //表单签名模式的请求参数
+	signHeader  http.Header //HEADER签名模式的签名HEADER
+	signContent string      //HEADER签名模式的业务参数
+}
+
+// restDiagnosticsRedact 诊断信息脱敏
+var restDiagnosticsRedact = RedactBody()
+
+// redactUrl URL参数脱敏
+func redactUrl(u string) string {
+	if i := strings.IndexByte(u, '?'); i >= 0 {
+		return u[:i+1] + string(restDiagnosticsRedact([]byte(u[i+1:])))
+	}
+	return u
+}
+
+// diagnosticsBody 脱敏并截断内容
+func diagnosticsBody(body string) string {
+	if len(body) == 0 {
+		return ""
+	}
+	body = string(restDiagnosticsRedact([]byte(body)))
+	if len(body) > restDiagnosticsMaxBody {
+		body = body[:restDiagnosticsMaxBody]
+	}
+	return body
+}
+
+// signInputs 脱敏后的签名参数
+func (info *restRequestInfo) signInputs() map[string]string {
+	redact := make(map[string]bool, len(DefaultRedactKeys))
+	for _, key := range DefaultRedactKeys {
+		redact[key] = true
+	}
+	if len(info.signForm) > 0 {
+		form, err := url.ParseQuery(info.signForm)
+		if err != nil {
+			return nil
+		}
+		inputs := make(map[string]string, len(form))
+		for key := range form {
+			inputs[key] = form.Get(key)
+			if redact[key] {
+				inputs[key] = "***"
+			} else if key == "content" {
+				inputs[key] = diagnosticsBody(inputs[key])
+			}
+		}
+		return inputs
+	}
+	if info.signHeader != nil {
+		inputs := make(map[string]string, len(info.signHeader)+1)
+		for key := range info.signHeader {
+			inputs[key] = info.signHeader.Get(key)
+			if redact[strings.ToLower(strings.TrimPrefix(key, "X-"))] {
+				inputs[key] = "***"
+			}
+		}
+		inputs["content"] = diagnosticsBody(info.signContent)
+		return inputs
+	}
+	return nil
+}
+
+// Diagnostics 请求失败时的诊断信息,包括请求地址,方法,HTTP状态码,返回内容片段及签名参数,均已脱敏,成功时返回nil
+func (res *RestResult) Diagnostics() *RestDiagnostics {
+	if res == nil || res.err == nil {
+		return nil
+	}
+	diag := &RestDiagnostics{
+		Body:   diagnosticsBody(res.rawBody),
+		Caller: res.caller,
+		Err:    res.err,
+	}
+	if res.info != nil {
+		diag.ConfigName, diag.Key = res.info.configName, res.info.key
+		diag.Method, diag.Url = res.info.method, redactUrl(res.info.url)
+		diag.SignInputs = res.info.signInputs()
+	}
+	if res.response != nil {
+		diag.HttpCode = res.response.StatusCode
+		if req := res.response.Request; req != nil && len(diag.Method) == 0 {
+			diag.Method, diag.Url = req.Method, redactUrl(req.URL.String())
+		}
+	}
+	return diag
+}
+
+// Diagnostics 请求失败时的诊断信息,成功或非请求返回的错误为nil
+func (res *JsonResult) Diagnostics() *RestDiagnostics {
+	return res.diagnostics
+}
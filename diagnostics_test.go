@@ -0,0 +1,99 @@
+package rest_client
+
+import (
+	"context"
+	"github.com/tidwall/gjson"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRestDiagnostics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"result":{"code":"400","state":"fail","message":"bad param"},"access_token":"abc"}`))
+	}))
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodPost, Path: "/a", Method: "user.get"},
+		2: &AppRestBuild{HttpMethod: http.MethodGet, Path: "/b?token=abc", SignMode: AppSignHeader},
+		3: &AppRestBuild{HttpMethod: http.MethodGet, Path: "/c"},
+	})
+	res := (<-client.Do(context.Background(), 1, map[string]string{"password": "123", "name": "a"})).JsonResult()
+	if res.Err() == nil {
+		t.Fatal("result must fail")
+	}
+	diag := res.Diagnostics()
+	if diag == nil || diag != res.GetData("data").diagnostics {
+		t.Fatal("diagnostics not set")
+	}
+	if diag.ConfigName != "test" || diag.Key != 1 || diag.Method != http.MethodPost || diag.Url != server.URL+"/a" ||
+		diag.HttpCode != http.StatusBadRequest || diag.Err != res.Err() || diag.Caller == nil {
+		t.Error("diagnostics wrong", diag)
+	}
+	if !strings.Contains(diag.Body, "bad param") || strings.Contains(diag.Body, "abc") {
+		t.Error("diagnostics body wrong", diag.Body)
+	}
+	if diag.SignInputs["app"] != "dome1" || diag.SignInputs["method"] != "user.get" || diag.SignInputs["sign"] != "***" ||
+		strings.Contains(diag.SignInputs["content"], "123") || !strings.Contains(diag.SignInputs["content"], `"name":"a"`) {
+		t.Error("diagnostics sign wrong", diag.SignInputs)
+	}
+	if str := diag.String(); strings.Contains(str, "123") || !strings.Contains(str, "bad param") {
+		t.Error("diagnostics string wrong", str)
+	}
+
+	diag = (<-client.Do(context.Background(), 2, nil)).JsonResult().Diagnostics()
+	if diag == nil || strings.Contains(diag.Url, "abc") || diag.SignInputs["X-Sign"] != "***" || diag.SignInputs["X-App"] != "dome1" {
+		t.Error("diagnostics header sign wrong", diag)
+	}
+
+	server.Close()
+	result := <-client.Do(context.Background(), 3, nil)
+	diag = result.Diagnostics()
+	if diag == nil || diag.HttpCode != 0 || diag.Method != http.MethodGet || !strings.HasPrefix(diag.Url, server.URL+"/c?") ||
+		!strings.Contains(diag.Url, "sign=%2A%2A%2A") || diag.SignInputs["sign"] != "***" {
+		t.Error("diagnostics network error wrong", diag)
+	}
+
+	server = newTestAppServer(func(method string, content gjson.Result) string {
+		return `{}`
+	})
+	defer server.Close()
+	client = newTestAppClient(server.URL, map[int]RestBuild{1: &AppRestBuild{}})
+	result = <-client.Do(context.Background(), 1, nil)
+	if res := result.JsonResult(); res.Err() != nil || res.Diagnostics() != nil || result.Diagnostics() != nil {
+		t.Error("success must not diagnostics")
+	}
+}
+
+func TestRestResultNil(t *testing.T) {
+	var res *RestResult
+	if err, _ := res.Header(); err == nil {
+		t.Error("nil result header")
+	}
+	if _, err := res.Read(make([]byte, 1)); err == nil {
+		t.Error("nil result read")
+	}
+	if res.Decode(&struct{}{}) == nil || res.XmlResult().Err() == nil || res.Diagnostics() != nil || res.Caller() != nil {
+		t.Error("nil result decode")
+	}
+	if _, err := res.Tee(2); err == nil {
+		t.Error("nil result tee")
+	}
+	if _, err := res.TeeTo(); err == nil {
+		t.Error("nil result tee to")
+	}
+	if _, err := res.SinkTo(context.Background(), "", nil, 1); err == nil {
+		t.Error("nil result sink")
+	}
+	if item := <-res.NdjsonResult(context.Background()); item == nil || item.Err() == nil {
+		t.Error("nil result ndjson")
+	}
+	w := httptest.NewRecorder()
+	if res.ProxyTo(w, nil) == nil || w.Code != http.StatusBadGateway {
+		t.Error("nil result proxy")
+	}
+	if res.Timings() != (RestTimings{}) {
+		t.Error("nil result timings")
+	}
+}
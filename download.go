@@ -0,0 +1,234 @@
+package rest_client
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RangeDownload 分段并发下载,通过多个Range请求并发下载并按偏移写入文件
+// 服务端不支持Range或未返回长度时退化为单个请求下载
+type RangeDownload struct {
+	Url       string
+	Header    http.Header      //附加请求HEADER
+	Parallel  int              //并发请求数,默认4
+	PartSize  int64            //每段大小,默认8M
+	Retry     int              //单段失败重试次数,默认2
+	RetryWait time.Duration    //重试等待时间,按重试次数递增,默认200ms
+	Hash      func() hash.Hash //下载完成后校验算法,为nil时不校验
+	Checksum  string           //期望的十六进制校验值
+}
+
+// offsetWriter 从指定偏移写入文件
+type offsetWriter struct {
+	file   *os.File
+	offset int64
+}
+
+func (w *offsetWriter) Write(p []byte) (int, error) {
+	n, err := w.file.WriteAt(p, w.offset)
+	w.offset += int64(n)
+	return n, err
+}
+
+// NewRangeDownload 创建分段下载
+func NewRangeDownload(url string) *RangeDownload {
+	return &RangeDownload{
+		Url:       url,
+		Parallel:  4,
+		PartSize:  8 * 1024 * 1024,
+		Retry:     2,
+		RetryWait: 200 * time.Millisecond,
+	}
+}
+
+func (down *RangeDownload) newRequest(ctx context.Context, method string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, down.Url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, val := range down.Header {
+		req.Header[key] = val
+	}
+	return req, nil
+}
+
+// probe 获取文件长度及是否支持Range
+func (down *RangeDownload) probe(ctx context.Context, httpClient *http.Client) (int64, bool, error) {
+	req, err := down.newRequest(ctx, http.MethodHead)
+	if err != nil {
+		return 0, false, err
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return 0, false, err
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return 0, false, NewRestClientError(ErrDownload, fmt.Sprintf("download probe http code:%d", res.StatusCode))
+	}
+	return res.ContentLength, strings.Contains(res.Header.Get("Accept-Ranges"), "bytes"), nil
+}
+
+// Download 下载到指定文件
+func (down *RangeDownload) Download(ctx context.Context, client *RestClient, filePath string) error {
+	httpClient := &http.Client{
+		Transport: client.GetTransport(),
+	}
+	size, ranges, err := down.probe(ctx, httpClient)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if ranges && size > 0 && down.PartSize > 0 {
+		if err = file.Truncate(size); err != nil {
+			return err
+		}
+		err = down.downloadRanges(ctx, httpClient, file, size)
+	} else {
+		err = down.withRetry(ctx, func() error {
+			if _, sErr := file.Seek(0, io.SeekStart); sErr != nil {
+				return sErr
+			}
+			return down.downloadRange(ctx, httpClient, file, -1, -1)
+		})
+	}
+	if err != nil {
+		return err
+	}
+	return down.verify(file)
+}
+
+func (down *RangeDownload) withRetry(ctx context.Context, call func() error) error {
+	var err error
+	for i := 0; i <= down.Retry; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(down.RetryWait * time.Duration(i)):
+			}
+		}
+		if err = call(); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// downloadRange 下载一段内容,start小于0时下载全部
+func (down *RangeDownload) downloadRange(ctx context.Context, httpClient *http.Client, file *os.File, start, end int64) error {
+	req, err := down.newRequest(ctx, http.MethodGet)
+	if err != nil {
+		return err
+	}
+	if start >= 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	}
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if start >= 0 {
+		if res.StatusCode != http.StatusPartialContent {
+			return NewRestClientError(ErrDownload, fmt.Sprintf("download range http code:%d", res.StatusCode))
+		}
+		n, err := io.Copy(&offsetWriter{file: file, offset: start}, res.Body)
+		if err != nil {
+			return err
+		}
+		if n != end-start+1 {
+			return NewRestClientError(ErrDownload, fmt.Sprintf("download range %d-%d short read:%d", start, end, n))
+		}
+		return nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return NewRestClientError(ErrDownload, fmt.Sprintf("download http code:%d", res.StatusCode))
+	}
+	_, err = io.Copy(file, res.Body)
+	return err
+}
+
+func (down *RangeDownload) downloadRanges(ctx context.Context, httpClient *http.Client, file *os.File, size int64) error {
+	rangeCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	parallel := down.Parallel
+	if parallel <= 0 {
+		parallel = 1
+	}
+	starts := make(chan int64)
+	var (
+		lock     sync.Mutex
+		wait     sync.WaitGroup
+		firstErr error
+	)
+	for i := 0; i < parallel; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for start := range starts {
+				end := start + down.PartSize - 1
+				if end >= size {
+					end = size - 1
+				}
+				err := down.withRetry(rangeCtx, func() error {
+					return down.downloadRange(rangeCtx, httpClient, file, start, end)
+				})
+				if err != nil {
+					lock.Lock()
+					if firstErr == nil {
+						firstErr = err
+						cancel()
+					}
+					lock.Unlock()
+				}
+			}
+		}()
+	}
+	for start := int64(0); start < size; start += down.PartSize {
+		select {
+		case starts <- start:
+		case <-rangeCtx.Done():
+		}
+		if rangeCtx.Err() != nil {
+			break
+		}
+	}
+	close(starts)
+	wait.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// verify 校验下载文件
+func (down *RangeDownload) verify(file *os.File) error {
+	if down.Hash == nil {
+		return nil
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	sum := down.Hash()
+	if _, err := io.Copy(sum, file); err != nil {
+		return err
+	}
+	get := hex.EncodeToString(sum.Sum(nil))
+	if !strings.EqualFold(get, down.Checksum) {
+		return NewRestClientError(ErrChecksum, fmt.Sprintf("download checksum mismatch,expect:%s get:%s", down.Checksum, get))
+	}
+	return nil
+}
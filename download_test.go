@@ -0,0 +1,39 @@
+package rest_client
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRangeDownload(t *testing.T) {
+	body := bytes.Repeat([]byte("0123456789"), 100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "a.bin", time.Now(), bytes.NewReader(body))
+	}))
+	defer server.Close()
+	sum := md5.Sum(body)
+	down := NewRangeDownload(server.URL)
+	down.PartSize = 64
+	down.Hash = md5.New
+	down.Checksum = hex.EncodeToString(sum[:])
+	path := filepath.Join(t.TempDir(), "a.bin")
+	if err := down.Download(context.Background(), NewRestClientManager().NewApi(nil), path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadFile(path)
+	if !bytes.Equal(data, body) {
+		t.Error("download data error")
+	}
+	down.Checksum = "00"
+	if err := down.Download(context.Background(), NewRestClientManager().NewApi(nil), path); err == nil {
+		t.Error("download checksum error")
+	}
+}
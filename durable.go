@@ -0,0 +1,334 @@
+package rest_client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// RestDurableTask 持久化的请求,由队列实现序列化保存,执行时再签名,避免排队后 timestamp 过期
+type RestDurableTask struct {
+	Id         string          `json:"id"`
+	Api        string          `json:"api"`                   //RestApi 带包路径的类型名,执行时按已注册的类型查找
+	ConfigName string          `json:"config_name,omitempty"` //指定的服务配置,为空时使用 RestApi.ConfigName
+	Key        int             `json:"key"`
+	Param      json.RawMessage `json:"param"`
+	Header     http.Header     `json:"header,omitempty"` //WithHeader 设置的HEADER
+	Attempts   int             `json:"attempts"`         //已执行的次数
+	Error      string          `json:"error,omitempty"`  //最后一次失败的原因
+	CreateTime time.Time       `json:"create_time"`
+	Tenant     string          `json:"tenant,omitempty"`   //WithTenant 设置的租户
+	Override   *RestOverride   `json:"override,omitempty"` //WithOverride 及 Do 选项设置的单次请求配置
+	Priority   *int            `json:"priority,omitempty"` //WithPriority 设置的优先级
+}
+
+// RestDurableQueue 持久化队列,如 Kafka redis,任务需在 Ack 后才能删除,进程崩溃后未 Ack 的任务应重新投递
+type RestDurableQueue interface {
+	Push(ctx context.Context, task *RestDurableTask) error
+	Pop(ctx context.Context) (*RestDurableTask, error) //阻塞到获取任务或ctx结束
+	Ack(ctx context.Context, task *RestDurableTask) error
+}
+
+// SetDurableQueue 设置 DoAsyncDurable 使用的持久化队列
+func (c *RestClientManager) SetDurableQueue(queue RestDurableQueue) *RestClientManager {
+	c.durable = queue
+	return c
+}
+
+// restApiName RestApi 在任务中的名称,带包路径,不同包的同名类型不冲突
+func restApiName(api RestApi) string {
+	return restTypeName(reflect.TypeOf(api))
+}
+
+// restTypeName 带包路径的类型名,如 *github.com/a/b.OrderApi
+func restTypeName(typ reflect.Type) string {
+	prefix := ""
+	for typ.Kind() == reflect.Ptr {
+		prefix += "*"
+		typ = typ.Elem()
+	}
+	if len(typ.PkgPath()) == 0 || len(typ.Name()) == 0 {
+		return prefix + typ.String()
+	}
+	return prefix + typ.PkgPath() + "." + typ.Name()
+}
+
+// find 按类型名查找已注册的 RestClient
+// 兼容旧版本写入的不带包路径的类型名,仅在只有一个类型匹配时使用
+func (registry *restApiRegistry) find(name string) *RestClient {
+	registry.lock.RLock()
+	defer registry.lock.RUnlock()
+	var legacy *RestClient
+	legacyCount := 0
+	for typ, client := range registry.clients {
+		if restTypeName(typ) == name {
+			return client
+		}
+		if typ.String() == name {
+			legacy = client
+			legacyCount++
+		}
+	}
+	if legacyCount == 1 {
+		return legacy
+	}
+	return nil
+}
+
+// durableParamError 检查参数能否持久化,参数以JSON保存,执行时解码为 map 等通用类型
+func (client *RestClient) durableParamError(ctx context.Context, build RestBuild, param interface{}) error {
+	switch param.(type) {
+	case []byte, io.Reader:
+		return NewRestClientError(ErrDurable, "durable param can not be []byte or io.Reader")
+	}
+	appBuild, ok := build.(*AppRestBuild)
+	if !ok {
+		return nil
+	}
+	if appBuild.ParamEncoder != nil || appBuild.Codec != nil {
+		return NewRestClientError(ErrDurable, "durable param not support build with ParamEncoder or Codec")
+	}
+	if config, err := client.GetConfig(ctx); err == nil {
+		if appConfig, ok := config.(*AppRestConfig); ok && appConfig.ParamEncoder != nil {
+			return NewRestClientError(ErrDurable, "durable param not support config with ParamEncoder")
+		}
+	}
+	return nil
+}
+
+// DoAsyncDurable 将请求写入持久化队列后返回,由 RestDurableWorker 在后台执行并重试
+// 用于不需要结果但不能丢失的请求,如通知,api 的类型会注册到管理器,执行任务的进程需通过 RegisterApi 或 GetApi 注册
+func (client *RestClient) DoAsyncDurable(ctx context.Context, key int, param interface{}) error {
+	if client.manager == nil || client.manager.durable == nil {
+		return NewRestClientError(ErrDurable, "durable queue not set")
+	}
+	builds, err := client.configBuilds(ctx)
+	if err != nil {
+		return err
+	}
+	build, find := builds[key]
+	if !find {
+		return NewRestClientError(ErrApiNotFound, "not find rest api")
+	}
+	if err = client.durableParamError(ctx, build, param); err != nil {
+		return err
+	}
+	data, err := json.Marshal(param)
+	if err != nil {
+		return &RestClientError{Code: ErrDurable, Msg: "durable param encode fail:" + err.Error(), err: err}
+	}
+	client.manager.GetApi(client.Api)
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	task := &RestDurableTask{
+		Id:         hex.EncodeToString(id),
+		Api:        restApiName(client.Api),
+		Key:        key,
+		Param:      data,
+		Header:     contextHeader(ctx),
+		CreateTime: time.Now(),
+		Tenant:     TenantFrom(ctx),
+		Override:   contextOverride(ctx),
+	}
+	task.ConfigName, _ = ctx.Value(configNameKey{}).(string)
+	if priority, ok := ctx.Value(priorityKey{}).(int); ok {
+		task.Priority = &priority
+	}
+	if err = client.manager.durable.Push(ctx, task); err != nil {
+		return &RestClientError{Code: ErrDurable, Msg: "durable queue push fail:" + err.Error(), err: err}
+	}
+	return nil
+}
+
+// RestDurableWorker 执行持久化队列中的请求,失败时按次数重新入队,不可重试的错误或超过次数时放弃
+type RestDurableWorker struct {
+	Manager     *RestClientManager
+	Queue       RestDurableQueue
+	Concurrency int           //并发执行数,默认1
+	MaxAttempts int           //最多执行次数,默认3
+	Wait        time.Duration //失败后重新入队前的等待,按执行次数递增,默认1s
+	//放弃任务时回调,如写入死信队列,可以为nil
+	OnFail func(ctx context.Context, task *RestDurableTask, err error)
+}
+
+// NewRestDurableWorker 创建后台执行,queue 为nil时使用管理器设置的队列
+func NewRestDurableWorker(manager *RestClientManager, queue RestDurableQueue) *RestDurableWorker {
+	if queue == nil {
+		queue = manager.durable
+	}
+	return &RestDurableWorker{
+		Manager:     manager,
+		Queue:       queue,
+		Concurrency: 1,
+		MaxAttempts: 3,
+		Wait:        time.Second,
+	}
+}
+
+// Run 执行任务到ctx结束,获取任务失败时返回错误
+func (worker *RestDurableWorker) Run(ctx context.Context) error {
+	concurrency := worker.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var wg sync.WaitGroup
+	var once sync.Once
+	var runErr error
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				task, err := worker.Queue.Pop(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						once.Do(func() {
+							runErr = err
+							cancel()
+						})
+					}
+					return
+				}
+				worker.execute(ctx, task)
+			}
+		}()
+	}
+	wg.Wait()
+	return runErr
+}
+
+// execute 执行任务,失败时重新入队,完成或放弃后 Ack
+func (worker *RestDurableWorker) execute(ctx context.Context, task *RestDurableTask) {
+	err := worker.call(ctx, task)
+	task.Attempts++
+	if err == nil {
+		_ = worker.Queue.Ack(ctx, task)
+		return
+	}
+	task.Error = err.Error()
+	maxAttempts := worker.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+	if task.Attempts < maxAttempts && ClassifyError(err) != ResultFatal {
+		if worker.Wait > 0 {
+			timer := time.NewTimer(worker.Wait * time.Duration(task.Attempts))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				//未 Ack 的任务由队列重新投递
+				return
+			case <-timer.C:
+			}
+		}
+		if pushErr := worker.Queue.Push(ctx, task); pushErr == nil {
+			_ = worker.Queue.Ack(ctx, task)
+			return
+		}
+	}
+	if worker.OnFail != nil {
+		worker.OnFail(ctx, task, err)
+	}
+	_ = worker.Queue.Ack(ctx, task)
+}
+
+// call 执行一次请求并检测结果
+func (worker *RestDurableWorker) call(ctx context.Context, task *RestDurableTask) error {
+	client := worker.Manager.apis.find(task.Api)
+	if client == nil {
+		return NewRestClientError(ErrDurable, "durable api not registered:"+task.Api)
+	}
+	var param interface{}
+	if len(task.Param) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(task.Param))
+		dec.UseNumber()
+		if err := dec.Decode(&param); err != nil {
+			return &RestClientError{Code: ErrDurable, Msg: "durable param decode fail:" + err.Error(), err: err}
+		}
+	}
+	if len(task.ConfigName) > 0 {
+		ctx = withConfigName(ctx, task.ConfigName)
+	}
+	for name, values := range task.Header {
+		for _, value := range values {
+			ctx = WithHeader(ctx, name, value)
+		}
+	}
+	if len(task.Tenant) > 0 {
+		ctx = WithTenant(ctx, task.Tenant)
+	}
+	if task.Override != nil {
+		ctx = WithOverride(ctx, task.Override)
+	}
+	if task.Priority != nil {
+		ctx = WithPriority(ctx, *task.Priority)
+	}
+	res, err := client.DoSync(ctx, task.Key, param)
+	if err != nil {
+		return err
+	}
+	//未设置 Classifier 的接口检测返回内容时不区分HTTP状态码,可重试的状态码直接重试
+	if res.response != nil && ClassifyHttpCode(res.response.StatusCode) == ResultRetryable {
+		_ = res.Close()
+		return NewRestClientError(ErrServerHttp, fmt.Sprintf("server http code:%d", res.response.StatusCode))
+	}
+	return res.JsonResult().Err()
+}
+
+// RestMemoryQueue 内存队列,进程退出后任务丢失,用于测试及本地开发
+type RestMemoryQueue struct {
+	lock    sync.Mutex
+	tasks   chan *RestDurableTask
+	pending map[*RestDurableTask]bool //已取出未 Ack 的任务,重新入队的任务为新实例
+}
+
+// NewRestMemoryQueue 创建内存队列
+func NewRestMemoryQueue(size int) *RestMemoryQueue {
+	return &RestMemoryQueue{tasks: make(chan *RestDurableTask, size), pending: map[*RestDurableTask]bool{}}
+}
+
+func (queue *RestMemoryQueue) Push(ctx context.Context, task *RestDurableTask) error {
+	copied := *task
+	select {
+	case queue.tasks <- &copied:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (queue *RestMemoryQueue) Pop(ctx context.Context) (*RestDurableTask, error) {
+	select {
+	case task := <-queue.tasks:
+		queue.lock.Lock()
+		queue.pending[task] = true
+		queue.lock.Unlock()
+		return task, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (queue *RestMemoryQueue) Ack(_ context.Context, task *RestDurableTask) error {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+	delete(queue.pending, task)
+	return nil
+}
+
+// Pending 已取出未 Ack 的任务数
+func (queue *RestMemoryQueue) Pending() int {
+	queue.lock.Lock()
+	defer queue.lock.Unlock()
+	return len(queue.pending)
+}
@@ -0,0 +1,158 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDoAsyncDurable(t *testing.T) {
+	var lock sync.Mutex
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		lock.Lock()
+		calls = append(calls, r.Form.Get("method")+":"+r.Form.Get("content")+":"+r.Header.Get("X-Source"))
+		count := len(calls)
+		lock.Unlock()
+		switch {
+		case r.Form.Get("method") == "notify.bad":
+			_, _ = w.Write([]byte(`{"result":{"code":"400","state":"fail","message":"param error"}}`))
+		case count == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"}}`))
+		}
+	}))
+	defer server.Close()
+	manager := NewRestClientManager()
+	defer manager.Close()
+	manager.SetRestConfig(&AppRestConfig{Name: "test", AppUrl: server.URL})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{Method: "notify.send"},
+		2: &AppRestBuild{Method: "notify.bad"},
+	}})
+	if err := client.DoAsyncDurable(context.Background(), 1, nil); ErrorCode(err) != ErrDurable {
+		t.Error("durable without queue should fail", err)
+	}
+
+	queue := NewRestMemoryQueue(10)
+	manager.SetDurableQueue(queue)
+	ctx := WithHeader(context.Background(), "X-Source", "order")
+	if err := client.DoAsyncDurable(ctx, 1, map[string]interface{}{"order_id": 10000000000000001}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DoAsyncDurable(ctx, 2, map[string]interface{}{"order_id": 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DoAsyncDurable(ctx, 3, nil); ErrorCode(err) != ErrApiNotFound {
+		t.Error("durable should check api key", err)
+	}
+
+	failed := make(chan *RestDurableTask, 1)
+	worker := NewRestDurableWorker(manager, nil)
+	worker.Wait = time.Millisecond
+	worker.OnFail = func(_ context.Context, task *RestDurableTask, _ error) {
+		failed <- task
+	}
+	runCtx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- worker.Run(runCtx)
+	}()
+	var task *RestDurableTask
+	select {
+	case task = <-failed:
+	case <-time.After(time.Second):
+		t.Fatal("fatal task should fail")
+	}
+	if task.Key != 2 || task.Attempts != 1 || task.Error == "" {
+		t.Error("fatal error should not retry", task)
+	}
+	deadline := time.Now().Add(time.Second)
+	for queue.Pending() > 0 || len(queue.tasks) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("durable task not finish")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Error("worker should stop without error", err)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	success := 0
+	for _, call := range calls {
+		if call == `notify.send:{"order_id":10000000000000001}:order` {
+			success++
+		}
+	}
+	if len(calls) != 3 || success != 2 {
+		t.Error("durable retry error", calls)
+	}
+}
+
+func TestRestDurableWorkerNotRegistered(t *testing.T) {
+	manager := NewRestClientManager()
+	defer manager.Close()
+	queue := NewRestMemoryQueue(1)
+	_ = queue.Push(context.Background(), &RestDurableTask{Id: "1", Api: "*rest_client.unknownApi"})
+	worker := NewRestDurableWorker(manager, queue)
+	var failErr error
+	worker.OnFail = func(_ context.Context, _ *RestDurableTask, err error) {
+		failErr = err
+	}
+	task, _ := queue.Pop(context.Background())
+	worker.execute(context.Background(), task)
+	if ErrorCode(failErr) != ErrDurable || queue.Pending() != 0 {
+		t.Error("not registered api should fail", failErr)
+	}
+}
+
+func TestDoAsyncDurableContext(t *testing.T) {
+	apps := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		apps <- r.Form.Get("app") + ":" + r.Header.Get("X-Region")
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"}}`))
+	}))
+	defer server.Close()
+	manager := NewRestClientManager()
+	defer manager.Close()
+	manager.SetRestConfig(&AppRestConfig{Name: "test", AppKey: "default", AppUrl: server.URL, Tenants: StaticTenantCredentials{
+		"t1": {AppKey: "tenant1"},
+	}})
+	queue := NewRestMemoryQueue(10)
+	manager.SetDurableQueue(queue)
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{Method: "notify.send"},
+		2: &AppRestBuild{Method: "notify.send", Codec: JsonCodec{}},
+	}})
+	for _, param := range []interface{}{[]byte("a"), strings.NewReader("a")} {
+		if err := client.DoAsyncDurable(context.Background(), 1, param); ErrorCode(err) != ErrDurable {
+			t.Error("raw param must reject", err)
+		}
+	}
+	if err := client.DoAsyncDurable(context.Background(), 2, map[string]int{"a": 1}); ErrorCode(err) != ErrDurable {
+		t.Error("codec build must reject", err)
+	}
+	ctx := WithOverride(WithTenant(context.Background(), "t1"), &RestOverride{Headers: map[string]string{"X-Region": "eu"}})
+	if err := client.DoAsyncDurable(WithPriority(ctx, PriorityLow), 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	task, _ := queue.Pop(context.Background())
+	if task.Api != "*github.com/hsbteam/rest_client.testBuildApi" || task.Tenant != "t1" || task.Priority == nil || *task.Priority != PriorityLow {
+		t.Error("durable task context wrong", task)
+	}
+	worker := NewRestDurableWorker(manager, queue)
+	worker.execute(context.Background(), task)
+	if app := <-apps; app != "tenant1:eu" {
+		t.Error("durable tenant or override lost", app)
+	}
+}
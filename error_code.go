@@ -0,0 +1,156 @@
+package rest_client
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// 错误码,对应 RestClientError.Code
+const (
+	ErrConfigMissing  = "1"  //服务配置不存在
+	ErrApiNotFound    = "2"  //接口KEY不存在
+	ErrPanic          = "3"  //请求过程中发生panic
+	ErrReaderEmpty    = "10" //请求内容为空
+	ErrConfigType     = "11" //服务配置类型错误
+	ErrUpload         = "12" //分片上传失败
+	ErrDownload       = "13" //下载失败
+	ErrChecksum       = "14" //校验值不匹配
+	ErrServerHttp     = "15" //服务端返回异常的HTTP状态码
+	ErrFaultDrop      = "16" //故障注入丢弃请求
+	ErrDeadlineBudget = "17" //剩余时间不足
+	ErrCanceled       = "18" //ctx取消或超时
+	ErrTenantMissing  = "19" //租户密钥不存在
+	ErrJsonValid      = "20" //JSON解析或校验失败
+	ErrPathNotExists  = "21" //JSON路径不存在
+	ErrTypeMismatch   = "22" //JSON值类型不匹配
+	ErrQueueFull      = "23" //请求排队已满
+	ErrTaskFail       = "24" //轮询的任务失败
+	ErrTimeout        = "25" //连接或等待返回超时
+	ErrRetryBudget    = "26" //重试预算不足,放弃重试
+	ErrCodec          = "27" //返回内容解码失败
+	ErrXmlValid       = "28" //XML编码或解析失败
+	ErrDurable        = "29" //持久化请求入队或执行失败
+	ErrCrypto         = "30" //业务内容加密或解密失败
+	ErrPrecision      = "31" //数字转换丢失精度
+	ErrTransform      = "32" //返回内容转换失败
+	ErrInvalidArg     = "33" //调用参数错误
+)
+
+// RestErrorCode 错误码说明
+type RestErrorCode struct {
+	Code string
+	Desc string
+}
+
+type restErrorRegistry struct {
+	lock     sync.RWMutex
+	desc     map[string]string
+	messages map[string]map[string]string //语言 => 错误码 => 消息
+}
+
+var restErrorCodes = &restErrorRegistry{
+	desc: map[string]string{
+		ErrConfigMissing:  "rest config not exists",
+		ErrApiNotFound:    "rest api not found",
+		ErrPanic:          "panic in request",
+		ErrReaderEmpty:    "request reader is empty",
+		ErrConfigType:     "rest config type is wrong",
+		ErrUpload:         "upload fail",
+		ErrDownload:       "download fail",
+		ErrChecksum:       "checksum mismatch",
+		ErrServerHttp:     "server http code error",
+		ErrFaultDrop:      "request dropped by fault injector",
+		ErrDeadlineBudget: "deadline budget not enough",
+		ErrCanceled:       "request canceled",
+		ErrTenantMissing:  "tenant credential not found",
+		ErrJsonValid:      "json decode or valid fail",
+		ErrPathNotExists:  "json path not exists",
+		ErrTypeMismatch:   "json value type mismatch",
+		ErrQueueFull:      "request queue is full",
+		ErrTaskFail:       "polled task fail",
+		ErrTimeout:        "request timeout",
+		ErrRetryBudget:    "retry budget exhausted",
+		ErrCodec:          "codec decode fail",
+		ErrXmlValid:       "xml encode or parse fail",
+		ErrDurable:        "durable request fail",
+		ErrCrypto:         "payload encrypt or decrypt fail",
+		ErrPrecision:      "number precision loss",
+		ErrTransform:      "response transform fail",
+		ErrInvalidArg:     "invalid argument",
+	},
+	messages: map[string]map[string]string{},
+}
+
+// RegisterErrorCode 注册自定义错误码说明,已存在时覆盖
+func RegisterErrorCode(code, desc string) {
+	restErrorCodes.lock.Lock()
+	defer restErrorCodes.lock.Unlock()
+	restErrorCodes.desc[code] = desc
+}
+
+// ErrorCodeDesc 获取错误码说明,未注册时返回空
+func ErrorCodeDesc(code string) string {
+	restErrorCodes.lock.RLock()
+	defer restErrorCodes.lock.RUnlock()
+	return restErrorCodes.desc[code]
+}
+
+// ErrorCodes 获取已注册的全部错误码,按错误码排序
+func ErrorCodes() []RestErrorCode {
+	restErrorCodes.lock.RLock()
+	defer restErrorCodes.lock.RUnlock()
+	out := make([]RestErrorCode, 0, len(restErrorCodes.desc))
+	for code, desc := range restErrorCodes.desc {
+		out = append(out, RestErrorCode{Code: code, Desc: desc})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, aErr := strconv.Atoi(out[i].Code)
+		b, bErr := strconv.Atoi(out[j].Code)
+		if aErr == nil && bErr == nil {
+			return a < b
+		}
+		return out[i].Code < out[j].Code
+	})
+	return out
+}
+
+// SetErrorMessages 设置错误码在指定语言下的消息,如 SetErrorMessages("zh", map[string]string{ErrCanceled: "请求已取消"})
+func SetErrorMessages(lang string, messages map[string]string) {
+	restErrorCodes.lock.Lock()
+	defer restErrorCodes.lock.Unlock()
+	set, ok := restErrorCodes.messages[lang]
+	if !ok {
+		set = map[string]string{}
+		restErrorCodes.messages[lang] = set
+	}
+	for code, msg := range messages {
+		set[code] = msg
+	}
+}
+
+// ErrorCode 获取错误的错误码,非 RestClientError 时返回空
+func ErrorCode(err error) string {
+	var rErr *RestClientError
+	if errors.As(err, &rErr) {
+		return rErr.Code
+	}
+	return ""
+}
+
+// LocalizeError 获取错误在指定语言下的消息,未设置该语言消息时返回原始错误信息
+func LocalizeError(err error, lang string) string {
+	if err == nil {
+		return ""
+	}
+	if code := ErrorCode(err); len(code) > 0 {
+		restErrorCodes.lock.RLock()
+		msg, ok := restErrorCodes.messages[lang][code]
+		restErrorCodes.lock.RUnlock()
+		if ok {
+			return msg
+		}
+	}
+	return err.Error()
+}
@@ -0,0 +1,26 @@
+package rest_client
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestErrorCode(t *testing.T) {
+	err := cancelError(context.Canceled)
+	if ErrorCode(err) != ErrCanceled || ErrorCode(errors.New("other")) != "" {
+		t.Error("error code wrong")
+	}
+	if ErrorCodeDesc(ErrCanceled) != "request canceled" {
+		t.Error("error code desc wrong")
+	}
+	RegisterErrorCode("100", "custom error")
+	codes := ErrorCodes()
+	if codes[0].Code != ErrConfigMissing || codes[len(codes)-1].Code != "100" {
+		t.Error("error codes sort wrong")
+	}
+	SetErrorMessages("zh", map[string]string{ErrCanceled: "请求已取消"})
+	if LocalizeError(err, "zh") != "请求已取消" || LocalizeError(err, "en") != err.Error() {
+		t.Error("localize error wrong")
+	}
+}
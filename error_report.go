@@ -0,0 +1,219 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultRedactKeys 默认脱敏的参数名
+var DefaultRedactKeys = []string{"password", "passwd", "secret", "app_secret", "token", "access_token", "sign"}
+
+// RestErrorReport 失败请求的上报内容
+type RestErrorReport struct {
+	ConfigName string
+	Key        int
+	Method     string
+	Url        string
+	HttpCode   int
+	Header     http.Header //返回HEADER,已脱敏
+	Request    string      //请求内容,已脱敏及截断
+	Response   string      //返回内容,已脱敏及截断
+	Err        error
+	Panic      *RestPanicError //panic 时不为nil
+	Caller     *RestCallerInfo //发起请求的调用方,非 Do 发起的请求为nil
+}
+
+// RestErrorReporter 错误上报,如封装 sentry.CaptureException
+type RestErrorReporter interface {
+	Report(ctx context.Context, report *RestErrorReport)
+}
+
+// RestErrorReporterFunc 函数形式的错误上报
+type RestErrorReporterFunc func(ctx context.Context, report *RestErrorReport)
+
+func (fn RestErrorReporterFunc) Report(ctx context.Context, report *RestErrorReport) {
+	fn(ctx, report)
+}
+
+// NewRestPanicReporter 创建上报panic的回调,用于 RestClientManager.SetPanicHandler
+func NewRestPanicReporter(reporter RestErrorReporter) RestPanicHandler {
+	return func(ctx context.Context, err *RestPanicError) {
+		reporter.Report(ctx, &RestErrorReport{
+			ConfigName: err.ConfigName,
+			Key:        err.Key,
+			Err:        err,
+			Panic:      err,
+			Caller:     err.Caller,
+		})
+	}
+}
+
+var restRedactHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// RedactBody 创建内容脱敏函数,支持表单及JSON内容,表单中的JSON值同样脱敏
+// @param keys 需脱敏的参数名,不传时使用 DefaultRedactKeys
+func RedactBody(keys ...string) func(body []byte) []byte {
+	if len(keys) == 0 {
+		keys = DefaultRedactKeys
+	}
+	quoted := make([]string, 0, len(keys))
+	redact := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		quoted = append(quoted, regexp.QuoteMeta(key))
+		redact[key] = true
+	}
+	jsonReg := regexp.MustCompile(`"(` + strings.Join(quoted, "|") + `)"\s*:\s*("(?:[^"\\]|\\.)*"|[^,}\]\s]+)`)
+	redactJson := func(body string) string {
+		return jsonReg.ReplaceAllString(body, `"$1":"***"`)
+	}
+	return func(body []byte) []byte {
+		str := strings.TrimSpace(string(body))
+		if strings.HasPrefix(str, "{") || strings.HasPrefix(str, "[") {
+			return []byte(redactJson(str))
+		}
+		form, err := url.ParseQuery(str)
+		if err != nil || len(form) == 0 {
+			return body
+		}
+		for key, vals := range form {
+			for i, val := range vals {
+				if redact[key] {
+					vals[i] = "***"
+				} else if strings.HasPrefix(val, "{") || strings.HasPrefix(val, "[") {
+					vals[i] = redactJson(val)
+				}
+			}
+		}
+		return []byte(form.Encode())
+	}
+}
+
+// redactBody 脱敏并截断记录的内容,maxBody 为0时不截断
+func redactBody(body []byte, redact func(body []byte) []byte, maxBody int) string {
+	if redact != nil && len(body) > 0 {
+		body = redact(body)
+	}
+	if maxBody > 0 && len(body) > maxBody {
+		body = body[:maxBody]
+	}
+	return string(body)
+}
+
+// restBodyCapture 记录请求地址 状态码及内容,用于上报及审计事件,内容超过 maxBody 后不再记录
+type restBodyCapture struct {
+	method   string
+	url      string
+	httpCode int
+	header   http.Header
+	request  []byte
+	response []byte
+}
+
+func (capture *restBodyCapture) captureStart(method, url string) {
+	capture.method = method
+	capture.url = url
+}
+func (capture *restBodyCapture) captureRequest(p []byte, maxBody int) {
+	if len(capture.request) < maxBody {
+		capture.request = append(capture.request, p...)
+	}
+}
+func (capture *restBodyCapture) captureHeader(httpCode int, header http.Header) {
+	capture.httpCode = httpCode
+	capture.header = header
+}
+func (capture *restBodyCapture) captureResponse(p []byte, maxBody int) {
+	if len(capture.response) < maxBody {
+		capture.response = append(capture.response, p...)
+	}
+}
+
+// RestReportEvent 请求失败时上报的事件,包括网络错误,非正常的HTTP状态码及 CheckJsonResult 未通过
+// 同时转发回调及可选事件接口到 next,可与日志事件一起使用
+type RestReportEvent struct {
+	restEventForward
+	restBodyCapture
+	ctx      context.Context
+	reporter RestErrorReporter
+	next     RestEvent
+	Redact   func(body []byte) []byte //内容脱敏,默认 RedactBody()
+	MaxBody  int                      //上报内容最大长度,默认4096
+	once     sync.Once
+}
+
+// NewRestReportEvent 创建上报事件,在 EventCreate 中使用
+// @param next 同时回调的事件,可以为nil
+func NewRestReportEvent(ctx context.Context, reporter RestErrorReporter, next RestEvent) *RestReportEvent {
+	if next == nil {
+		next = &RestEventNoop{}
+	}
+	return &RestReportEvent{
+		restEventForward: restEventForward{next},
+		ctx:              ctx,
+		reporter:         reporter,
+		next:             next,
+		Redact:           RedactBody(),
+		MaxBody:          4096,
+	}
+}
+
+func (event *RestReportEvent) RequestStart(method, url string) {
+	event.captureStart(method, url)
+	event.next.RequestStart(method, url)
+}
+func (event *RestReportEvent) RequestRead(p []byte) {
+	event.captureRequest(p, event.MaxBody)
+	event.next.RequestRead(p)
+}
+func (event *RestReportEvent) ResponseHeader(httpCode int, header map[string][]string) {
+	event.captureHeader(httpCode, header)
+	event.next.ResponseHeader(httpCode, header)
+}
+func (event *RestReportEvent) ResponseRead(p []byte) {
+	event.captureResponse(p, event.MaxBody)
+	event.next.ResponseRead(p)
+}
+func (event *RestReportEvent) ResponseFinish(err error) {
+	if err != nil {
+		event.report(err)
+	}
+	event.next.ResponseFinish(err)
+}
+func (event *RestReportEvent) ResponseCheck(err error) {
+	if err == nil && event.httpCode >= 300 {
+		err = NewRestClientError(ErrServerHttp, "server http code:"+strconv.Itoa(event.httpCode))
+	}
+	if err != nil {
+		event.report(err)
+	}
+	event.next.ResponseCheck(err)
+}
+
+func (event *RestReportEvent) report(err error) {
+	event.once.Do(func() {
+		report := &RestErrorReport{
+			Method:   event.method,
+			Url:      event.url,
+			HttpCode: event.httpCode,
+			Request:  redactBody(event.request, event.Redact, event.MaxBody),
+			Response: redactBody(event.response, event.Redact, event.MaxBody),
+			Err:      err,
+		}
+		report.ConfigName, report.Key, _ = CallInfo(event.ctx)
+		report.Caller = Caller(event.ctx)
+		if event.header != nil {
+			report.Header = event.header.Clone()
+			for _, name := range restRedactHeaders {
+				if _, ok := report.Header[name]; ok {
+					report.Header[name] = []string{"***"}
+				}
+			}
+		}
+		event.reporter.Report(event.ctx, report)
+	})
+}
@@ -0,0 +1,73 @@
+package rest_client
+
+import (
+	"context"
+	"github.com/tidwall/gjson"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedactBody(t *testing.T) {
+	redact := RedactBody()
+	out := string(redact([]byte(`{"name":"a","password":"p\"1","token":123,"list":[{"secret":"s"}]}`)))
+	if out != `{"name":"a","password":"***","token":"***","list":[{"secret":"***"}]}` {
+		t.Error("redact json error:" + out)
+	}
+	out = string(redact([]byte(`app=a&sign=abc&content=%7B%22password%22%3A%22p%22%7D`)))
+	if out != `app=a&content=%7B%22password%22%3A%22%2A%2A%2A%22%7D&sign=%2A%2A%2A` {
+		t.Error("redact form error:" + out)
+	}
+	capture := &restBodyCapture{}
+	capture.captureRequest([]byte(`{"token":"t",`), 8)
+	capture.captureRequest([]byte(`"a":1}`), 8)
+	capture.captureRequest([]byte(`more`), 8)
+	if out = redactBody(capture.request, redact, 12); out != `{"token":"**` || redactBody(capture.request, nil, 0) != `{"token":"t",` {
+		t.Error("capture body error:" + out)
+	}
+}
+
+func TestRestReportEvent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "sid=1")
+		_, _ = w.Write([]byte(`{"result":{"code":"500","state":"fail","message":"fail"}}`))
+	}))
+	defer server.Close()
+	var reports []*RestErrorReport
+	reporter := RestErrorReporterFunc(func(_ context.Context, report *RestErrorReport) {
+		reports = append(reports, report)
+	})
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:      "test",
+		AppKey:    "dome1",
+		AppSecret: "dome111111",
+		AppUrl:    server.URL,
+		EventCreate: func(ctx context.Context) RestEvent {
+			return NewRestReportEvent(ctx, reporter, nil)
+		},
+	})
+	manager.SetPanicHandler(NewRestPanicReporter(reporter))
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{Method: "call"},
+		2: &testPanicBuild{},
+	}})
+	if err := (<-client.Do(context.Background(), 1, map[string]string{"password": "123"})).JsonResult().Err(); err == nil {
+		t.Fatal("fail result not return error")
+	}
+	if len(reports) != 1 {
+		t.Fatal("fail result not report")
+	}
+	report := reports[0]
+	if report.ConfigName != "test" || report.Key != 1 || report.Header.Get("Set-Cookie") != "***" {
+		t.Error("report info error")
+	}
+	if strings.Contains(report.Request, "123") || gjson.Get(report.Response, "result.code").String() != "500" {
+		t.Error("report body error:" + report.Request)
+	}
+	_ = (<-client.Do(context.Background(), 2, nil)).Err()
+	if len(reports) != 2 || reports[1].Panic == nil || reports[1].Key != 2 {
+		t.Error("panic not report")
+	}
+}
@@ -0,0 +1,156 @@
+package rest_client
+
+// RestCompositeEvent 同时回调多个事件,如指标,日志及链路追踪,不需要手写转发的包装事件
+// 可选事件接口(上传下载进度,废弃,镜像结果,慢请求,证书固定失败,关闭)转发给实现了该接口的事件
+type RestCompositeEvent []RestEvent
+
+// NewRestCompositeEvent 创建同时回调多个事件的事件,忽略nil,只有一个事件时直接返回该事件
+func NewRestCompositeEvent(events ...RestEvent) RestEvent {
+	composite := make(RestCompositeEvent, 0, len(events))
+	for _, event := range events {
+		if event != nil {
+			composite = append(composite, event)
+		}
+	}
+	switch len(composite) {
+	case 0:
+		return &RestEventNoop{}
+	case 1:
+		return composite[0]
+	}
+	return composite
+}
+
+func (events RestCompositeEvent) RequestStart(method, url string) {
+	for _, event := range events {
+		event.RequestStart(method, url)
+	}
+}
+func (events RestCompositeEvent) RequestRead(p []byte) {
+	for _, event := range events {
+		event.RequestRead(p)
+	}
+}
+func (events RestCompositeEvent) ResponseHeader(httpCode int, header map[string][]string) {
+	for _, event := range events {
+		event.ResponseHeader(httpCode, header)
+	}
+}
+func (events RestCompositeEvent) ResponseRead(p []byte) {
+	for _, event := range events {
+		event.ResponseRead(p)
+	}
+}
+func (events RestCompositeEvent) ResponseFinish(err error) {
+	for _, event := range events {
+		event.ResponseFinish(err)
+	}
+}
+func (events RestCompositeEvent) ResponseCheck(err error) {
+	for _, event := range events {
+		event.ResponseCheck(err)
+	}
+}
+
+func (events RestCompositeEvent) UploadProgress(sent, total int64, rate float64) {
+	for _, event := range events {
+		if progress, ok := event.(RestUploadEvent); ok {
+			progress.UploadProgress(sent, total, rate)
+		}
+	}
+}
+
+func (events RestCompositeEvent) DownloadProgress(written, total int64) {
+	for _, event := range events {
+		if progress, ok := event.(RestDownloadEvent); ok {
+			progress.DownloadProgress(written, total)
+		}
+	}
+}
+
+func (events RestCompositeEvent) Deprecated(info *RestDeprecation) {
+	for _, event := range events {
+		if dEvent, ok := event.(RestDeprecationEvent); ok {
+			dEvent.Deprecated(info)
+		}
+	}
+}
+
+func (events RestCompositeEvent) SlowCall(call *RestSlowCall) {
+	for _, event := range events {
+		if sEvent, ok := event.(RestSlowEvent); ok {
+			sEvent.SlowCall(call)
+		}
+	}
+}
+
+func (events RestCompositeEvent) PinFailed(err *RestPinError) {
+	for _, event := range events {
+		if pEvent, ok := event.(RestPinEvent); ok {
+			pEvent.PinFailed(err)
+		}
+	}
+}
+
+func (events RestCompositeEvent) MirrorResult(diff *RestMirrorDiff) {
+	for _, event := range events {
+		if mEvent, ok := event.(RestMirrorEvent); ok {
+			mEvent.MirrorResult(diff)
+		}
+	}
+}
+
+func (events RestCompositeEvent) ResponseClose() {
+	for _, event := range events {
+		if cEvent, ok := event.(RestCloseEvent); ok {
+			cEvent.ResponseClose()
+		}
+	}
+}
+
+// restEventForward 包装事件内嵌使用,将可选事件接口转发给被包装的事件,被包装的事件未实现时忽略
+type restEventForward struct {
+	target interface{}
+}
+
+func (forward restEventForward) UploadProgress(sent, total int64, rate float64) {
+	if progress, ok := forward.target.(RestUploadEvent); ok {
+		progress.UploadProgress(sent, total, rate)
+	}
+}
+
+func (forward restEventForward) DownloadProgress(written, total int64) {
+	if progress, ok := forward.target.(RestDownloadEvent); ok {
+		progress.DownloadProgress(written, total)
+	}
+}
+
+func (forward restEventForward) Deprecated(info *RestDeprecation) {
+	if dEvent, ok := forward.target.(RestDeprecationEvent); ok {
+		dEvent.Deprecated(info)
+	}
+}
+
+func (forward restEventForward) SlowCall(call *RestSlowCall) {
+	if sEvent, ok := forward.target.(RestSlowEvent); ok {
+		sEvent.SlowCall(call)
+	}
+}
+
+func (forward restEventForward) PinFailed(err *RestPinError) {
+	if pEvent, ok := forward.target.(RestPinEvent); ok {
+		pEvent.PinFailed(err)
+	}
+}
+
+func (forward restEventForward) MirrorResult(diff *RestMirrorDiff) {
+	if mEvent, ok := forward.target.(RestMirrorEvent); ok {
+		mEvent.MirrorResult(diff)
+	}
+}
+
+func (forward restEventForward) ResponseClose() {
+	if cEvent, ok := forward.target.(RestCloseEvent); ok {
+		cEvent.ResponseClose()
+	}
+}
@@ -0,0 +1,80 @@
+package rest_client
+
+import (
+	"context"
+	"github.com/tidwall/gjson"
+	"testing"
+)
+
+func TestRestCompositeEvent(t *testing.T) {
+	if _, ok := NewRestCompositeEvent(nil).(*RestEventNoop); !ok {
+		t.Error("empty composite not noop")
+	}
+	single := &testStartEvent{}
+	if NewRestCompositeEvent(nil, single) != single {
+		t.Error("single composite not self")
+	}
+	upload := &testUploadEvent{}
+	composite := NewRestCompositeEvent(single, upload)
+	composite.RequestStart("POST", "http://a")
+	composite.(RestUploadEvent).UploadProgress(10, 20, 1)
+	if single.url != "http://a" || upload.sent != 10 {
+		t.Error("composite forward wrong", single.url, upload.sent)
+	}
+}
+
+func TestAppEventCreates(t *testing.T) {
+	server := newTestAppServer(func(_ string, _ gjson.Result) string {
+		return `{}`
+	})
+	defer server.Close()
+	global, metrics, trace := &testStartEvent{}, &testStartEvent{}, &testStartEvent{}
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:      "test",
+		AppKey:    "dome1",
+		AppSecret: "dome111111",
+		AppUrl:    server.URL,
+		EventCreate: func(_ context.Context) RestEvent {
+			return global
+		},
+		EventCreates: []func(ctx context.Context) RestEvent{
+			func(_ context.Context) RestEvent { return metrics },
+			func(_ context.Context) RestEvent { return trace },
+		},
+	})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{1: &AppRestBuild{}}})
+	if err := (<-client.Do(context.Background(), 1, nil)).JsonResult().Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(global.url) == 0 || global.url != metrics.url || global.url != trace.url {
+		t.Error("event creates not called", global.url, metrics.url, trace.url)
+	}
+}
+
+type testSlowEventV2 struct {
+	testEventV2
+	testSlowEvent
+}
+
+func TestRestEventForward(t *testing.T) {
+	slow := &testSlowEvent{}
+	slowV2 := &testSlowEventV2{}
+	events := []RestEvent{
+		(&AppRestClockSkew{}).event(slow),
+		(&RestAuditor{}).event(context.Background(), slow),
+		NewRestReportEvent(context.Background(), nil, slow),
+		NewRestEventV2Adapter(context.Background(), slowV2),
+	}
+	for i, event := range events {
+		sEvent, ok := event.(RestSlowEvent)
+		if !ok {
+			t.Fatal("wrap event must forward slow event", i)
+		}
+		sEvent.SlowCall(&RestSlowCall{Key: i})
+		event.(RestPinEvent).PinFailed(&RestPinError{})
+	}
+	if len(slow.calls) != 3 || len(slowV2.calls) != 1 {
+		t.Error("forward slow call wrong", len(slow.calls), len(slowV2.calls))
+	}
+}
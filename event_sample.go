@@ -0,0 +1,137 @@
+package rest_client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AppRestEventSample 事件采样配置,在调用 EventCreate 前判断,避免高QPS时完整记录请求及返回拖垮日志
+// 满足任一条件的请求会创建事件,全部条件为空时不记录任何请求
+type AppRestEventSample struct {
+	Rate  int           //正常请求每N次记录1次,小于等于0不记录正常请求
+	Error bool          //记录出错的请求
+	Slow  time.Duration //大于0时记录耗时超过此值的请求
+	count uint32
+}
+
+// event 创建采样事件
+// 按比例选中的请求直接使用 EventCreate 创建的事件
+// 否则需要记录出错或慢请求时先缓存回调,请求结束时满足条件再创建事件并重放
+func (sample *AppRestEventSample) event(ctx context.Context, create func(ctx context.Context) RestEvent) RestEvent {
+	if sample.Rate > 0 && atomic.AddUint32(&sample.count, 1)%uint32(sample.Rate) == 0 {
+		return create(ctx)
+	}
+	if !sample.Error && sample.Slow <= 0 {
+		return &RestEventNoop{}
+	}
+	return &restSampleEvent{
+		ctx:    ctx,
+		create: create,
+		sample: sample,
+		start:  time.Now(),
+	}
+}
+
+// restSampleEvent 缓存回调的采样事件
+// 废弃,慢请求,证书固定失败及镜像结果的可选事件回调时创建事件并转发,上传下载进度仅在已创建事件后转发
+type restSampleEvent struct {
+	restEventForward
+	lock     sync.Mutex //镜像结果在其他协程回调
+	ctx      context.Context
+	create   func(ctx context.Context) RestEvent
+	sample   *AppRestEventSample
+	start    time.Time
+	event    RestEvent //满足条件后创建的事件
+	method   string
+	url      string
+	request  []byte
+	httpCode int
+	header   map[string][]string
+	hasHead  bool
+	response []byte
+}
+
+func (event *restSampleEvent) RequestStart(method, url string) {
+	event.method = method
+	event.url = url
+}
+func (event *restSampleEvent) RequestRead(p []byte) {
+	event.request = append(event.request, p...)
+}
+func (event *restSampleEvent) ResponseHeader(httpCode int, header map[string][]string) {
+	event.httpCode = httpCode
+	event.header = header
+	event.hasHead = true
+}
+func (event *restSampleEvent) ResponseRead(p []byte) {
+	event.response = append(event.response, p...)
+}
+func (event *restSampleEvent) ResponseFinish(err error) {
+	if created := event.created(); created != nil {
+		created.ResponseFinish(err)
+	} else if (err != nil && event.sample.Error) || (event.sample.Slow > 0 && time.Since(event.start) >= event.sample.Slow) {
+		event.replay().ResponseFinish(err)
+	}
+}
+func (event *restSampleEvent) ResponseCheck(err error) {
+	if event.created() == nil && err != nil && event.sample.Error {
+		event.replay().ResponseFinish(nil)
+	}
+	if created := event.created(); created != nil {
+		created.ResponseCheck(err)
+	}
+}
+
+func (event *restSampleEvent) Deprecated(info *RestDeprecation) {
+	event.replay()
+	event.restEventForward.Deprecated(info)
+}
+
+func (event *restSampleEvent) SlowCall(call *RestSlowCall) {
+	event.replay()
+	event.restEventForward.SlowCall(call)
+}
+
+func (event *restSampleEvent) PinFailed(err *RestPinError) {
+	event.replay()
+	event.restEventForward.PinFailed(err)
+}
+
+func (event *restSampleEvent) MirrorResult(diff *RestMirrorDiff) {
+	event.replay()
+	event.restEventForward.MirrorResult(diff)
+}
+
+// created 已创建的事件,未创建时为nil
+func (event *restSampleEvent) created() RestEvent {
+	event.lock.Lock()
+	defer event.lock.Unlock()
+	return event.event
+}
+
+// replay 创建事件并重放已缓存的回调,之后可选事件接口转发给创建的事件
+func (event *restSampleEvent) replay() RestEvent {
+	event.lock.Lock()
+	defer event.lock.Unlock()
+	if event.event != nil {
+		return event.event
+	}
+	event.event = event.create(event.ctx)
+	if len(event.url) > 0 {
+		event.event.RequestStart(event.method, event.url)
+	}
+	if len(event.request) > 0 {
+		event.event.RequestRead(event.request)
+	}
+	if event.hasHead {
+		event.event.ResponseHeader(event.httpCode, event.header)
+	}
+	if len(event.response) > 0 {
+		event.event.ResponseRead(event.response)
+	}
+	event.request, event.response = nil, nil
+	event.target = event.event
+	return event.event
+}
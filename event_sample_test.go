@@ -0,0 +1,113 @@
+package rest_client
+
+import (
+	"context"
+	"github.com/tidwall/gjson"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAppRestEventSample(t *testing.T) {
+	server := newTestAppServer(func(method string, _ gjson.Result) string {
+		if method == "slow" {
+			time.Sleep(30 * time.Millisecond)
+		}
+		return `{}`
+	})
+	defer server.Close()
+	var logs []string
+	sample := &AppRestEventSample{Rate: 5, Error: true, Slow: 20 * time.Millisecond}
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:   "test",
+		AppUrl: server.URL,
+		EventCreate: func(_ context.Context) RestEvent {
+			return NewAppRestEvent(func(_ string, url string, httpCode int, _ map[string][]string, _ []byte, response []byte, err error) {
+				if httpCode == 200 && len(response) == 0 && err == nil {
+					t.Error("sample event not replay")
+				}
+				logs = append(logs, url)
+			})
+		},
+		EventSample: sample,
+	})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{Method: "fast"},
+		2: &AppRestBuild{Method: "slow"},
+		3: &AppRestBuild{HttpMethod: http.MethodPost, Path: "\n"},
+	}})
+	for i := 0; i < 10; i++ {
+		_ = (<-client.Do(context.Background(), 1, nil)).JsonResult()
+	}
+	if len(logs) != 2 {
+		t.Errorf("rate sample error:%d", len(logs))
+	}
+	_ = (<-client.Do(context.Background(), 2, nil)).JsonResult()
+	if len(logs) != 3 {
+		t.Error("slow call not log")
+	}
+	_ = (<-client.Do(context.Background(), 3, nil)).JsonResult()
+	if len(logs) != 4 {
+		t.Error("error call not log")
+	}
+	sample.Rate, sample.Error, sample.Slow = 0, false, 0
+	_ = (<-client.Do(context.Background(), 3, nil)).JsonResult()
+	if len(logs) != 4 {
+		t.Error("disabled sample log")
+	}
+}
+
+// testSampleDeprecatedEvent 记录废弃回调的事件
+type testSampleDeprecatedEvent struct {
+	RestEventNoop
+	deprecated []*RestDeprecation
+	finished   int
+}
+
+func (event *testSampleDeprecatedEvent) Deprecated(info *RestDeprecation) {
+	event.deprecated = append(event.deprecated, info)
+}
+
+func (event *testSampleDeprecatedEvent) ResponseFinish(_ error) {
+	event.finished++
+}
+
+func TestAppRestEventSampleForward(t *testing.T) {
+	server := newTestAppServer(func(_ string, _ gjson.Result) string {
+		return `{}`
+	})
+	defer server.Close()
+	deprecated := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"}}`))
+	}))
+	defer deprecated.Close()
+	var events []*testSampleDeprecatedEvent
+	manager := NewRestClientManager()
+	for name, url := range map[string]string{"ok": server.URL, "old": deprecated.URL} {
+		manager.SetRestConfig(&AppRestConfig{
+			Name:   name,
+			AppUrl: url,
+			EventCreate: func(_ context.Context) RestEvent {
+				event := &testSampleDeprecatedEvent{}
+				events = append(events, event)
+				return event
+			},
+			EventSample: &AppRestEventSample{Error: true},
+		})
+	}
+	builds := map[int]RestBuild{1: &AppRestBuild{Method: "test"}}
+	_ = (<-manager.NewApi(&testBuildApi{name: "ok", builds: builds}).Do(context.Background(), 1, nil)).JsonResult()
+	if len(events) != 0 {
+		t.Fatal("normal request must not create event")
+	}
+	//未采样的请求在可选事件回调时创建事件并转发
+	if err := (<-manager.NewApi(&testBuildApi{name: "old", builds: builds}).Do(context.Background(), 1, nil)).JsonResult().Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || len(events[0].deprecated) != 1 || events[0].finished != 1 {
+		t.Error("sample event not forward deprecated", len(events))
+	}
+}
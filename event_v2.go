@@ -0,0 +1,138 @@
+package rest_client
+
+import (
+	"context"
+	"time"
+)
+
+// RestEventInfo 事件回调时的请求信息,用于关联同一请求的多次回调及按次记录重试
+type RestEventInfo struct {
+	Ctx        context.Context
+	ConfigName string
+	Key        int
+	Attempt    int             //第几次发送,从1开始,重试及重新签名时递增
+	Caller     *RestCallerInfo //发起请求的调用方,非 Do 发起的请求为nil
+	Start      time.Time       //本次发送的开始时间,RequestStart 前为零值
+	Time       time.Time       //回调时间
+}
+
+// RestEventV2 带请求信息的事件接口,通过服务配置的 EventCreateV2 或 WithEventV2 设置,回调时机同 RestEvent
+type RestEventV2 interface {
+	RequestStart(info RestEventInfo, method, url string)
+	RequestRead(info RestEventInfo, p []byte)
+	ResponseHeader(info RestEventInfo, httpCode int, header map[string][]string)
+	ResponseRead(info RestEventInfo, p []byte)
+	ResponseFinish(info RestEventInfo, err error)
+	ResponseCheck(info RestEventInfo, err error)
+}
+
+type attemptKey struct{}
+
+func withAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// Attempt 获取当前是第几次发送,可在 EventCreate 中使用,非请求过程中为0
+func Attempt(ctx context.Context) int {
+	attempt, _ := ctx.Value(attemptKey{}).(int)
+	return attempt
+}
+
+// NewRestEventV2Adapter 将 RestEventV2 转为 RestEvent,请求信息从ctx获取,ctx 为 EventCreate 的参数
+func NewRestEventV2Adapter(ctx context.Context, event RestEventV2) RestEvent {
+	info := RestEventInfo{
+		Ctx:     ctx,
+		Attempt: Attempt(ctx),
+		Caller:  Caller(ctx),
+	}
+	if info.Attempt <= 0 {
+		info.Attempt = 1
+	}
+	info.ConfigName, info.Key, _ = CallInfo(ctx)
+	return &restEventV2Adapter{restEventForward: restEventForward{event}, event: event, info: info}
+}
+
+// restEventV2Adapter 回调时补充请求信息,可选事件接口转发给 RestEventV2 的实现
+type restEventV2Adapter struct {
+	restEventForward
+	event RestEventV2
+	info  RestEventInfo
+}
+
+func (adapter *restEventV2Adapter) now() RestEventInfo {
+	info := adapter.info
+	info.Time = time.Now()
+	return info
+}
+
+func (adapter *restEventV2Adapter) RequestStart(method, url string) {
+	info := adapter.now()
+	adapter.info.Start, info.Start = info.Time, info.Time
+	adapter.event.RequestStart(info, method, url)
+}
+func (adapter *restEventV2Adapter) RequestRead(p []byte) {
+	adapter.event.RequestRead(adapter.now(), p)
+}
+func (adapter *restEventV2Adapter) ResponseHeader(httpCode int, header map[string][]string) {
+	adapter.event.ResponseHeader(adapter.now(), httpCode, header)
+}
+func (adapter *restEventV2Adapter) ResponseRead(p []byte) {
+	adapter.event.ResponseRead(adapter.now(), p)
+}
+func (adapter *restEventV2Adapter) ResponseFinish(err error) {
+	adapter.event.ResponseFinish(adapter.now(), err)
+}
+func (adapter *restEventV2Adapter) ResponseCheck(err error) {
+	adapter.event.ResponseCheck(adapter.now(), err)
+}
+
+// NewRestEventV1Adapter 将已有的 RestEvent 实现转为 RestEventV2,忽略请求信息,用于逐步迁移
+func NewRestEventV1Adapter(event RestEvent) RestEventV2 {
+	return &restEventV1Adapter{event: event}
+}
+
+type restEventV1Adapter struct {
+	event RestEvent
+}
+
+func (adapter *restEventV1Adapter) RequestStart(_ RestEventInfo, method, url string) {
+	adapter.event.RequestStart(method, url)
+}
+func (adapter *restEventV1Adapter) RequestRead(_ RestEventInfo, p []byte) {
+	adapter.event.RequestRead(p)
+}
+func (adapter *restEventV1Adapter) ResponseHeader(_ RestEventInfo, httpCode int, header map[string][]string) {
+	adapter.event.ResponseHeader(httpCode, header)
+}
+func (adapter *restEventV1Adapter) ResponseRead(_ RestEventInfo, p []byte) {
+	adapter.event.ResponseRead(p)
+}
+func (adapter *restEventV1Adapter) ResponseFinish(_ RestEventInfo, err error) {
+	adapter.event.ResponseFinish(err)
+}
+func (adapter *restEventV1Adapter) ResponseCheck(_ RestEventInfo, err error) {
+	adapter.event.ResponseCheck(err)
+}
+
+// eventCreate 服务配置的事件创建,EventCreateV2 优先,设置了 EventCreates 时同时回调
+func eventCreate(create func(ctx context.Context) RestEvent, createV2 func(ctx context.Context) RestEventV2,
+	creates []func(ctx context.Context) RestEvent) func(ctx context.Context) RestEvent {
+	if createV2 != nil {
+		create = func(ctx context.Context) RestEvent {
+			return NewRestEventV2Adapter(ctx, createV2(ctx))
+		}
+	}
+	if len(creates) == 0 {
+		return create
+	}
+	return func(ctx context.Context) RestEvent {
+		events := make([]RestEvent, 0, len(creates)+1)
+		if create != nil {
+			events = append(events, create(ctx))
+		}
+		for _, item := range creates {
+			events = append(events, item(ctx))
+		}
+		return NewRestCompositeEvent(events...)
+	}
+}
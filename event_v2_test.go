@@ -0,0 +1,102 @@
+package rest_client
+
+import (
+	"context"
+	"github.com/tidwall/gjson"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// testEventV2 记录每次回调的请求信息
+type testEventV2 struct {
+	lock   sync.Mutex
+	starts []RestEventInfo
+	checks []RestEventInfo
+	codes  []int
+}
+
+func (event *testEventV2) RequestStart(info RestEventInfo, _, _ string) {
+	event.lock.Lock()
+	event.starts = append(event.starts, info)
+	event.lock.Unlock()
+}
+func (event *testEventV2) RequestRead(_ RestEventInfo, _ []byte) {}
+func (event *testEventV2) ResponseHeader(_ RestEventInfo, httpCode int, _ map[string][]string) {
+	event.lock.Lock()
+	event.codes = append(event.codes, httpCode)
+	event.lock.Unlock()
+}
+func (event *testEventV2) ResponseRead(_ RestEventInfo, _ []byte)  {}
+func (event *testEventV2) ResponseFinish(_ RestEventInfo, _ error) {}
+func (event *testEventV2) ResponseCheck(info RestEventInfo, _ error) {
+	event.lock.Lock()
+	event.checks = append(event.checks, info)
+	event.lock.Unlock()
+}
+
+func TestRestEventV2(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":{}}`))
+	}))
+	defer server.Close()
+	event := &testEventV2{}
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:      "test",
+		AppKey:    "dome1",
+		AppSecret: "dome111111",
+		AppUrl:    server.URL,
+		Retry:     &AppRestRetry{Max: 1},
+		EventCreate: func(ctx context.Context) RestEvent {
+			t.Error("EventCreate called with EventCreateV2")
+			return &RestEventNoop{}
+		},
+		EventCreateV2: func(ctx context.Context) RestEventV2 {
+			return event
+		},
+	})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{3: &AppRestBuild{Idempotent: true}}})
+	if err := (<-client.Do(context.Background(), 3, nil)).JsonResult().Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(event.starts) != 2 || len(event.codes) != 2 || event.codes[0] != http.StatusServiceUnavailable {
+		t.Fatal("event callbacks wrong", len(event.starts), event.codes)
+	}
+	for i, info := range event.starts {
+		if info.Attempt != i+1 || info.ConfigName != "test" || info.Key != 3 || info.Start.IsZero() || info.Caller == nil {
+			t.Error("start info wrong", info)
+		}
+	}
+	check := event.checks[len(event.checks)-1]
+	if check.Attempt != 2 || check.Start != event.starts[1].Start || check.Time.Before(check.Start) {
+		t.Error("check info wrong", check)
+	}
+}
+
+func TestRestEventV1Adapter(t *testing.T) {
+	server := newTestAppServer(func(method string, content gjson.Result) string {
+		return `{}`
+	})
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{1: &AppRestBuild{}})
+	event := &testEventV2{}
+	if err := (<-client.Do(WithEventV2(context.Background(), event), 1, nil)).JsonResult().Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(event.starts) != 1 || event.starts[0].Attempt != 1 || event.starts[0].ConfigName != "test" {
+		t.Error("context event v2 wrong", event.starts)
+	}
+	start := &testStartEvent{}
+	NewRestEventV1Adapter(start).RequestStart(RestEventInfo{}, http.MethodPost, "http://a")
+	if start.url != "http://a" {
+		t.Error("v1 adapter wrong")
+	}
+}
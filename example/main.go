@@ -81,15 +81,13 @@ func main() {
 		return
 	}
 	fmt.Printf("data:%s", data.GetData(""))
-	//调用接口
-	data.GetData(rest_client.JsonKey{
-		Path: "data",
-		//ToType: func(result gjson.Result) interface{} {
-		//	return result.String()
-		//},
-		Tag: "glen:10",
-		JsonValid: &rest_client.JsonValid{
-			Context: context.Background(),
-		},
-	})
+	//绑定到结构并校验,校验失败时返回全部未通过字段
+	var product struct {
+		Id   string `json:"id" validate:"required"`
+		Name string `json:"name" validate:"required,max=10"`
+	}
+	if err := data.Bind("data", &product); err != nil {
+		fmt.Printf("error:%s", err)
+		return
+	}
 }
@@ -0,0 +1,439 @@
+package rest_client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// RestExportOption 导出接口描述的设置
+type RestExportOption struct {
+	Title    string                         //文档标题,默认 rest_client
+	Version  string                         //文档版本,默认 1.0
+	Examples map[string]map[int]interface{} //参数示例,KEY为服务配置名及接口KEY,合并服务默认参数及参数规则后导出
+}
+
+// restExportOperation 导出的单个接口
+type restExportOperation struct {
+	configName string
+	key        int
+	baseUrl    string
+	appKey     string
+	token      bool
+	version    string
+	build      *AppRestBuild
+	example    interface{} //合并默认参数后的示例参数,未设置时为nil
+	content    string      //签名格式的 content 示例
+}
+
+func (op *restExportOperation) httpMethod() string {
+	return appHttpMethod(op.build.HttpMethod)
+}
+
+func (op *restExportOperation) name() string {
+	if len(op.build.Method) > 0 {
+		return op.build.Method
+	}
+	return op.build.Path
+}
+
+// signedFields 签名格式的请求字段及示例值,按字段名排序
+func (op *restExportOperation) signedFields() [][2]string {
+	fields := [][2]string{
+		{"app", op.appKey},
+		{"content", op.content},
+	}
+	if len(op.build.Method) > 0 {
+		fields = append(fields, [2]string{"method", op.build.Method})
+	}
+	fields = append(fields, [2]string{"sign", "{{sign}}"}, [2]string{"timestamp", "{{timestamp}}"})
+	if op.token {
+		fields = append(fields, [2]string{"token", "{{token}}"})
+	}
+	return append(fields, [2]string{"version", op.version})
+}
+
+// exportOperations 读取接口定义,仅导出 AppRestBuild 定义的接口
+func (c *RestClientManager) exportOperations(ctx context.Context, option *RestExportOption, apis []RestApi) ([]*restExportOperation, error) {
+	var out []*restExportOperation
+	for _, api := range apis {
+		configName, err := api.ConfigName(ctx)
+		if err != nil {
+			return nil, err
+		}
+		config, ok := c.restConfig[configName]
+		if !ok {
+			return nil, NewRestClientError(ErrConfigMissing, fmt.Sprintf("rest config not exists:%s", configName))
+		}
+		appConfig, ok := config.(*AppRestConfig)
+		if !ok {
+			return nil, NewRestClientError(ErrConfigType, fmt.Sprintf("rest config type is wrong:%s", configName))
+		}
+		builds, err := api.ConfigBuilds(ctx)
+		if err != nil {
+			return nil, err
+		}
+		baseUrl := appConfig.AppUrl
+		if balancer, ok := appConfig.Balancer.(RestHealthBalancer); ok && len(baseUrl) == 0 && len(balancer.Urls()) > 0 {
+			baseUrl = balancer.Urls()[0]
+		}
+		_, token := api.(RestTokenApi)
+		keys := make([]int, 0, len(builds))
+		for key := range builds {
+			keys = append(keys, key)
+		}
+		sort.Ints(keys)
+		for _, key := range keys {
+			build, ok := builds[key].(*AppRestBuild)
+			if !ok {
+				continue
+			}
+			op := &restExportOperation{
+				configName: configName,
+				key:        key,
+				baseUrl:    strings.TrimRight(baseUrl, "/"),
+				appKey:     appConfig.AppKey,
+				token:      token,
+				version:    build.appVersion(appConfig),
+				build:      build,
+			}
+			var example interface{}
+			hasExample := false
+			if option != nil && option.Examples[configName] != nil {
+				example, hasExample = option.Examples[configName][key]
+			}
+			//与请求时相同,未设置示例时也合并默认参数
+			op.example, err = mergeDefaultParams(appConfig.DefaultParams, example)
+			if err == nil {
+				op.example, err = applyParamRules(build.ParamRules, op.example)
+			}
+			if err != nil {
+				if hasExample {
+					return nil, fmt.Errorf("config:%s key:%d example error:%w", configName, key, err)
+				}
+				op.example = nil
+			}
+			if !build.Raw {
+				op.content = "{}"
+				if op.example != nil {
+					if op.content, err = encodeParam(op.example, build.paramEncoder(), appConfig.ParamEncoder); err != nil {
+						return nil, err
+					}
+				}
+			}
+			out = append(out, op)
+		}
+	}
+	return out, nil
+}
+
+// exportSchema 按示例值及参数规则生成JSON Schema
+func exportSchema(example interface{}, rules map[string]*AppParamRule) map[string]interface{} {
+	if example != nil {
+		//转为JSON的通用结构,结构体按json标签导出
+		if data, err := json.Marshal(example); err == nil {
+			_ = json.Unmarshal(data, &example)
+		}
+	}
+	schema := map[string]interface{}{}
+	switch val := example.(type) {
+	case map[string]interface{}:
+		schema["type"] = "object"
+		props := map[string]interface{}{}
+		for name, item := range val {
+			props[name] = exportSchema(item, nil)
+		}
+		if len(props) > 0 {
+			schema["properties"] = props
+		}
+	case []interface{}:
+		schema["type"] = "array"
+		if len(val) > 0 {
+			schema["items"] = exportSchema(val[0], nil)
+		} else {
+			schema["items"] = map[string]interface{}{}
+		}
+	case string:
+		schema["type"] = "string"
+	case float64:
+		schema["type"] = "number"
+		if val == float64(int64(val)) {
+			schema["type"] = "integer"
+		}
+	case bool:
+		schema["type"] = "boolean"
+	}
+	if len(rules) == 0 {
+		return schema
+	}
+	schema["type"] = "object"
+	props, _ := schema["properties"].(map[string]interface{})
+	if props == nil {
+		props = map[string]interface{}{}
+		schema["properties"] = props
+	}
+	var required []string
+	for name, rule := range rules {
+		prop, _ := props[name].(map[string]interface{})
+		if prop == nil {
+			prop = exportSchema(rule.Default, nil)
+			props[name] = prop
+		}
+		if rule.Default != nil {
+			prop["default"] = rule.Default
+		}
+		if len(rule.Enum) > 0 {
+			prop["type"] = "string"
+			prop["enum"] = rule.Enum
+		}
+		if rule.Required {
+			required = append(required, name)
+		}
+	}
+	if len(required) > 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+// exportQuery Raw 的GET等请求时 QueryParams 示例参数转为查询参数,其他参数作为请求内容发送
+func exportQuery(op *restExportOperation) url.Values {
+	query := url.Values{}
+	if params, ok := op.example.(QueryParams); ok {
+		query, _ = url.ParseQuery(params.Encode(op.build.QueryArrayStyle))
+	}
+	return query
+}
+
+// exportRawBody Raw 请求的内容类型及示例内容
+func exportRawBody(op *restExportOperation) (string, string) {
+	if params, ok := op.example.(QueryParams); ok {
+		return "application/x-www-form-urlencoded", params.Encode(op.build.QueryArrayStyle)
+	}
+	contentType := op.build.ContentType
+	if len(contentType) == 0 {
+		contentType = "application/json"
+	}
+	switch val := op.example.(type) {
+	case nil:
+		return contentType, ""
+	case []byte:
+		return contentType, string(val)
+	case string:
+		return contentType, val
+	}
+	body, _ := encodeParam(op.example, op.build.paramEncoder())
+	return contentType, body
+}
+
+// ExportOpenApi 按接口定义导出 OpenAPI 3 文档(JSON),描述调用的外部接口,供下游团队查看
+// 签名格式的接口导出为 app method timestamp content sign 等字段,并设置 x-method x-signed x-timeout 扩展,可由 restgen 重新导入
+func (c *RestClientManager) ExportOpenApi(ctx context.Context, option *RestExportOption, apis ...RestApi) ([]byte, error) {
+	ops, err := c.exportOperations(ctx, option, apis)
+	if err != nil {
+		return nil, err
+	}
+	title, version := "rest_client", "1.0"
+	if option != nil && len(option.Title) > 0 {
+		title = option.Title
+	}
+	if option != nil && len(option.Version) > 0 {
+		version = option.Version
+	}
+	paths := map[string]map[string]interface{}{}
+	for _, op := range ops {
+		operation := map[string]interface{}{
+			"operationId": fmt.Sprintf("%s_%d", op.configName, op.key),
+			"summary":     op.name(),
+			"tags":        []string{op.configName},
+			"servers":     []map[string]string{{"url": op.baseUrl}},
+		}
+		if timeout := op.build.ResponseHeaderTimeout; timeout > 0 {
+			operation["x-timeout"] = timeout.String()
+		} else if op.build.Timeout > 0 {
+			operation["x-timeout"] = op.build.Timeout.String()
+		}
+		hasBody := appMethodHasBody(op.httpMethod())
+		_, isQuery := op.example.(QueryParams)
+		if op.build.Raw {
+			if hasBody || (op.example != nil && !isQuery) {
+				contentType, body := exportRawBody(op)
+				media := map[string]interface{}{"schema": exportSchema(op.example, op.build.ParamRules)}
+				if len(body) > 0 {
+					media["example"] = body
+				}
+				operation["requestBody"] = map[string]interface{}{"content": map[string]interface{}{contentType: media}}
+			} else {
+				var params []map[string]interface{}
+				query := exportQuery(op)
+				names := make([]string, 0, len(query))
+				for name := range query {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				for _, name := range names {
+					params = append(params, map[string]interface{}{
+						"name": name, "in": "query", "example": query.Get(name), "schema": map[string]string{"type": "string"},
+					})
+				}
+				if len(params) > 0 {
+					operation["parameters"] = params
+				}
+			}
+			operation["responses"] = map[string]interface{}{"200": map[string]interface{}{"description": "返回内容"}}
+		} else {
+			if len(op.build.Method) > 0 {
+				operation["x-method"] = op.build.Method
+			} else {
+				operation["x-signed"] = true
+			}
+			props := map[string]interface{}{}
+			var required []string
+			for _, field := range op.signedFields() {
+				prop := map[string]interface{}{"type": "string", "example": field[1]}
+				switch field[0] {
+				case "content":
+					prop["description"] = "JSON参数"
+					prop["x-content-schema"] = exportSchema(op.example, op.build.ParamRules)
+				case "sign":
+					prop["description"] = "MD5签名,见 AppRestParamSign"
+				case "timestamp":
+					prop["description"] = "请求时间,格式 2006-01-02 15:04:05"
+				case "method", "version":
+					prop["enum"] = []string{field[1]}
+				}
+				props[field[0]] = prop
+				required = append(required, field[0])
+			}
+			form := map[string]interface{}{"type": "object", "properties": props, "required": required}
+			if hasBody {
+				operation["requestBody"] = map[string]interface{}{
+					"required": true,
+					"content":  map[string]interface{}{"application/x-www-form-urlencoded": map[string]interface{}{"schema": form}},
+				}
+			} else {
+				params := make([]map[string]interface{}, 0, len(required))
+				for _, name := range required {
+					params = append(params, map[string]interface{}{"name": name, "in": "query", "required": true, "schema": props[name]})
+				}
+				operation["parameters"] = params
+			}
+			result := map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"code":    map[string]interface{}{"type": "string", "example": "200"},
+					"state":   map[string]interface{}{"type": "string", "example": "ok"},
+					"message": map[string]interface{}{"type": "string"},
+				},
+			}
+			operation["responses"] = map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "result.code 为200且 result.state 为ok时成功,返回数据在 data 中",
+					"content": map[string]interface{}{"application/json": map[string]interface{}{"schema": map[string]interface{}{
+						"type":       "object",
+						"properties": map[string]interface{}{"result": result, "data": map[string]interface{}{}},
+					}}},
+				},
+			}
+		}
+		path := op.build.Path
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		method := strings.ToLower(op.httpMethod())
+		//不同服务存在相同路径及请求方式的接口时,路径后加服务配置名区分
+		if item, ok := paths[path]; ok && item[method] != nil {
+			path += "#" + op.configName
+		}
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][method] = operation
+	}
+	return json.MarshalIndent(map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    map[string]string{"title": title, "version": version},
+		"paths":   paths,
+	}, "", "  ")
+}
+
+// ExportPostman 按接口定义导出 Postman Collection v2.1,按服务配置分组
+// 签名格式接口的 timestamp sign token 为变量,需在 Postman 的 Pre-request Script 中计算
+func (c *RestClientManager) ExportPostman(ctx context.Context, option *RestExportOption, apis ...RestApi) ([]byte, error) {
+	ops, err := c.exportOperations(ctx, option, apis)
+	if err != nil {
+		return nil, err
+	}
+	title := "rest_client"
+	if option != nil && len(option.Title) > 0 {
+		title = option.Title
+	}
+	var folders []map[string]interface{}
+	folderIndex := map[string]int{}
+	for _, op := range ops {
+		rawUrl := op.baseUrl + op.build.Path
+		request := map[string]interface{}{"method": op.httpMethod()}
+		var header []map[string]string
+		hasBody := appMethodHasBody(op.httpMethod())
+		_, isQuery := op.example.(QueryParams)
+		if op.build.Raw {
+			if hasBody || (op.example != nil && !isQuery) {
+				contentType, body := exportRawBody(op)
+				if len(body) > 0 {
+					header = append(header, map[string]string{"key": "Content-Type", "value": contentType})
+					request["body"] = map[string]interface{}{"mode": "raw", "raw": body}
+				}
+			} else if query := exportQuery(op); len(query) > 0 {
+				rawUrl += "?" + query.Encode()
+			}
+		} else {
+			fields := op.signedFields()
+			if hasBody {
+				form := make([]map[string]string, 0, len(fields))
+				for _, field := range fields {
+					form = append(form, map[string]string{"key": field[0], "value": field[1]})
+				}
+				header = append(header, map[string]string{"key": "Content-Type", "value": "application/x-www-form-urlencoded"})
+				request["body"] = map[string]interface{}{"mode": "urlencoded", "urlencoded": form}
+			} else {
+				query := make([]string, 0, len(fields))
+				for _, field := range fields {
+					val := field[1]
+					if !strings.HasPrefix(val, "{{") {
+						val = url.QueryEscape(val)
+					}
+					query = append(query, field[0]+"="+val)
+				}
+				rawUrl += "?" + strings.Join(query, "&")
+			}
+			request["description"] = "签名格式接口,timestamp sign 需在 Pre-request Script 中按 AppRestParamSign 计算"
+		}
+		if header == nil {
+			header = []map[string]string{}
+		}
+		request["header"] = header
+		request["url"] = rawUrl
+		item := map[string]interface{}{"name": op.name(), "request": request}
+		idx, ok := folderIndex[op.configName]
+		if !ok {
+			idx = len(folders)
+			folderIndex[op.configName] = idx
+			folders = append(folders, map[string]interface{}{"name": op.configName, "item": []map[string]interface{}{}})
+		}
+		folders[idx]["item"] = append(folders[idx]["item"].([]map[string]interface{}), item)
+	}
+	if folders == nil {
+		folders = []map[string]interface{}{}
+	}
+	return json.MarshalIndent(map[string]interface{}{
+		"info": map[string]string{
+			"name":   title,
+			"schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json",
+		},
+		"item": folders,
+	}, "", "  ")
+}
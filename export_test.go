@@ -0,0 +1,148 @@
+package rest_client
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/hsbteam/rest_client/restgen"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestExportManager() (*RestClientManager, RestApi) {
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:          "product",
+		AppKey:        "dome1",
+		AppSecret:     "secret",
+		AppUrl:        "http://127.0.0.1:8080/",
+		DefaultParams: map[string]interface{}{"shop": 1},
+	})
+	return manager, &testBuildApi{name: "product", builds: map[int]RestBuild{
+		1: &AppRestBuild{Path: "/product/detail", Method: "detail", ResponseHeaderTimeout: 3 * time.Second, ParamRules: map[string]*AppParamRule{
+			"status": {Enum: []string{"on", "off"}, Default: "on"},
+		}},
+		2: &AppRestBuild{HttpMethod: http.MethodGet, Path: "/product/list", Raw: true},
+		3: &AppRestBuild{HttpMethod: http.MethodGet, Path: "/product/sku", Method: "sku"},
+	}}
+}
+
+func TestExportOpenApi(t *testing.T) {
+	manager, api := newTestExportManager()
+	data, err := manager.ExportOpenApi(context.Background(), &RestExportOption{
+		Title: "product",
+		Examples: map[string]map[int]interface{}{"product": {
+			1: map[string]interface{}{"id": 1},
+			2: QueryParams{"page": 2},
+		}},
+	}, api)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Paths map[string]map[string]struct {
+			OperationId string `json:"operationId"`
+			XMethod     string `json:"x-method"`
+			XTimeout    string `json:"x-timeout"`
+			Servers     []struct {
+				Url string `json:"url"`
+			} `json:"servers"`
+			Parameters []struct {
+				Name    string      `json:"name"`
+				Example interface{} `json:"example"`
+			} `json:"parameters"`
+			RequestBody struct {
+				Content map[string]struct {
+					Schema struct {
+						Properties map[string]struct {
+							Example       string                 `json:"example"`
+							ContentSchema map[string]interface{} `json:"x-content-schema"`
+						} `json:"properties"`
+					} `json:"schema"`
+				} `json:"content"`
+			} `json:"requestBody"`
+		} `json:"paths"`
+	}
+	if err = json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	detail := doc.Paths["/product/detail"]["post"]
+	if detail.OperationId != "product_1" || detail.XMethod != "detail" || detail.XTimeout != "3s" || detail.Servers[0].Url != "http://127.0.0.1:8080" {
+		t.Error("export operation error")
+	}
+	content := detail.RequestBody.Content["application/x-www-form-urlencoded"].Schema.Properties["content"]
+	if content.Example != `{"id":1,"shop":1,"status":"on"}` {
+		t.Error("export content example error:" + content.Example)
+	}
+	if props, ok := content.ContentSchema["properties"].(map[string]interface{}); !ok || props["status"] == nil || props["id"] == nil {
+		t.Error("export content schema error")
+	}
+	list := doc.Paths["/product/list"]["get"]
+	if len(list.Parameters) != 2 || list.Parameters[0].Name != "page" || list.Parameters[0].Example != "2" {
+		t.Error("export raw query error")
+	}
+	if len(doc.Paths["/product/sku"]["get"].Parameters) != 6 {
+		t.Error("export signed query error")
+	}
+
+	//导出的文档可由 restgen 重新生成接口代码
+	gen, err := restgen.ParseOpenApi(data, &restgen.OpenApiOption{Package: "product", Name: "ProductApi", ConfigName: "product"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := restgen.Generate(gen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(code), `Method:     "detail"`) || !strings.Contains(string(code), "3 * time.Second") {
+		t.Error("export openapi import error")
+	}
+
+	if _, err = manager.ExportOpenApi(context.Background(), nil, &testBuildApi{name: "none"}); err == nil {
+		t.Error("export missing config not error")
+	}
+}
+
+func TestExportPostman(t *testing.T) {
+	manager, api := newTestExportManager()
+	data, err := manager.ExportPostman(context.Background(), nil, api)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Item []struct {
+			Name string `json:"name"`
+			Item []struct {
+				Name    string `json:"name"`
+				Request struct {
+					Method string `json:"method"`
+					Url    string `json:"url"`
+					Body   struct {
+						Mode       string `json:"mode"`
+						Urlencoded []struct {
+							Key   string `json:"key"`
+							Value string `json:"value"`
+						} `json:"urlencoded"`
+					} `json:"body"`
+				} `json:"request"`
+			} `json:"item"`
+		} `json:"item"`
+	}
+	if err = json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Item) != 1 || doc.Item[0].Name != "product" || len(doc.Item[0].Item) != 3 {
+		t.Fatal("export postman folder error")
+	}
+	detail := doc.Item[0].Item[0].Request
+	if detail.Method != http.MethodPost || detail.Url != "http://127.0.0.1:8080/product/detail" || detail.Body.Mode != "urlencoded" {
+		t.Error("export postman request error")
+	}
+	if detail.Body.Urlencoded[1].Key != "content" || detail.Body.Urlencoded[1].Value != `{"shop":1,"status":"on"}` {
+		t.Error("export postman default param error")
+	}
+	if !strings.Contains(doc.Item[0].Item[2].Request.Url, "method=sku&sign={{sign}}") {
+		t.Error("export postman query error:" + doc.Item[0].Item[2].Request.Url)
+	}
+}
@@ -0,0 +1,152 @@
+package rest_client
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RestFaultRule 故障注入规则,用于测试重试,熔断等配置是否有效
+type RestFaultRule struct {
+	ConfigName string        //匹配的服务配置,为空匹配所有
+	Keys       []int         //匹配的接口KEY,为空匹配所有
+	Percent    float64       //触发比例 0-100,为0时总是触发
+	Latency    time.Duration //增加延迟
+	Drop       bool          //模拟连接断开,不发送请求直接返回错误
+	StatusCode int           //不发送请求直接返回指定HTTP状态码,如503
+	Corrupt    bool          //返回内容截断为一半,模拟损坏的JSON
+}
+
+func (rule *RestFaultRule) match(call *restCallInfo) bool {
+	if len(rule.ConfigName) > 0 && rule.ConfigName != call.configName {
+		return false
+	}
+	if len(rule.Keys) == 0 {
+		return true
+	}
+	for _, key := range rule.Keys {
+		if key == call.key {
+			return true
+		}
+	}
+	return false
+}
+
+// RestFaultInjector 故障注入,通过 RestClientManager.SetFaultInjector 设置,仅用于测试环境
+type RestFaultInjector struct {
+	lock  sync.RWMutex
+	rules []*RestFaultRule
+}
+
+// NewRestFaultInjector 创建故障注入
+func NewRestFaultInjector(rules ...*RestFaultRule) *RestFaultInjector {
+	return &RestFaultInjector{rules: rules}
+}
+
+// SetRules 替换故障注入规则
+func (fault *RestFaultInjector) SetRules(rules ...*RestFaultRule) {
+	fault.lock.Lock()
+	defer fault.lock.Unlock()
+	fault.rules = rules
+}
+
+func (fault *RestFaultInjector) find(call *restCallInfo) *RestFaultRule {
+	fault.lock.RLock()
+	defer fault.lock.RUnlock()
+	for _, rule := range fault.rules {
+		if !rule.match(call) {
+			continue
+		}
+		if rule.Percent <= 0 || rand.Float64()*100 < rule.Percent {
+			return rule
+		}
+	}
+	return nil
+}
+
+// restFaultTransport 注入故障的 RoundTripper
+type restFaultTransport struct {
+	fault *RestFaultInjector
+	next  http.RoundTripper
+}
+
+func (rt *restFaultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	call := contextCallInfo(req.Context())
+	if call == nil {
+		return rt.next.RoundTrip(req)
+	}
+	rule := rt.fault.find(call)
+	if rule == nil {
+		return rt.next.RoundTrip(req)
+	}
+	if rule.Latency > 0 {
+		timer := time.NewTimer(rule.Latency)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+	if rule.Drop {
+		return nil, NewRestClientError(ErrFaultDrop, "fault injection: connection dropped")
+	}
+	if rule.StatusCode > 0 {
+		body := "fault injection: http code " + strconv.Itoa(rule.StatusCode)
+		return &http.Response{
+			Status:        strconv.Itoa(rule.StatusCode) + " " + http.StatusText(rule.StatusCode),
+			StatusCode:    rule.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": {"text/plain"}},
+			Body:          ioutil.NopCloser(bytes.NewBufferString(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	res, err := rt.next.RoundTrip(req)
+	if err != nil || !rule.Corrupt {
+		return res, err
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	body = body[:len(body)/2]
+	res.Body = ioutil.NopCloser(bytes.NewReader(body))
+	res.ContentLength = int64(len(body))
+	res.Header.Del("Content-Length")
+	return res, nil
+}
+
+// restCallInfo 请求的服务配置及接口,通过请求上下文传递给 RoundTripper
+type restCallInfo struct {
+	configName string
+	key        int
+}
+
+type callInfoKey struct{}
+
+func withCallInfo(ctx context.Context, configName string, key int) context.Context {
+	return context.WithValue(ctx, callInfoKey{}, &restCallInfo{configName: configName, key: key})
+}
+
+func contextCallInfo(ctx context.Context) *restCallInfo {
+	call, _ := ctx.Value(callInfoKey{}).(*restCallInfo)
+	return call
+}
+
+// CallInfo 获取当前请求的服务配置名及接口KEY,可在 EventCreate 中使用
+func CallInfo(ctx context.Context) (configName string, key int, ok bool) {
+	if call := contextCallInfo(ctx); call != nil {
+		return call.configName, call.key, true
+	}
+	return "", 0, false
+}
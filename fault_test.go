@@ -0,0 +1,44 @@
+package rest_client
+
+import (
+	"context"
+	"github.com/tidwall/gjson"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRestFaultInjector(t *testing.T) {
+	server := newTestAppServer(func(_ string, _ gjson.Result) string {
+		return `{"id":"1"}`
+	})
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodPost},
+		2: &AppRestBuild{HttpMethod: http.MethodPost},
+	})
+	fault := NewRestFaultInjector(&RestFaultRule{Keys: []int{1}, StatusCode: 503})
+	client.manager.SetFaultInjector(fault)
+	if (<-client.Do(context.Background(), 1, nil)).JsonResult().Err() == nil {
+		t.Error("fault status code not inject")
+	}
+	if (<-client.Do(context.Background(), 2, nil)).JsonResult().Err() != nil {
+		t.Error("fault rule match error")
+	}
+	fault.SetRules(&RestFaultRule{ConfigName: "test", Drop: true})
+	if (<-client.Do(context.Background(), 2, nil)).Err() == nil {
+		t.Error("fault drop not inject")
+	}
+	fault.SetRules(&RestFaultRule{Corrupt: true, Latency: 50 * time.Millisecond})
+	start := time.Now()
+	if (<-client.Do(context.Background(), 2, nil)).JsonResult().Err() == nil {
+		t.Error("fault corrupt not inject")
+	}
+	if time.Since(start) < 50*time.Millisecond {
+		t.Error("fault latency not inject")
+	}
+	client.manager.SetFaultInjector(nil)
+	if (<-client.Do(context.Background(), 1, nil)).JsonResult().Err() != nil {
+		t.Error("fault remove error")
+	}
+}
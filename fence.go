@@ -0,0 +1,107 @@
+package rest_client
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/tidwall/gjson"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// AppRestFenceParam 按参数字段值生成并发隔离KEY,如同一个SKU的请求不并发执行
+// @param path 参数JSON中的路径,如 sku 或 product.sku
+func AppRestFenceParam(path string) func(param interface{}) string {
+	return func(param interface{}) string {
+		body, err := json.Marshal(param)
+		if err != nil {
+			return ""
+		}
+		return gjson.GetBytes(body, path).String()
+	}
+}
+
+type fenceLock struct {
+	ch  chan struct{}
+	ref int
+}
+
+// restFence 进程内按KEY互斥
+type restFence struct {
+	lock  sync.Mutex
+	locks map[string]*fenceLock
+}
+
+var restFences = &restFence{locks: map[string]*fenceLock{}}
+
+// acquire 获取KEY对应的锁,ctx结束时返回错误,成功时返回释放函数
+func (fence *restFence) acquire(ctx context.Context, name string) (func(), error) {
+	fence.lock.Lock()
+	item, ok := fence.locks[name]
+	if !ok {
+		item = &fenceLock{ch: make(chan struct{}, 1)}
+		fence.locks[name] = item
+	}
+	item.ref++
+	fence.lock.Unlock()
+
+	done := func() {
+		fence.lock.Lock()
+		item.ref--
+		if item.ref == 0 {
+			delete(fence.locks, name)
+		}
+		fence.lock.Unlock()
+	}
+	select {
+	case item.ch <- struct{}{}:
+	case <-ctx.Done():
+		done()
+		return nil, ctx.Err()
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			<-item.ch
+			done()
+		})
+	}, nil
+}
+
+// fenceName 生成隔离KEY,未配置或参数未生成KEY时返回空
+func (clt *AppRestBuild) fenceName(configName string, key int, param interface{}) string {
+	if clt.Fence == nil {
+		return ""
+	}
+	name := clt.Fence(param)
+	if len(name) == 0 {
+		return ""
+	}
+	return configName + "|" + strconv.Itoa(key) + "|" + name
+}
+
+// fenceBody 返回内容读取完成或关闭(如 RestResult.Close)时释放隔离锁,排队位置及超时
+type fenceBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+// newFenceBody 创建返回内容,调用方需读取完或关闭
+func newFenceBody(body io.ReadCloser, release func()) *fenceBody {
+	return &fenceBody{ReadCloser: body, release: release}
+}
+
+func (body *fenceBody) Read(p []byte) (int, error) {
+	n, err := body.ReadCloser.Read(p)
+	if err != nil {
+		body.once.Do(body.release)
+	}
+	return n, err
+}
+
+func (body *fenceBody) Close() error {
+	err := body.ReadCloser.Close()
+	body.once.Do(body.release)
+	return err
+}
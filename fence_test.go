@@ -0,0 +1,107 @@
+package rest_client
+
+import (
+	"context"
+	"github.com/tidwall/gjson"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAppRestFence(t *testing.T) {
+	var running, maxRunning int32
+	server := newTestAppServer(func(_ string, _ gjson.Result) string {
+		now := atomic.AddInt32(&running, 1)
+		if now > atomic.LoadInt32(&maxRunning) {
+			atomic.StoreInt32(&maxRunning, now)
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return `{}`
+	})
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodPost, Fence: AppRestFenceParam("sku")},
+	})
+	var wait sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			if err := (<-client.Do(context.Background(), 1, map[string]string{"sku": "a"})).JsonResult().Err(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wait.Wait()
+	if maxRunning != 1 {
+		t.Errorf("fence run concurrent:%d", maxRunning)
+	}
+	if len(restFences.locks) != 0 {
+		t.Error("fence lock not release")
+	}
+}
+
+func TestAppRestFenceClose(t *testing.T) {
+	server := newTestAppServer(func(_ string, _ gjson.Result) string {
+		return `{}`
+	})
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodPost, Fence: AppRestFenceParam("sku")},
+	})
+	param := map[string]string{"sku": "close"}
+	//只检查HEADER后关闭
+	res := <-client.Do(context.Background(), 1, param)
+	if err, _ := res.Header(); err != nil {
+		t.Fatal(err)
+	}
+	if err := res.Close(); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if err := (<-client.Do(ctx, 1, param)).JsonResult().Err(); err != nil {
+		t.Fatal("fence not release after close", err)
+	}
+	if err := (*RestResult)(nil).Close(); err != nil {
+		t.Error("nil result close", err)
+	}
+}
+
+func TestAppRestFenceScheduler(t *testing.T) {
+	server := newTestAppServer(func(_ string, _ gjson.Result) string {
+		return `{}`
+	})
+	defer server.Close()
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:      "test",
+		AppKey:    "dome1",
+		AppSecret: "dome111111",
+		AppUrl:    server.URL,
+		Scheduler: &AppRestScheduler{Concurrency: 2},
+	})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodPost, Fence: AppRestFenceParam("sku")},
+	}})
+	hold := <-client.Do(context.Background(), 1, map[string]string{"sku": "a"})
+	if err := hold.Err(); err != nil {
+		t.Fatal(err)
+	}
+	waited := make(chan error, 1)
+	go func() {
+		waited <- (<-client.Do(context.Background(), 1, map[string]string{"sku": "a"})).JsonResult().Err()
+	}()
+	time.Sleep(20 * time.Millisecond)
+	//等待隔离锁的请求不占用调度的并发数
+	if err := (<-client.Do(context.Background(), 1, map[string]string{"sku": "b"})).JsonResult().Err(); err != nil {
+		t.Error("fence wait hold scheduler slot", err)
+	}
+	_ = hold.Close()
+	if err := <-waited; err != nil {
+		t.Error("fence wait request fail", err)
+	}
+}
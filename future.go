@@ -0,0 +1,119 @@
+package rest_client
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// RestFuture 请求结果的封装,替代直接使用 Do 返回的通道
+// 结果只从通道接收一次,可多次 Get,回调按注册顺序在结果返回后执行
+// 注意 RestResult 的内容只能读取一次,多个回调中只应有一个读取内容
+type RestFuture struct {
+	done      chan struct{}
+	lock      sync.Mutex
+	res       *RestResult
+	callbacks []func(res *RestResult)
+}
+
+// NewRestFuture 通过 Do 返回的通道创建
+func NewRestFuture(rc <-chan *RestResult) *RestFuture {
+	future := &RestFuture{
+		done: make(chan struct{}),
+	}
+	go func() {
+		res, ok := <-rc
+		if !ok || res == nil {
+			res = NewRestResultFromError(errResultClosed(), nil)
+		}
+		future.lock.Lock()
+		future.res = res
+		callbacks := future.callbacks
+		future.callbacks = nil
+		close(future.done)
+		future.lock.Unlock()
+		for _, callback := range callbacks {
+			callback(res)
+		}
+	}()
+	return future
+}
+
+// errResultClosed Do 返回的通道已关闭,结果已被接收
+func errResultClosed() error {
+	return NewRestClientError(ErrPanic, "rest result channel closed")
+}
+
+// DoFuture 执行请求并返回 RestFuture
+func (client *RestClient) DoFuture(ctx context.Context, key int, param interface{}, opts ...DoOption) *RestFuture {
+	return NewRestFuture(client.Do(ctx, key, param, opts...))
+}
+
+// Done 结果返回后回调,无论是否出错
+func (future *RestFuture) Done(callback func(res *RestResult)) *RestFuture {
+	future.lock.Lock()
+	if future.res == nil {
+		future.callbacks = append(future.callbacks, callback)
+		future.lock.Unlock()
+		return future
+	}
+	res := future.res
+	future.lock.Unlock()
+	callback(res)
+	return future
+}
+
+// Then 请求成功时回调
+func (future *RestFuture) Then(callback func(res *RestResult)) *RestFuture {
+	return future.Done(func(res *RestResult) {
+		if res.Err() == nil {
+			callback(res)
+		}
+	})
+}
+
+// Catch 请求出错时回调
+func (future *RestFuture) Catch(callback func(err error)) *RestFuture {
+	return future.Done(func(res *RestResult) {
+		if err := res.Err(); err != nil {
+			callback(err)
+		}
+	})
+}
+
+// Get 等待并返回结果,ctx结束时返回ctx的错误
+func (future *RestFuture) Get(ctx context.Context) (*RestResult, error) {
+	select {
+	case <-future.done:
+		return future.res, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// WaitAll 等待全部结果,返回结果与参数顺序一致,ctx结束时返回ctx的错误
+func WaitAll(ctx context.Context, futures ...*RestFuture) ([]*RestResult, error) {
+	out := make([]*RestResult, len(futures))
+	for i, future := range futures {
+		res, err := future.Get(ctx)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = res
+	}
+	return out, nil
+}
+
+// WaitAny 等待最先返回的结果,返回其在参数中的下标,ctx结束时返回ctx的错误
+func WaitAny(ctx context.Context, futures ...*RestFuture) (int, *RestResult, error) {
+	cases := make([]reflect.SelectCase, 0, len(futures)+1)
+	cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())})
+	for _, future := range futures {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(future.done)})
+	}
+	i, _, _ := reflect.Select(cases)
+	if i == 0 {
+		return -1, nil, ctx.Err()
+	}
+	return i - 1, futures[i-1].res, nil
+}
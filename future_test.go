@@ -0,0 +1,72 @@
+package rest_client
+
+import (
+	"context"
+	"github.com/tidwall/gjson"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRestFuture(t *testing.T) {
+	server := newTestAppServer(func(method string, _ gjson.Result) string {
+		if method == "slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		return `{"method":"` + method + `"}`
+	})
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{Method: "fast"},
+		2: &AppRestBuild{Method: "slow"},
+		3: &AppRestBuild{Method: "fail", Path: "\n"},
+	})
+	var wait sync.WaitGroup
+	wait.Add(2)
+	var method string
+	var failErr error
+	client.DoFuture(context.Background(), 1, nil).Then(func(res *RestResult) {
+		method = res.JsonResult().MustString("data.method")
+		wait.Done()
+	}).Catch(func(_ error) {
+		t.Error("success call catch")
+	})
+	client.DoFuture(context.Background(), 3, nil).Then(func(_ *RestResult) {
+		t.Error("fail call then")
+	}).Catch(func(err error) {
+		failErr = err
+		wait.Done()
+	})
+	wait.Wait()
+	if method != "fast" || failErr == nil {
+		t.Error("future callback error")
+	}
+
+	future := client.DoFuture(context.Background(), 1, nil)
+	res1, err1 := future.Get(context.Background())
+	res2, err2 := future.Get(context.Background())
+	if err1 != nil || err2 != nil || res1 != res2 {
+		t.Error("future get error")
+	}
+	called := false
+	future.Done(func(_ *RestResult) {
+		called = true
+	})
+	if !called {
+		t.Error("done callback after resolve not call")
+	}
+
+	i, res, err := WaitAny(context.Background(), client.DoFuture(context.Background(), 2, nil), client.DoFuture(context.Background(), 1, nil))
+	if err != nil || i != 1 || res.JsonResult().MustString("data.method") != "fast" {
+		t.Error("wait any error")
+	}
+	all, err := WaitAll(context.Background(), client.DoFuture(context.Background(), 2, nil), client.DoFuture(context.Background(), 1, nil))
+	if err != nil || len(all) != 2 || all[0].JsonResult().MustString("data.method") != "slow" {
+		t.Error("wait all error")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err = WaitAll(ctx, client.DoFuture(context.Background(), 2, nil)); err == nil {
+		t.Error("wait all ctx error not return")
+	}
+}
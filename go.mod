@@ -1,10 +1,16 @@
 module github.com/hsbteam/rest_client
 
-go 1.17
+go 1.18
 
 require (
 	github.com/go-playground/validator/v10 v10.9.0
 	github.com/tidwall/gjson v1.12.1
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+	golang.org/x/crypto v0.17.0
+	golang.org/x/net v0.19.0
+	golang.org/x/text v0.14.0
+	google.golang.org/protobuf v1.28.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -13,7 +19,6 @@ require (
 	github.com/leodido/go-urn v1.2.1 // indirect
 	github.com/tidwall/match v1.1.1 // indirect
 	github.com/tidwall/pretty v1.2.0 // indirect
-	golang.org/x/crypto v0.0.0-20210711020723-a769d52b0f97 // indirect
-	golang.org/x/sys v0.0.0-20210806184541-e5e7981a1069 // indirect
-	golang.org/x/text v0.3.6 // indirect
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	golang.org/x/sys v0.15.0 // indirect
 )
@@ -0,0 +1,324 @@
+package rest_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/tidwall/gjson"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GrpcCode gRPC状态码
+type GrpcCode int
+
+const (
+	GrpcOk                 GrpcCode = 0
+	GrpcCanceled           GrpcCode = 1
+	GrpcUnknown            GrpcCode = 2
+	GrpcInvalidArgument    GrpcCode = 3
+	GrpcDeadlineExceeded   GrpcCode = 4
+	GrpcNotFound           GrpcCode = 5
+	GrpcAlreadyExists      GrpcCode = 6
+	GrpcPermissionDenied   GrpcCode = 7
+	GrpcResourceExhausted  GrpcCode = 8
+	GrpcFailedPrecondition GrpcCode = 9
+	GrpcAborted            GrpcCode = 10
+	GrpcOutOfRange         GrpcCode = 11
+	GrpcUnimplemented      GrpcCode = 12
+	GrpcInternal           GrpcCode = 13
+	GrpcUnavailable        GrpcCode = 14
+	GrpcDataLoss           GrpcCode = 15
+	GrpcUnauthenticated    GrpcCode = 16
+)
+
+var grpcCodeNames = [...]string{
+	"OK", "CANCELLED", "UNKNOWN", "INVALID_ARGUMENT", "DEADLINE_EXCEEDED", "NOT_FOUND", "ALREADY_EXISTS", "PERMISSION_DENIED",
+	"RESOURCE_EXHAUSTED", "FAILED_PRECONDITION", "ABORTED", "OUT_OF_RANGE", "UNIMPLEMENTED", "INTERNAL", "UNAVAILABLE", "DATA_LOSS", "UNAUTHENTICATED",
+}
+
+func (code GrpcCode) String() string {
+	if code >= 0 && int(code) < len(grpcCodeNames) {
+		return grpcCodeNames[code]
+	}
+	return fmt.Sprintf("CODE(%d)", int(code))
+}
+
+// Class 状态码对应的结果分类,UNAVAILABLE RESOURCE_EXHAUSTED DEADLINE_EXCEEDED 为可重试
+func (code GrpcCode) Class() RestResultClass {
+	switch code {
+	case GrpcOk:
+		return ResultSuccess
+	case GrpcUnavailable, GrpcResourceExhausted, GrpcDeadlineExceeded:
+		return ResultRetryable
+	case GrpcUnauthenticated, GrpcPermissionDenied:
+		return ResultAuth
+	}
+	return ResultFatal
+}
+
+// grpcHttpCode 非 gateway 返回的错误(如代理返回)按HTTP状态码对应的gRPC状态码
+func grpcHttpCode(httpCode int) GrpcCode {
+	switch httpCode {
+	case http.StatusBadRequest:
+		return GrpcInternal
+	case http.StatusUnauthorized:
+		return GrpcUnauthenticated
+	case http.StatusForbidden:
+		return GrpcPermissionDenied
+	case http.StatusNotFound:
+		return GrpcUnimplemented
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return GrpcUnavailable
+	}
+	return GrpcUnknown
+}
+
+// GrpcStatusError gRPC-gateway 返回的错误状态
+type GrpcStatusError struct {
+	HttpCode int
+	Code     GrpcCode
+	Message  string
+	Details  []json.RawMessage //google.rpc.Status 中的 details,含 @type
+}
+
+func (err *GrpcStatusError) Error() string {
+	return fmt.Sprintf("grpc status:%s message:%s [%d]", err.Code, err.Message, err.HttpCode)
+}
+
+// GrpcGatewayClassifier gRPC-gateway 返回格式的分类,非2xx时解析 google.rpc.Status 为 *GrpcStatusError
+func GrpcGatewayClassifier(httpCode int, body string) (RestResultClass, error) {
+	if httpCode >= 200 && httpCode < 300 {
+		return ResultSuccess, nil
+	}
+	status := &GrpcStatusError{HttpCode: httpCode, Code: grpcHttpCode(httpCode), Message: body}
+	if gjson.Valid(body) {
+		data := gjson.Parse(body)
+		if code := data.Get("code"); code.Exists() {
+			status.Code = GrpcCode(code.Int())
+		}
+		if msg := data.Get("message"); msg.Exists() {
+			status.Message = msg.String()
+		} else if msg = data.Get("error"); msg.Exists() {
+			status.Message = msg.String()
+		}
+		data.Get("details").ForEach(func(_, detail gjson.Result) bool {
+			status.Details = append(status.Details, json.RawMessage(detail.Raw))
+			return true
+		})
+	}
+	class := status.Code.Class()
+	if class == ResultSuccess {
+		class = ClassifyHttpCode(httpCode)
+	}
+	return class, status
+}
+
+// GrpcGatewayBuild gRPC-gateway 生成的JSON接口,使用 AppRestConfig 的地址,连接,事件及重试,不签名
+// 参数为 proto.Message 时按 protojson 编码,返回错误为 *GrpcStatusError,可使用 RestResult.ProtoResult 解析结果
+type GrpcGatewayBuild struct {
+	//路径模板,同 google.api.http,如 /v1/shops/{shop_id}/goods/{goods.id},模板中的字段从参数中取出
+	Path string
+	//请求方式,默认POST
+	HttpMethod string
+	//同 google.api.http 的 body,* 为全部参数,字段名为只发送该字段,未发送的参数作为查询参数
+	//为空时 GET DELETE 全部参数作为查询参数,其他请求方式为 *
+	Body string
+	//proto.Message 参数使用 proto 中的字段名,否则为 lowerCamelCase,需与 gateway 的 MarshalOptions 一致
+	ProtoNames bool
+	//等待返回HEADER超时,0时使用连接配置
+	Timeout time.Duration
+	//整个请求的超时,包含排队及读取返回内容,0不限制
+	TotalTimeout time.Duration
+}
+
+// BuildRequest 执行请求
+func (clt *GrpcGatewayBuild) BuildRequest(ctx context.Context, client *RestClient, key int, param interface{}, callerInfo *RestCallerInfo) *RestResult {
+	build, body, err := clt.restBuild(param)
+	if err != nil {
+		return NewRestResultFromError(err, &RestEventNoop{})
+	}
+	if body == nil {
+		return build.BuildRequest(ctx, client, key, nil, callerInfo)
+	}
+	return build.BuildRequest(ctx, client, key, body, callerInfo)
+}
+
+// restBuild 按参数生成本次请求的 AppRestBuild 及请求内容
+func (clt *GrpcGatewayBuild) restBuild(param interface{}) (*AppRestBuild, []byte, error) {
+	params, err := clt.paramMap(param)
+	if err != nil {
+		return nil, nil, &RestClientError{Code: ErrJsonValid, Msg: "grpc gateway param encode fail:" + err.Error(), err: err}
+	}
+	path, err := grpcGatewayPath(clt.Path, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	httpMethod := appHttpMethod(clt.HttpMethod)
+	bodyField := clt.Body
+	if len(bodyField) == 0 && appMethodHasBody(httpMethod) && httpMethod != http.MethodDelete {
+		bodyField = "*"
+	}
+	var body []byte
+	switch bodyField {
+	case "":
+	case "*":
+		body, err = json.Marshal(params)
+		params = nil
+	default:
+		if val, ok := params[bodyField]; ok {
+			body, err = json.Marshal(val)
+			delete(params, bodyField)
+		}
+	}
+	if err != nil {
+		return nil, nil, &RestClientError{Code: ErrJsonValid, Msg: "grpc gateway body encode fail:" + err.Error(), err: err}
+	}
+	query := url.Values{}
+	grpcGatewayQuery(query, "", reflect.ValueOf(params))
+	if encoded := query.Encode(); len(encoded) > 0 {
+		path += "?" + encoded
+	}
+	return &AppRestBuild{
+		Path:                  path,
+		HttpMethod:            httpMethod,
+		Raw:                   true,
+		ContentType:           "application/json",
+		Classifier:            GrpcGatewayClassifier,
+		ResponseHeaderTimeout: clt.Timeout,
+		TotalTimeout:          clt.TotalTimeout,
+	}, body, nil
+}
+
+// paramMap 参数转为JSON对象,数字保留原文
+func (clt *GrpcGatewayBuild) paramMap(param interface{}) (map[string]interface{}, error) {
+	var data []byte
+	var err error
+	switch val := param.(type) {
+	case nil:
+		return map[string]interface{}{}, nil
+	case proto.Message:
+		data, err = protojson.MarshalOptions{UseProtoNames: clt.ProtoNames}.Marshal(val)
+	default:
+		data, err = json.Marshal(val)
+	}
+	if err != nil {
+		return nil, err
+	}
+	params := map[string]interface{}{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err = dec.Decode(&params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// grpcGatewayPath 替换路径模板中的字段,支持 {field} {a.b} 及 {name=shops/*},替换后从参数中移除
+func grpcGatewayPath(template string, params map[string]interface{}) (string, error) {
+	var out strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			out.WriteString(template)
+			return out.String(), nil
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			return "", NewRestClientError(ErrJsonValid, "grpc gateway path template error:"+template)
+		}
+		out.WriteString(template[:start])
+		field := template[start+1 : start+end]
+		multi := false
+		if i := strings.IndexByte(field, '='); i >= 0 {
+			multi = strings.Contains(field[i+1:], "/") || strings.Contains(field[i+1:], "**")
+			field = field[:i]
+		}
+		val, ok := grpcGatewayTake(params, strings.Split(field, "."))
+		if !ok {
+			return "", NewRestClientError(ErrJsonValid, "grpc gateway path param not exists:"+field)
+		}
+		if multi {
+			segments := strings.Split(val, "/")
+			for i, segment := range segments {
+				segments[i] = url.PathEscape(segment)
+			}
+			out.WriteString(strings.Join(segments, "/"))
+		} else {
+			out.WriteString(url.PathEscape(val))
+		}
+		template = template[start+end+1:]
+	}
+}
+
+// grpcGatewayTake 取出并移除参数中的字段
+func grpcGatewayTake(params map[string]interface{}, path []string) (string, bool) {
+	val, ok := params[path[0]]
+	if !ok || val == nil {
+		return "", false
+	}
+	if len(path) > 1 {
+		child, ok := val.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		return grpcGatewayTake(child, path[1:])
+	}
+	switch val.(type) {
+	case map[string]interface{}, []interface{}:
+		return "", false
+	}
+	delete(params, path[0])
+	return fmt.Sprint(val), true
+}
+
+// grpcGatewayQuery 查询参数,嵌套对象为 a.b=1,数组为重复KEY
+func grpcGatewayQuery(query url.Values, prefix string, val reflect.Value) {
+	for val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return
+		}
+		val = val.Elem()
+	}
+	switch val.Kind() {
+	case reflect.Map:
+		keys := make([]string, 0, val.Len())
+		for _, key := range val.MapKeys() {
+			keys = append(keys, key.String())
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			name := key
+			if len(prefix) > 0 {
+				name = prefix + "." + key
+			}
+			grpcGatewayQuery(query, name, val.MapIndex(reflect.ValueOf(key)))
+		}
+	case reflect.Slice:
+		for i := 0; i < val.Len(); i++ {
+			grpcGatewayQuery(query, prefix, val.Index(i))
+		}
+	case reflect.Invalid:
+	default:
+		query.Add(prefix, fmt.Sprint(val.Interface()))
+	}
+}
+
+// ProtoResult 检测返回结果并按 protojson 解析到 msg,忽略未知字段
+func (res *RestResult) ProtoResult(msg proto.Message) error {
+	result := res.JsonResult()
+	if result.err != nil {
+		return result.err
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal([]byte(result.body), msg); err != nil {
+		return &RestClientError{Code: ErrJsonValid, Msg: "proto result parse fail:" + err.Error(), err: err}
+	}
+	return nil
+}
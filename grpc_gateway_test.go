@@ -0,0 +1,99 @@
+package rest_client
+
+import (
+	"context"
+	"errors"
+	"google.golang.org/protobuf/types/known/structpb"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGrpcGatewayBuild(t *testing.T) {
+	var method, uri, body string
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		method, uri, body = r.Method, r.URL.RequestURI(), string(data)
+		calls++
+		switch r.URL.Path {
+		case "/v1/goods/404":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":5,"message":"goods not found","details":[{"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"GOODS"}]}`))
+		case "/v1/goods/503":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`upstream connect error`))
+		default:
+			_, _ = w.Write([]byte(`{"id":"1","name":"goods","unknown":true}`))
+		}
+	}))
+	defer server.Close()
+	manager := NewRestClientManager()
+	defer manager.Close()
+	manager.SetRestConfig(&AppRestConfig{Name: "grpc", AppUrl: server.URL, Retry: &AppRestRetry{Max: 1}})
+	client := manager.NewApi(&testBuildApi{name: "grpc", builds: map[int]RestBuild{
+		1: &GrpcGatewayBuild{Path: "/v1/shops/{shop.id}/goods/{id}", HttpMethod: http.MethodGet},
+		2: &GrpcGatewayBuild{Path: "/v1/{name=shops/*}/goods", Body: "goods"},
+		3: &GrpcGatewayBuild{Path: "/v1/goods", ProtoNames: true},
+		4: &GrpcGatewayBuild{Path: "/v1/goods/{id}", HttpMethod: http.MethodGet},
+	}})
+
+	res, err := client.DoSync(context.Background(), 1, map[string]interface{}{
+		"id": 1, "shop": map[string]interface{}{"id": 2, "type": "self"}, "tags": []string{"a", "b"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := &structpb.Struct{}
+	if err = res.ProtoResult(msg); err != nil || msg.Fields["name"].GetStringValue() != "goods" {
+		t.Error("proto result error", err)
+	}
+	if method != http.MethodGet || uri != "/v1/shops/2/goods/1?shop.type=self&tags=a&tags=b" || body != "" {
+		t.Error("grpc gateway get request error", method, uri, body)
+	}
+
+	_, _ = client.DoSync(context.Background(), 2, map[string]interface{}{"name": "shops/1", "goods": map[string]interface{}{"id": 1}, "validate": true})
+	if method != http.MethodPost || uri != "/v1/shops/1/goods?validate=true" || body != `{"id":1}` {
+		t.Error("grpc gateway body field error", method, uri, body)
+	}
+
+	param, _ := structpb.NewStruct(map[string]interface{}{"goods_name": "goods"})
+	_, _ = client.DoSync(context.Background(), 3, param)
+	if uri != "/v1/goods" || body != `{"goods_name":"goods"}` {
+		t.Error("grpc gateway proto param error", uri, body)
+	}
+
+	res, _ = client.DoSync(context.Background(), 4, map[string]interface{}{"id": 404})
+	var status *GrpcStatusError
+	err = res.JsonResult().Err()
+	if !errors.As(err, &status) || status.Code != GrpcNotFound || status.Message != "goods not found" || len(status.Details) != 1 {
+		t.Fatal("grpc status error", err)
+	}
+	if ClassifyError(err) != ResultFatal {
+		t.Error("not found should be fatal")
+	}
+
+	calls = 0
+	res, _ = client.DoSync(context.Background(), 4, map[string]interface{}{"id": 503})
+	err = res.JsonResult().Err()
+	if !errors.As(err, &status) || status.Code != GrpcUnavailable || ClassifyError(err) != ResultRetryable {
+		t.Error("unavailable status error", err)
+	}
+	if calls != 2 {
+		t.Error("unavailable should retry", calls)
+	}
+
+	if _, err = client.DoSync(context.Background(), 4, nil); ErrorCode(err) != ErrJsonValid {
+		t.Error("missing path param should fail", err)
+	}
+}
+
+func TestGrpcCode(t *testing.T) {
+	if GrpcUnauthenticated.String() != "UNAUTHENTICATED" || GrpcCode(99).String() != "CODE(99)" {
+		t.Error("grpc code string error")
+	}
+	if GrpcUnauthenticated.Class() != ResultAuth || GrpcOk.Class() != ResultSuccess {
+		t.Error("grpc code class error")
+	}
+}
@@ -0,0 +1,257 @@
+package rest_client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RestHealthBalancer 支持健康检查的负载均衡,如 EwmaBalancer,检查不通过的地址不再分配请求
+type RestHealthBalancer interface {
+	RestBalancer
+	Urls() []string                      //需要检查的全部地址
+	SetHealthy(url string, healthy bool) //更新地址健康状态
+}
+
+// AppRestHealthCheck 主动健康检查配置,后台定时请求各地址的检查路径
+type AppRestHealthCheck struct {
+	Path      string        //检查路径,如 /health,返回2xx为通过
+	Interval  time.Duration //检查间隔,默认10s
+	Timeout   time.Duration //单次检查超时,默认2s
+	Unhealthy int           //连续失败多少次标记为不健康,默认2
+	Healthy   int           //不健康后连续成功多少次恢复,默认1
+}
+
+// RestEndpointHealth 单个地址的健康状态
+type RestEndpointHealth struct {
+	Config    string    //服务配置名
+	Url       string    //地址
+	Healthy   bool      //是否健康,未检查时为健康
+	LastCheck time.Time //最后检查时间
+	LastError string    //最后一次检查失败的原因
+}
+
+// RestHealth 全部配置了健康检查的服务状态
+type RestHealth struct {
+	Ready     bool                 //每个服务至少有一个健康的地址,可用于就绪检查
+	Endpoints []RestEndpointHealth //按服务配置名及地址排序
+}
+
+type restEndpointState struct {
+	health    RestEndpointHealth
+	fails     int
+	successes int
+}
+
+// restHealthChecker 单个服务配置的健康检查
+type restHealthChecker struct {
+	config    *AppRestConfig
+	check     AppRestHealthCheck
+	transport http.RoundTripper
+	lock      sync.Mutex
+	endpoints map[string]*restEndpointState
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+func newRestHealthChecker(config *AppRestConfig, transport http.RoundTripper) *restHealthChecker {
+	check := *config.HealthCheck
+	if check.Interval <= 0 {
+		check.Interval = 10 * time.Second
+	}
+	if check.Timeout <= 0 {
+		check.Timeout = 2 * time.Second
+	}
+	if check.Unhealthy <= 0 {
+		check.Unhealthy = 2
+	}
+	if check.Healthy <= 0 {
+		check.Healthy = 1
+	}
+	checker := &restHealthChecker{
+		config:    config,
+		check:     check,
+		transport: transport,
+		endpoints: map[string]*restEndpointState{},
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	for _, url := range checker.urls() {
+		checker.endpoints[url] = &restEndpointState{health: RestEndpointHealth{Config: config.Name, Url: url, Healthy: true}}
+	}
+	return checker
+}
+
+// urls 配置了支持健康检查的负载均衡时检查全部地址,否则检查 AppUrl
+func (checker *restHealthChecker) urls() []string {
+	if balancer, ok := checker.config.Balancer.(RestHealthBalancer); ok {
+		return balancer.Urls()
+	}
+	return []string{checker.config.AppUrl}
+}
+
+// run 立即执行一次检查,之后按间隔检查,直到停止
+func (checker *restHealthChecker) run() {
+	defer close(checker.done)
+	ticker := time.NewTicker(checker.check.Interval)
+	defer ticker.Stop()
+	for {
+		checker.checkAll()
+		select {
+		case <-checker.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// close 停止检查并等待正在进行的检查结束,不再检查后恢复负载均衡中的地址
+func (checker *restHealthChecker) close() {
+	close(checker.stop)
+	<-checker.done
+	if balancer, ok := checker.config.Balancer.(RestHealthBalancer); ok {
+		for _, url := range balancer.Urls() {
+			balancer.SetHealthy(url, true)
+		}
+	}
+}
+
+func (checker *restHealthChecker) checkAll() {
+	urls := checker.urls()
+	var wg sync.WaitGroup
+	for _, url := range urls {
+		wg.Add(1)
+		go func(url string) {
+			defer wg.Done()
+			checker.report(url, checker.probe(url))
+		}(url)
+	}
+	wg.Wait()
+}
+
+// probe 请求检查路径,返回2xx为通过
+func (checker *restHealthChecker) probe(url string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), checker.check.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+checker.check.Path, nil)
+	if err != nil {
+		return err
+	}
+	res, err := checker.transport.RoundTrip(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(res.Body, 4096))
+	_ = res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("health check http code:%d", res.StatusCode)
+	}
+	return nil
+}
+
+// report 记录检查结果,按连续成功失败次数切换状态并通知负载均衡
+func (checker *restHealthChecker) report(url string, err error) {
+	checker.lock.Lock()
+	state, ok := checker.endpoints[url]
+	if !ok {
+		state = &restEndpointState{health: RestEndpointHealth{Config: checker.config.Name, Url: url, Healthy: true}}
+		checker.endpoints[url] = state
+	}
+	state.health.LastCheck = time.Now()
+	healthy := state.health.Healthy
+	if err != nil {
+		state.health.LastError = err.Error()
+		state.successes = 0
+		state.fails++
+		if state.fails >= checker.check.Unhealthy {
+			healthy = false
+		}
+	} else {
+		state.health.LastError = ""
+		state.fails = 0
+		state.successes++
+		if state.successes >= checker.check.Healthy {
+			healthy = true
+		}
+	}
+	changed := healthy != state.health.Healthy
+	state.health.Healthy = healthy
+	checker.lock.Unlock()
+	if balancer, ok := checker.config.Balancer.(RestHealthBalancer); ok && changed {
+		balancer.SetHealthy(url, healthy)
+	}
+}
+
+// health 当前各地址状态
+func (checker *restHealthChecker) health() []RestEndpointHealth {
+	checker.lock.Lock()
+	defer checker.lock.Unlock()
+	out := make([]RestEndpointHealth, 0, len(checker.endpoints))
+	for _, state := range checker.endpoints {
+		out = append(out, state.health)
+	}
+	return out
+}
+
+// restHealthCheckers 按服务配置名管理健康检查
+type restHealthCheckers struct {
+	lock     sync.Mutex
+	checkers map[string]*restHealthChecker
+}
+
+// set 替换服务配置的健康检查,config为nil或未配置检查时仅停止原有检查
+func (checkers *restHealthCheckers) set(name string, config *AppRestConfig, transport http.RoundTripper) {
+	checkers.lock.Lock()
+	defer checkers.lock.Unlock()
+	if prev, ok := checkers.checkers[name]; ok {
+		prev.close()
+		delete(checkers.checkers, name)
+	}
+	if config == nil || config.HealthCheck == nil {
+		return
+	}
+	if checkers.checkers == nil {
+		checkers.checkers = map[string]*restHealthChecker{}
+	}
+	checker := newRestHealthChecker(config, transport)
+	checkers.checkers[name] = checker
+	go checker.run()
+}
+
+// closeAll 停止全部健康检查
+func (checkers *restHealthCheckers) closeAll() {
+	checkers.lock.Lock()
+	defer checkers.lock.Unlock()
+	for name, checker := range checkers.checkers {
+		checker.close()
+		delete(checkers.checkers, name)
+	}
+}
+
+func (checkers *restHealthCheckers) health() RestHealth {
+	checkers.lock.Lock()
+	defer checkers.lock.Unlock()
+	health := RestHealth{Ready: true}
+	for _, checker := range checkers.checkers {
+		endpoints := checker.health()
+		ready := false
+		for _, endpoint := range endpoints {
+			ready = ready || endpoint.Healthy
+		}
+		health.Ready = health.Ready && ready
+		health.Endpoints = append(health.Endpoints, endpoints...)
+	}
+	sort.Slice(health.Endpoints, func(i, j int) bool {
+		a, b := health.Endpoints[i], health.Endpoints[j]
+		if a.Config != b.Config {
+			return a.Config < b.Config
+		}
+		return a.Url < b.Url
+	})
+	return health
+}
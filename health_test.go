@@ -0,0 +1,79 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRestHealthCheck(t *testing.T) {
+	newServer := func(name string, healthCode int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/health" {
+				w.WriteHeader(healthCode)
+				return
+			}
+			_, _ = w.Write([]byte(`{"name":"` + name + `"}`))
+		}))
+	}
+	good := newServer("good", http.StatusOK)
+	defer good.Close()
+	bad := newServer("bad", http.StatusServiceUnavailable)
+	defer bad.Close()
+
+	manager := NewRestClientManager()
+	defer manager.Close()
+	balancer := NewEwmaBalancer(good.URL, bad.URL)
+	manager.SetRestConfig(&AppRestConfig{
+		Name:        "test",
+		Balancer:    balancer,
+		HealthCheck: &AppRestHealthCheck{Path: "/health", Interval: 10 * time.Millisecond, Unhealthy: 1},
+	})
+	var health RestHealth
+	for i := 0; i < 100; i++ {
+		health = manager.Health()
+		if len(health.Endpoints) == 2 && !health.Endpoints[0].LastCheck.IsZero() && !health.Endpoints[1].LastCheck.IsZero() {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !health.Ready || len(health.Endpoints) != 2 {
+		t.Fatal("health error", health)
+	}
+	for _, endpoint := range health.Endpoints {
+		if endpoint.Healthy != (endpoint.Url == good.URL) {
+			t.Error("endpoint health error", endpoint)
+		}
+	}
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true},
+	}})
+	for i := 0; i < 10; i++ {
+		if name := (<-client.Do(context.Background(), 1, nil)).JsonResult().MustString("name"); name != "good" {
+			t.Error("request unhealthy endpoint")
+		}
+	}
+
+	manager.SetRestConfig(&AppRestConfig{
+		Name:        "test",
+		AppUrl:      bad.URL,
+		HealthCheck: &AppRestHealthCheck{Path: "/health", Interval: 10 * time.Millisecond, Unhealthy: 1},
+	})
+	for i := 0; i < 100 && manager.Health().Ready; i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if health = manager.Health(); health.Ready || len(health.Endpoints) != 1 || health.Endpoints[0].LastError == "" {
+		t.Error("unhealthy config ready", health)
+	}
+	for _, node := range balancer.Nodes() {
+		if node.Down {
+			t.Error("replaced balancer not reset")
+		}
+	}
+	manager.SetRestConfig(&AppRestConfig{Name: "test", AppUrl: bad.URL})
+	if len(manager.Health().Endpoints) != 0 {
+		t.Error("health check not stop")
+	}
+}
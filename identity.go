@@ -0,0 +1,85 @@
+package rest_client
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Version 包版本,用于默认的 User-Agent
+const Version = "1.0.0"
+
+// RestClientIdentity 调用方标识,通过 User-Agent 及 X-Client-* HEADER 发送,便于下游在排查问题时识别调用方
+// 服务配置未设置时使用默认标识,User-Agent 为 rest_client/版本 应用名,应用名为当前程序名
+// 服务配置 Headers 及 WithHeader 设置的同名HEADER优先
+type RestClientIdentity struct {
+	AppName    string            //应用名,为空时使用当前程序名,通过 X-Client-Name 发送
+	AppVersion string            //应用版本,通过 X-Client-Version 发送,为空时不发送
+	Instance   string            //实例标识,如主机名或POD名,通过 X-Client-Instance 发送,为空时不发送
+	UserAgent  string            //自定义 User-Agent,为空时为 rest_client/版本 应用名/应用版本
+	Headers    map[string]string //其他标识,KEY加上 X-Client- 前缀发送,如 Team 发送为 X-Client-Team
+	Disable    bool              //不发送标识HEADER,也不发送 Go 默认的 User-Agent
+	once       sync.Once
+	header     http.Header
+}
+
+// defaultIdentity 服务配置未设置标识时使用
+var defaultIdentity = &RestClientIdentity{}
+
+// build 生成发送的HEADER,只生成一次
+func (identity *RestClientIdentity) build() http.Header {
+	identity.once.Do(func() {
+		header := http.Header{}
+		if identity.Disable {
+			//HEADER存在且为空时 net/http 不发送默认的 User-Agent
+			header["User-Agent"] = []string{""}
+			identity.header = header
+			return
+		}
+		appName := identity.AppName
+		if len(appName) == 0 && len(os.Args) > 0 {
+			appName = filepath.Base(os.Args[0])
+		}
+		userAgent := identity.UserAgent
+		if len(userAgent) == 0 {
+			userAgent = "rest_client/" + Version
+			if len(appName) > 0 {
+				userAgent += " " + appName
+				if len(identity.AppVersion) > 0 {
+					userAgent += "/" + identity.AppVersion
+				}
+			}
+		}
+		header.Set("User-Agent", userAgent)
+		if len(appName) > 0 {
+			header.Set("X-Client-Name", appName)
+		}
+		if len(identity.AppVersion) > 0 {
+			header.Set("X-Client-Version", identity.AppVersion)
+		}
+		if len(identity.Instance) > 0 {
+			header.Set("X-Client-Instance", identity.Instance)
+		}
+		for key, val := range identity.Headers {
+			header.Set("X-Client-"+key, val)
+		}
+		identity.header = header
+	})
+	return identity.header
+}
+
+// identityHeader 调用方标识HEADER,identity 为nil时使用默认标识
+func identityHeader(identity *RestClientIdentity) http.Header {
+	if identity == nil {
+		identity = defaultIdentity
+	}
+	return identity.build()
+}
+
+// setIdentityHeader 设置调用方标识HEADER
+func setIdentityHeader(identity *RestClientIdentity, header http.Header) {
+	for key, val := range identityHeader(identity) {
+		header[key] = val
+	}
+}
@@ -0,0 +1,69 @@
+package rest_client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRestClientIdentity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"user_agent": r.Header.Get("User-Agent"),
+			"has_agent":  len(r.Header.Values("User-Agent")) > 0,
+			"name":       r.Header.Get("X-Client-Name"),
+			"version":    r.Header.Get("X-Client-Version"),
+			"instance":   r.Header.Get("X-Client-Instance"),
+			"team":       r.Header.Get("X-Client-Team"),
+		})
+	}))
+	defer server.Close()
+	call := func(identity *RestClientIdentity, headers map[string]string) *JsonResult {
+		manager := NewRestClientManager()
+		manager.SetRestConfig(&AppRestConfig{
+			Name:     "test",
+			AppUrl:   server.URL,
+			Headers:  headers,
+			Identity: identity,
+		})
+		client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+			1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true},
+		}})
+		return (<-client.Do(context.Background(), 1, nil)).JsonResult()
+	}
+
+	//默认标识
+	res := call(nil, nil)
+	if !strings.HasPrefix(res.MustString("user_agent"), "rest_client/"+Version+" ") || res.MustString("name") == "" {
+		t.Error("default identity error", res.MustString("user_agent"))
+	}
+
+	res = call(&RestClientIdentity{
+		AppName:    "order",
+		AppVersion: "2.1",
+		Instance:   "pod-1",
+		Headers:    map[string]string{"Team": "trade"},
+	}, nil)
+	if res.MustString("user_agent") != "rest_client/"+Version+" order/2.1" {
+		t.Error("identity user agent error", res.MustString("user_agent"))
+	}
+	if res.MustString("name") != "order" || res.MustString("version") != "2.1" ||
+		res.MustString("instance") != "pod-1" || res.MustString("team") != "trade" {
+		t.Error("identity header error")
+	}
+
+	//服务配置 Headers 优先
+	res = call(&RestClientIdentity{AppName: "order", UserAgent: "order-service"}, map[string]string{"X-Client-Name": "custom"})
+	if res.MustString("user_agent") != "order-service" || res.MustString("name") != "custom" {
+		t.Error("identity override error")
+	}
+
+	//关闭后不发送任何标识
+	res = call(&RestClientIdentity{AppName: "order", Disable: true}, nil)
+	if res.MustBool("has_agent") || res.MustString("name") != "" {
+		t.Error("identity disable error", res.MustString("user_agent"))
+	}
+}
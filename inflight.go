@@ -0,0 +1,142 @@
+package rest_client
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RestInFlight 进行中的对外请求,用于排查等待慢服务而阻塞的协程
+type RestInFlight struct {
+	ConfigName string        `json:"config_name"`
+	Key        int           `json:"key"`
+	Method     string        `json:"method"`
+	Url        string        `json:"url"`   //参数已脱敏
+	Stage      string        `json:"stage"` //header 等待返回HEADER,body 读取返回内容
+	Start      time.Time     `json:"start"`
+	Elapsed    time.Duration `json:"elapsed"`
+	Caller     string        `json:"caller,omitempty"` //发起请求的调用方,文件:行 函数
+}
+
+const (
+	inFlightHeader = "header"
+	inFlightBody   = "body"
+)
+
+// restInFlightRegistry 管理器记录的进行中请求
+type restInFlightRegistry struct {
+	lock  sync.Mutex
+	seq   uint64
+	calls map[uint64]*RestInFlight
+}
+
+func (reg *restInFlightRegistry) add(req *http.Request) uint64 {
+	call := &RestInFlight{
+		Method: req.Method,
+		Url:    req.URL.String(),
+		Stage:  inFlightHeader,
+		Start:  time.Now(),
+	}
+	call.Url = redactUrl(call.Url)
+	if info := contextCallInfo(req.Context()); info != nil {
+		call.ConfigName, call.Key = info.configName, info.key
+	}
+	if caller := Caller(req.Context()); caller != nil {
+		call.Caller = caller.String()
+	}
+	reg.lock.Lock()
+	defer reg.lock.Unlock()
+	if reg.calls == nil {
+		reg.calls = make(map[uint64]*RestInFlight)
+	}
+	reg.seq++
+	reg.calls[reg.seq] = call
+	return reg.seq
+}
+
+func (reg *restInFlightRegistry) stage(id uint64, stage string) {
+	reg.lock.Lock()
+	if call, ok := reg.calls[id]; ok {
+		call.Stage = stage
+	}
+	reg.lock.Unlock()
+}
+
+func (reg *restInFlightRegistry) remove(id uint64) {
+	reg.lock.Lock()
+	delete(reg.calls, id)
+	reg.lock.Unlock()
+}
+
+// list 按开始时间排序,最早的在前
+func (reg *restInFlightRegistry) list() []RestInFlight {
+	now := time.Now()
+	reg.lock.Lock()
+	out := make([]RestInFlight, 0, len(reg.calls))
+	for _, call := range reg.calls {
+		item := *call
+		item.Elapsed = now.Sub(item.Start)
+		out = append(out, item)
+	}
+	reg.lock.Unlock()
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Start.Before(out[j].Start)
+	})
+	return out
+}
+
+// restInFlightTransport 请求开始时登记,返回内容读取完或关闭时移除
+type restInFlightTransport struct {
+	registry *restInFlightRegistry
+	next     http.RoundTripper
+}
+
+func (transport *restInFlightTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	id := transport.registry.add(req)
+	res, err := transport.next.RoundTrip(req)
+	if err != nil || res.Body == nil {
+		transport.registry.remove(id)
+		return res, err
+	}
+	transport.registry.stage(id, inFlightBody)
+	res.Body = &restInFlightBody{ReadCloser: res.Body, done: func() { transport.registry.remove(id) }}
+	return res, nil
+}
+
+// restInFlightBody 读取完或关闭时结束登记
+type restInFlightBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (body *restInFlightBody) Read(p []byte) (int, error) {
+	n, err := body.ReadCloser.Read(p)
+	if err != nil {
+		body.once.Do(body.done)
+	}
+	return n, err
+}
+
+func (body *restInFlightBody) Close() error {
+	body.once.Do(body.done)
+	return body.ReadCloser.Close()
+}
+
+// InFlight 获取进行中的对外请求,按开始时间排序,最早的在前
+func (c *RestClientManager) InFlight() []RestInFlight {
+	return c.inFlight.list()
+}
+
+// InFlightHandler 以JSON输出进行中请求的 http.Handler,用于挂载到内部调试端口,如 /debug/rest/inflight
+func (c *RestClientManager) InFlightHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(c.InFlight())
+	})
+}
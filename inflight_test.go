@@ -0,0 +1,60 @@
+package rest_client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRestInFlight(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":{}}`))
+	}))
+	defer server.Close()
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:      "test",
+		AppKey:    "dome1",
+		AppSecret: "dome111111",
+		AppUrl:    server.URL,
+	})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		4: &AppRestBuild{HttpMethod: http.MethodGet, Path: "/a?token=abc&id=1"},
+	}})
+	rc := client.Do(context.Background(), 4, nil)
+	var calls []RestInFlight
+	for i := 0; i < 100 && len(calls) == 0; i++ {
+		time.Sleep(5 * time.Millisecond)
+		calls = manager.InFlight()
+	}
+	if len(calls) != 1 {
+		t.Fatal("in flight not record")
+	}
+	call := calls[0]
+	if call.ConfigName != "test" || call.Key != 4 || call.Method != http.MethodGet || call.Stage != "header" ||
+		call.Elapsed <= 0 || !strings.HasSuffix(call.Caller, "TestRestInFlight") {
+		t.Error("in flight wrong", call)
+	}
+	if strings.Contains(call.Url, "abc") || !strings.Contains(call.Url, "id=1") {
+		t.Error("in flight url not redact", call.Url)
+	}
+	rec := httptest.NewRecorder()
+	manager.InFlightHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	var out []RestInFlight
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil || len(out) != 1 || out[0].Key != 4 {
+		t.Error("in flight handler wrong", rec.Body.String(), err)
+	}
+	close(release)
+	if err := (<-rc).JsonResult().Err(); err != nil {
+		t.Fatal(err)
+	}
+	if calls := manager.InFlight(); len(calls) != 0 {
+		t.Error("in flight not remove", calls)
+	}
+}
@@ -0,0 +1,106 @@
+package rest_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-playground/validator/v10"
+	"strings"
+)
+
+// NewJsonValid 创建JSON校验结构
+// @param valid 自定义校验器,如注册了自定义校验标签,传nil使用默认校验器
+func NewJsonValid(ctx context.Context, valid *validator.Validate) *JsonValid {
+	return &JsonValid{
+		valid:   valid,
+		Context: ctx,
+	}
+}
+
+// JsonFieldError 单个字段校验错误
+type JsonFieldError struct {
+	Field string      //结构字段路径,如 Item.Name
+	Tag   string      //未通过的校验标签,如 required
+	Param string      //校验标签参数,如 max=10 中的 10
+	Value interface{} //字段值
+}
+
+func (err *JsonFieldError) Error() string {
+	if len(err.Param) > 0 {
+		return fmt.Sprintf("field:%s tag:%s=%s value:%v", err.Field, err.Tag, err.Param, err.Value)
+	}
+	return fmt.Sprintf("field:%s tag:%s value:%v", err.Field, err.Tag, err.Value)
+}
+
+// JsonBindError 绑定校验错误,包含全部未通过的字段
+type JsonBindError struct {
+	Path   string
+	Fields []*JsonFieldError
+}
+
+func (err *JsonBindError) Error() string {
+	msg := make([]string, 0, len(err.Fields))
+	for _, field := range err.Fields {
+		msg = append(msg, field.Error())
+	}
+	return fmt.Sprintf("path:%s valid fail: %s", err.Path, strings.Join(msg, "; "))
+}
+
+// Bind 将节点数据解析到结构并按 validate 标签校验
+// 校验失败时返回 *JsonBindError,包含所有未通过的字段,而不是第一个错误
+// @param path 节点路径,传空为根节点
+// @param structPtr 结构指针,字段使用 json 及 validate 标签
+func (res *JsonResult) Bind(path string, structPtr interface{}, jsonValid ...*JsonValid) error {
+	if res.err != nil {
+		return res.err
+	}
+	path = pathCreate(res.basePath, path)
+	param := res.lookup(path).Raw
+	if len(param) == 0 {
+		param = "{}"
+	}
+	if err := res.decode(param, structPtr); err != nil {
+		return NewRestClientError(ErrJsonValid, fmt.Sprintf("path:%s decode error:%s", path, err.Error()))
+	}
+
+	var valid *validator.Validate
+	var ctx context.Context
+	if len(jsonValid) > 0 && jsonValid[0] != nil {
+		valid = jsonValid[0].valid
+		ctx = jsonValid[0].Context
+	}
+	if valid == nil {
+		if res.valid == nil {
+			res.valid = validator.New()
+		}
+		valid = res.valid
+	}
+	var err error
+	if ctx == nil {
+		err = valid.Struct(structPtr)
+	} else {
+		err = valid.StructCtx(ctx, structPtr)
+	}
+	if err == nil {
+		return nil
+	}
+	var vErrs validator.ValidationErrors
+	if !errors.As(err, &vErrs) {
+		return err
+	}
+	bindErr := &JsonBindError{Path: path}
+	for _, vErr := range vErrs {
+		field := vErr.Namespace()
+		//去掉顶层结构名
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+		bindErr.Fields = append(bindErr.Fields, &JsonFieldError{
+			Field: field,
+			Tag:   vErr.Tag(),
+			Param: vErr.Param(),
+			Value: vErr.Value(),
+		})
+	}
+	return bindErr
+}
@@ -0,0 +1,34 @@
+package rest_client
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestJsonResultBind(t *testing.T) {
+	type Item struct {
+		Name  string `json:"name" validate:"required"`
+		Email string `json:"email" validate:"required,email"`
+		Age   int    `json:"age" validate:"gte=0,lte=130"`
+	}
+	read := NewJsonResult(`{"data":{"name":"a","email":"a@b.com","age":10}}`, "")
+	var item Item
+	if err := read.Bind("data", &item); err != nil {
+		t.Fatal(err)
+	}
+	if item.Name != "a" || item.Age != 10 {
+		t.Error("json bind data error")
+	}
+	read = NewJsonResult(`{"data":{"email":"bad","age":200}}`, "")
+	err := read.Bind("data", &Item{})
+	var bindErr *JsonBindError
+	if !errors.As(err, &bindErr) {
+		t.Fatal("json bind error type wrong")
+	}
+	if len(bindErr.Fields) != 3 {
+		t.Errorf("json bind error fields:%d", len(bindErr.Fields))
+	}
+	if bindErr.Fields[0].Field != "Name" || bindErr.Fields[0].Tag != "required" {
+		t.Error("json bind field error wrong")
+	}
+}
@@ -0,0 +1,51 @@
+package rest_client
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// JsonDecoder 将JSON完整解析到结构的解码器,用于 GetStruct 及 Bind
+// 可替换为 jsoniter,sonic 等,如 JsonDecoderFunc(sonic.Unmarshal)
+type JsonDecoder interface {
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JsonDecoderFunc 函数形式的解码器
+type JsonDecoderFunc func(data []byte, v interface{}) error
+
+func (fn JsonDecoderFunc) Unmarshal(data []byte, v interface{}) error {
+	return fn(data, v)
+}
+
+// StdJsonDecoder encoding/json 解码,数字解析为 json.Number
+var StdJsonDecoder JsonDecoder = JsonDecoderFunc(func(data []byte, v interface{}) error {
+	dec := json.NewDecoder(strings.NewReader(string(data)))
+	dec.UseNumber()
+	return dec.Decode(v)
+})
+
+var defaultJsonDecoder = StdJsonDecoder
+
+// SetJsonDecoder 设置默认解码器,应在启动时设置,传nil恢复为 StdJsonDecoder
+func SetJsonDecoder(decoder JsonDecoder) {
+	if decoder == nil {
+		decoder = StdJsonDecoder
+	}
+	defaultJsonDecoder = decoder
+}
+
+// WithDecoder 指定本结果使用的解码器
+func (res *JsonResult) WithDecoder(decoder JsonDecoder) *JsonResult {
+	res.decoder = decoder
+	return res
+}
+
+// decode 解析节点内容到结构
+func (res *JsonResult) decode(param string, v interface{}) error {
+	decoder := res.decoder
+	if decoder == nil {
+		decoder = defaultJsonDecoder
+	}
+	return decoder.Unmarshal([]byte(param), v)
+}
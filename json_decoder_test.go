@@ -0,0 +1,61 @@
+package rest_client
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type testDecodeItem struct {
+	Id   int64  `json:"id"`
+	Name string `json:"name" validate:"required"`
+}
+
+func TestJsonDecoder(t *testing.T) {
+	calls := 0
+	decoder := JsonDecoderFunc(func(data []byte, v interface{}) error {
+		calls++
+		return json.Unmarshal(data, v)
+	})
+	res := NewJsonResult(`{"data":{"id":1,"name":"a"}}`, "data").WithDecoder(decoder)
+	var item testDecodeItem
+	if err := res.GetStruct("", &item); err != nil || item.Id != 1 || calls != 1 {
+		t.Error("get struct decoder not use", err)
+	}
+	if err := res.Bind("", &item); err != nil || calls != 2 {
+		t.Error("bind decoder not use", err)
+	}
+	var bound struct {
+		Item testDecodeItem `rest:"item"`
+	}
+	if err := NewJsonResult(`{"item":{"id":3,"name":"c"}}`, "").WithDecoder(decoder).BindPath(&bound); err != nil || bound.Item.Id != 3 || calls != 3 {
+		t.Error("bind path decoder not use", err)
+	}
+	SetJsonDecoder(decoder)
+	defer SetJsonDecoder(nil)
+	if err := NewJsonResult(`{"id":2,"name":"b"}`, "").Bind("", &item); err != nil || item.Id != 2 || calls != 4 {
+		t.Error("default decoder not use", err)
+	}
+}
+
+func benchmarkJsonBody(n int) string {
+	items := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		items = append(items, `{"id":`+strconv.Itoa(i)+`,"name":"item`+strconv.Itoa(i)+`"}`)
+	}
+	return `{"result":{"code":"200","state":"ok"},"data":{"list":[` + strings.Join(items, ",") + `]}}`
+}
+
+func BenchmarkJsonResultGetStruct(b *testing.B) {
+	res := NewJsonResult(benchmarkJsonBody(1000), "data")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var out struct {
+			List []testDecodeItem `json:"list"`
+		}
+		if err := res.GetStruct("", &out); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package rest_client
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/tidwall/gjson"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// WithExactNumber 数字转换丢失精度时返回 ErrPrecision,不再静默截断或舍入
+// 影响 GetInt GetFloat 及 BindPath: 有小数的值转整数,超出 int64 的整数,超出 float64 有效位数的金额等
+// 需要完整精度时使用 GetNumber GetRat 或绑定到 json.Number 及实现 encoding.TextUnmarshaler 的类型,如 decimal.Decimal
+func (res *JsonResult) WithExactNumber() *JsonResult {
+	res.exact = true
+	return res
+}
+
+func precisionError(path string, data *gjson.Result, toType string) error {
+	return NewRestClientError(ErrPrecision, fmt.Sprintf("path:%s value:%s convert to %s lose precision", path, data.Raw, toType))
+}
+
+// jsonNumberText 数字或数字字符串的原始文本,不经过 float64 转换
+func jsonNumberText(data *gjson.Result) (string, bool) {
+	switch data.Type {
+	case gjson.Number:
+		return data.Raw, true
+	case gjson.String:
+		text := strings.TrimSpace(data.Str)
+		if len(text) > 0 && (text[0] == '-' || (text[0] >= '0' && text[0] <= '9')) && json.Valid([]byte(text)) {
+			return text, true
+		}
+	}
+	return "", false
+}
+
+// exactInteger 数字文本转整数,有小数部分时返回false,支持 1.0 1e3 等形式
+func exactInteger(text string) (*big.Int, bool) {
+	rat, ok := new(big.Rat).SetString(text)
+	if !ok || !rat.IsInt() {
+		return nil, false
+	}
+	return rat.Num(), true
+}
+
+// exactInt 数字节点转 int64,有小数或超出范围时返回 ErrPrecision
+func exactInt(path string, data *gjson.Result) (int64, error) {
+	if n, err := strconv.ParseInt(data.Raw, 10, 64); err == nil {
+		return n, nil
+	}
+	n, ok := exactInteger(data.Raw)
+	if !ok || !n.IsInt64() {
+		return 0, precisionError(path, data, "int")
+	}
+	return n.Int64(), nil
+}
+
+// floatExact 浮点数的最短表示是否与数字文本相等,有效位数超过 float64 的金额等返回false
+func floatExact(text string, f float64, bitSize int) bool {
+	want, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return false
+	}
+	get, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, bitSize))
+	return ok && want.Cmp(get) == 0
+}
+
+// GetNumber 获取数字的原始文本,支持数字字符串,不丢失精度
+func (res *JsonResult) GetNumber(path string) (json.Number, error) {
+	data, err := res.getResult(path)
+	if err != nil {
+		return "", err
+	}
+	text, ok := jsonNumberText(data)
+	if !ok {
+		return "", jsonTypeError(path, data, "number")
+	}
+	return json.Number(text), nil
+}
+
+// GetFloat 获取浮点数,支持数字字符串,WithExactNumber 时丢失精度返回 ErrPrecision
+func (res *JsonResult) GetFloat(path string) (float64, error) {
+	data, err := res.getResult(path)
+	if err != nil {
+		return 0, err
+	}
+	text, ok := jsonNumberText(data)
+	if !ok {
+		return 0, jsonTypeError(path, data, "float")
+	}
+	f, pErr := strconv.ParseFloat(text, 64)
+	if pErr != nil {
+		return 0, jsonTypeError(path, data, "float")
+	}
+	if res.exact && !floatExact(text, f, 64) {
+		return 0, precisionError(path, data, "float")
+	}
+	return f, nil
+}
+
+// GetBigInt 获取任意长度的整数,支持数字字符串,有小数部分时返回 ErrPrecision
+func (res *JsonResult) GetBigInt(path string) (*big.Int, error) {
+	data, err := res.getResult(path)
+	if err != nil {
+		return nil, err
+	}
+	text, ok := jsonNumberText(data)
+	if !ok {
+		return nil, jsonTypeError(path, data, "big.Int")
+	}
+	n, ok := exactInteger(text)
+	if !ok {
+		return nil, precisionError(path, data, "big.Int")
+	}
+	return n, nil
+}
+
+// GetRat 获取精确的十进制数,用于金额计算,支持数字字符串
+func (res *JsonResult) GetRat(path string) (*big.Rat, error) {
+	data, err := res.getResult(path)
+	if err != nil {
+		return nil, err
+	}
+	text, ok := jsonNumberText(data)
+	if !ok {
+		return nil, jsonTypeError(path, data, "big.Rat")
+	}
+	rat, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return nil, jsonTypeError(path, data, "big.Rat")
+	}
+	return rat, nil
+}
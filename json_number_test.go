@@ -0,0 +1,113 @@
+package rest_client
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+)
+
+const testNumberBody = `{"data":{"amount":12345678901234567.89,"str_amount":"12345678901234567.89",
+	"price":0.1,"big":123456789012345678901234567890,"id":9007199254740993,"rate":"1.5","one":1.0,"name":"a"}}`
+
+// testNumberText 实现 encoding.TextUnmarshaler 的金额类型,模拟 decimal.Decimal
+type testNumberText struct {
+	text string
+}
+
+func (num *testNumberText) UnmarshalText(text []byte) error {
+	num.text = string(text)
+	return nil
+}
+
+func TestJsonResultGetNumber(t *testing.T) {
+	res := NewJsonResult(testNumberBody, "data")
+	if num, err := res.GetNumber("amount"); err != nil || num != "12345678901234567.89" {
+		t.Error(num, err)
+	}
+	if num, err := res.GetNumber("str_amount"); err != nil || num != "12345678901234567.89" {
+		t.Error(num, err)
+	}
+	if _, err := res.GetNumber("name"); ErrorCode(err) != ErrTypeMismatch {
+		t.Error(err)
+	}
+	if n, err := res.GetBigInt("big"); err != nil || n.String() != "123456789012345678901234567890" {
+		t.Error(n, err)
+	}
+	if _, err := res.GetBigInt("amount"); ErrorCode(err) != ErrPrecision {
+		t.Error(err)
+	}
+	want, _ := new(big.Rat).SetString("12345678901234567.89")
+	if rat, err := res.GetRat("str_amount"); err != nil || rat.Cmp(want) != 0 {
+		t.Error(rat, err)
+	}
+	if f, err := res.GetFloat("rate"); err != nil || f != 1.5 {
+		t.Error(f, err)
+	}
+	//默认静默舍入
+	if _, err := res.GetFloat("amount"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestJsonResultExactNumber(t *testing.T) {
+	res := NewJsonResult(testNumberBody, "data").WithExactNumber()
+	if _, err := res.GetFloat("amount"); ErrorCode(err) != ErrPrecision {
+		t.Error(err)
+	}
+	if f, err := res.GetFloat("price"); err != nil || f != 0.1 {
+		t.Error(f, err)
+	}
+	if n, err := res.GetInt("id"); err != nil || n != 9007199254740993 {
+		t.Error(n, err)
+	}
+	if n, err := res.GetInt("one"); err != nil || n != 1 {
+		t.Error(n, err)
+	}
+	if _, err := res.GetInt("amount"); ErrorCode(err) != ErrPrecision {
+		t.Error(err)
+	}
+	if _, err := res.GetInt("big"); ErrorCode(err) != ErrPrecision {
+		t.Error(err)
+	}
+}
+
+func TestJsonResultBindPathNumber(t *testing.T) {
+	var out struct {
+		Amount json.Number    `rest:"amount"`
+		Text   testNumberText `rest:"str_amount"`
+		Raw    testNumberText `rest:"amount"`
+		Big    *big.Int       `rest:"big"`
+		Rat    big.Rat        `rest:"str_amount"`
+		Id     int64          `rest:"id"`
+		Price  float64        `rest:"price"`
+	}
+	res := NewJsonResult(testNumberBody, "data").WithExactNumber()
+	if err := res.BindPath(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Amount != "12345678901234567.89" || out.Text.text != "12345678901234567.89" || out.Raw.text != "12345678901234567.89" {
+		t.Error(out.Amount, out.Text, out.Raw)
+	}
+	if out.Big.String() != "123456789012345678901234567890" || out.Rat.FloatString(2) != "12345678901234567.89" {
+		t.Error(out.Big, out.Rat.String())
+	}
+	if out.Id != 9007199254740993 || out.Price != 0.1 {
+		t.Error(out.Id, out.Price)
+	}
+	var lose struct {
+		Amount float64 `rest:"amount"`
+	}
+	if err := res.BindPath(&lose); ErrorCode(err) != ErrPrecision {
+		t.Error(err)
+	}
+	var count struct {
+		Count int `rest:"amount"`
+	}
+	if err := res.BindPath(&count); ErrorCode(err) != ErrPrecision {
+		t.Error(err)
+	}
+	//未设置时保持原有的转换
+	if err := NewJsonResult(testNumberBody, "data").BindPath(&lose); err != nil || lose.Amount == 0 {
+		t.Error(lose.Amount, err)
+	}
+}
@@ -0,0 +1,308 @@
+package rest_client
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// JsonPresence 节点状态,用于区分部分更新返回中未返回的字段与明确返回零值的字段
+type JsonPresence int
+
+const (
+	JsonAbsent  JsonPresence = iota //节点不存在
+	JsonNull                        //节点为 null
+	JsonPresent                     //节点存在且不为 null
+)
+
+// presence 获取节点状态,结果错误时返回错误
+func (res *JsonResult) presence(path string) (JsonPresence, error) {
+	if res.err != nil {
+		return JsonAbsent, res.err
+	}
+	data := res.lookup(pathCreate(res.basePath, path))
+	switch {
+	case !data.Exists():
+		return JsonAbsent, nil
+	case data.Raw == "null":
+		return JsonNull, nil
+	}
+	return JsonPresent, nil
+}
+
+// Presence 获取节点状态,结果错误时为 JsonAbsent
+func (res *JsonResult) Presence(path string) JsonPresence {
+	state, _ := res.presence(path)
+	return state
+}
+
+// IsNull 节点是否存在且为 null
+func (res *JsonResult) IsNull(path string) bool {
+	return res.Presence(path) == JsonNull
+}
+
+// optionalResult 将 UnmarshalJSON 的内容作为根节点,为 null 时返回nil
+func optionalResult(b []byte) *JsonResult {
+	if string(bytes.TrimSpace(b)) == "null" {
+		return nil
+	}
+	return NewJsonResult(string(b), "")
+}
+
+// optionalJson 值有效时编码值,否则编码为 null
+func optionalJson(valid bool, val interface{}) ([]byte, error) {
+	if !valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(val)
+}
+
+// OptionalString 可区分不存在 null 及空字符串的字符串,可用于 GetStruct 及 BindPath 的字段
+type OptionalString struct {
+	Value string
+	State JsonPresence
+}
+
+// Valid 是否有值
+func (opt OptionalString) Valid() bool {
+	return opt.State == JsonPresent
+}
+
+// Or 有值时返回值,否则返回 def
+func (opt OptionalString) Or(def string) string {
+	if opt.Valid() {
+		return opt.Value
+	}
+	return def
+}
+
+func (opt *OptionalString) UnmarshalJSON(b []byte) error {
+	res := optionalResult(b)
+	if res == nil {
+		*opt = OptionalString{State: JsonNull}
+		return nil
+	}
+	val, err := res.GetString("")
+	if err != nil {
+		return err
+	}
+	*opt = OptionalString{Value: val, State: JsonPresent}
+	return nil
+}
+
+func (opt OptionalString) MarshalJSON() ([]byte, error) {
+	return optionalJson(opt.Valid(), opt.Value)
+}
+
+// GetOptionalString 获取可选字符串,节点不存在或为 null 时不返回错误
+func (res *JsonResult) GetOptionalString(path string) (OptionalString, error) {
+	state, err := res.presence(path)
+	if err != nil || state != JsonPresent {
+		return OptionalString{State: state}, err
+	}
+	val, err := res.GetString(path)
+	if err != nil {
+		return OptionalString{}, err
+	}
+	return OptionalString{Value: val, State: state}, nil
+}
+
+// OptionalInt 可区分不存在 null 及0的整数,支持数字字符串
+type OptionalInt struct {
+	Value int64
+	State JsonPresence
+}
+
+// Valid 是否有值
+func (opt OptionalInt) Valid() bool {
+	return opt.State == JsonPresent
+}
+
+// Or 有值时返回值,否则返回 def
+func (opt OptionalInt) Or(def int64) int64 {
+	if opt.Valid() {
+		return opt.Value
+	}
+	return def
+}
+
+func (opt *OptionalInt) UnmarshalJSON(b []byte) error {
+	res := optionalResult(b)
+	if res == nil {
+		*opt = OptionalInt{State: JsonNull}
+		return nil
+	}
+	val, err := res.GetInt("")
+	if err != nil {
+		return err
+	}
+	*opt = OptionalInt{Value: val, State: JsonPresent}
+	return nil
+}
+
+func (opt OptionalInt) MarshalJSON() ([]byte, error) {
+	return optionalJson(opt.Valid(), opt.Value)
+}
+
+// GetOptionalInt 获取可选整数,节点不存在或为 null 时不返回错误
+func (res *JsonResult) GetOptionalInt(path string) (OptionalInt, error) {
+	state, err := res.presence(path)
+	if err != nil || state != JsonPresent {
+		return OptionalInt{State: state}, err
+	}
+	val, err := res.GetInt(path)
+	if err != nil {
+		return OptionalInt{}, err
+	}
+	return OptionalInt{Value: val, State: state}, nil
+}
+
+// OptionalFloat 可区分不存在 null 及0的浮点数,支持数字字符串
+type OptionalFloat struct {
+	Value float64
+	State JsonPresence
+}
+
+// Valid 是否有值
+func (opt OptionalFloat) Valid() bool {
+	return opt.State == JsonPresent
+}
+
+// Or 有值时返回值,否则返回 def
+func (opt OptionalFloat) Or(def float64) float64 {
+	if opt.Valid() {
+		return opt.Value
+	}
+	return def
+}
+
+func (opt *OptionalFloat) UnmarshalJSON(b []byte) error {
+	res := optionalResult(b)
+	if res == nil {
+		*opt = OptionalFloat{State: JsonNull}
+		return nil
+	}
+	val, err := res.GetFloat("")
+	if err != nil {
+		return err
+	}
+	*opt = OptionalFloat{Value: val, State: JsonPresent}
+	return nil
+}
+
+func (opt OptionalFloat) MarshalJSON() ([]byte, error) {
+	return optionalJson(opt.Valid(), opt.Value)
+}
+
+// GetOptionalFloat 获取可选浮点数,节点不存在或为 null 时不返回错误
+func (res *JsonResult) GetOptionalFloat(path string) (OptionalFloat, error) {
+	state, err := res.presence(path)
+	if err != nil || state != JsonPresent {
+		return OptionalFloat{State: state}, err
+	}
+	val, err := res.GetFloat(path)
+	if err != nil {
+		return OptionalFloat{}, err
+	}
+	return OptionalFloat{Value: val, State: state}, nil
+}
+
+// OptionalBool 可区分不存在 null 及false的布尔值,支持 "true" "1" 等字符串及数字
+type OptionalBool struct {
+	Value bool
+	State JsonPresence
+}
+
+// Valid 是否有值
+func (opt OptionalBool) Valid() bool {
+	return opt.State == JsonPresent
+}
+
+// Or 有值时返回值,否则返回 def
+func (opt OptionalBool) Or(def bool) bool {
+	if opt.Valid() {
+		return opt.Value
+	}
+	return def
+}
+
+func (opt *OptionalBool) UnmarshalJSON(b []byte) error {
+	res := optionalResult(b)
+	if res == nil {
+		*opt = OptionalBool{State: JsonNull}
+		return nil
+	}
+	val, err := res.GetBool("")
+	if err != nil {
+		return err
+	}
+	*opt = OptionalBool{Value: val, State: JsonPresent}
+	return nil
+}
+
+func (opt OptionalBool) MarshalJSON() ([]byte, error) {
+	return optionalJson(opt.Valid(), opt.Value)
+}
+
+// GetOptionalBool 获取可选布尔值,节点不存在或为 null 时不返回错误
+func (res *JsonResult) GetOptionalBool(path string) (OptionalBool, error) {
+	state, err := res.presence(path)
+	if err != nil || state != JsonPresent {
+		return OptionalBool{State: state}, err
+	}
+	val, err := res.GetBool(path)
+	if err != nil {
+		return OptionalBool{}, err
+	}
+	return OptionalBool{Value: val, State: state}, nil
+}
+
+// OptionalTime 可区分不存在 null 及零值的时间,解析规则同 GetTime 的默认格式
+type OptionalTime struct {
+	Value time.Time
+	State JsonPresence
+}
+
+// Valid 是否有值
+func (opt OptionalTime) Valid() bool {
+	return opt.State == JsonPresent
+}
+
+// Or 有值时返回值,否则返回 def
+func (opt OptionalTime) Or(def time.Time) time.Time {
+	if opt.Valid() {
+		return opt.Value
+	}
+	return def
+}
+
+func (opt *OptionalTime) UnmarshalJSON(b []byte) error {
+	res := optionalResult(b)
+	if res == nil {
+		*opt = OptionalTime{State: JsonNull}
+		return nil
+	}
+	val, err := res.GetTime("")
+	if err != nil {
+		return err
+	}
+	*opt = OptionalTime{Value: val, State: JsonPresent}
+	return nil
+}
+
+func (opt OptionalTime) MarshalJSON() ([]byte, error) {
+	return optionalJson(opt.Valid(), opt.Value)
+}
+
+// GetOptionalTime 获取可选时间,节点不存在或为 null 时不返回错误
+func (res *JsonResult) GetOptionalTime(path string, layout ...string) (OptionalTime, error) {
+	state, err := res.presence(path)
+	if err != nil || state != JsonPresent {
+		return OptionalTime{State: state}, err
+	}
+	val, err := res.GetTime(path, layout...)
+	if err != nil {
+		return OptionalTime{}, err
+	}
+	return OptionalTime{Value: val, State: state}, nil
+}
@@ -0,0 +1,77 @@
+package rest_client
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const testOptionalBody = `{"data":{"name":"","count":0,"price":null,"enable":"false","nick":null}}`
+
+func TestJsonResultPresence(t *testing.T) {
+	res := NewJsonResult(testOptionalBody, "data")
+	if res.Presence("name") != JsonPresent || res.Presence("price") != JsonNull || res.Presence("miss") != JsonAbsent {
+		t.Error(res.Presence("name"), res.Presence("price"), res.Presence("miss"))
+	}
+	if !res.IsNull("price") || res.IsNull("count") || res.IsNull("miss") {
+		t.Error("is null")
+	}
+	if name, err := res.GetOptionalString("name"); err != nil || !name.Valid() || name.Value != "" {
+		t.Error(name, err)
+	}
+	if count, err := res.GetOptionalInt("count"); err != nil || !count.Valid() || count.Or(5) != 0 {
+		t.Error(count, err)
+	}
+	if price, err := res.GetOptionalFloat("price"); err != nil || price.State != JsonNull || price.Or(1.5) != 1.5 {
+		t.Error(price, err)
+	}
+	if miss, err := res.GetOptionalBool("miss"); err != nil || miss.State != JsonAbsent || !miss.Or(true) {
+		t.Error(miss, err)
+	}
+	if enable, err := res.GetOptionalBool("enable"); err != nil || !enable.Valid() || enable.Value {
+		t.Error(enable, err)
+	}
+	if _, err := res.GetOptionalInt("name"); ErrorCode(err) != ErrTypeMismatch {
+		t.Error(err)
+	}
+	if _, err := NewJsonResultFromError(NewRestClientError(ErrServerHttp, "fail")).GetOptionalTime("name"); ErrorCode(err) != ErrServerHttp {
+		t.Error(err)
+	}
+}
+
+type testOptionalBind struct {
+	Name   OptionalString `rest:"name" json:"name"`
+	Count  OptionalInt    `rest:"count" json:"count"`
+	Price  OptionalFloat  `rest:"price" json:"price"`
+	Enable OptionalBool   `rest:"enable" json:"enable"`
+	Nick   OptionalString `rest:"nick" json:"nick"`
+	Miss   OptionalTime   `rest:"miss" json:"miss"`
+}
+
+func TestJsonResultOptionalBind(t *testing.T) {
+	check := func(out testOptionalBind) {
+		if out.Name.State != JsonPresent || out.Count.State != JsonPresent || out.Count.Value != 0 {
+			t.Error(out.Name, out.Count)
+		}
+		if out.Price.State != JsonNull || out.Nick.State != JsonNull || out.Miss.State != JsonAbsent {
+			t.Error(out.Price, out.Nick, out.Miss)
+		}
+		if !out.Enable.Valid() || out.Enable.Value {
+			t.Error(out.Enable)
+		}
+	}
+	res := NewJsonResult(testOptionalBody, "data")
+	var bind testOptionalBind
+	if err := res.BindPath(&bind); err != nil {
+		t.Fatal(err)
+	}
+	check(bind)
+	var st testOptionalBind
+	if err := res.GetStruct("", &st); err != nil {
+		t.Fatal(err)
+	}
+	check(st)
+	b, _ := json.Marshal(st)
+	if string(b) != `{"name":"","count":0,"price":null,"enable":false,"nick":null,"miss":null}` {
+		t.Error(string(b))
+	}
+}
@@ -0,0 +1,322 @@
+package rest_client
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/tidwall/gjson"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	jsonTimeType   = reflect.TypeOf(time.Time{})
+	jsonNumberType = reflect.TypeOf(json.Number(""))
+)
+
+// BindPath 按结构字段 rest 标签中的路径绑定返回内容,如 `rest:"data.items.#.name"`,路径语法同 gjson
+// 路径相对于 basePath,结构字段的路径相对于上级字段的节点,没有 rest 标签的字段忽略,匿名结构按当前节点绑定
+// 标签加 ,required 时节点不存在返回错误,其他字段节点不存在时保持原值
+// 字符串与数字布尔值间自动转换,时间支持UNIX秒及 GetTime 的默认格式,[]byte 为base64,
+// 没有 rest 标签的结构按 json 标签由 JsonDecoder 解析,json.Number 保留原始文本,实现 encoding.TextUnmarshaler 或 json.Unmarshaler
+// 的类型(如 decimal.Decimal big.Int)由类型自行解析,WithExactNumber 时数字转换丢失精度返回 ErrPrecision
+// @param structPtr 结构指针
+func (res *JsonResult) BindPath(structPtr interface{}) error {
+	if res.err != nil {
+		return res.err
+	}
+	val := reflect.ValueOf(structPtr)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return NewRestClientError(ErrJsonValid, "bind path need struct pointer")
+	}
+	return res.bindPathStruct(res.lookup(res.basePath), res.basePath, val.Elem())
+}
+
+// hasRestTag 结构是否有 rest 标签的字段
+func hasRestTag(typ reflect.Type) bool {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if _, ok := field.Tag.Lookup("rest"); ok {
+			return true
+		}
+		if field.Anonymous {
+			embed := field.Type
+			if embed.Kind() == reflect.Ptr {
+				embed = embed.Elem()
+			}
+			if embed.Kind() == reflect.Struct && hasRestTag(embed) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (res *JsonResult) bindPathStruct(data gjson.Result, path string, val reflect.Value) error {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag, ok := field.Tag.Lookup("rest")
+		if !ok {
+			if !field.Anonymous {
+				continue
+			}
+			embed := val.Field(i)
+			if embed.Kind() == reflect.Ptr && embed.Type().Elem().Kind() == reflect.Struct && embed.CanSet() {
+				if embed.IsNil() {
+					embed.Set(reflect.New(embed.Type().Elem()))
+				}
+				embed = embed.Elem()
+			}
+			if embed.Kind() == reflect.Struct {
+				if err := res.bindPathStruct(data, path, embed); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		//未导出的匿名结构可以绑定导出的字段
+		if len(field.PkgPath) > 0 && !(field.Anonymous && field.Type.Kind() == reflect.Struct && hasRestTag(field.Type)) {
+			continue
+		}
+		name, opts := tag, ""
+		if pos := strings.IndexByte(tag, ','); pos >= 0 {
+			name, opts = tag[:pos], tag[pos+1:]
+		}
+		if name == "-" {
+			continue
+		}
+		value := data
+		if len(name) > 0 {
+			value = data.Get(name)
+		}
+		fieldPath := pathCreate(path, name)
+		if !value.Exists() {
+			if opts == "required" {
+				return NewRestClientError(ErrPathNotExists, "path not exists:"+fieldPath)
+			}
+			continue
+		}
+		if err := res.bindPathValue(value, fieldPath, val.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (res *JsonResult) bindPathValue(data gjson.Result, path string, val reflect.Value) error {
+	typ := val.Type()
+	if typ == jsonTimeType {
+		tm, err := jsonTime(path, &data)
+		if err != nil {
+			return err
+		}
+		val.Set(reflect.ValueOf(tm))
+		return nil
+	}
+	if typ == jsonNumberType {
+		text, ok := jsonNumberText(&data)
+		if !ok {
+			return jsonTypeError(path, &data, typ.String())
+		}
+		val.SetString(text)
+		return nil
+	}
+	if val.Kind() != reflect.Ptr && val.Kind() != reflect.Interface && val.CanAddr() && val.CanInterface() {
+		if ok, err := bindPathUnmarshaler(data, path, val.Addr().Interface()); ok {
+			return err
+		}
+	}
+	switch val.Kind() {
+	case reflect.Ptr:
+		if data.Type == gjson.Null {
+			val.Set(reflect.Zero(typ))
+			return nil
+		}
+		if val.IsNil() {
+			val.Set(reflect.New(typ.Elem()))
+		}
+		return res.bindPathValue(data, path, val.Elem())
+	case reflect.Interface:
+		if typ.NumMethod() > 0 {
+			return jsonTypeError(path, &data, typ.String())
+		}
+		if value := data.Value(); value != nil {
+			val.Set(reflect.ValueOf(value))
+		} else {
+			val.Set(reflect.Zero(typ))
+		}
+	case reflect.String:
+		if data.IsObject() || data.IsArray() {
+			return jsonTypeError(path, &data, "string")
+		}
+		val.SetString(data.String())
+	case reflect.Bool:
+		switch data.Type {
+		case gjson.True, gjson.False, gjson.Number:
+			val.SetBool(data.Bool())
+		case gjson.String:
+			b, err := strconv.ParseBool(strings.TrimSpace(data.Str))
+			if err != nil {
+				return jsonTypeError(path, &data, "bool")
+			}
+			val.SetBool(b)
+		default:
+			return jsonTypeError(path, &data, "bool")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		switch data.Type {
+		case gjson.Number:
+			if res.exact {
+				var err error
+				if n, err = exactInt(path, &data); err != nil {
+					return err
+				}
+			} else {
+				n = data.Int()
+			}
+		case gjson.String:
+			var err error
+			if n, err = strconv.ParseInt(strings.TrimSpace(data.Str), 10, 64); err != nil {
+				return jsonTypeError(path, &data, typ.String())
+			}
+		default:
+			return jsonTypeError(path, &data, typ.String())
+		}
+		if val.OverflowInt(n) {
+			return jsonTypeError(path, &data, typ.String())
+		}
+		val.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		switch data.Type {
+		case gjson.Number:
+			if data.Num < 0 {
+				return jsonTypeError(path, &data, typ.String())
+			}
+			if res.exact {
+				var err error
+				if n, err = strconv.ParseUint(data.Raw, 10, 64); err != nil {
+					num, ok := exactInteger(data.Raw)
+					if !ok || !num.IsUint64() {
+						return precisionError(path, &data, typ.String())
+					}
+					n = num.Uint64()
+				}
+			} else {
+				n = data.Uint()
+			}
+		case gjson.String:
+			var err error
+			if n, err = strconv.ParseUint(strings.TrimSpace(data.Str), 10, 64); err != nil {
+				return jsonTypeError(path, &data, typ.String())
+			}
+		default:
+			return jsonTypeError(path, &data, typ.String())
+		}
+		if val.OverflowUint(n) {
+			return jsonTypeError(path, &data, typ.String())
+		}
+		val.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch data.Type {
+		case gjson.Number:
+			f = data.Num
+		case gjson.String:
+			var err error
+			if f, err = strconv.ParseFloat(strings.TrimSpace(data.Str), 64); err != nil {
+				return jsonTypeError(path, &data, typ.String())
+			}
+		default:
+			return jsonTypeError(path, &data, typ.String())
+		}
+		if res.exact {
+			text, _ := jsonNumberText(&data)
+			if !floatExact(text, f, typ.Bits()) {
+				return precisionError(path, &data, typ.String())
+			}
+		}
+		val.SetFloat(f)
+	case reflect.Slice:
+		if data.Type == gjson.Null {
+			val.Set(reflect.Zero(typ))
+			return nil
+		}
+		if typ.Elem().Kind() == reflect.Uint8 && data.Type == gjson.String {
+			b, err := base64.StdEncoding.DecodeString(data.Str)
+			if err != nil {
+				return jsonTypeError(path, &data, "[]byte")
+			}
+			val.SetBytes(b)
+			return nil
+		}
+		//非数组的值作为只有一个元素的数组
+		items := data.Array()
+		slice := reflect.MakeSlice(typ, len(items), len(items))
+		for i, item := range items {
+			if err := res.bindPathValue(item, path+"."+strconv.Itoa(i), slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		val.Set(slice)
+	case reflect.Map:
+		if typ.Key().Kind() != reflect.String || !data.IsObject() {
+			return jsonTypeError(path, &data, typ.String())
+		}
+		out := reflect.MakeMapWithSize(typ, 0)
+		var err error
+		data.ForEach(func(key, value gjson.Result) bool {
+			item := reflect.New(typ.Elem()).Elem()
+			if err = res.bindPathValue(value, path+"."+key.String(), item); err != nil {
+				return false
+			}
+			out.SetMapIndex(reflect.ValueOf(key.String()).Convert(typ.Key()), item)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		val.Set(out)
+	case reflect.Struct:
+		if !data.IsObject() {
+			return jsonTypeError(path, &data, typ.String())
+		}
+		if hasRestTag(typ) {
+			return res.bindPathStruct(data, path, val)
+		}
+		if err := res.decode(data.Raw, val.Addr().Interface()); err != nil {
+			return NewRestClientError(ErrJsonValid, "path:"+path+" decode error:"+err.Error())
+		}
+	default:
+		return jsonTypeError(path, &data, typ.String())
+	}
+	return nil
+}
+
+// bindPathUnmarshaler 类型实现解析接口时由类型解析,字符串优先使用 UnmarshalText,未实现接口时返回false
+func bindPathUnmarshaler(data gjson.Result, path string, ptr interface{}) (bool, error) {
+	text, isText := ptr.(encoding.TextUnmarshaler)
+	var err error
+	if unmarshaler, ok := ptr.(json.Unmarshaler); ok && !(isText && data.Type == gjson.String) {
+		err = unmarshaler.UnmarshalJSON([]byte(data.Raw))
+	} else if isText {
+		switch data.Type {
+		case gjson.String:
+			err = text.UnmarshalText([]byte(data.Str))
+		case gjson.Number, gjson.True, gjson.False:
+			err = text.UnmarshalText([]byte(data.Raw))
+		default:
+			return true, jsonTypeError(path, &data, reflect.TypeOf(ptr).Elem().String())
+		}
+	} else {
+		return false, nil
+	}
+	if err != nil {
+		return true, NewRestClientError(ErrJsonValid, "path:"+path+" decode error:"+err.Error())
+	}
+	return true, nil
+}
@@ -0,0 +1,80 @@
+package rest_client
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type testPathItem struct {
+	Name  string  `rest:"name"`
+	Price float64 `rest:"price"`
+}
+
+type testPathPage struct {
+	Total int `rest:"total"`
+}
+
+type testPathBind struct {
+	testPathPage `rest:"data.page"`
+	Code         int                    `rest:"result.code"`
+	Ok           bool                   `rest:"data.ok"`
+	Names        []string               `rest:"data.items.#.name"`
+	Items        []testPathItem         `rest:"data.items"`
+	First        *testPathItem          `rest:"data.items.0"`
+	Tags         map[string]int         `rest:"data.tags"`
+	Created      time.Time              `rest:"data.created"`
+	Raw          []byte                 `rest:"data.raw"`
+	Extra        map[string]interface{} `rest:"data.extra"`
+	Plain        struct {
+		Id int64 `json:"id"`
+	} `rest:"data.plain"`
+	Missing string `rest:"data.missing"`
+	Ignore  string
+}
+
+func TestJsonResultBindPath(t *testing.T) {
+	res := NewJsonResult(`{"result":{"code":"200"},"data":{"page":{"total":"3"},"ok":"true",
+		"items":[{"name":"a","price":"1.5"},{"name":"b","price":2}],"tags":{"x":1,"y":"2"},
+		"created":"2023-01-02 03:04:05","raw":"aGk=","extra":{"k":[1]},"plain":{"id":9}}}`, "")
+	var out testPathBind
+	out.Missing = "keep"
+	if err := res.BindPath(&out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Code != 200 || !out.Ok || out.Total != 3 {
+		t.Error("bind path coercion error", out.Code, out.Ok, out.Total)
+	}
+	if len(out.Names) != 2 || out.Names[1] != "b" || len(out.Items) != 2 || out.Items[0].Price != 1.5 || out.First.Name != "a" {
+		t.Error("bind path slice error", out.Names, out.Items)
+	}
+	if out.Tags["y"] != 2 || out.Created.Hour() != 3 || string(out.Raw) != "hi" || out.Plain.Id != 9 {
+		t.Error("bind path value error", out.Tags, out.Created, out.Raw, out.Plain)
+	}
+	if _, ok := out.Extra["k"].([]interface{}); !ok || out.Missing != "keep" {
+		t.Error("bind path interface error", out.Extra)
+	}
+
+	//基础路径
+	var item testPathItem
+	if err := NewJsonResult(`{"data":{"name":"c","price":3}}`, "data").BindPath(&item); err != nil || item.Name != "c" {
+		t.Error("bind path base path error", err)
+	}
+
+	var restErr *RestClientError
+	var mismatch struct {
+		Price int `rest:"price"`
+	}
+	if err := NewJsonResult(`{"price":"abc"}`, "").BindPath(&mismatch); !errors.As(err, &restErr) || restErr.Code != ErrTypeMismatch {
+		t.Error("bind path type mismatch error", err)
+	}
+	var required struct {
+		Name string `rest:"name,required"`
+	}
+	if err := NewJsonResult(`{}`, "").BindPath(&required); !errors.As(err, &restErr) || restErr.Code != ErrPathNotExists {
+		t.Error("bind path required error", err)
+	}
+	if err := NewJsonResult(`{}`, "").BindPath(required); err == nil {
+		t.Error("bind path need pointer")
+	}
+}
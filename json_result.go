@@ -1,14 +1,15 @@
 package rest_client
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/go-playground/validator/v10"
 	"github.com/tidwall/gjson"
 	"reflect"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
 // JsonResult JSON结果集
@@ -17,6 +18,87 @@ type JsonResult struct {
 	basePath string
 	body     string
 	err      error
+	decoder  JsonDecoder
+	lock     sync.Mutex
+	root     *gjson.Result           //首次获取根节点时解析
+	paths    map[string]gjson.Result //已获取的简单路径节点,子路径从父节点查找
+	exact    bool                    //数字转换丢失精度时返回错误
+	//请求失败时的诊断信息
+	diagnostics *RestDiagnostics
+}
+
+// maxJsonPathCache 每个结果最多缓存的节点数,避免遍历数组时无限增长
+const maxJsonPathCache = 256
+
+// jsonStart 跳过根节点前的多余内容,如BOM及防JSON劫持的前缀 )]}',未找到对象或数组时返回原内容
+// 以字符串,数字,true false null 开头的内容为合法的JSON值,不跳过
+func jsonStart(body string) string {
+	trimmed := strings.TrimLeft(body, " \t\r\n")
+	if len(trimmed) == 0 || strings.IndexByte("{[\"-0123456789tfn", trimmed[0]) >= 0 {
+		return trimmed
+	}
+	if i := strings.IndexAny(trimmed, "{["); i > 0 {
+		return trimmed[i:]
+	}
+	return body
+}
+
+// simpleJsonPath 是否为只有KEY及下标的路径,不含通配符,查询,修饰符及转义等语法
+func simpleJsonPath(path string) bool {
+	if len(path) == 0 || path[0] == '.' || path[len(path)-1] == '.' || strings.Contains(path, "..") {
+		return false
+	}
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if !(c == '.' || c == '_' || c == '-' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup 获取节点,根节点只解析一次
+// 简单路径逐级从已获取的父节点查找并缓存,如获取 data.a 及 data.b 时只查找一次 data,之后只在 data 节点内查找
+// 其他路径从根节点查找
+func (res *JsonResult) lookup(path string) gjson.Result {
+	res.lock.Lock()
+	defer res.lock.Unlock()
+	if res.root == nil {
+		root := gjson.Parse(jsonStart(res.body))
+		res.root = &root
+	}
+	if len(path) == 0 {
+		return *res.root
+	}
+	if !simpleJsonPath(path) {
+		return res.root.Get(path)
+	}
+	if data, ok := res.paths[path]; ok {
+		return data
+	}
+	if res.paths == nil {
+		res.paths = map[string]gjson.Result{}
+	}
+	node, start := *res.root, 0
+	for end := 0; end <= len(path); end++ {
+		if end < len(path) && path[end] != '.' {
+			continue
+		}
+		prefix := path[:end]
+		if data, ok := res.paths[prefix]; ok {
+			node = data
+		} else {
+			node = node.Get(path[start:end])
+			if len(res.paths) < maxJsonPathCache {
+				res.paths[prefix] = node
+			}
+		}
+		if !node.Exists() {
+			return node
+		}
+		start = end + 1
+	}
+	return node
 }
 
 // NewJsonResult 解析一个JSON字符串为JSON结果
@@ -74,20 +156,12 @@ func (res *JsonResult) GetStruct(path string, structPtr interface{}, jsonValid .
 	if res.err != nil {
 		return res.err
 	}
-	body := res.body
-	var param string
 	path = pathCreate(res.basePath, path)
-	if len(path) == 0 {
-		param = body
-	} else {
-		param = gjson.Get(body, path).String()
-	}
+	param := res.lookup(path).String()
 	if len(param) == 0 {
 		param = "{}"
 	}
-	dec := json.NewDecoder(bytes.NewBuffer([]byte(param)))
-	dec.UseNumber()
-	err := dec.Decode(&structPtr)
+	err := res.decode(param, &structPtr)
 	if err != nil {
 		return err
 	}
@@ -152,7 +226,7 @@ func (res *JsonResult) GetStruct(path string, structPtr interface{}, jsonValid .
 				}
 			}
 			if vErr != nil {
-				return NewRestClientError("20", fmt.Sprintf("path:%s field:%s tag:%s error:%s ", path, field.Name, vTag, vErr.Error()))
+				return NewRestClientError(ErrJsonValid, fmt.Sprintf("path:%s field:%s tag:%s error:%s ", path, field.Name, vTag, vErr.Error()))
 			}
 		} else {
 			allJsonData = false
@@ -188,7 +262,9 @@ type JsonKey struct {
 // @param dataKey 传入string 表示不校验直接获取某节点数据,传空获取所有数据
 func (res *JsonResult) GetData(key interface{}) *JsonData {
 	if res.err != nil {
-		return NewJsonDataFromError(res.err)
+		data := NewJsonDataFromError(res.err)
+		data.diagnostics = res.diagnostics
+		return data
 	}
 	var dKey *JsonKey
 	if _dKey, ok := key.(*JsonKey); ok {
@@ -200,7 +276,7 @@ func (res *JsonResult) GetData(key interface{}) *JsonData {
 	} else if key == nil {
 		dKey = &JsonKey{}
 	} else {
-		return NewJsonDataFromError(NewRestClientError("20", "dataKey type not support"))
+		return NewJsonDataFromError(NewRestClientError(ErrJsonValid, "dataKey type not support"))
 	}
 	body := res.body
 	_path := pathCreate(res.basePath, dKey.Path)
@@ -210,7 +286,7 @@ func (res *JsonResult) GetData(key interface{}) *JsonData {
 			Str:  body,
 		})
 	}
-	data := gjson.Get(body, _path)
+	data := res.lookup(_path)
 	if len(dKey.Tag) > 0 {
 		var valid *validator.Validate
 		if dKey.JsonValid != nil {
@@ -235,7 +311,7 @@ func (res *JsonResult) GetData(key interface{}) *JsonData {
 			err = valid.Var(val, dKey.Tag)
 		}
 		if err != nil {
-			return NewJsonDataFromError(NewRestClientError("20", fmt.Sprintf("path:%s tag:%s error:%s ", _path, dKey.Tag, err.Error())))
+			return NewJsonDataFromError(NewRestClientError(ErrJsonValid, fmt.Sprintf("path:%s tag:%s error:%s ", _path, dKey.Tag, err.Error())))
 		}
 	}
 	return NewJsonData(&data)
@@ -246,7 +322,8 @@ func (res *JsonResult) GetData(key interface{}) *JsonData {
 // JsonData JSON数据
 type JsonData struct {
 	*gjson.Result
-	err error
+	err         error
+	diagnostics *RestDiagnostics //请求失败时的诊断信息
 }
 
 // Err JSON数据是否错误,如校验失败时通过此函数返回错误详细
@@ -270,7 +347,7 @@ func (hand *JsonData) UnmarshalJSON(data []byte) error {
 		&gjson.Result{
 			Type: gjson.String,
 			Str:  strings.Trim(string(data), "\""),
-		}, nil,
+		}, nil, nil,
 	}
 	*hand = jDat
 	return nil
@@ -297,10 +374,209 @@ func (hand *JsonData) JsonDataDefault() interface{} {
 
 // NewJsonData 创建一个正常JSON数据
 func NewJsonData(result *gjson.Result) *JsonData {
-	return &JsonData{result, nil}
+	return &JsonData{result, nil, nil}
 }
 
 // NewJsonDataFromError 创建一个错误JSON数据
 func NewJsonDataFromError(err error) *JsonData {
 	return &JsonData{err: err, Result: &gjson.Result{}}
 }
+
+/////////////类型获取//////////////////
+
+// defaultTimeLayouts 默认时间格式,跟请求时timestamp格式一致
+var defaultTimeLayouts = []string{"2006-01-02 15:04:05", time.RFC3339}
+
+// getResult 获取存在的节点,不存在时返回错误
+func (res *JsonResult) getResult(path string) (*gjson.Result, error) {
+	if res.err != nil {
+		return nil, res.err
+	}
+	_path := pathCreate(res.basePath, path)
+	data := res.lookup(_path)
+	if !data.Exists() {
+		return nil, NewRestClientError(ErrPathNotExists, "path not exists:"+_path)
+	}
+	return &data, nil
+}
+
+func jsonTypeError(path string, data *gjson.Result, toType string) error {
+	return NewRestClientError(ErrTypeMismatch, fmt.Sprintf("path:%s value:%s can't convert to %s", path, data.Raw, toType))
+}
+
+// Exists 判断节点是否存在
+func (res *JsonResult) Exists(path string) bool {
+	_, err := res.getResult(path)
+	return err == nil
+}
+
+// GetString 获取字符串,节点不存在或为对象数组时返回错误
+func (res *JsonResult) GetString(path string) (string, error) {
+	data, err := res.getResult(path)
+	if err != nil {
+		return "", err
+	}
+	if data.IsObject() || data.IsArray() {
+		return "", jsonTypeError(path, data, "string")
+	}
+	return data.String(), nil
+}
+
+// GetInt 获取整数,支持数字字符串,WithExactNumber 时有小数或超出范围返回 ErrPrecision
+func (res *JsonResult) GetInt(path string) (int64, error) {
+	data, err := res.getResult(path)
+	if err != nil {
+		return 0, err
+	}
+	switch data.Type {
+	case gjson.Number:
+		if res.exact {
+			return exactInt(path, data)
+		}
+		return data.Int(), nil
+	case gjson.String:
+		val, pErr := strconv.ParseInt(strings.TrimSpace(data.Str), 10, 64)
+		if pErr != nil {
+			return 0, jsonTypeError(path, data, "int")
+		}
+		return val, nil
+	}
+	return 0, jsonTypeError(path, data, "int")
+}
+
+// GetBool 获取布尔值,支持 "true" "1" 等字符串及数字
+func (res *JsonResult) GetBool(path string) (bool, error) {
+	data, err := res.getResult(path)
+	if err != nil {
+		return false, err
+	}
+	switch data.Type {
+	case gjson.True, gjson.False, gjson.Number:
+		return data.Bool(), nil
+	case gjson.String:
+		val, pErr := strconv.ParseBool(strings.TrimSpace(data.Str))
+		if pErr != nil {
+			return false, jsonTypeError(path, data, "bool")
+		}
+		return val, nil
+	}
+	return false, jsonTypeError(path, data, "bool")
+}
+
+// GetTime 获取时间,数字按UNIX秒解析,字符串按 layout 解析
+// @param layout 不传时按 "2006-01-02 15:04:05" 及 RFC3339 尝试解析
+func (res *JsonResult) GetTime(path string, layout ...string) (time.Time, error) {
+	data, err := res.getResult(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return jsonTime(path, data, layout...)
+}
+
+// jsonTime 数字按UNIX秒解析,字符串按 layout 解析
+func jsonTime(path string, data *gjson.Result, layout ...string) (time.Time, error) {
+	switch data.Type {
+	case gjson.Number:
+		return time.Unix(data.Int(), 0), nil
+	case gjson.String:
+		if len(layout) == 0 {
+			layout = defaultTimeLayouts
+		}
+		for _, tmp := range layout {
+			val, pErr := time.ParseInLocation(tmp, data.Str, time.Local)
+			if pErr == nil {
+				return val, nil
+			}
+		}
+	}
+	return time.Time{}, jsonTypeError(path, data, "time")
+}
+
+// GetArray 获取数组
+func (res *JsonResult) GetArray(path string) ([]*JsonData, error) {
+	data, err := res.getResult(path)
+	if err != nil {
+		return nil, err
+	}
+	if !data.IsArray() {
+		return nil, jsonTypeError(path, data, "array")
+	}
+	items := data.Array()
+	out := make([]*JsonData, len(items))
+	for i := range items {
+		out[i] = NewJsonData(&items[i])
+	}
+	return out, nil
+}
+
+// GetMap 获取对象
+func (res *JsonResult) GetMap(path string) (map[string]*JsonData, error) {
+	data, err := res.getResult(path)
+	if err != nil {
+		return nil, err
+	}
+	if !data.IsObject() {
+		return nil, jsonTypeError(path, data, "map")
+	}
+	out := make(map[string]*JsonData)
+	data.ForEach(func(key, value gjson.Result) bool {
+		tmp := value
+		out[key.String()] = NewJsonData(&tmp)
+		return true
+	})
+	return out, nil
+}
+
+// MustString 同 GetString,错误时panic
+func (res *JsonResult) MustString(path string) string {
+	val, err := res.GetString(path)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+// MustInt 同 GetInt,错误时panic
+func (res *JsonResult) MustInt(path string) int64 {
+	val, err := res.GetInt(path)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+// MustBool 同 GetBool,错误时panic
+func (res *JsonResult) MustBool(path string) bool {
+	val, err := res.GetBool(path)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+// MustTime 同 GetTime,错误时panic
+func (res *JsonResult) MustTime(path string, layout ...string) time.Time {
+	val, err := res.GetTime(path, layout...)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+// MustArray 同 GetArray,错误时panic
+func (res *JsonResult) MustArray(path string) []*JsonData {
+	val, err := res.GetArray(path)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
+
+// MustMap 同 GetMap,错误时panic
+func (res *JsonResult) MustMap(path string) map[string]*JsonData {
+	val, err := res.GetMap(path)
+	if err != nil {
+		panic(err)
+	}
+	return val
+}
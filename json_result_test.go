@@ -85,3 +85,90 @@ func TestNewJsonResultErr(t *testing.T) {
 		t.Error("json parse error wrong")
 	}
 }
+
+func TestJsonResultTypeGet(t *testing.T) {
+	read := NewJsonResult(`{"a":{"s":"str","i":"12","b":"true","t":"2021-01-02 03:04:05","l":[1,2],"m":{"k":"v"}}}`, "a")
+	if !read.Exists("s") || read.Exists("none") {
+		t.Error("json exists error")
+	}
+	if read.MustString("s") != "str" {
+		t.Error("json get string error")
+	}
+	if read.MustInt("i") != 12 {
+		t.Error("json get int error")
+	}
+	if !read.MustBool("b") {
+		t.Error("json get bool error")
+	}
+	if read.MustTime("t").Year() != 2021 {
+		t.Error("json get time error")
+	}
+	if len(read.MustArray("l")) != 2 {
+		t.Error("json get array error")
+	}
+	if read.MustMap("m")["k"].String() != "v" {
+		t.Error("json get map error")
+	}
+	if _, err := read.GetInt("s"); err == nil {
+		t.Error("json get int type error")
+	}
+	if _, err := read.GetString("none"); err == nil {
+		t.Error("json get not exists error")
+	}
+}
+
+func TestJsonResultPathCache(t *testing.T) {
+	res := NewJsonResult(`{"data":{"id":1,"list":[1,2,3]}}`, "data")
+	for i := 0; i < 3; i++ {
+		if res.MustInt("id") != 1 {
+			t.Error("cached path wrong")
+		}
+	}
+	//子路径从已获取的父节点查找
+	if _, ok := res.paths["data"]; !ok || len(res.paths) != 2 || res.MustInt("list.1") != 2 {
+		t.Error("path not cache", len(res.paths))
+	}
+	if res.MustInt("list.#") != 3 || len(res.paths) != 4 {
+		t.Error("path cache wrong", len(res.paths))
+	}
+	root := NewJsonResult(`{"id":1}`, "")
+	root.Exists("")
+	if root.root == nil {
+		t.Error("root not cache")
+	}
+	for i := 0; i < maxJsonPathCache+10; i++ {
+		res.Exists("list." + strconv.Itoa(i))
+	}
+	if len(res.paths) != maxJsonPathCache || !res.Exists("list.2") {
+		t.Error("path cache not limit")
+	}
+}
+
+func TestJsonResultLeadingGarbage(t *testing.T) {
+	for _, body := range []string{")]}'\n{\"data\":{\"id\":1}}", "\ufeff{\"data\":{\"id\":1}}", " {\"data\":{\"id\":1}}"} {
+		res := NewJsonResult(body, "data")
+		if res.MustInt("id") != 1 || !NewJsonResult(body, "").Exists("data.id") {
+			t.Errorf("leading garbage not skip:%q", body)
+		}
+	}
+	if NewJsonResult(`"a{b}"`, "").Exists("b") {
+		t.Error("json string must not skip")
+	}
+	var data struct {
+		Id int `json:"id"`
+	}
+	if err := NewJsonResult(")]}'\n{\"data\":{\"id\":2}}", "data").GetStruct("", &data); err != nil || data.Id != 2 {
+		t.Error("get struct from data node wrong", err, data.Id)
+	}
+	var root struct {
+		Data struct {
+			Id int `json:"id"`
+		} `json:"data"`
+	}
+	if err := NewJsonResult(")]}'\n{\"data\":{\"id\":3}}", "").GetStruct("", &root); err != nil || root.Data.Id != 3 {
+		t.Error("get struct from root must skip leading garbage", err)
+	}
+	if err := NewJsonResult(")]}'\n{\"data\":{\"id\":4}}", "data").Bind("", &data); err != nil || data.Id != 4 {
+		t.Error("bind from data node wrong", err, data.Id)
+	}
+}
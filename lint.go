@@ -0,0 +1,146 @@
+package rest_client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 检查结果级别
+const (
+	LintError   = "error"
+	LintWarning = "warning"
+)
+
+// RestLintFinding 配置检查结果
+type RestLintFinding struct {
+	Level      string //LintError 或 LintWarning
+	Rule       string //规则名称,如 empty-path
+	ConfigName string
+	Key        int //接口KEY,配置级别的问题为-1
+	Message    string
+}
+
+func (find *RestLintFinding) String() string {
+	return fmt.Sprintf("[%s] %s config:%s key:%d %s", find.Level, find.Rule, find.ConfigName, find.Key, find.Message)
+}
+
+// RestLintOption 配置检查选项
+type RestLintOption struct {
+	DialTimeout time.Duration //大于0时检测服务地址是否可连接,适用于开发环境
+}
+
+// HasLintError 检查结果中是否存在错误级别的问题
+func HasLintError(findings []*RestLintFinding) bool {
+	for _, find := range findings {
+		if find.Level == LintError {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint 静态检查接口及配置的常见错误,用于调用方CI
+// 检查项: 配置不存在,服务地址错误,重复的接口定义,空路径,GET请求带内容,未设置超时,地址不可连接
+func (c *RestClientManager) Lint(ctx context.Context, option *RestLintOption, apis ...RestApi) []*RestLintFinding {
+	var findings []*RestLintFinding
+	add := func(level, rule, configName string, key int, msg string) {
+		findings = append(findings, &RestLintFinding{
+			Level:      level,
+			Rule:       rule,
+			ConfigName: configName,
+			Key:        key,
+			Message:    msg,
+		})
+	}
+	usedConfig := map[string]bool{}
+	buildSeen := map[string]int{}
+	for _, api := range apis {
+		configName, err := api.ConfigName(ctx)
+		if err != nil {
+			add(LintError, "config-name", "", -1, err.Error())
+			continue
+		}
+		if _, ok := c.restConfig[configName]; !ok {
+			add(LintError, "config-missing", configName, -1, "rest config not set")
+		}
+		usedConfig[configName] = true
+		builds, err := api.ConfigBuilds(ctx)
+		if err != nil {
+			add(LintError, "config-builds", configName, -1, err.Error())
+			continue
+		}
+		keys := make([]int, 0, len(builds))
+		for key := range builds {
+			keys = append(keys, key)
+		}
+		sort.Ints(keys)
+		for _, key := range keys {
+			build, ok := builds[key].(*AppRestBuild)
+			if !ok {
+				if builds[key] == nil {
+					add(LintError, "nil-build", configName, key, "build is nil")
+				}
+				continue
+			}
+			if len(build.Path) == 0 {
+				add(LintWarning, "empty-path", configName, key, "path is empty")
+			}
+			if build.Raw && !appMethodHasBody(appHttpMethod(build.HttpMethod)) {
+				add(LintWarning, "get-body", configName, key, "raw "+appHttpMethod(build.HttpMethod)+" request will send param as body")
+			}
+			if build.Timeout <= 0 && build.ResponseHeaderTimeout <= 0 && build.TotalTimeout <= 0 {
+				add(LintWarning, "missing-timeout", configName, key, "timeout not set,use transport default")
+			}
+			sign := strings.Join([]string{configName, build.HttpMethod, build.Path, build.Method}, "|")
+			if prev, ok := buildSeen[sign]; ok {
+				add(LintWarning, "duplicate-build", configName, key, fmt.Sprintf("same as key:%d", prev))
+			} else {
+				buildSeen[sign] = key
+			}
+		}
+	}
+	names := make([]string, 0, len(usedConfig))
+	for name := range usedConfig {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		config, ok := c.restConfig[name].(*AppRestConfig)
+		if !ok {
+			continue
+		}
+		if len(config.AppKey) == 0 || len(config.AppSecret) == 0 {
+			add(LintError, "empty-secret", name, -1, "app key or app secret is empty")
+		}
+		if config.Balancer != nil {
+			continue
+		}
+		host, err := url.Parse(config.AppUrl)
+		if err != nil || len(host.Host) == 0 {
+			add(LintError, "invalid-url", name, -1, "app url is invalid:"+config.AppUrl)
+			continue
+		}
+		if option != nil && option.DialTimeout > 0 {
+			addr := host.Host
+			if len(host.Port()) == 0 {
+				if host.Scheme == "https" {
+					addr += ":443"
+				} else {
+					addr += ":80"
+				}
+			}
+			conn, err := net.DialTimeout("tcp", addr, option.DialTimeout)
+			if err != nil {
+				add(LintWarning, "host-unreachable", name, -1, err.Error())
+			} else {
+				_ = conn.Close()
+			}
+		}
+	}
+	return findings
+}
@@ -0,0 +1,35 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestManagerLint(t *testing.T) {
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:   "test",
+		AppKey: "dome1",
+		AppUrl: "http://127.0.0.1:1",
+	})
+	api := &testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodGet, Path: "/a", Method: "a", Timeout: time.Second},
+		2: &AppRestBuild{HttpMethod: http.MethodGet, Path: "/a", Method: "a", Timeout: time.Second},
+		3: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true},
+	}}
+	findings := manager.Lint(context.Background(), &RestLintOption{DialTimeout: time.Second}, api, &testBuildApi{name: "none"})
+	rules := map[string]bool{}
+	for _, find := range findings {
+		rules[find.Rule] = true
+	}
+	for _, rule := range []string{"duplicate-build", "empty-path", "get-body", "missing-timeout", "config-missing", "empty-secret", "host-unreachable"} {
+		if !rules[rule] {
+			t.Error("lint not find rule:" + rule)
+		}
+	}
+	if !HasLintError(findings) {
+		t.Error("lint error level wrong")
+	}
+}
@@ -0,0 +1,111 @@
+package rest_client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestAppParamSignLowAlloc(t *testing.T) {
+	token := "t&k"
+	for _, method := range []string{"", "order.get"} {
+		for _, tk := range []*string{nil, &token} {
+			sign := AppRestParamSign("1.0", "dome1", method, "2023-01-01 00:00:00", `{"a":"b c+&"}`, "secret", tk)
+			if appParamSignLowAlloc("1.0", "dome1", method, "2023-01-01 00:00:00", `{"a":"b c+&"}`, "secret", tk) != sign {
+				t.Error("low alloc sign not match")
+			}
+		}
+	}
+	expect, _ := encodeParam(map[string]string{"a": "<b>"})
+	body, err := encodeParamLowAlloc(map[string]string{"a": "<b>"})
+	if err != nil || body != expect {
+		t.Error("low alloc encode error", body)
+	}
+}
+
+func TestAppRestLowAlloc(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if !AppRestCheckSign(r.Form, "dome111111") || r.Header.Get("X-App") != "test" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":` + r.Form.Get("content") + `}`))
+	}))
+	defer server.Close()
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:      "test",
+		AppKey:    "dome1",
+		AppSecret: "dome111111",
+		AppUrl:    server.URL,
+		Headers:   map[string]string{"X-App": "test"},
+		LowAlloc:  true,
+	})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodPost, Method: "a.b"},
+		2: &AppRestBuild{HttpMethod: http.MethodGet},
+	}})
+	for key := 1; key <= 2; key++ {
+		res := (<-client.Do(WithHeader(context.Background(), "X-Trace", "1"), key, map[string]string{"q": "a b&c"})).JsonResult()
+		if res.Err() != nil || res.MustString("data.q") != "a b&c" {
+			t.Error("low alloc request error", res.Err())
+		}
+	}
+}
+
+// benchmarkAppClient 基准测试使用的服务及客户端
+func benchmarkAppClient(lowAlloc bool) (*RestClient, func()) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = ioutil.ReadAll(r.Body)
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":{}}`))
+	}))
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:      "test",
+		AppKey:    "dome1",
+		AppSecret: "dome111111",
+		AppUrl:    server.URL,
+		Headers:   map[string]string{"X-App": "test", "X-Env": "bench"},
+		LowAlloc:  lowAlloc,
+	})
+	return manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodPost, Method: "bench.call"},
+	}}), server.Close
+}
+
+func BenchmarkAppRestParamSign(b *testing.B) {
+	content := benchmarkJsonBody(10)
+	b.Run("default", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			AppRestParamSign("1.0", "dome1", "bench.call", "2023-01-01 00:00:00", content, "secret", nil)
+		}
+	})
+	b.Run("low_alloc", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			appParamSignLowAlloc("1.0", "dome1", "bench.call", "2023-01-01 00:00:00", content, "secret", nil)
+		}
+	})
+}
+
+func BenchmarkAppRestBuildRequest(b *testing.B) {
+	param := url.Values{"sku": {"1001"}, "name": {"bench"}}
+	for name, lowAlloc := range map[string]bool{"default": false, "low_alloc": true} {
+		b.Run(name, func(b *testing.B) {
+			client, done := benchmarkAppClient(lowAlloc)
+			defer done()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := (<-client.Do(context.Background(), 1, param)).JsonResult().Err(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package rest_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// AppRestMirror 镜像流量配置,按比例将请求异步复制到另一个服务配置,用于验证迁移
+// 镜像请求不影响正常请求的结果,两者返回的差异通过 RestMirrorEvent 回调
+// 重试时只镜像最后一次请求
+type AppRestMirror struct {
+	ConfigName string        //镜像目标的服务配置名
+	Percent    float64       //镜像比例,0-100
+	Wait       time.Duration //等待正常请求读取完成的最长时间,默认30s
+	MaxBody    int64         //记录及对比的最大返回内容长度,任一返回超过时不对比,默认1M
+}
+
+// defaultMirrorMaxBody 默认记录的最大返回内容长度
+const defaultMirrorMaxBody = 1024 * 1024
+
+// RestMirrorDiff 镜像请求结果对比
+type RestMirrorDiff struct {
+	ConfigName       string
+	MirrorConfigName string
+	Key              int
+	Same             bool //返回内容是否一致,JSON内容按结构对比
+	Skipped          bool //返回内容超过 MaxBody 未对比,Primary Mirror 为空
+	PrimaryCode      int
+	MirrorCode       int
+	Primary          []byte
+	Mirror           []byte
+	PrimaryErr       error
+	MirrorErr        error
+}
+
+// RestMirrorEvent 可选事件接口,镜像请求完成后回调,在正常请求的事件上调用
+type RestMirrorEvent interface {
+	MirrorResult(diff *RestMirrorDiff)
+}
+
+type mirroringKey struct{}
+
+// detachContext 保留上下文的值但不继承取消,用于异步请求
+type detachContext struct {
+	context.Context
+}
+
+func (ctx detachContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (ctx detachContext) Done() <-chan struct{}       { return nil }
+func (ctx detachContext) Err() error                  { return nil }
+
+// mirrorBody 记录正常请求的返回内容,读取完成或关闭后通知
+// 关闭可能与读取在不同协程,记录的内容加锁,完成时复制一份给对比的协程
+// 超过 maxBody 后不再记录
+type mirrorBody struct {
+	body    io.ReadCloser
+	maxBody int64
+	lock    sync.Mutex
+	buf     bytes.Buffer
+	over    bool   //超过 maxBody
+	data    []byte //完成时记录的内容,done 关闭后只读
+	err     error
+	once    sync.Once
+	done    chan struct{}
+}
+
+func (body *mirrorBody) finish(err error) {
+	body.once.Do(func() {
+		body.lock.Lock()
+		if !body.over {
+			body.data = append([]byte(nil), body.buf.Bytes()...)
+		}
+		body.lock.Unlock()
+		body.err = err
+		close(body.done)
+	})
+}
+
+func (body *mirrorBody) Read(p []byte) (int, error) {
+	n, err := body.body.Read(p)
+	body.lock.Lock()
+	if !body.over && int64(body.buf.Len()+n) > body.maxBody {
+		body.over = true
+		body.buf = bytes.Buffer{}
+	}
+	if !body.over {
+		body.buf.Write(p[:n])
+	}
+	body.lock.Unlock()
+	if err == io.EOF {
+		body.finish(nil)
+	} else if err != nil {
+		body.finish(err)
+	}
+	return n, err
+}
+
+func (body *mirrorBody) Close() error {
+	err := body.body.Close()
+	body.finish(io.ErrUnexpectedEOF)
+	return err
+}
+
+// shouldMirror 是否镜像本次请求,镜像请求及流式参数不镜像
+func shouldMirror(ctx context.Context, mirror *AppRestMirror, param interface{}) bool {
+	if mirror == nil || mirror.Percent <= 0 {
+		return false
+	}
+	if _, ok := ctx.Value(mirroringKey{}).(bool); ok {
+		return false
+	}
+	if _, ok := param.(io.Reader); ok {
+		return false
+	}
+	return rand.Float64()*100 < mirror.Percent
+}
+
+// mirrorSame JSON按结构对比,非JSON按内容对比
+func mirrorSame(a, b []byte) bool {
+	var aVal, bVal interface{}
+	if json.Unmarshal(a, &aVal) == nil && json.Unmarshal(b, &bVal) == nil {
+		return reflect.DeepEqual(aVal, bVal)
+	}
+	return bytes.Equal(a, b)
+}
+
+// mirrorResult 重试及重新签名后对最终的结果镜像,失败及已读取内容(如条件请求缓存)的结果不镜像
+func (clt *AppRestBuild) mirrorResult(ctx context.Context, client *RestClient, key int, param interface{}, result *RestResult) {
+	if result.err != nil || result.response == nil || result.bodyReadOffset >= 0 {
+		return
+	}
+	tConfig, err := client.GetConfig(ctx)
+	if err != nil {
+		return
+	}
+	config, ok := tConfig.(*AppRestConfig)
+	if !ok || !shouldMirror(ctx, config.Mirror, param) {
+		return
+	}
+	clt.mirror(ctx, client, config, key, param, result.response, result.event)
+}
+
+// mirror 异步镜像请求,替换正常请求的返回内容以便记录
+func (clt *AppRestBuild) mirror(ctx context.Context, client *RestClient, config *AppRestConfig, key int, param interface{}, res *http.Response, event RestEvent) {
+	mirror := config.Mirror
+	maxBody := mirror.MaxBody
+	if maxBody <= 0 {
+		maxBody = defaultMirrorMaxBody
+	}
+	primary := &mirrorBody{body: res.Body, maxBody: maxBody, done: make(chan struct{})}
+	res.Body = primary
+	mirrorCtx := context.WithValue(withoutEvent(detachContext{ctx}), mirroringKey{}, true)
+	mirrorCtx = withConfigName(withoutOverrideUrl(mirrorCtx), mirror.ConfigName)
+	go func() {
+		diff := &RestMirrorDiff{
+			ConfigName:       config.Name,
+			MirrorConfigName: mirror.ConfigName,
+			Key:              key,
+			PrimaryCode:      res.StatusCode,
+		}
+		mirrorRes := clt.BuildRequest(mirrorCtx, client, key, param, nil)
+		if mirrorRes.response != nil {
+			diff.MirrorCode = mirrorRes.response.StatusCode
+		}
+		diff.Mirror, diff.MirrorErr = ioutil.ReadAll(io.LimitReader(mirrorRes, maxBody+1))
+		mirrorOver := int64(len(diff.Mirror)) > maxBody
+		if diff.MirrorErr == nil && mirrorOver {
+			_, diff.MirrorErr = io.Copy(ioutil.Discard, mirrorRes)
+		}
+		_ = mirrorRes.Close()
+		wait := mirror.Wait
+		if wait <= 0 {
+			wait = 30 * time.Second
+		}
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-primary.done:
+			diff.Primary = primary.data
+			diff.PrimaryErr = primary.err
+			diff.Skipped = primary.over || mirrorOver
+		case <-timer.C:
+			diff.PrimaryErr = context.DeadlineExceeded
+		}
+		if diff.Skipped {
+			diff.Primary, diff.Mirror = nil, nil
+		}
+		diff.Same = !diff.Skipped && diff.PrimaryErr == nil && diff.MirrorErr == nil &&
+			diff.PrimaryCode == diff.MirrorCode && mirrorSame(diff.Primary, diff.Mirror)
+		if mEvent, ok := event.(RestMirrorEvent); ok {
+			mEvent.MirrorResult(diff)
+		}
+	}()
+}
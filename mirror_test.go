@@ -0,0 +1,126 @@
+package rest_client
+
+import (
+	"context"
+	"github.com/tidwall/gjson"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testMirrorEvent struct {
+	RestEventNoop
+	diff   chan *RestMirrorDiff
+	starts int32
+}
+
+func (event *testMirrorEvent) RequestStart(_, _ string) {
+	atomic.AddInt32(&event.starts, 1)
+}
+
+func (event *testMirrorEvent) MirrorResult(diff *RestMirrorDiff) {
+	event.diff <- diff
+}
+
+func TestAppRestMirror(t *testing.T) {
+	primary := newTestAppServer(func(_ string, content gjson.Result) string {
+		return `{"id":"` + content.Get("id").String() + `"}`
+	})
+	defer primary.Close()
+	shadow := newTestAppServer(func(_ string, _ gjson.Result) string {
+		return `{"id":"other"}`
+	})
+	defer shadow.Close()
+	event := &testMirrorEvent{diff: make(chan *RestMirrorDiff, 1)}
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:   "test",
+		AppUrl: primary.URL,
+		EventCreate: func(_ context.Context) RestEvent {
+			return event
+		},
+		Mirror: &AppRestMirror{ConfigName: "shadow", Percent: 100},
+	})
+	manager.SetRestConfig(&AppRestConfig{Name: "shadow", AppUrl: shadow.URL})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodPost},
+	}})
+	res := (<-client.Do(context.Background(), 1, map[string]string{"id": "1"})).JsonResult()
+	if res.MustString("data.id") != "1" {
+		t.Fatal("mirror change primary result")
+	}
+	select {
+	case diff := <-event.diff:
+		if diff.Same || gjson.GetBytes(diff.Mirror, "data.id").String() != "other" || gjson.GetBytes(diff.Primary, "data.id").String() != "1" {
+			t.Error("mirror diff error")
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("mirror event not call")
+	}
+
+	callEvent := &testMirrorEvent{diff: make(chan *RestMirrorDiff, 1)}
+	if err := (<-client.Do(WithEvent(context.Background(), callEvent), 1, nil)).JsonResult().Err(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-callEvent.diff:
+		if n := atomic.LoadInt32(&callEvent.starts); n != 1 {
+			t.Error("mirror request must not call the call event", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("mirror event not call")
+	}
+}
+
+func TestAppRestMirrorFinalAttempt(t *testing.T) {
+	var primaryCalls int32
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&primaryCalls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(strings.Repeat("a", 64)))
+	}))
+	defer primary.Close()
+	var mirrorCalls int32
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&mirrorCalls, 1)
+		_, _ = w.Write([]byte(strings.Repeat("a", 64)))
+	}))
+	defer shadow.Close()
+	event := &testMirrorEvent{diff: make(chan *RestMirrorDiff, 2)}
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:   "test",
+		AppUrl: primary.URL,
+		EventCreate: func(_ context.Context) RestEvent {
+			return event
+		},
+		Retry:  &AppRestRetry{Max: 1},
+		Mirror: &AppRestMirror{ConfigName: "shadow", Percent: 100, MaxBody: 32},
+	})
+	manager.SetRestConfig(&AppRestConfig{Name: "shadow", AppUrl: shadow.URL})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true},
+	}})
+	res := <-client.Do(context.Background(), 1, nil)
+	if body, err := ioutil.ReadAll(res); err != nil || len(body) != 64 {
+		t.Fatal("mirror change primary result", err, len(body))
+	}
+	select {
+	case diff := <-event.diff:
+		if !diff.Skipped || diff.Same || diff.Primary != nil || diff.Mirror != nil {
+			t.Error("mirror body over max must skip compare", diff.Skipped)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("mirror event not call")
+	}
+	//只镜像重试后的最终请求
+	if p, m := atomic.LoadInt32(&primaryCalls), atomic.LoadInt32(&mirrorCalls); p != 2 || m != 1 {
+		t.Error("mirror must only copy final attempt", p, m)
+	}
+}
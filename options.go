@@ -0,0 +1,139 @@
+package rest_client
+
+import (
+	"net/http"
+	"time"
+)
+
+// NewAppRestConfig 按选项创建服务配置,HEADER及默认参数已初始化,新增字段时不影响已有调用
+func NewAppRestConfig(name string, opts ...AppRestConfigOption) *AppRestConfig {
+	config := &AppRestConfig{
+		Name:          name,
+		Headers:       map[string]string{},
+		DefaultParams: map[string]interface{}{},
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+	return config
+}
+
+// ConfigWithSigner 设置签名使用的 app 及密钥
+func ConfigWithSigner(appKey, appSecret string) AppRestConfigOption {
+	return func(config *AppRestConfig) {
+		config.AppKey = appKey
+		config.AppSecret = appSecret
+	}
+}
+
+// ConfigWithHeader 设置默认请求HEADER
+func ConfigWithHeader(key, value string) AppRestConfigOption {
+	return func(config *AppRestConfig) {
+		if config.Headers == nil {
+			config.Headers = map[string]string{}
+		}
+		config.Headers[key] = value
+	}
+}
+
+// ConfigWithDefaultParam 设置默认参数
+func ConfigWithDefaultParam(key string, value interface{}) AppRestConfigOption {
+	return func(config *AppRestConfig) {
+		if config.DefaultParams == nil {
+			config.DefaultParams = map[string]interface{}{}
+		}
+		config.DefaultParams[key] = value
+	}
+}
+
+// ConfigWithRetry 设置失败重试
+func ConfigWithRetry(retry *AppRestRetry) AppRestConfigOption {
+	return func(config *AppRestConfig) {
+		config.Retry = retry
+	}
+}
+
+// ConfigWithBalancer 设置多地址负载均衡
+func ConfigWithBalancer(balancer RestBalancer) AppRestConfigOption {
+	return func(config *AppRestConfig) {
+		config.Balancer = balancer
+	}
+}
+
+// AppRestBuildOption 创建接口定义时的设置项
+type AppRestBuildOption func(build *AppRestBuild)
+
+// NewAppRestBuild 按选项创建接口定义,默认为POST请求
+// @param path 接口路径
+// @param method 签名参数中的接口名称
+func NewAppRestBuild(path, method string, opts ...AppRestBuildOption) *AppRestBuild {
+	build := &AppRestBuild{
+		Path:       path,
+		Method:     method,
+		HttpMethod: http.MethodPost,
+	}
+	for _, opt := range opts {
+		opt(build)
+	}
+	return build
+}
+
+// BuildWithHttpMethod 设置请求方式
+func BuildWithHttpMethod(httpMethod string) AppRestBuildOption {
+	return func(build *AppRestBuild) {
+		build.HttpMethod = httpMethod
+	}
+}
+
+// BuildWithTimeout 设置等待返回HEADER超时
+func BuildWithTimeout(timeout time.Duration) AppRestBuildOption {
+	return func(build *AppRestBuild) {
+		build.ResponseHeaderTimeout = timeout
+	}
+}
+
+// BuildWithTotalTimeout 设置整个请求的超时
+func BuildWithTotalTimeout(timeout time.Duration) AppRestBuildOption {
+	return func(build *AppRestBuild) {
+		build.TotalTimeout = timeout
+	}
+}
+
+// BuildWithRaw 不使用签名格式,contentType 为空时为 application/json
+func BuildWithRaw(contentType string) AppRestBuildOption {
+	return func(build *AppRestBuild) {
+		build.Raw = true
+		build.ContentType = contentType
+	}
+}
+
+// BuildWithParamEncoder 设置参数编码
+func BuildWithParamEncoder(encoder ParamEncoder) AppRestBuildOption {
+	return func(build *AppRestBuild) {
+		build.ParamEncoder = encoder
+	}
+}
+
+// BuildWithCodec 设置请求参数及返回内容的编解码
+func BuildWithCodec(codec RestCodec) AppRestBuildOption {
+	return func(build *AppRestBuild) {
+		build.Codec = codec
+	}
+}
+
+// BuildWithParamRule 设置参数的默认值及规范化规则
+func BuildWithParamRule(name string, rule *AppParamRule) AppRestBuildOption {
+	return func(build *AppRestBuild) {
+		if build.ParamRules == nil {
+			build.ParamRules = map[string]*AppParamRule{}
+		}
+		build.ParamRules[name] = rule
+	}
+}
+
+// BuildWithClassifier 设置返回结果分类
+func BuildWithClassifier(classifier RestClassifier) AppRestBuildOption {
+	return func(build *AppRestBuild) {
+		build.Classifier = classifier
+	}
+}
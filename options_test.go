@@ -0,0 +1,46 @@
+package rest_client
+
+import (
+	"context"
+	"github.com/tidwall/gjson"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewAppRestConfig(t *testing.T) {
+	server := newTestAppServer(func(method string, content gjson.Result) string {
+		return `{"method":"` + method + `","channel":"` + content.Get("channel").String() + `"}`
+	})
+	defer server.Close()
+	config := NewAppRestConfig("test",
+		ConfigWithUrl(server.URL),
+		ConfigWithSigner("dome1", "dome111111"),
+		ConfigWithHeader("X-Env", "test"),
+		ConfigWithHeaders(map[string]string{"X-Region": "cn"}),
+		ConfigWithDefaultParam("channel", "app"),
+		ConfigWithRetry(&AppRestRetry{Max: 1}),
+	)
+	if config.AppKey != "dome1" || config.Headers["X-Env"] != "test" || config.Headers["X-Region"] != "cn" || config.Retry.Max != 1 {
+		t.Error("config option error")
+	}
+	build := NewAppRestBuild("/product", "detail",
+		BuildWithTimeout(time.Second),
+		BuildWithTotalTimeout(2*time.Second),
+		BuildWithParamRule("id", &AppParamRule{Default: "1"}),
+	)
+	if build.HttpMethod != http.MethodPost || build.ResponseHeaderTimeout != time.Second || build.TotalTimeout != 2*time.Second || build.ParamRules["id"] == nil {
+		t.Error("build option error")
+	}
+	raw := NewAppRestBuild("/raw", "", BuildWithHttpMethod(http.MethodGet), BuildWithRaw(""))
+	if !raw.Raw || raw.HttpMethod != http.MethodGet {
+		t.Error("build raw option error")
+	}
+
+	manager := NewRestClientManager()
+	manager.SetRestConfig(config)
+	res := (<-manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{1: build}}).Do(context.Background(), 1, nil)).JsonResult()
+	if res.MustString("data.method") != "detail" || res.MustString("data.channel") != "app" {
+		t.Error("option request error")
+	}
+}
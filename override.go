@@ -0,0 +1,130 @@
+package rest_client
+
+import (
+	"context"
+	"time"
+)
+
+// RestOverride 单次请求覆盖的服务配置,未设置的项使用服务配置,用于如临时调用其他区域等个别请求
+type RestOverride struct {
+	AppUrl  string            `json:"app_url,omitempty"` //服务地址,设置后不使用 Balancer 及灰度路由
+	Token   *string           `json:"token,omitempty"`   //TOKEN,替换 RestTokenApi 的返回,接口未实现 RestTokenApi 时也参与签名
+	Timeout time.Duration     `json:"timeout,omitempty"` //整个请求的超时,替换接口的 TotalTimeout
+	Headers map[string]string `json:"headers,omitempty"` //请求HEADER,覆盖服务配置及 WithHeader 设置的同名HEADER
+}
+
+type overrideKey struct{}
+
+// WithOverride 设置单次请求覆盖的服务配置,与ctx中已设置的合并,后设置的优先
+func WithOverride(ctx context.Context, override *RestOverride) context.Context {
+	merged := RestOverride{}
+	if prev := contextOverride(ctx); prev != nil {
+		merged = *prev
+	}
+	if len(override.AppUrl) > 0 {
+		merged.AppUrl = override.AppUrl
+	}
+	if override.Token != nil {
+		merged.Token = override.Token
+	}
+	if override.Timeout > 0 {
+		merged.Timeout = override.Timeout
+	}
+	if len(override.Headers) > 0 {
+		headers := make(map[string]string, len(merged.Headers)+len(override.Headers))
+		for key, val := range merged.Headers {
+			headers[key] = val
+		}
+		for key, val := range override.Headers {
+			headers[key] = val
+		}
+		merged.Headers = headers
+	}
+	return context.WithValue(ctx, overrideKey{}, &merged)
+}
+
+// contextOverride 获取单次请求覆盖的服务配置,未设置时返回nil
+func contextOverride(ctx context.Context) *RestOverride {
+	override, _ := ctx.Value(overrideKey{}).(*RestOverride)
+	return override
+}
+
+// withoutOverrideUrl 去除单次请求指定的地址,用于镜像等发送到其他服务的请求
+func withoutOverrideUrl(ctx context.Context) context.Context {
+	override := contextOverride(ctx)
+	if override == nil || len(override.AppUrl) == 0 {
+		return ctx
+	}
+	copied := *override
+	copied.AppUrl = ""
+	return context.WithValue(ctx, overrideKey{}, &copied)
+}
+
+// DoOption 调用 Do 时的单次请求设置
+type DoOption func(override *RestOverride)
+
+// DoWithUrl 使用指定的服务地址
+func DoWithUrl(url string) DoOption {
+	return func(override *RestOverride) {
+		override.AppUrl = url
+	}
+}
+
+// DoWithToken 使用指定的TOKEN
+func DoWithToken(token string) DoOption {
+	return func(override *RestOverride) {
+		override.Token = &token
+	}
+}
+
+// DoWithTimeout 设置整个请求的超时
+func DoWithTimeout(timeout time.Duration) DoOption {
+	return func(override *RestOverride) {
+		override.Timeout = timeout
+	}
+}
+
+// DoWithHeader 设置请求HEADER
+func DoWithHeader(key, value string) DoOption {
+	return func(override *RestOverride) {
+		if override.Headers == nil {
+			override.Headers = map[string]string{}
+		}
+		override.Headers[key] = value
+	}
+}
+
+// withDoOptions 将 Do 的设置合并到ctx
+func withDoOptions(ctx context.Context, opts []DoOption) context.Context {
+	if len(opts) == 0 {
+		return ctx
+	}
+	override := &RestOverride{}
+	for _, opt := range opts {
+		opt(override)
+	}
+	return WithOverride(ctx, override)
+}
+
+// appToken 获取签名使用的TOKEN,单次请求设置的优先,均未设置时返回nil
+func appToken(ctx context.Context, client *RestClient, config *AppRestConfig) (*string, error) {
+	if override := contextOverride(ctx); override != nil && override.Token != nil {
+		return override.Token, nil
+	}
+	if config.tenantToken != nil {
+		token, err := config.tenantToken(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &token, nil
+	}
+	tokenApi, find := client.Api.(RestTokenApi)
+	if !find {
+		return nil, nil
+	}
+	token, err := tokenApi.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
@@ -0,0 +1,92 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type testTokenBuildApi struct {
+	testBuildApi
+}
+
+func (api *testTokenBuildApi) Token(_ context.Context) (string, error) {
+	return "api-token", nil
+}
+
+func TestRestOverride(t *testing.T) {
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/slow" {
+				time.Sleep(100 * time.Millisecond)
+			}
+			_ = r.ParseForm()
+			_, _ = w.Write([]byte(`{"name":"` + name + `","env":"` + r.Header.Get("X-Env") + `","token":"` + r.Form.Get("token") + `"}`))
+		}))
+	}
+	primary := newServer("primary")
+	defer primary.Close()
+	region := newServer("region")
+	defer region.Close()
+	canary := &AppRestCanary{Url: primary.URL, Percent: 0}
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{Name: "test", AppUrl: primary.URL, Headers: map[string]string{"X-Env": "prod"}, Canary: canary})
+	client := manager.NewApi(&testTokenBuildApi{testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true, Path: "/raw"},
+		2: &AppRestBuild{Method: "sign"},
+		3: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true, Path: "/slow"},
+	}}})
+
+	res := (<-client.Do(context.Background(), 1, nil, DoWithUrl(region.URL), DoWithHeader("X-Env", "test"))).JsonResult()
+	if res.MustString("name") != "region" || res.MustString("env") != "test" {
+		t.Error("do option override error")
+	}
+	if stats := canary.Stats(); stats.Stable.Requests != 0 {
+		t.Error("override url should not report canary stats")
+	}
+	res = (<-client.Do(context.Background(), 1, nil)).JsonResult()
+	if res.MustString("name") != "primary" || res.MustString("env") != "prod" {
+		t.Error("override should only affect single request")
+	}
+
+	ctx := WithOverride(context.Background(), &RestOverride{AppUrl: region.URL})
+	ctx = WithOverride(ctx, &RestOverride{Token: new(string)})
+	if override := contextOverride(ctx); override.AppUrl != region.URL || override.Token == nil {
+		t.Error("override merge error")
+	}
+	res = (<-client.Do(ctx, 1, nil)).JsonResult()
+	if res.MustString("name") != "region" {
+		t.Error("context override url error")
+	}
+
+	_, err := client.DoSync(context.Background(), 3, nil, DoWithTimeout(20*time.Millisecond))
+	if err == nil {
+		t.Error("override timeout not work")
+	}
+	if _, err = client.DoSync(context.Background(), 3, nil); err != nil {
+		t.Error("timeout override should only affect single request", err)
+	}
+
+	var token string
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		token = r.Form.Get("token")
+		form, _ := url.ParseQuery(r.Form.Encode())
+		if !AppRestCheckSign(form, "") {
+			token = "sign error"
+		}
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":{}}`))
+	}))
+	defer tokenServer.Close()
+	<-client.Do(context.Background(), 2, nil, DoWithUrl(tokenServer.URL), DoWithToken("user-token"))
+	if token != "user-token" {
+		t.Error("override token error:" + token)
+	}
+	<-client.Do(context.Background(), 2, nil, DoWithUrl(tokenServer.URL))
+	if token != "api-token" {
+		t.Error("api token error:" + token)
+	}
+}
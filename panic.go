@@ -0,0 +1,54 @@
+package rest_client
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+)
+
+// RestPanicError 请求过程中发生的panic,作为错误码3的原始错误,可通过 errors.As 获取
+type RestPanicError struct {
+	Value      interface{} //recover 返回值
+	Stack      []byte      //panic 时的调用栈
+	ConfigName string
+	Key        int
+	Caller     *RestCallerInfo //发起请求的调用方
+}
+
+func (err *RestPanicError) Error() string {
+	return fmt.Sprintf("panic %v config:%s key:%d", err.Value, err.ConfigName, err.Key)
+}
+
+// RestPanicHandler panic 回调,如上报到 Sentry
+type RestPanicHandler func(ctx context.Context, err *RestPanicError)
+
+// SetPanicHandler 设置请求发生panic时的回调,传nil取消
+func (c *RestClientManager) SetPanicHandler(handler RestPanicHandler) *RestClientManager {
+	c.panicHandler = handler
+	return c
+}
+
+// panicError 转换panic为错误并回调,需在 recover 所在的 defer 中调用以获取完整调用栈
+func (client *RestClient) panicError(ctx context.Context, key int, info interface{}) *RestClientError {
+	pErr := &RestPanicError{
+		Value:  info,
+		Stack:  debug.Stack(),
+		Key:    key,
+		Caller: Caller(ctx),
+	}
+	if name, ok := ctx.Value(configNameKey{}).(string); ok {
+		pErr.ConfigName = name
+	} else {
+		func() {
+			defer func() { _ = recover() }()
+			pErr.ConfigName, _ = client.Api.ConfigName(ctx)
+		}()
+	}
+	if client.manager != nil && client.manager.panicHandler != nil {
+		func() {
+			defer func() { _ = recover() }()
+			client.manager.panicHandler(ctx, pErr)
+		}()
+	}
+	return &RestClientError{Code: ErrPanic, Msg: pErr.Error(), err: pErr}
+}
@@ -0,0 +1,38 @@
+package rest_client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type testPanicBuild struct{}
+
+func (build *testPanicBuild) BuildRequest(_ context.Context, _ *RestClient, _ int, _ interface{}, _ *RestCallerInfo) *RestResult {
+	panic("build fail")
+}
+
+func TestRestPanicHandler(t *testing.T) {
+	var report *RestPanicError
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{Name: "test"})
+	manager.SetPanicHandler(func(_ context.Context, err *RestPanicError) {
+		report = err
+	})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		5: &testPanicBuild{},
+	}})
+	err := (<-client.Do(context.Background(), 5, nil)).Err()
+	var rErr *RestClientError
+	var pErr *RestPanicError
+	if !errors.As(err, &rErr) || rErr.Code != ErrPanic || !errors.As(err, &pErr) {
+		t.Fatal("panic error wrong")
+	}
+	if pErr != report || pErr.ConfigName != "test" || pErr.Key != 5 || pErr.Value != "build fail" {
+		t.Error("panic report wrong")
+	}
+	if !strings.Contains(string(pErr.Stack), "testPanicBuild") {
+		t.Error("panic stack not capture")
+	}
+}
@@ -0,0 +1,83 @@
+package rest_client
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// paramToMap 将参数转为对象,结构体先转为JSON对象
+// 返回false表示非对象参数或原样发送的内容
+func paramToMap(param interface{}) (map[string]interface{}, bool, error) {
+	out := map[string]interface{}{}
+	switch data := param.(type) {
+	case nil:
+		return out, true, nil
+	case []byte, string, io.Reader, json.RawMessage:
+		return nil, false, nil
+	case QueryParams:
+		for key, val := range data {
+			out[key] = val
+		}
+		return out, true, nil
+	case map[string]interface{}:
+		for key, val := range data {
+			out[key] = val
+		}
+		return out, true, nil
+	case map[string]string:
+		for key, val := range data {
+			out[key] = val
+		}
+		return out, true, nil
+	}
+	body, err := json.Marshal(param)
+	if err != nil {
+		return nil, false, err
+	}
+	if !bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+		return nil, false, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err = dec.Decode(&out); err != nil {
+		return nil, false, err
+	}
+	return out, true, nil
+}
+
+// isMapParam 是否为 map 参数,合并默认参数时不需要经JSON转换
+func isMapParam(param interface{}) bool {
+	switch param.(type) {
+	case nil, QueryParams, map[string]interface{}, map[string]string:
+		return true
+	}
+	return false
+}
+
+// typedParam 是否按参数类型编码,设置 ParamEncoder 或 Codec 时结构体参数不能转为 map
+func (clt *AppRestBuild) typedParam(config *AppRestConfig) bool {
+	return clt.ParamEncoder != nil || clt.Codec != nil || config.ParamEncoder != nil
+}
+
+// mergeDefaultParams 合并默认参数,调用参数中已存在的KEY不覆盖
+// 结构体参数先转为JSON对象再合并,非对象参数及原样发送的内容不合并
+// 设置 ParamEncoder 或 Codec 时结构体参数保持原类型不合并,避免转换后数字等类型被编码器改变
+func mergeDefaultParams(defaults map[string]interface{}, param interface{}) (interface{}, error) {
+	if len(defaults) == 0 {
+		return param, nil
+	}
+	data, ok, err := paramToMap(param)
+	if err != nil || !ok {
+		return param, err
+	}
+	for key, val := range defaults {
+		if _, find := data[key]; !find {
+			data[key] = val
+		}
+	}
+	if _, query := param.(QueryParams); query {
+		return QueryParams(data), nil
+	}
+	return data, nil
+}
@@ -0,0 +1,98 @@
+package rest_client
+
+import (
+	"context"
+	"github.com/tidwall/gjson"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMergeDefaultParams(t *testing.T) {
+	defaults := map[string]interface{}{"channel": "app", "locale": "zh"}
+	type Param struct {
+		Id     int    `json:"id"`
+		Locale string `json:"locale"`
+	}
+	data, err := mergeDefaultParams(defaults, &Param{Id: 1, Locale: "en"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := data.(map[string]interface{})
+	if out["channel"] != "app" || out["locale"] != "en" || out["id"] == nil {
+		t.Error("merge struct param error")
+	}
+	data, _ = mergeDefaultParams(defaults, "raw")
+	if data != "raw" {
+		t.Error("merge raw param error")
+	}
+}
+
+func TestAppDefaultParams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		content := gjson.Parse(r.Form.Get("content"))
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":{"channel":"` + content.Get("channel").String() +
+			`","id":"` + content.Get("id").String() + `","source":"` + r.Header.Get("X-Source") + `"}}`))
+	}))
+	defer server.Close()
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:          "test",
+		AppUrl:        server.URL,
+		DefaultParams: map[string]interface{}{"channel": "app"},
+		Headers:       map[string]string{"X-Source": "config"},
+	})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodPost},
+	}})
+	res := (<-client.Do(context.Background(), 1, map[string]string{"id": "1"})).JsonResult("data")
+	if res.MustString("channel") != "app" || res.MustString("id") != "1" || res.MustString("source") != "config" {
+		t.Error("default params or header error")
+	}
+	res = (<-client.Do(WithHeader(context.Background(), "X-Source", "call"), 1, nil)).JsonResult("data")
+	if res.MustString("source") != "call" {
+		t.Error("context header override error")
+	}
+}
+
+type testTypeEncoder struct {
+	param interface{}
+}
+
+func (enc *testTypeEncoder) EncodeParam(param interface{}) (string, error) {
+	enc.param = param
+	return `{}`, nil
+}
+
+func TestAppDefaultParamsTyped(t *testing.T) {
+	server := newTestAppServer(func(method string, content gjson.Result) string {
+		return `{}`
+	})
+	defer server.Close()
+	type Param struct {
+		Id int64 `json:"id"`
+	}
+	encoder := &testTypeEncoder{}
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:          "test",
+		AppUrl:        server.URL,
+		DefaultParams: map[string]interface{}{"channel": "app"},
+	})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodPost, ParamEncoder: encoder},
+	}})
+	if err := (<-client.Do(context.Background(), 1, &Param{Id: 1})).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if param, ok := encoder.param.(*Param); !ok || param.Id != 1 {
+		t.Error("typed param must not convert to map", encoder.param)
+	}
+	if err := (<-client.Do(context.Background(), 1, map[string]interface{}{"id": 1})).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if param, ok := encoder.param.(map[string]interface{}); !ok || param["channel"] != "app" {
+		t.Error("map param must merge defaults", encoder.param)
+	}
+}
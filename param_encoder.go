@@ -0,0 +1,51 @@
+package rest_client
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// ParamEncoder 参数编码,生成请求中 content 的内容
+type ParamEncoder interface {
+	EncodeParam(param interface{}) (string, error)
+}
+
+// ParamEncoderFunc 函数形式的参数编码
+type ParamEncoderFunc func(param interface{}) (string, error)
+
+func (fn ParamEncoderFunc) EncodeParam(param interface{}) (string, error) {
+	return fn(param)
+}
+
+// JsonParamEncoder JSON参数编码,默认编码方式
+// 已序列化的JSON可使用 json.RawMessage 作为参数原样发送
+type JsonParamEncoder struct {
+	EscapeHTML bool   //是否转义 < > & 等字符,json.Marshal 默认转义
+	Indent     string //缩进,为空时不格式化
+}
+
+func (enc *JsonParamEncoder) EncodeParam(param interface{}) (string, error) {
+	buf := bytes.NewBuffer(nil)
+	jsonEnc := json.NewEncoder(buf)
+	jsonEnc.SetEscapeHTML(enc.EscapeHTML)
+	if len(enc.Indent) > 0 {
+		jsonEnc.SetIndent("", enc.Indent)
+	}
+	if err := jsonEnc.Encode(param); err != nil {
+		return "", err
+	}
+	return string(bytes.TrimRight(buf.Bytes(), "\n")), nil
+}
+
+// defaultParamEncoder 默认参数编码,与 json.Marshal 一致
+var defaultParamEncoder ParamEncoder = &JsonParamEncoder{EscapeHTML: true}
+
+// encodeParam 按接口配置,服务配置,默认的顺序选择编码
+func encodeParam(param interface{}, encoders ...ParamEncoder) (string, error) {
+	for _, enc := range encoders {
+		if enc != nil {
+			return enc.EncodeParam(param)
+		}
+	}
+	return defaultParamEncoder.EncodeParam(param)
+}
@@ -0,0 +1,28 @@
+package rest_client
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEncodeParam(t *testing.T) {
+	param := map[string]string{"a": "<b>"}
+	data, err := encodeParam(param, nil, nil)
+	if err != nil || data != `{"a":"\u003cb\u003e"}` {
+		t.Error("default encode error:" + data)
+	}
+	data, _ = encodeParam(param, nil, &JsonParamEncoder{})
+	if data != `{"a":"<b>"}` {
+		t.Error("json encode error:" + data)
+	}
+	data, _ = encodeParam(param, ParamEncoderFunc(func(_ interface{}) (string, error) {
+		return "build", nil
+	}), &JsonParamEncoder{})
+	if data != "build" {
+		t.Error("build encode not first")
+	}
+	data, _ = encodeParam(json.RawMessage(`{"raw": 1}`))
+	if data != `{"raw":1}` {
+		t.Error("raw json encode error:" + data)
+	}
+}
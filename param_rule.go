@@ -0,0 +1,112 @@
+package rest_client
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AppParamRule 参数默认值及规范化规则,在签名前执行
+type AppParamRule struct {
+	Default  interface{} //参数不存在或为nil时的默认值
+	Required bool        //必须存在且不为空
+	Trim     bool        //字符串去除首尾空白
+	Lower    bool        //字符串转小写
+	Upper    bool        //字符串转大写
+	Enum     []string    //允许的值,为空不限制
+}
+
+// AppParamFieldError 参数校验错误
+type AppParamFieldError struct {
+	Field string
+	Rule  string //未通过的规则 required 或 enum
+	Value interface{}
+}
+
+// AppParamError 参数校验错误,包含全部未通过的字段,请求未发送
+type AppParamError struct {
+	Fields []*AppParamFieldError
+}
+
+func (err *AppParamError) Error() string {
+	msg := make([]string, 0, len(err.Fields))
+	for _, field := range err.Fields {
+		msg = append(msg, fmt.Sprintf("field:%s rule:%s value:%v", field.Field, field.Rule, field.Value))
+	}
+	return "param valid fail: " + strings.Join(msg, "; ")
+}
+
+// applyParamRules 按规则处理参数顶层字段,非对象参数不处理
+func applyParamRules(rules map[string]*AppParamRule, param interface{}) (interface{}, error) {
+	if len(rules) == 0 {
+		return param, nil
+	}
+	data, ok, err := paramToMap(param)
+	if err != nil || !ok {
+		return param, err
+	}
+	names := make([]string, 0, len(rules))
+	for name := range rules {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	paramErr := &AppParamError{}
+	for _, name := range names {
+		rule := rules[name]
+		val, find := data[name]
+		if (!find || val == nil) && rule.Default != nil {
+			val = rule.Default
+			find = true
+		}
+		if str, isStr := val.(string); isStr {
+			if rule.Trim {
+				str = strings.TrimSpace(str)
+			}
+			if rule.Lower {
+				str = strings.ToLower(str)
+			}
+			if rule.Upper {
+				str = strings.ToUpper(str)
+			}
+			val = str
+		}
+		if rule.Required && (!find || val == nil || val == "") {
+			paramErr.Fields = append(paramErr.Fields, &AppParamFieldError{Field: name, Rule: "required", Value: val})
+			continue
+		}
+		if find && len(rule.Enum) > 0 {
+			strVal := fmt.Sprint(val)
+			allow := false
+			for _, item := range rule.Enum {
+				if item == strVal {
+					allow = true
+					break
+				}
+			}
+			if !allow {
+				paramErr.Fields = append(paramErr.Fields, &AppParamFieldError{Field: name, Rule: "enum", Value: val})
+				continue
+			}
+		}
+		if find {
+			data[name] = val
+		}
+	}
+	if len(paramErr.Fields) > 0 {
+		return nil, paramErr
+	}
+	if _, query := param.(QueryParams); query {
+		return QueryParams(data), nil
+	}
+	return data, nil
+}
+
+// checkParamRules 只校验不修改参数,用于按类型编码的结构体参数
+// 默认值及规范化不生效,参数无法转为JSON对象时不校验
+func checkParamRules(rules map[string]*AppParamRule, param interface{}) error {
+	_, err := applyParamRules(rules, param)
+	if paramErr, ok := err.(*AppParamError); ok {
+		return paramErr
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package rest_client
+
+import (
+	"context"
+	"errors"
+	"github.com/tidwall/gjson"
+	"net/http"
+	"testing"
+)
+
+func TestApplyParamRules(t *testing.T) {
+	rules := map[string]*AppParamRule{
+		"name":   {Required: true, Trim: true},
+		"type":   {Default: "phone", Lower: true, Enum: []string{"phone", "pad"}},
+		"locale": {Default: "zh"},
+	}
+	data, err := applyParamRules(rules, map[string]interface{}{"name": " a ", "type": "PAD"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := data.(map[string]interface{})
+	if out["name"] != "a" || out["type"] != "pad" || out["locale"] != "zh" {
+		t.Error("param rules apply error")
+	}
+	_, err = applyParamRules(rules, map[string]interface{}{"name": "  ", "type": "pc"})
+	var paramErr *AppParamError
+	if !errors.As(err, &paramErr) || len(paramErr.Fields) != 2 {
+		t.Fatal("param rules error wrong")
+	}
+	if paramErr.Fields[0].Field != "name" || paramErr.Fields[1].Rule != "enum" {
+		t.Error("param rules error fields wrong")
+	}
+}
+
+func TestAppParamRulesTyped(t *testing.T) {
+	server := newTestAppServer(func(method string, content gjson.Result) string {
+		return `{}`
+	})
+	defer server.Close()
+	type Param struct {
+		Type string `json:"type"`
+	}
+	encoder := &testTypeEncoder{}
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodPost, ParamEncoder: encoder, ParamRules: map[string]*AppParamRule{
+			"type": {Required: true, Trim: true, Enum: []string{"a", "b"}},
+		}},
+	})
+	if err := (<-client.Do(context.Background(), 1, &Param{Type: "a"})).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if param, ok := encoder.param.(*Param); !ok || param.Type != "a" {
+		t.Error("typed param must not convert to map", encoder.param)
+	}
+	var paramErr *AppParamError
+	if err := (<-client.Do(context.Background(), 1, &Param{Type: "c"})).Err(); !errors.As(err, &paramErr) {
+		t.Error("typed param must valid", err)
+	}
+}
@@ -0,0 +1,153 @@
+package rest_client
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AppPoll 提交任务并轮询结果,用于返回任务ID需轮询状态的接口
+type AppPoll struct {
+	Client      *RestClient
+	SubmitKey   int           //提交任务接口
+	PollKey     int           //查询任务接口
+	TaskIdPath  string        //提交接口返回任务ID的路径,默认 data.task_id
+	TaskIdParam string        //查询接口任务ID参数名,默认 task_id
+	StatusPath  string        //查询接口返回状态的路径,默认 data.status
+	ResultPath  string        //查询接口返回结果的路径,默认 data.result
+	Success     []string      //完成状态,默认 success
+	Fail        []string      //失败状态,默认 fail failed
+	Interval    time.Duration //首次查询间隔,默认1s
+	MaxInterval time.Duration //最大查询间隔,默认10s
+	Backoff     float64       //每次查询后间隔的倍数,默认1.5
+	MaxErrors   int           //查询连续返回可重试错误(如网络错误,5xx)的最多次数,超过后返回错误,默认3
+}
+
+// NewAppPoll 创建任务轮询
+func NewAppPoll(client *RestClient, submitKey, pollKey int) *AppPoll {
+	return &AppPoll{
+		Client:      client,
+		SubmitKey:   submitKey,
+		PollKey:     pollKey,
+		TaskIdPath:  "data.task_id",
+		TaskIdParam: "task_id",
+		StatusPath:  "data.status",
+		ResultPath:  "data.result",
+		Success:     []string{"success"},
+		Fail:        []string{"fail", "failed"},
+		Interval:    time.Second,
+		MaxInterval: 10 * time.Second,
+		Backoff:     1.5,
+		MaxErrors:   3,
+	}
+}
+
+// withDefaults 未设置的字段使用默认值,直接创建 AppPoll 时避免间隔为0不停查询
+func (poll *AppPoll) withDefaults() *AppPoll {
+	def := NewAppPoll(poll.Client, poll.SubmitKey, poll.PollKey)
+	conf := *poll
+	if len(conf.TaskIdPath) == 0 {
+		conf.TaskIdPath = def.TaskIdPath
+	}
+	if len(conf.TaskIdParam) == 0 {
+		conf.TaskIdParam = def.TaskIdParam
+	}
+	if len(conf.StatusPath) == 0 {
+		conf.StatusPath = def.StatusPath
+	}
+	if len(conf.ResultPath) == 0 {
+		conf.ResultPath = def.ResultPath
+	}
+	if len(conf.Success) == 0 {
+		conf.Success = def.Success
+	}
+	if len(conf.Fail) == 0 {
+		conf.Fail = def.Fail
+	}
+	if conf.Interval <= 0 {
+		conf.Interval = def.Interval
+	}
+	if conf.MaxInterval <= 0 {
+		conf.MaxInterval = def.MaxInterval
+	}
+	if conf.Backoff == 0 {
+		conf.Backoff = def.Backoff
+	}
+	if conf.MaxErrors <= 0 {
+		conf.MaxErrors = def.MaxErrors
+	}
+	return &conf
+}
+
+func pollStatusIn(status string, list []string) bool {
+	for _, item := range list {
+		if item == status {
+			return true
+		}
+	}
+	return false
+}
+
+// pollRetryable 查询错误是否可重试,按错误分类或HTTP状态码(如5xx返回的非JSON内容)
+func pollRetryable(result *RestResult, err error) bool {
+	if ClassifyError(err) == ResultRetryable {
+		return true
+	}
+	return result != nil && result.response != nil && ClassifyHttpCode(result.response.StatusCode) == ResultRetryable
+}
+
+// PollUntil 提交任务并查询到完成,返回结果节点,任务失败或ctx结束时返回错误
+// @param param 提交接口的参数
+func (poll *AppPoll) PollUntil(ctx context.Context, param interface{}) (*JsonResult, error) {
+	poll = poll.withDefaults()
+	submit := (<-poll.Client.Do(ctx, poll.SubmitKey, param)).JsonResult()
+	taskId, err := submit.GetString(poll.TaskIdPath)
+	if err != nil {
+		return nil, err
+	}
+	return poll.Poll(ctx, taskId, nil)
+}
+
+// Poll 查询已提交的任务到完成
+// 查询返回可重试的错误时按间隔继续查询,连续超过 MaxErrors 次或不可重试的错误时返回错误
+// @param param 查询接口附带的参数,可以为nil
+func (poll *AppPoll) Poll(ctx context.Context, taskId string, param map[string]interface{}) (*JsonResult, error) {
+	poll = poll.withDefaults()
+	pollParam := uploadParam(param, map[string]interface{}{
+		poll.TaskIdParam: taskId,
+	})
+	interval := poll.Interval
+	errs := 0
+	for {
+		result := <-poll.Client.Do(ctx, poll.PollKey, pollParam)
+		res := result.JsonResult()
+		status, err := res.GetString(poll.StatusPath)
+		if err != nil {
+			errs++
+			if errs > poll.MaxErrors || ctx.Err() != nil || !pollRetryable(result, err) {
+				return nil, err
+			}
+		} else {
+			errs = 0
+		}
+		if pollStatusIn(status, poll.Success) {
+			return NewJsonResult(res.body, poll.ResultPath), nil
+		}
+		if pollStatusIn(status, poll.Fail) {
+			return nil, NewRestClientError(ErrTaskFail, fmt.Sprintf("task %s fail,status:%s", taskId, status))
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, cancelError(ctx.Err())
+		case <-timer.C:
+		}
+		if poll.Backoff > 1 {
+			interval = time.Duration(float64(interval) * poll.Backoff)
+		}
+		if poll.MaxInterval > 0 && interval > poll.MaxInterval {
+			interval = poll.MaxInterval
+		}
+	}
+}
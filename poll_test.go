@@ -0,0 +1,79 @@
+package rest_client
+
+import (
+	"context"
+	"github.com/tidwall/gjson"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAppPoll(t *testing.T) {
+	var polls int32
+	server := newTestAppServer(func(method string, content gjson.Result) string {
+		switch method {
+		case "submit":
+			return `{"task_id":"t1"}`
+		case "poll":
+			if content.Get("task_id").String() == "t2" {
+				return `{"status":"failed"}`
+			}
+			if atomic.AddInt32(&polls, 1) < 3 {
+				return `{"status":"running"}`
+			}
+			return `{"status":"success","result":{"url":"http://file"}}`
+		}
+		return `{}`
+	})
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{Method: "submit"},
+		2: &AppRestBuild{Method: "poll"},
+	})
+	poll := NewAppPoll(client, 1, 2)
+	poll.Interval = 5 * time.Millisecond
+	res, err := poll.PollUntil(context.Background(), map[string]string{"file": "a"})
+	if err != nil || res.MustString("url") != "http://file" || polls != 3 {
+		t.Error("poll result wrong", err)
+	}
+	if _, err = poll.Poll(context.Background(), "t2", nil); ErrorCode(err) != ErrTaskFail {
+		t.Error("task fail not return error")
+	}
+	atomic.StoreInt32(&polls, -100)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err = poll.Poll(ctx, "t1", nil); ErrorCode(err) != ErrCanceled {
+		t.Error("poll ctx not cancel", err)
+	}
+}
+
+func TestAppPollRetry(t *testing.T) {
+	var polls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&polls, 1)
+		if n == 1 || n > 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		status := "running"
+		if n == 3 {
+			status = "success"
+		}
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":{"status":"` + status + `","result":{"id":1}}}`))
+	}))
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{2: &AppRestBuild{Method: "poll"}})
+	poll := &AppPoll{Client: client, PollKey: 2, Interval: 5 * time.Millisecond}
+	res, err := poll.Poll(context.Background(), "t1", nil)
+	if err != nil || res.MustInt("id") != 1 || atomic.LoadInt32(&polls) != 3 {
+		t.Fatal("poll must retry transient error", err, polls)
+	}
+	if _, err = poll.Poll(context.Background(), "t1", nil); err == nil || atomic.LoadInt32(&polls) != 7 {
+		t.Error("poll must stop after max errors", err, polls)
+	}
+	if def := (&AppPoll{}).withDefaults(); def.Interval != time.Second || def.MaxInterval != 10*time.Second || def.StatusPath != "data.status" {
+		t.Error("poll defaults wrong", def.Interval, def.MaxInterval)
+	}
+}
@@ -0,0 +1,119 @@
+package rest_client
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// QueryArrayStyle 查询参数数组编码方式
+type QueryArrayStyle int
+
+const (
+	QueryArrayRepeat  QueryArrayStyle = iota //重复KEY a=1&a=2
+	QueryArrayComma                          //逗号分隔 a=1,2
+	QueryArrayBracket                        //带括号 a[]=1&a[]=2
+)
+
+// QueryParams 查询参数,支持嵌套对象及数组
+// 嵌套对象编码为 a[b]=1,数组按 QueryArrayStyle 编码,数组中的对象编码为 a[0][b]=1
+type QueryParams map[string]interface{}
+
+// Encode 编码为查询字符串,KEY按字母排序
+func (query QueryParams) Encode(style QueryArrayStyle) string {
+	var out []string
+	queryEncode(&out, "", reflect.ValueOf(map[string]interface{}(query)), style)
+	return strings.Join(out, "&")
+}
+
+func queryValue(val reflect.Value) string {
+	switch val.Kind() {
+	case reflect.String:
+		return val.String()
+	case reflect.Bool:
+		return strconv.FormatBool(val.Bool())
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(val.Float(), 'f', -1, 64)
+	}
+	return fmt.Sprint(val.Interface())
+}
+
+func queryEncode(out *[]string, prefix string, val reflect.Value, style QueryArrayStyle) {
+	for val.Kind() == reflect.Interface || val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return
+		}
+		val = val.Elem()
+	}
+	switch val.Kind() {
+	case reflect.Map:
+		keys := make([]string, 0, val.Len())
+		values := map[string]reflect.Value{}
+		for _, key := range val.MapKeys() {
+			name := fmt.Sprint(key.Interface())
+			keys = append(keys, name)
+			values[name] = val.MapIndex(key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			name := key
+			if len(prefix) > 0 {
+				name = prefix + "[" + key + "]"
+			}
+			queryEncode(out, name, values[key], style)
+		}
+	case reflect.Slice, reflect.Array:
+		if val.Kind() == reflect.Slice && val.Type().Elem().Kind() == reflect.Uint8 {
+			*out = append(*out, url.QueryEscape(prefix)+"="+url.QueryEscape(string(val.Bytes())))
+			return
+		}
+		var scalar []string
+		for i := 0; i < val.Len(); i++ {
+			item := val.Index(i)
+			for item.Kind() == reflect.Interface || item.Kind() == reflect.Ptr {
+				if item.IsNil() {
+					break
+				}
+				item = item.Elem()
+			}
+			switch item.Kind() {
+			case reflect.Map, reflect.Slice, reflect.Array:
+				queryEncode(out, prefix+"["+strconv.Itoa(i)+"]", item, style)
+				continue
+			case reflect.Interface, reflect.Ptr:
+				continue
+			}
+			scalar = append(scalar, queryValue(item))
+		}
+		switch style {
+		case QueryArrayComma:
+			if len(scalar) > 0 {
+				*out = append(*out, url.QueryEscape(prefix)+"="+url.QueryEscape(strings.Join(scalar, ",")))
+			}
+		case QueryArrayBracket:
+			for _, item := range scalar {
+				*out = append(*out, url.QueryEscape(prefix+"[]")+"="+url.QueryEscape(item))
+			}
+		default:
+			for _, item := range scalar {
+				*out = append(*out, url.QueryEscape(prefix)+"="+url.QueryEscape(item))
+			}
+		}
+	default:
+		*out = append(*out, url.QueryEscape(prefix)+"="+url.QueryEscape(queryValue(val)))
+	}
+}
+
+// urlAppendQuery 在URL后追加查询字符串
+func urlAppendQuery(apiUrl string, query string) string {
+	if len(query) == 0 {
+		return apiUrl
+	}
+	if strings.Index(apiUrl, "?") == -1 {
+		return apiUrl + "?" + query
+	}
+	return apiUrl + "&" + query
+}
@@ -0,0 +1,43 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestQueryParamsEncode(t *testing.T) {
+	query := QueryParams{
+		"b": []int{1, 2},
+		"a": map[string]interface{}{"x": "1 2", "y": true},
+		"c": []map[string]string{{"n": "v"}},
+	}
+	repeat, _ := url.QueryUnescape(query.Encode(QueryArrayRepeat))
+	if repeat != "a[x]=1 2&a[y]=true&b=1&b=2&c[0][n]=v" {
+		t.Error("query repeat encode error:" + repeat)
+	}
+	comma, _ := url.QueryUnescape(query.Encode(QueryArrayComma))
+	if comma != "a[x]=1 2&a[y]=true&b=1,2&c[0][n]=v" {
+		t.Error("query comma encode error:" + comma)
+	}
+	bracket, _ := url.QueryUnescape(query.Encode(QueryArrayBracket))
+	if bracket != "a[x]=1 2&a[y]=true&b[]=1&b[]=2&c[0][n]=v" {
+		t.Error("query bracket encode error:" + bracket)
+	}
+}
+
+func TestAppQueryParams(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"query":"` + r.URL.RawQuery + `"}`))
+	}))
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true, QueryArrayStyle: QueryArrayComma},
+	})
+	res := (<-client.Do(context.Background(), 1, QueryParams{"id": []int{1, 2}})).JsonResult()
+	if res.MustString("query") != "id=1%2C2" {
+		t.Error("query params send error:" + res.MustString("query"))
+	}
+}
@@ -0,0 +1,87 @@
+package rest_client
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RestRateLimiter 令牌桶限流
+type RestRateLimiter struct {
+	rate   float64 //每秒产生令牌数
+	burst  float64 //桶容量
+	lock   sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewRestRateLimiter 创建限流
+// @param rate 每秒请求数
+// @param burst 允许突发的请求数,小于1时为1
+func NewRestRateLimiter(rate float64, burst int) *RestRateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RestRateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// reserve 预占一个令牌,返回需要等待的时间
+func (limit *RestRateLimiter) reserve() time.Duration {
+	limit.lock.Lock()
+	defer limit.lock.Unlock()
+	now := time.Now()
+	limit.tokens += now.Sub(limit.last).Seconds() * limit.rate
+	if limit.tokens > limit.burst {
+		limit.tokens = limit.burst
+	}
+	limit.last = now
+	limit.tokens--
+	if limit.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-limit.tokens / limit.rate * float64(time.Second))
+}
+
+// allow 有可用令牌时取走一个,不等待
+func (limit *RestRateLimiter) allow() bool {
+	limit.lock.Lock()
+	defer limit.lock.Unlock()
+	now := time.Now()
+	limit.tokens += now.Sub(limit.last).Seconds() * limit.rate
+	if limit.tokens > limit.burst {
+		limit.tokens = limit.burst
+	}
+	limit.last = now
+	if limit.tokens < 1 {
+		return false
+	}
+	limit.tokens--
+	return true
+}
+
+// Wait 等待获取令牌,ctx结束时返回错误
+func (limit *RestRateLimiter) Wait(ctx context.Context) error {
+	if limit.rate <= 0 {
+		return nil
+	}
+	wait := limit.reserve()
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		limit.lock.Lock()
+		limit.tokens++
+		limit.lock.Unlock()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
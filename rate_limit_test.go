@@ -0,0 +1,35 @@
+package rest_client
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRestRateLimiter(t *testing.T) {
+	limit := NewRestRateLimiter(20, 1)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limit.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if time.Since(start) < 90*time.Millisecond {
+		t.Error("rate limit not wait")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	limit.Wait(ctx)
+	if err := limit.Wait(ctx); err == nil {
+		t.Error("rate limit not return ctx error")
+	}
+}
+
+func TestBackgroundContext(t *testing.T) {
+	if IsBackground(context.Background()) {
+		t.Error("background mark error")
+	}
+	if !IsBackground(WithBackground(context.Background())) {
+		t.Error("background mark error")
+	}
+}
@@ -0,0 +1,150 @@
+package rest_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RestCapture 记录的请求,可由 AppRestEvent 的日志回调或HAR文件生成,用于重放
+type RestCapture struct {
+	Method string
+	Url    string
+	Header http.Header //请求HEADER,AppRestEvent 未记录时为nil
+	Body   []byte
+}
+
+// NewRestCapture 按 AppRestEvent 日志回调中的请求信息创建
+func NewRestCapture(method, url string, request []byte) *RestCapture {
+	return &RestCapture{Method: method, Url: url, Body: request}
+}
+
+// NewRestCapturesFromHar 从浏览器或抓包工具导出的HAR内容读取请求
+func NewRestCapturesFromHar(reader io.Reader) ([]*RestCapture, error) {
+	var har struct {
+		Log struct {
+			Entries []struct {
+				Request struct {
+					Method  string `json:"method"`
+					Url     string `json:"url"`
+					Headers []struct {
+						Name  string `json:"name"`
+						Value string `json:"value"`
+					} `json:"headers"`
+					PostData *struct {
+						Text string `json:"text"`
+					} `json:"postData"`
+				} `json:"request"`
+			} `json:"entries"`
+		} `json:"log"`
+	}
+	if err := json.NewDecoder(reader).Decode(&har); err != nil {
+		return nil, err
+	}
+	out := make([]*RestCapture, 0, len(har.Log.Entries))
+	for _, entry := range har.Log.Entries {
+		capture := &RestCapture{Method: entry.Request.Method, Url: entry.Request.Url, Header: http.Header{}}
+		for _, header := range entry.Request.Headers {
+			//HTTP/2 伪HEADER及由客户端生成的HEADER不重放
+			if strings.HasPrefix(header.Name, ":") || strings.EqualFold(header.Name, "Content-Length") || strings.EqualFold(header.Name, "Host") {
+				continue
+			}
+			capture.Header.Add(header.Name, header.Value)
+		}
+		if entry.Request.PostData != nil {
+			capture.Body = []byte(entry.Request.PostData.Text)
+		}
+		out = append(out, capture)
+	}
+	return out, nil
+}
+
+// RestReplayer 重放记录的请求到指定环境,用于排查如生产正常测试环境签名失败等问题
+type RestReplayer struct {
+	Target    string            //目标环境地址,如 http://staging.example.com,替换原请求的协议及域名,为空时使用原地址
+	AppKey    string            //重新签名时替换的 app,为空时使用原值
+	AppSecret string            //重新签名使用的密钥,为空时不重新签名
+	Build     RestBuild         //检测返回内容,如 &AppRestBuild{},为nil时不检测
+	Transport http.RoundTripper //为nil时使用 http.DefaultTransport
+}
+
+// Replay 重放一个请求,设置了 AppSecret 时使用当前时间重新签名
+func (replay *RestReplayer) Replay(ctx context.Context, capture *RestCapture) (*RestResult, error) {
+	target, err := url.Parse(capture.Url)
+	if err != nil {
+		return nil, err
+	}
+	if len(replay.Target) > 0 {
+		base, err := url.Parse(replay.Target)
+		if err != nil {
+			return nil, err
+		}
+		target.Scheme, target.Host = base.Scheme, base.Host
+		target.Path = strings.TrimRight(base.Path, "/") + target.Path
+	}
+	method := appHttpMethod(capture.Method)
+	body := capture.Body
+	if len(replay.AppSecret) > 0 {
+		if appMethodHasBody(method) {
+			body = replay.resign(body)
+		} else {
+			target.RawQuery = string(replay.resign([]byte(target.RawQuery)))
+		}
+	}
+	var reader io.Reader
+	if len(body) > 0 {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	for key, val := range capture.Header {
+		req.Header[key] = val
+	}
+	if len(body) > 0 && len(req.Header.Get("Content-Type")) == 0 {
+		req.Header.Set("Content-Type", "application/json")
+		if isSignedForm(body) {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	transport := replay.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	res, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	return NewRestResult(replay.Build, res, NewRestEventNoop()), nil
+}
+
+// isSignedForm 是否为签名格式的表单参数
+func isSignedForm(body []byte) bool {
+	form, err := url.ParseQuery(string(body))
+	return err == nil && len(form.Get("sign")) > 0
+}
+
+// resign 签名格式的参数使用当前时间重新签名,非签名格式原样返回
+func (replay *RestReplayer) resign(body []byte) []byte {
+	if !isSignedForm(body) {
+		return body
+	}
+	form, _ := url.ParseQuery(string(body))
+	if len(replay.AppKey) > 0 {
+		form.Set("app", replay.AppKey)
+	}
+	form.Set("timestamp", time.Now().Format("2006-01-02 15:04:05"))
+	var token *string
+	if _, ok := form["token"]; ok {
+		tmp := form.Get("token")
+		token = &tmp
+	}
+	form.Set("sign", AppRestParamSign(form.Get("version"), form.Get("app"), form.Get("method"), form.Get("timestamp"), form.Get("content"), replay.AppSecret, token))
+	return []byte(form.Encode())
+}
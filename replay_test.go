@@ -0,0 +1,67 @@
+package rest_client
+
+import (
+	"context"
+	"github.com/tidwall/gjson"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRestReplayer(t *testing.T) {
+	var captured *RestCapture
+	prod := newTestAppServer(func(_ string, content gjson.Result) string {
+		return content.Raw
+	})
+	defer prod.Close()
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:      "test",
+		AppKey:    "dome1",
+		AppSecret: "prod",
+		AppUrl:    prod.URL,
+		EventCreate: func(_ context.Context) RestEvent {
+			return NewAppRestEvent(func(method string, url string, _ int, _ map[string][]string, request []byte, _ []byte, _ error) {
+				captured = NewRestCapture(method, url, request)
+			})
+		},
+	})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodPost, Path: "/api/a", Method: "a.b"},
+	}})
+	if err := (<-client.Do(context.Background(), 1, map[string]int{"a": 1})).JsonResult().Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	staging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.URL.Path != "/api/a" || !AppRestCheckSign(r.Form, "staging") || r.Form.Get("app") != "dome2" {
+			_, _ = w.Write([]byte(`{"result":{"code":"403","state":"fail","message":"sign error"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":` + r.Form.Get("content") + `}`))
+	}))
+	defer staging.Close()
+	replay := &RestReplayer{Target: staging.URL, Build: &AppRestBuild{}}
+	res, err := replay.Replay(context.Background(), captured)
+	if err != nil || res.JsonResult().Err() == nil {
+		t.Error("replay without resign should fail")
+	}
+	replay.AppKey, replay.AppSecret = "dome2", "staging"
+	res, err = replay.Replay(context.Background(), captured)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := res.JsonResult(); data.Err() != nil || data.MustInt("data.a") != 1 {
+		t.Error("replay with resign error", data.Err())
+	}
+}
+
+func TestNewRestCapturesFromHar(t *testing.T) {
+	captures, err := NewRestCapturesFromHar(strings.NewReader(`{"log":{"entries":[{"request":{"method":"POST","url":"http://a.com/x",
+"headers":[{"name":":authority","value":"a.com"},{"name":"X-A","value":"1"},{"name":"Content-Length","value":"3"}],"postData":{"text":"a=1"}}}]}}`))
+	if err != nil || len(captures) != 1 || string(captures[0].Body) != "a=1" || captures[0].Header.Get("X-A") != "1" || len(captures[0].Header) != 1 {
+		t.Error("har capture error", err)
+	}
+}
@@ -0,0 +1,141 @@
+package rest_client
+
+import (
+	"bytes"
+	"context"
+	"github.com/tidwall/gjson"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// AppRestResign 网关因 timestamp 过期或 nonce 已使用拒绝请求时,重新生成时间并签名后重试一次
+// 按 result.state(子错误码) 及 result.code 检测,仅对签名格式的接口有效,参数为 io.Reader 时不重试
+// 仅检测 Content-Type 为JSON,text/plain 或未设置的返回,设置 Detect 时检测全部返回
+type AppRestResign struct {
+	States []string //需要重新签名的子错误码,如 timestamp expired 及 nonce used 对应的 result.state
+	Codes  []string //需要重新签名的 result.code
+	//自定义检测,设置后忽略 States 及 Codes,body 为返回内容的前 MaxBody 字节
+	Detect  func(httpCode int, body []byte) bool
+	MaxBody int64 //检测读取的最大内容长度,默认4096,之后读取返回内容不受影响
+	resigns int64
+}
+
+// NewAppRestResign 创建按子错误码重新签名的配置
+func NewAppRestResign(states ...string) *AppRestResign {
+	return &AppRestResign{States: states}
+}
+
+// Resigns 已重新签名重试的次数
+func (resign *AppRestResign) Resigns() int64 {
+	return atomic.LoadInt64(&resign.resigns)
+}
+
+type resignKey struct{}
+
+// withResigned 标记为重新签名的请求,签名时不使用签名缓存
+func withResigned(ctx context.Context) context.Context {
+	return context.WithValue(ctx, resignKey{}, true)
+}
+
+// isResigned 是否为重新签名的请求
+func isResigned(ctx context.Context) bool {
+	resigned, _ := ctx.Value(resignKey{}).(bool)
+	return resigned
+}
+
+// detect 是否为需要重新签名的错误
+func (resign *AppRestResign) detect(httpCode int, body []byte) bool {
+	if resign.Detect != nil {
+		return resign.Detect(httpCode, body)
+	}
+	if len(resign.States) > 0 {
+		state := gjson.GetBytes(body, "result.state").String()
+		for _, val := range resign.States {
+			if val == state {
+				return true
+			}
+		}
+	}
+	if len(resign.Codes) > 0 {
+		code := gjson.GetBytes(body, "result.code").String()
+		for _, val := range resign.Codes {
+			if val == code {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// mayCarry 返回是否可能是拒绝的返回格式,下载文件或流式返回等不读取内容,避免延迟首字节
+// 设置 Detect 时由调用方判断,均需读取
+func (resign *AppRestResign) mayCarry(res *http.Response) bool {
+	if resign.Detect != nil {
+		return true
+	}
+	if res.StatusCode == http.StatusNoContent || res.StatusCode == http.StatusNotModified {
+		return false
+	}
+	mediaType, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	switch {
+	case len(mediaType) == 0, mediaType == "text/plain":
+		return true
+	case strings.Contains(mediaType, "ndjson"):
+		return false
+	}
+	return strings.HasSuffix(mediaType, "/json") || strings.HasSuffix(mediaType, "+json")
+}
+
+// restPeekBody 已读取部分内容后拼接剩余内容的返回BODY
+type restPeekBody struct {
+	io.Reader
+	io.Closer
+}
+
+// peekResponseBody 读取返回内容的前 n 字节,不触发事件,之后读取时仍从头读取
+func peekResponseBody(result *RestResult, n int64) []byte {
+	body := result.response.Body
+	prefix, _ := ioutil.ReadAll(io.LimitReader(body, n))
+	result.response.Body = &restPeekBody{Reader: io.MultiReader(bytes.NewReader(prefix), body), Closer: body}
+	return prefix
+}
+
+// withResign 按服务配置检测签名时间错误,重新签名后重试一次
+func (clt *AppRestBuild) withResign(ctx context.Context, client *RestClient, param interface{}, call func(ctx context.Context) *RestResult) *RestResult {
+	result := call(ctx)
+	if clt.Raw || result.err != nil || result.response == nil || result.response.Body == nil {
+		return result
+	}
+	if _, ok := param.(io.Reader); ok {
+		return result
+	}
+	tConfig, err := client.GetConfig(ctx)
+	if err != nil {
+		return result
+	}
+	config, ok := tConfig.(*AppRestConfig)
+	if !ok || config.Resign == nil || !config.Resign.mayCarry(result.response) {
+		return result
+	}
+	maxBody := config.Resign.MaxBody
+	if maxBody <= 0 {
+		maxBody = 4096
+	}
+	received := time.Now()
+	body := peekResponseBody(result, maxBody)
+	if !config.Resign.detect(result.response.StatusCode, body) || ctx.Err() != nil {
+		return result
+	}
+	//时钟偏差导致的错误,先修正再签名
+	if config.ClockSkew != nil {
+		config.ClockSkew.observeResponse(result.response.Header.Get("Date"), body, received)
+	}
+	atomic.AddInt64(&config.Resign.resigns, 1)
+	_ = result.Close()
+	return call(withResigned(ctx))
+}
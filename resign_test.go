@@ -0,0 +1,98 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestAppRestResign(t *testing.T) {
+	var calls int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		n := atomic.AddInt64(&calls, 1)
+		switch r.Form.Get("method") {
+		case "expired":
+			//第一次请求签名时间过期
+			if n == 1 {
+				_, _ = w.Write([]byte(`{"result":{"code":"401","state":"timestamp_expired","message":"expired"}}`))
+				return
+			}
+		case "fail":
+			_, _ = w.Write([]byte(`{"result":{"code":"500","state":"fail","message":"fail"}}`))
+			return
+		case "html":
+			//非JSON返回不检测
+			w.Header().Set("Content-Type", "text/html")
+			_, _ = w.Write([]byte(`{"result":{"code":"401","state":"timestamp_expired","message":"expired"}}`))
+			return
+		case "always":
+			_, _ = w.Write([]byte(`{"result":{"code":"401","state":"nonce_used","message":"used"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":{"body":"` + strings.Repeat("a", 8192) + `"}}`))
+	}))
+	defer server.Close()
+	resign := NewAppRestResign("timestamp_expired", "nonce_used")
+	config := &AppRestConfig{
+		Name:      "test",
+		AppKey:    "dome1",
+		AppSecret: "dome111111",
+		AppUrl:    server.URL,
+		Resign:    resign,
+		ClockSkew: &AppRestClockSkew{},
+		SignCache: NewAppRestSignCache(0),
+	}
+	manager := NewRestClientManager()
+	manager.SetRestConfig(config)
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{Method: "expired"},
+		2: &AppRestBuild{Method: "fail"},
+		3: &AppRestBuild{Method: "always"},
+		4: &AppRestBuild{Method: "ok"},
+		5: &AppRestBuild{Method: "html"},
+	}})
+	call := func(key int) (*JsonResult, int64) {
+		atomic.StoreInt64(&calls, 0)
+		res := (<-client.Do(context.Background(), key, map[string]string{"a": "b"})).JsonResult()
+		return res, atomic.LoadInt64(&calls)
+	}
+	if res, n := call(1); res.Err() != nil || n != 2 {
+		t.Error("resign retry error", res.Err(), n)
+	}
+	if res, n := call(2); res.Err() == nil || n != 1 {
+		t.Error("other error should not resign", n)
+	}
+	//只重试一次
+	if res, n := call(3); res.Err() == nil || n != 2 {
+		t.Error("resign should retry once", n)
+	}
+	//被拒绝的签名不再缓存
+	config.SignCache.lock.Lock()
+	_, cached := config.SignCache.data[appSignCacheKey(config, "1.0", "always", `{"a":"b"}`, nil)]
+	config.SignCache.lock.Unlock()
+	if cached {
+		t.Error("resign should bypass sign cache")
+	}
+	//检测后返回内容完整
+	if res, n := call(4); res.Err() != nil || len(res.MustString("data.body")) != 8192 || n != 1 {
+		t.Error("resign peek body error", res.Err())
+	}
+	if _, n := call(5); n != 1 {
+		t.Error("non json response should not resign", n)
+	}
+	if resign.Resigns() != 2 {
+		t.Error("resign count error", resign.Resigns())
+	}
+
+	detect := &AppRestResign{Detect: func(httpCode int, body []byte) bool {
+		return httpCode == http.StatusOK && strings.Contains(string(body), "expired")
+	}}
+	if !detect.detect(http.StatusOK, []byte(`{"result":{"code":"401","state":"timestamp_expired"}}`)) ||
+		(&AppRestResign{Codes: []string{"401"}}).detect(http.StatusOK, []byte(`{"result":{"code":"500"}}`)) {
+		t.Error("resign detect error")
+	}
+}
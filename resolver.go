@@ -0,0 +1,136 @@
+package rest_client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// RestResolver 域名解析,*net.Resolver 可直接使用
+// 可实现静态HOSTS,DNS-over-HTTPS,consul DNS等
+type RestResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// RestResolverFunc 函数形式的解析
+type RestResolverFunc func(ctx context.Context, host string) ([]string, error)
+
+func (fn RestResolverFunc) LookupHost(ctx context.Context, host string) ([]string, error) {
+	return fn(ctx, host)
+}
+
+// RestStaticResolver 静态HOSTS解析,未配置的域名使用 Fallback 解析
+type RestStaticResolver struct {
+	Hosts    map[string][]string //域名 => IP列表
+	Fallback RestResolver        //为nil时使用 net.DefaultResolver
+}
+
+func (res *RestStaticResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if addrs, ok := res.Hosts[host]; ok && len(addrs) > 0 {
+		return addrs, nil
+	}
+	if res.Fallback != nil {
+		return res.Fallback.LookupHost(ctx, host)
+	}
+	return net.DefaultResolver.LookupHost(ctx, host)
+}
+
+type restDnsEntry struct {
+	addrs  []string
+	expire time.Time
+}
+
+// RestDnsCache 带TTL的进程内DNS缓存
+// 解析失败时在 StaleTTL 内继续使用过期的结果
+type RestDnsCache struct {
+	Resolver RestResolver  //为nil时使用 net.DefaultResolver
+	TTL      time.Duration //缓存时间,默认60s
+	StaleTTL time.Duration //解析失败时过期结果可继续使用的时间,0不使用
+	lock     sync.RWMutex
+	data     map[string]*restDnsEntry
+}
+
+// NewRestDnsCache 创建DNS缓存
+func NewRestDnsCache(resolver RestResolver, ttl time.Duration) *RestDnsCache {
+	return &RestDnsCache{
+		Resolver: resolver,
+		TTL:      ttl,
+	}
+}
+
+func (cache *RestDnsCache) LookupHost(ctx context.Context, host string) ([]string, error) {
+	now := time.Now()
+	cache.lock.RLock()
+	entry, ok := cache.data[host]
+	cache.lock.RUnlock()
+	if ok && now.Before(entry.expire) {
+		return entry.addrs, nil
+	}
+	var resolver RestResolver = net.DefaultResolver
+	if cache.Resolver != nil {
+		resolver = cache.Resolver
+	}
+	addrs, err := resolver.LookupHost(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		if ok && now.Before(entry.expire.Add(cache.StaleTTL)) {
+			return entry.addrs, nil
+		}
+		if err == nil {
+			err = &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return nil, err
+	}
+	ttl := cache.TTL
+	if ttl <= 0 {
+		ttl = 60 * time.Second
+	}
+	cache.lock.Lock()
+	if cache.data == nil {
+		cache.data = map[string]*restDnsEntry{}
+	}
+	cache.data[host] = &restDnsEntry{addrs: addrs, expire: now.Add(ttl)}
+	cache.lock.Unlock()
+	return addrs, nil
+}
+
+// Remove 移除缓存,不传域名时清空
+func (cache *RestDnsCache) Remove(hosts ...string) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	if len(hosts) == 0 {
+		cache.data = nil
+		return
+	}
+	for _, host := range hosts {
+		delete(cache.data, host)
+	}
+}
+
+// resolveDialContext 使用指定解析建立连接,按解析结果顺序尝试
+func resolveDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error), resolver RestResolver) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil || net.ParseIP(host) != nil {
+			return dial(ctx, network, addr)
+		}
+		ips, err := resolver.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			var conn net.Conn
+			conn, err = dial(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		return nil, err
+	}
+}
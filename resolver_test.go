@@ -0,0 +1,65 @@
+package rest_client
+
+import (
+	"context"
+	"errors"
+	"github.com/tidwall/gjson"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRestDnsCache(t *testing.T) {
+	var calls int
+	var fail bool
+	cache := NewRestDnsCache(RestResolverFunc(func(ctx context.Context, host string) ([]string, error) {
+		calls++
+		if fail {
+			return nil, errors.New("dns fail")
+		}
+		return []string{"127.0.0.1"}, nil
+	}), time.Millisecond*50)
+	cache.StaleTTL = time.Second
+	for i := 0; i < 3; i++ {
+		if addrs, err := cache.LookupHost(context.Background(), "api.test"); err != nil || addrs[0] != "127.0.0.1" {
+			t.Error("lookup error")
+		}
+	}
+	if calls != 1 {
+		t.Errorf("cache not use:%d", calls)
+	}
+	time.Sleep(time.Millisecond * 60)
+	fail = true
+	if _, err := cache.LookupHost(context.Background(), "api.test"); err != nil || calls != 2 {
+		t.Error("stale result not use")
+	}
+	cache.Remove()
+	if _, err := cache.LookupHost(context.Background(), "api.test"); err == nil {
+		t.Error("lookup error not return")
+	}
+}
+
+func TestRestStaticResolver(t *testing.T) {
+	server := newTestAppServer(func(_ string, _ gjson.Result) string {
+		return `{}`
+	})
+	defer server.Close()
+	port := server.URL[strings.LastIndex(server.URL, ":"):]
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:   "test",
+		AppUrl: "http://api.internal.test" + port,
+		Transport: &RestTransportConfig{
+			Resolver: &RestStaticResolver{Hosts: map[string][]string{
+				"api.internal.test": {"127.0.0.1"},
+			}},
+		},
+	})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodPost},
+	}})
+	if err := (<-client.Do(context.Background(), 1, nil)).JsonResult().Err(); err != nil {
+		t.Error(err)
+	}
+}
@@ -1,23 +1,31 @@
 package rest_client
 
 import (
+	"bytes"
 	"context"
-	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
+	"time"
 )
 
 // RestClientError  错误信息
 type RestClientError struct {
 	Msg  string
 	Code string
+	err  error //原始错误
+	//发起请求的调用方,通过 ErrorCaller 获取
+	caller *RestCallerInfo
 }
 
 func (err *RestClientError) Error() string {
 	return err.Msg
 }
 
+// Unwrap 返回原始错误,可通过 errors.Is 判断如 context.Canceled
+func (err *RestClientError) Unwrap() error {
+	return err.err
+}
+
 // NewRestClientError  错误创建
 func NewRestClientError(code string, msg string) *RestClientError {
 	return &RestClientError{
@@ -52,24 +60,58 @@ func NewRestEventNoop() *RestEventNoop {
 
 //RestRequestReader 对请求io.Reader封装,用于读取内容时事件回调
 type RestRequestReader struct {
-	reader io.Reader
-	event  RestEvent
+	reader   io.Reader
+	event    RestEvent
+	progress RestUploadEvent
+	interval time.Duration //进度回调间隔
+	total    int64
+	sent     int64
+	start    time.Time
+	last     time.Time
+}
+
+//RestUploadEvent 请求内容发送进度事件,RestEvent 实现该接口时按间隔回调
+type RestUploadEvent interface {
+	UploadProgress(sent, total int64, rate float64) //total 为-1时未知,rate 为平均每秒发送字节数
 }
 
 func NewRestRequestReader(reader io.Reader, event RestEvent) *RestRequestReader {
-	return &RestRequestReader{
-		reader: reader,
-		event:  event,
+	read := &RestRequestReader{
+		reader:   reader,
+		event:    event,
+		interval: 200 * time.Millisecond,
+		total:    -1,
 	}
+	read.progress, _ = event.(RestUploadEvent)
+	if size, ok := reader.(interface{ Len() int }); ok {
+		read.total = int64(size.Len())
+	}
+	return read
 }
 func (read *RestRequestReader) Read(p []byte) (int, error) {
 	if read.reader == nil {
-		return 0, NewRestClientError("10", "request reader is empty")
+		return 0, NewRestClientError(ErrReaderEmpty, "request reader is empty")
+	}
+	if read.progress != nil && read.start.IsZero() {
+		read.start = time.Now()
+		read.last = read.start
 	}
 	n, err := read.reader.Read(p)
 	if read.event != nil && n > 0 {
 		read.event.RequestRead(p[0:n])
 	}
+	if read.progress != nil {
+		read.sent += int64(n)
+		now := time.Now()
+		if err == io.EOF || now.Sub(read.last) >= read.interval {
+			read.last = now
+			rate := float64(read.sent)
+			if elapsed := now.Sub(read.start).Seconds(); elapsed > 0 {
+				rate = float64(read.sent) / elapsed
+			}
+			read.progress.UploadProgress(read.sent, read.total, rate)
+		}
+	}
 	return n, err
 }
 
@@ -104,6 +146,8 @@ type RestClient struct {
 	Api       RestApi
 	config    map[string]RestConfig
 	transport *http.Transport
+	manager   *RestClientManager
+	builds    *restBuildsCache //通过 RegisterApi 创建时缓存接口定义
 }
 
 //GetTransport 公共的Transport
@@ -111,46 +155,94 @@ func (client *RestClient) GetTransport() *http.Transport {
 	return client.transport
 }
 
+//GetRoundTripper 请求使用的RoundTripper,在公共Transport上附加管理器设置的处理
+func (client *RestClient) GetRoundTripper() http.RoundTripper {
+	return client.wrapRoundTripper(client.transport)
+}
+
+//wrapRoundTripper 附加管理器设置的处理
+func (client *RestClient) wrapRoundTripper(rt http.RoundTripper) http.RoundTripper {
+	if client.manager != nil && client.manager.fault != nil {
+		rt = &restFaultTransport{fault: client.manager.fault, next: rt}
+	}
+	if client.manager != nil {
+		rt = &restInFlightTransport{registry: &client.manager.inFlight, next: rt}
+	}
+	return rt
+}
+
 //GetConfig 获取当前使用配置
 func (client *RestClient) GetConfig(ctx context.Context) (RestConfig, error) {
-	configName, err := client.Api.ConfigName(ctx)
-	if err != nil {
-		return nil, err
+	configName, ok := ctx.Value(configNameKey{}).(string)
+	if !ok {
+		var err error
+		configName, err = client.Api.ConfigName(ctx)
+		if err != nil {
+			return nil, err
+		}
 	}
 	config, ok := client.config[configName]
 	if !ok {
-		return nil, NewRestClientError("1", "rest config is exits:"+configName)
+		return nil, NewRestClientError(ErrConfigMissing, "rest config is exits:"+configName)
 	}
 	return config, nil
 }
 
-//Do 执行请求
-func (client *RestClient) Do(ctx context.Context, key int, param interface{}) chan *RestResult {
+//Do 执行请求,opts 为单次请求覆盖的配置,如 DoWithUrl DoWithTimeout
+//返回的通道只发送一个结果后关闭,重复接收得到nil,需要多次获取结果时使用 DoFuture
+//结果未通过 JsonResult 等读取全部返回内容时需调用 RestResult.Close 释放连接
+func (client *RestClient) Do(ctx context.Context, key int, param interface{}, opts ...DoOption) chan *RestResult {
+	ctx = withDoOptions(ctx, opts)
+	caller := callerFileInfo(1, 32)
+	caller.Tags = contextCallerTags(ctx)
+	ctx = withCaller(ctx, caller)
 	rc := make(chan *RestResult, 1)
-	reqs, err := client.Api.ConfigBuilds(ctx)
+	reqs, err := client.configBuilds(ctx)
 	if err != nil {
-		rc <- NewRestResultFromError(err, nil)
+		rc <- NewRestResultFromError(withCallerError(err, caller), nil)
+		close(rc)
 		return rc
 	}
 	build, find := reqs[key]
 	if !find {
-		rc <- NewRestResultFromError(NewRestClientError("2", "not find rest api"), nil)
+		rc <- NewRestResultFromError(withCallerError(NewRestClientError(ErrApiNotFound, "not find rest api"), caller), nil)
 		close(rc)
-	} else {
-		caller := callerFileInfo("rest_client/rest_client.go", 1, 15)
+		return rc
+	}
+	go func() {
+		//panic 时也发送结果并关闭通道,接收方不会一直阻塞
+		defer close(rc)
+		defer func() {
+			if info := recover(); info != nil {
+				rc <- NewRestResultFromError(withCallerError(client.panicError(ctx, key, info), caller), nil)
+			}
+		}()
+		res := build.BuildRequest(ctx, client, key, param, caller)
+		if res == nil {
+			res = NewRestResultFromError(NewRestClientError(ErrPanic, "rest build return nil result"), nil)
+		}
+		res.caller = caller
+		res.err = withCallerError(res.err, caller)
+		rc <- res
+	}()
+	return rc
+}
+
+//DoSync 执行请求并等待结果,返回错误为请求错误或ctx结束的错误
+func (client *RestClient) DoSync(ctx context.Context, key int, param interface{}, opts ...DoOption) (*RestResult, error) {
+	rc := client.Do(ctx, key, param, opts...)
+	select {
+	case res := <-rc:
+		return res, res.Err()
+	case <-ctx.Done():
+		//之后返回的结果不会再被读取,关闭以释放连接
 		go func() {
-			defer func() {
-				if info := recover(); info != nil {
-					rc <- NewRestResultFromError(NewRestClientError("3", fmt.Sprintf("panic %v", info)), nil)
-					close(rc)
-				}
-			}()
-			res := build.BuildRequest(ctx, client, key, param, caller)
-			rc <- res
-			close(rc)
+			if res := <-rc; res != nil {
+				_ = res.Close()
+			}
 		}()
+		return nil, ctx.Err()
 	}
-	return rc
 }
 
 //RestResult 请求接口后返回数据结构
@@ -161,6 +253,10 @@ type RestResult struct {
 	body           string
 	bodyReadOffset int
 	err            error
+	timing         *restTiming      //请求各阶段耗时
+	caller         *RestCallerInfo  //发起请求的调用方
+	info           *restRequestInfo //请求诊断信息
+	rawBody        string           //读取的返回内容,用于诊断信息
 }
 
 //NewRestResultFromError 创建一个错误的请求结果
@@ -219,6 +315,9 @@ func NewRestBodyResult(build RestBuild, body string, response *http.Response, ev
 
 //Header 获取返回HEADER
 func (res *RestResult) Header() (error, *http.Header) {
+	if res == nil {
+		return errResultClosed(), nil
+	}
 	if res.err != nil {
 		return res.err, nil
 	}
@@ -230,40 +329,44 @@ func (res *RestResult) Header() (error, *http.Header) {
 
 //Read 读取接口
 func (res *RestResult) Read(p []byte) (int, error) {
+	if res == nil {
+		return 0, errResultClosed()
+	}
 	if res.err != nil {
 		return 0, res.err
 	}
 	if res.bodyReadOffset >= 0 {
-		bDat := []byte(res.body)
-		pLen := len(p)
-		sLen := len(bDat[res.bodyReadOffset:])
-		if sLen == 0 {
+		if res.bodyReadOffset >= len(res.body) {
 			return 0, io.EOF
 		}
-		if sLen > pLen {
-			tmp := bDat[res.bodyReadOffset : res.bodyReadOffset+pLen]
-			copy(p[0:pLen], tmp)
-			res.bodyReadOffset += pLen
-			return pLen, nil
-		} else {
-			tmp := bDat[res.bodyReadOffset : res.bodyReadOffset+sLen]
-			copy(p[0:sLen], tmp)
-			res.bodyReadOffset += sLen
-			return sLen, io.EOF
+		//直接从字符串复制,避免每次读取转换整个内容
+		n := copy(p, res.body[res.bodyReadOffset:])
+		res.bodyReadOffset += n
+		if res.bodyReadOffset >= len(res.body) {
+			return n, io.EOF
 		}
+		return n, nil
 	} else {
 		if res.response == nil {
 			return 0, io.EOF
 		}
 		n, err := res.response.Body.Read(p)
+		if err != nil && err != io.EOF {
+			err = cancelError(err)
+		}
 		if n > 0 {
 			res.event.ResponseRead(p[0:n])
 		}
 		if err == io.EOF {
+			if res.timing != nil {
+				res.timing.finish()
+				res.timing.checkSlow(nil)
+			}
 			if res.event != nil {
 				res.event.ResponseFinish(nil)
 			}
 		} else {
+			err = res.timing.timeoutError(err)
 			res.err = err
 			if res.event != nil {
 				res.event.ResponseFinish(err)
@@ -273,31 +376,66 @@ func (res *RestResult) Read(p []byte) (int, error) {
 	}
 }
 
-//Err 返回错误,无错误返回nil
+//Close 关闭返回内容,释放连接及请求占用的隔离锁,排队位置及超时,可重复调用
+//JsonResult 等读取全部返回内容的方法读取完后自动释放,只检查 Err Header 或未读取完返回内容时必须调用
+func (res *RestResult) Close() error {
+	if res == nil || res.response == nil || res.response.Body == nil {
+		return nil
+	}
+	err := res.response.Body.Close()
+	if cEvent, ok := res.event.(RestCloseEvent); ok {
+		cEvent.ResponseClose()
+	}
+	return err
+}
+
+//Err 返回错误,无错误返回nil,从已关闭的 Do 通道重复接收的nil结果返回错误
 func (res *RestResult) Err() error {
+	if res == nil {
+		return errResultClosed()
+	}
 	return res.err
 }
 
 //JsonResult 将结果转为JSON字符串
 func (res *RestResult) JsonResult(path ...string) *JsonResult {
+	if res == nil {
+		return NewJsonResultFromError(errResultClosed())
+	}
 	defer func() {
 		if res.event != nil {
 			res.event.ResponseCheck(res.err)
 		}
 	}()
 	if res.err != nil {
-		return NewJsonResultFromError(res.err)
+		return res.jsonError(res.err)
 	}
-	body, err := ioutil.ReadAll(res)
+	buf := getBuffer()
+	if res.response != nil && res.response.ContentLength > 0 && res.response.ContentLength < maxPoolBuffer {
+		buf.Grow(int(res.response.ContentLength) + bytes.MinRead)
+	}
+	_, err := buf.ReadFrom(res)
 	if err != nil {
-		return NewJsonResultFromError(res.err)
+		putBuffer(buf)
+		return res.jsonError(res.err)
 	}
-	bodyStr := string(body)
-	if check, ok := res.build.(RestJsonResult); ok {
-		res.err = check.CheckJsonResult(bodyStr)
-		if res.err != nil {
-			return NewJsonResultFromError(res.err)
-		}
+	res.rawBody = buf.String()
+	putBuffer(buf)
+	bodyStr, err := res.transcode(res.rawBody)
+	if err != nil {
+		return res.jsonError(err)
+	}
+	bodyStr, res.err = res.transform(res.sanitize(bodyStr))
+	if res.err != nil {
+		return res.jsonError(res.err)
+	}
+	res.err = res.checkResult(bodyStr)
+	if res.err != nil {
+		return res.jsonError(res.err)
+	}
+	bodyStr, res.err = res.decrypt(bodyStr)
+	if res.err != nil {
+		return res.jsonError(res.err)
 	}
 	basePath := ""
 	if path != nil {
@@ -1,16 +1,23 @@
 package rest_client
 
 import (
-	"net"
 	"net/http"
-	"time"
 )
 
 /////////////// 对外接口部分//////////////////
 
 type RestClientManager struct {
-	restConfig map[string]RestConfig
-	transport  *http.Transport
+	restConfig   map[string]RestConfig
+	transport    *http.Transport
+	deprecations restDeprecationRegistry
+	fault        *RestFaultInjector
+	propagators  []RestPropagator
+	transports   restTransports
+	panicHandler RestPanicHandler
+	health       restHealthCheckers
+	apis         restApiRegistry
+	durable      RestDurableQueue
+	inFlight     restInFlightRegistry
 }
 
 func (c *RestClientManager) NewApi(api RestApi) *RestClient {
@@ -18,35 +25,66 @@ func (c *RestClientManager) NewApi(api RestApi) *RestClient {
 		Api:       api,
 		config:    c.restConfig,
 		transport: c.transport,
+		manager:   c,
 	}
 	return rest
 }
 
+//Deprecations 请求过的被服务端标记废弃的接口,用于启动检查或定期上报
+func (c *RestClientManager) Deprecations() []RestDeprecation {
+	return c.deprecations.list()
+}
+
+//SetFaultInjector 设置故障注入,仅用于测试环境,传nil取消
+func (c *RestClientManager) SetFaultInjector(fault *RestFaultInjector) *RestClientManager {
+	c.fault = fault
+	return c
+}
+
+//SetPropagators 设置调用链HEADER注入方式,默认为W3C,不传参数时不注入
+func (c *RestClientManager) SetPropagators(propagators ...RestPropagator) *RestClientManager {
+	c.propagators = propagators
+	return c
+}
+
 //SetRestConfig 设置外部接口配置
 func (c *RestClientManager) SetRestConfig(config RestConfig) *RestClientManager {
 	c.restConfig[config.GetName()] = config
+	c.transports.remove(config.GetName())
+	if appConfig, ok := config.(*AppRestConfig); ok && appConfig.HealthCheck != nil {
+		var transport http.RoundTripper = c.transport
+		if appConfig.Transport != nil {
+			transport = c.transports.get(appConfig.Name, appConfig.Transport).roundTripper
+		}
+		c.health.set(config.GetName(), appConfig, transport)
+	} else {
+		c.health.set(config.GetName(), nil, nil)
+	}
 	return c
 }
 
+//Health 配置了 HealthCheck 的服务各地址健康状态,可用于就绪检查
+func (c *RestClientManager) Health() RestHealth {
+	return c.health.health()
+}
+
+//Close 停止健康检查等后台任务
+func (c *RestClientManager) Close() {
+	c.health.closeAll()
+}
+
 //NewRestClientManager 新建REST客户端
+//@param transport 不传时按 DefaultRestTransportConfig 创建,可通过 NewRestTransport 自定义连接池
 func NewRestClientManager(transport ...*http.Transport) *RestClientManager {
 	var setTransport *http.Transport
 	if transport == nil {
-		setTransport = &http.Transport{
-			DialContext: (&net.Dialer{
-				Timeout:   30 * time.Second,
-				KeepAlive: 300 * time.Second,
-			}).DialContext,
-			MaxIdleConns:          120,
-			MaxIdleConnsPerHost:   12,
-			IdleConnTimeout:       15 * time.Second,
-			ResponseHeaderTimeout: 60 * time.Second, //默认到header的等待时间最长
-		}
+		setTransport = NewRestTransport(DefaultRestTransportConfig())
 	} else {
 		setTransport = transport[0]
 	}
 	return &RestClientManager{
-		restConfig: make(map[string]RestConfig),
-		transport:  setTransport,
+		restConfig:  make(map[string]RestConfig),
+		transport:   setTransport,
+		propagators: []RestPropagator{&W3CPropagator{}},
 	}
 }
@@ -2,10 +2,13 @@ package rest_client
 
 import (
 	"bytes"
+	"context"
+	"github.com/tidwall/gjson"
 	"io/ioutil"
 	"net/http"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestRestRequestReader(t *testing.T) {
@@ -20,6 +23,32 @@ func TestRestRequestReader(t *testing.T) {
 	}
 }
 
+type testUploadEvent struct {
+	RestEventNoop
+	calls       int
+	sent, total int64
+}
+
+func (event *testUploadEvent) UploadProgress(sent, total int64, rate float64) {
+	event.calls++
+	event.sent, event.total = sent, total
+}
+
+func TestRestRequestReaderProgress(t *testing.T) {
+	event := &testUploadEvent{}
+	read := NewRestRequestReader(strings.NewReader(strings.Repeat("a", 4096)), event)
+	read.interval = 0
+	buf := make([]byte, 1024)
+	for {
+		if _, err := read.Read(buf); err != nil {
+			break
+		}
+	}
+	if event.calls < 4 || event.sent != 4096 || event.total != 4096 {
+		t.Error("upload progress error")
+	}
+}
+
 func TestRestResult(t *testing.T) {
 	err := NewAppClientError("1", "2", "err")
 	bb := NewRestResultFromError(err, NewRestEventNoop())
@@ -92,3 +121,68 @@ func TestRestRespResult(t *testing.T) {
 		t.Error("json parse struct error")
 	}
 }
+
+func TestRestClientDoSync(t *testing.T) {
+	server := newTestAppServer(func(method string, _ gjson.Result) string {
+		if method == "slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		return `{"method":"` + method + `"}`
+	})
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{Method: "fast"},
+		2: &AppRestBuild{Method: "slow"},
+	})
+	res, err := client.DoSync(context.Background(), 1, nil)
+	if err != nil || res.JsonResult().MustString("data.method") != "fast" {
+		t.Error("do sync error")
+	}
+	if _, err = client.DoSync(context.Background(), 3, nil); err == nil {
+		t.Error("do sync api error not return")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err = client.DoSync(ctx, 2, nil); err != context.DeadlineExceeded {
+		t.Error("do sync ctx error not return")
+	}
+}
+
+// TestRestClientDoClose 各种失败情况下通道都会关闭,重复接收不会阻塞
+func TestRestClientDoClose(t *testing.T) {
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{Name: "test"})
+	clients := map[string]*RestClient{
+		"builds": manager.NewApi(&testCountApi{fail: true}),
+		"panic":  manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{1: &testPanicBuild{}}}),
+		"miss":   manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{}}),
+	}
+	for name, client := range clients {
+		rc := client.Do(context.Background(), 1, nil)
+		for i := 0; i < 2; i++ {
+			select {
+			case res := <-rc:
+				if res.Err() == nil || (i == 1 && (res != nil || res.JsonResult().Err() == nil)) {
+					t.Error("do result wrong", name, i)
+				}
+			case <-time.After(time.Second):
+				t.Fatal("do channel not close", name)
+			}
+		}
+	}
+}
+
+func BenchmarkRestResultJsonResult(b *testing.B) {
+	body := benchmarkJsonBody(1000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		res := NewRestResult(&AppRestBuild{}, &http.Response{
+			StatusCode:    http.StatusOK,
+			ContentLength: int64(len(body)),
+			Body:          ioutil.NopCloser(strings.NewReader(body)),
+		}, NewRestEventNoop())
+		if err := res.JsonResult().Err(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package rest_client
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// RestDownloadEvent 下载进度事件,服务配置的 RestEvent 实现该接口时回调
+type RestDownloadEvent interface {
+	DownloadProgress(written, total int64) //written 已写入长度,含续传前已下载部分,total 为-1时未知
+}
+
+// RestDownload 通过接口将返回内容直接写入文件或 io.Writer,不在内存中保留内容
+// 接口应使用 Raw 方式,下载到文件时支持Range断点续传
+// 校验优先使用指定的 Hash 及 Checksum,未指定时使用 Content-MD5 或MD5形式的 ETag
+type RestDownload struct {
+	Client     *RestClient
+	Key        int
+	Hash       func() hash.Hash //指定校验算法,为nil时按返回HEADER校验
+	Checksum   string           //期望的十六进制校验值
+	Resume     bool             //下载到文件时从未完成的临时文件续传,默认开启
+	TempSuffix string           //未完成的临时文件后缀,默认 .download
+}
+
+// NewRestDownload 创建下载
+func NewRestDownload(client *RestClient, key int) *RestDownload {
+	return &RestDownload{
+		Client:     client,
+		Key:        key,
+		Resume:     true,
+		TempSuffix: ".download",
+	}
+}
+
+var restEtagMd5 = regexp.MustCompile(`^"?([0-9a-fA-F]{32})"?$`)
+
+// restDownloadWriter 写入时计算校验及回调进度
+type restDownloadWriter struct {
+	writer  io.Writer
+	sum     hash.Hash
+	event   RestDownloadEvent
+	written int64
+	total   int64
+}
+
+func (w *restDownloadWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	if n > 0 {
+		if w.sum != nil {
+			w.sum.Write(p[:n])
+		}
+		w.written += int64(n)
+		if w.event != nil {
+			w.event.DownloadProgress(w.written, w.total)
+		}
+	}
+	return n, err
+}
+
+// request 发起请求,offset大于0时请求剩余部分,返回实际开始的偏移
+func (down *RestDownload) request(ctx context.Context, param interface{}, offset int64) (*RestResult, int64, error) {
+	if offset > 0 {
+		ctx = WithHeader(ctx, "Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	res := <-down.Client.Do(ctx, down.Key, param)
+	if res.err != nil {
+		return nil, 0, res.err
+	}
+	if res.response == nil {
+		return res, 0, nil
+	}
+	switch res.response.StatusCode {
+	case http.StatusPartialContent:
+		if offset > 0 && strings.HasPrefix(res.response.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)) {
+			return res, offset, nil
+		}
+	case http.StatusRequestedRangeNotSatisfiable:
+		//已下载完成
+		if offset > 0 {
+			_ = res.Close()
+			return nil, offset, nil
+		}
+	default:
+		if res.response.StatusCode >= 200 && res.response.StatusCode < 300 {
+			return res, 0, nil
+		}
+	}
+	_ = res.Close()
+	return nil, 0, NewRestClientError(ErrDownload, fmt.Sprintf("download http code:%d", res.response.StatusCode))
+}
+
+// checksum 获取校验算法及期望值,无可用校验时返回nil
+func (down *RestDownload) checksum(res *RestResult, start int64) (hash.Hash, string) {
+	if down.Hash != nil {
+		return down.Hash(), strings.ToLower(down.Checksum)
+	}
+	if res == nil || res.response == nil {
+		return nil, ""
+	}
+	//Content-MD5 为本次返回内容的校验,续传时不可用
+	if start == 0 {
+		if val := res.response.Header.Get("Content-MD5"); len(val) > 0 {
+			if b, err := base64.StdEncoding.DecodeString(val); err == nil {
+				return md5.New(), hex.EncodeToString(b)
+			}
+		}
+	}
+	if match := restEtagMd5.FindStringSubmatch(res.response.Header.Get("ETag")); match != nil {
+		return md5.New(), strings.ToLower(match[1])
+	}
+	return nil, ""
+}
+
+func (down *RestDownload) verify(sum hash.Hash, expect string) error {
+	if sum == nil {
+		return nil
+	}
+	get := hex.EncodeToString(sum.Sum(nil))
+	if get != expect {
+		return NewRestClientError(ErrChecksum, fmt.Sprintf("download checksum mismatch,expect:%s get:%s", expect, get))
+	}
+	return nil
+}
+
+func (down *RestDownload) copy(res *RestResult, w io.Writer, sum hash.Hash, start int64) (int64, error) {
+	writer := &restDownloadWriter{writer: w, sum: sum, written: start, total: -1}
+	writer.event, _ = res.event.(RestDownloadEvent)
+	if res.response != nil && res.response.ContentLength >= 0 {
+		writer.total = start + res.response.ContentLength
+	}
+	_, err := io.Copy(writer, res)
+	if res.event != nil {
+		res.event.ResponseCheck(err)
+	}
+	return writer.written, err
+}
+
+// ToWriter 下载内容写入 w,返回写入长度
+func (down *RestDownload) ToWriter(ctx context.Context, param interface{}, w io.Writer) (int64, error) {
+	res, _, err := down.request(ctx, param, 0)
+	if err != nil {
+		return 0, err
+	}
+	sum, expect := down.checksum(res, 0)
+	n, err := down.copy(res, w, sum, 0)
+	if err != nil {
+		return n, err
+	}
+	return n, down.verify(sum, expect)
+}
+
+// ToFile 下载内容到文件,先写入临时文件,完成并校验通过后重命名为目标文件
+// 开启 Resume 时从已存在的临时文件末尾续传,服务端不支持Range时重新下载
+func (down *RestDownload) ToFile(ctx context.Context, param interface{}, filePath string) (int64, error) {
+	tempPath := filePath + down.TempSuffix
+	if len(down.TempSuffix) == 0 {
+		tempPath = filePath + ".download"
+	}
+	var offset int64
+	if down.Resume {
+		if info, err := os.Stat(tempPath); err == nil {
+			offset = info.Size()
+		}
+	}
+	res, start, err := down.request(ctx, param, offset)
+	if err != nil {
+		return 0, err
+	}
+	file, err := os.OpenFile(tempPath, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		_ = res.Close()
+		return 0, err
+	}
+	defer file.Close()
+	if err = file.Truncate(start); err == nil {
+		_, err = file.Seek(start, io.SeekStart)
+	}
+	if err != nil {
+		_ = res.Close()
+		return 0, err
+	}
+	size := start
+	sum, expect := down.checksum(res, start)
+	if res != nil {
+		if size, err = down.copy(res, file, nil, start); err != nil {
+			return size, err
+		}
+	}
+	if sum != nil {
+		//续传时需校验整个文件
+		if _, err = file.Seek(0, io.SeekStart); err != nil {
+			return size, err
+		}
+		if _, err = io.Copy(sum, file); err != nil {
+			return size, err
+		}
+		if err = down.verify(sum, expect); err != nil {
+			_ = file.Close()
+			_ = os.Remove(tempPath)
+			return size, err
+		}
+	}
+	if err = file.Close(); err != nil {
+		return size, err
+	}
+	return size, os.Rename(tempPath, filePath)
+}
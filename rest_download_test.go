@@ -0,0 +1,85 @@
+package rest_client
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testDownloadEvent struct {
+	RestEventNoop
+	written, total int64
+}
+
+func (event *testDownloadEvent) DownloadProgress(written, total int64) {
+	event.written, event.total = written, total
+}
+
+func TestRestDownload(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789", 1000))
+	sum := md5.Sum(content)
+	etag := `"` + hex.EncodeToString(sum[:]) + `"`
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		w.Header().Set("ETag", etag)
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+	event := &testDownloadEvent{}
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:   "test",
+		AppUrl: server.URL,
+		EventCreate: func(_ context.Context) RestEvent {
+			return event
+		},
+	})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true},
+	}})
+	down := NewRestDownload(client, 1)
+
+	buf := bytes.NewBuffer(nil)
+	if n, err := down.ToWriter(context.Background(), nil, buf); err != nil || n != int64(len(content)) || !bytes.Equal(buf.Bytes(), content) {
+		t.Error("download to writer error", err)
+	}
+	if event.written != int64(len(content)) || event.total != int64(len(content)) {
+		t.Error("download progress error")
+	}
+
+	dir, err := ioutil.TempDir("", "rest_download")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filePath := filepath.Join(dir, "file")
+	_ = ioutil.WriteFile(filePath+".download", content[:3000], 0644)
+	ranges = nil
+	if n, err := down.ToFile(context.Background(), nil, filePath); err != nil || n != int64(len(content)) {
+		t.Error("download to file error", err)
+	}
+	if data, _ := ioutil.ReadFile(filePath); !bytes.Equal(data, content) {
+		t.Error("download file content error")
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=3000-" {
+		t.Error("download not resume", ranges)
+	}
+
+	_ = ioutil.WriteFile(filePath+".download", []byte("broken"), 0644)
+	if _, err := down.ToFile(context.Background(), nil, filePath); err == nil {
+		t.Error("checksum mismatch not return")
+	}
+	if _, err := os.Stat(filePath + ".download"); !os.IsNotExist(err) {
+		t.Error("broken temp file not remove")
+	}
+}
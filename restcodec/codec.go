@@ -0,0 +1,87 @@
+// Package restcodec 非JSON的请求参数及返回内容编解码,设置到 AppRestBuild.Codec 使用
+package restcodec
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// MsgpackCodec msgpack编解码,JSON标签作为字段名
+type MsgpackCodec struct{}
+
+// Msgpack msgpack编解码
+var Msgpack = MsgpackCodec{}
+
+func (MsgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	enc := msgpack.GetEncoder()
+	defer msgpack.PutEncoder(enc)
+	var buf bytes.Buffer
+	enc.Reset(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (MsgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	dec := msgpack.GetDecoder()
+	defer msgpack.PutDecoder(dec)
+	dec.Reset(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+func (MsgpackCodec) ContentType() string {
+	return "application/msgpack"
+}
+
+// Protobuf protobuf编解码
+// 参数及解码目标为 proto.Message 时直接编解码,否则需设置 New,经 protojson 与非 proto.Message 的值转换
+type Protobuf struct {
+	New func() proto.Message //创建消息,用于 map 等参数的编码及解码到 interface{}
+}
+
+func (codec *Protobuf) Marshal(v interface{}) ([]byte, error) {
+	if msg, ok := v.(proto.Message); ok {
+		return proto.Marshal(msg)
+	}
+	if codec.New == nil {
+		return nil, fmt.Errorf("protobuf marshal %T: not proto.Message", v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	msg := codec.New()
+	if err = protojson.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	return proto.Marshal(msg)
+}
+
+func (codec *Protobuf) Unmarshal(data []byte, v interface{}) error {
+	if msg, ok := v.(proto.Message); ok {
+		return proto.Unmarshal(data, msg)
+	}
+	if codec.New == nil {
+		return fmt.Errorf("protobuf unmarshal %T: not proto.Message", v)
+	}
+	msg := codec.New()
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return err
+	}
+	jsonData, err := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(jsonData, v)
+}
+
+func (codec *Protobuf) ContentType() string {
+	return "application/x-protobuf"
+}
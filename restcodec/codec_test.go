@@ -0,0 +1,68 @@
+package restcodec
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+	"testing"
+)
+
+func TestMsgpack(t *testing.T) {
+	type goods struct {
+		Id   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	data, err := Msgpack.Marshal(&goods{Id: 1, Name: "goods"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]interface{}
+	if err = Msgpack.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out["name"] != "goods" {
+		t.Error("msgpack json tag error", out)
+	}
+	var item goods
+	if err = Msgpack.Unmarshal(data, &item); err != nil || item.Id != 1 {
+		t.Error("msgpack unmarshal struct error", err)
+	}
+	if Msgpack.ContentType() != "application/msgpack" {
+		t.Error("msgpack content type error")
+	}
+}
+
+func TestProtobuf(t *testing.T) {
+	msg, _ := structpb.NewStruct(map[string]interface{}{"name": "goods", "id": 1})
+	codec := &Protobuf{}
+	data, err := codec.Marshal(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &structpb.Struct{}
+	if err = codec.Unmarshal(data, decoded); err != nil || decoded.Fields["name"].GetStringValue() != "goods" {
+		t.Error("protobuf unmarshal message error", err)
+	}
+	var out interface{}
+	if err = codec.Unmarshal(data, &out); err == nil {
+		t.Error("protobuf without New should not unmarshal to interface")
+	}
+	if _, err = codec.Marshal(map[string]interface{}{"id": 1}); err == nil {
+		t.Error("protobuf without New should not marshal map")
+	}
+
+	codec.New = func() proto.Message {
+		return &structpb.Struct{}
+	}
+	if err = codec.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.(map[string]interface{})["name"] != "goods" {
+		t.Error("protobuf unmarshal interface error", out)
+	}
+	if data, err = codec.Marshal(map[string]interface{}{"id": 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err = codec.Unmarshal(data, decoded); err != nil || decoded.Fields["id"].GetNumberValue() != 2 {
+		t.Error("protobuf marshal map error", err)
+	}
+}
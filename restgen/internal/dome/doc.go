@@ -0,0 +1,4 @@
+// Package dome 由 restgen 按YAML定义生成的示例,与 example/main.go 中手写的 RestDome1 等价
+package dome
+
+//go:generate go run github.com/hsbteam/rest_client/cmd/restgen -yaml dome.yaml -o dome_gen.go
@@ -0,0 +1,113 @@
+// Code generated by restgen. DO NOT EDIT.
+
+package dome
+
+import (
+	"context"
+	"github.com/hsbteam/rest_client"
+	"net/http"
+	"time"
+)
+
+// 接口KEY
+const (
+	ProductDetail = iota //商品详情
+	ProductAdd           //添加商品
+)
+
+// Product 商品
+type Product struct {
+	Id   string        `json:"id"`
+	Name string        `json:"name,omitempty"`
+	Skus []*ProductSku `json:"skus,omitempty"`
+}
+
+// ProductAddParam 添加商品参数
+type ProductAddParam struct {
+	Name string        `json:"name"`           //商品名
+	Skus []*ProductSku `json:"skus,omitempty"` //规格
+}
+
+// ProductDetailParam 商品详情参数
+type ProductDetailParam struct {
+	Id string `json:"id"`
+}
+
+// ProductSku 商品规格
+type ProductSku struct {
+	SkuID int64   `json:"sku_id"`
+	Price float64 `json:"price,omitempty"`
+}
+
+// RestDome1 product 服务接口
+type RestDome1 struct {
+	TokenFunc func(ctx context.Context) (string, error) //获取当前TOKEN
+}
+
+// Token 未设置 TokenFunc 时为空
+func (api *RestDome1) Token(ctx context.Context) (string, error) {
+	if api.TokenFunc == nil {
+		return "", nil
+	}
+	return api.TokenFunc(ctx)
+}
+
+func (api *RestDome1) ConfigBuilds(_ context.Context) (map[int]rest_client.RestBuild, error) {
+	return map[int]rest_client.RestBuild{
+		ProductDetail: &rest_client.AppRestBuild{
+			HttpMethod: http.MethodGet,
+			Path:       "/jp/product",
+			Method:     "detail",
+			Timeout:    100 * time.Second,
+		},
+		ProductAdd: &rest_client.AppRestBuild{
+			HttpMethod: http.MethodPost,
+			Path:       "/jp/product",
+			Method:     "add",
+			Timeout:    100 * time.Second,
+		},
+	}, nil
+}
+
+func (api *RestDome1) ConfigName(_ context.Context) (string, error) {
+	return "product", nil
+}
+
+// RestDome1Client product 服务类型化调用
+type RestDome1Client struct {
+	Client *rest_client.RestClient
+}
+
+// NewRestDome1Client 创建类型化调用,api 为nil时不使用TOKEN
+func NewRestDome1Client(manager *rest_client.RestClientManager, api *RestDome1) *RestDome1Client {
+	if api == nil {
+		api = &RestDome1{}
+	}
+	return &RestDome1Client{Client: manager.NewApi(api)}
+}
+
+// ProductDetail 商品详情
+func (c *RestDome1Client) ProductDetail(ctx context.Context, param *ProductDetailParam) (*Product, error) {
+	res, err := c.Client.DoSync(ctx, ProductDetail, param)
+	if err != nil {
+		return nil, err
+	}
+	out := &Product{}
+	if err := res.JsonResult().GetStruct("data", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProductAdd 添加商品
+func (c *RestDome1Client) ProductAdd(ctx context.Context, param *ProductAddParam) (*Product, error) {
+	res, err := c.Client.DoSync(ctx, ProductAdd, param)
+	if err != nil {
+		return nil, err
+	}
+	out := &Product{}
+	if err := res.JsonResult().GetStruct("data", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
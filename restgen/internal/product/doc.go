@@ -0,0 +1,4 @@
+// Package product 由 restgen 生成的示例,用于检查生成的代码可以编译
+package product
+
+//go:generate go run github.com/hsbteam/rest_client/cmd/restgen -openapi ../../testdata/product.yaml -name ProductApi -config product -timeout 2s -o product_gen.go
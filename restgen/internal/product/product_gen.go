@@ -0,0 +1,125 @@
+// Code generated by restgen. DO NOT EDIT.
+
+package product
+
+import (
+	"context"
+	"github.com/hsbteam/rest_client"
+	"net/http"
+	"time"
+)
+
+// 接口KEY
+const (
+	ProductDetail = iota //商品详情
+	ProductAdd           //添加商品
+	GetV2Products        //商品列表
+)
+
+// Product 商品
+type Product struct {
+	Id      int64                 `json:"id"`
+	Name    string                `json:"name,omitempty"`
+	Price   float64               `json:"price,omitempty"`
+	SkuList []*ProductSkuListItem `json:"sku_list,omitempty"`
+}
+
+// ProductAddParam 添加商品 参数
+type ProductAddParam struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+// ProductAddResponse 添加商品 返回
+type ProductAddResponse struct {
+	Id int64 `json:"id,omitempty"`
+}
+
+// ProductDetailParam 商品详情 参数
+type ProductDetailParam struct {
+	Id int64 `json:"id"`
+}
+
+// ProductSkuListItem 结构
+type ProductSkuListItem struct {
+	Sku string `json:"sku,omitempty"`
+}
+
+// ProductApi product 服务接口
+type ProductApi struct{}
+
+func (api *ProductApi) ConfigBuilds(_ context.Context) (map[int]rest_client.RestBuild, error) {
+	return map[int]rest_client.RestBuild{
+		ProductDetail: &rest_client.AppRestBuild{
+			HttpMethod: http.MethodGet,
+			Path:       "/jp/product",
+			Method:     "detail",
+			Timeout:    3 * time.Second,
+		},
+		ProductAdd: &rest_client.AppRestBuild{
+			HttpMethod: http.MethodPost,
+			Path:       "/jp/product",
+			Method:     "add",
+			Timeout:    2 * time.Second,
+		},
+		GetV2Products: &rest_client.AppRestBuild{
+			HttpMethod: http.MethodGet,
+			Path:       "/v2/products",
+			Timeout:    2 * time.Second,
+			Raw:        true,
+		},
+	}, nil
+}
+
+func (api *ProductApi) ConfigName(_ context.Context) (string, error) {
+	return "product", nil
+}
+
+// ProductApiClient product 服务类型化调用
+type ProductApiClient struct {
+	Client *rest_client.RestClient
+}
+
+// NewProductApiClient 创建类型化调用
+func NewProductApiClient(manager *rest_client.RestClientManager) *ProductApiClient {
+	return &ProductApiClient{Client: manager.NewApi(&ProductApi{})}
+}
+
+// ProductDetail 商品详情
+func (c *ProductApiClient) ProductDetail(ctx context.Context, param *ProductDetailParam) (*Product, error) {
+	res, err := c.Client.DoSync(ctx, ProductDetail, param)
+	if err != nil {
+		return nil, err
+	}
+	out := &Product{}
+	if err := res.JsonResult().GetStruct("data", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProductAdd 添加商品
+func (c *ProductApiClient) ProductAdd(ctx context.Context, param *ProductAddParam) (*ProductAddResponse, error) {
+	res, err := c.Client.DoSync(ctx, ProductAdd, param)
+	if err != nil {
+		return nil, err
+	}
+	out := &ProductAddResponse{}
+	if err := res.JsonResult().GetStruct("data", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetV2Products 商品列表
+func (c *ProductApiClient) GetV2Products(ctx context.Context, param rest_client.QueryParams) (*[]*Product, error) {
+	res, err := c.Client.DoSync(ctx, GetV2Products, param)
+	if err != nil {
+		return nil, err
+	}
+	out := &[]*Product{}
+	if err := res.JsonResult().GetStruct("", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
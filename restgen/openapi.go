@@ -0,0 +1,294 @@
+package restgen
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OpenApiOption 从 OpenAPI 文档生成时的设置
+type OpenApiOption struct {
+	Package    string        //生成代码的包名
+	Name       string        //服务结构名
+	ConfigName string        //服务配置名
+	Timeout    time.Duration //接口未设置 x-timeout 时的超时时间
+}
+
+type openApiSchema struct {
+	Ref         string                    `yaml:"$ref"`
+	Type        string                    `yaml:"type"`
+	Format      string                    `yaml:"format"`
+	Description string                    `yaml:"description"`
+	Properties  map[string]*openApiSchema `yaml:"properties"`
+	Required    []string                  `yaml:"required"`
+	Items       *openApiSchema            `yaml:"items"`
+}
+
+type openApiParameter struct {
+	Name        string         `yaml:"name"`
+	In          string         `yaml:"in"`
+	Description string         `yaml:"description"`
+	Required    bool           `yaml:"required"`
+	Schema      *openApiSchema `yaml:"schema"`
+}
+
+type openApiContent map[string]struct {
+	Schema *openApiSchema `yaml:"schema"`
+}
+
+// jsonSchema JSON内容的结构
+func (content openApiContent) jsonSchema() *openApiSchema {
+	for _, mime := range []string{"application/json", "*/*"} {
+		if media, ok := content[mime]; ok {
+			return media.Schema
+		}
+	}
+	return nil
+}
+
+type openApiOperation struct {
+	OperationId string             `yaml:"operationId"`
+	Summary     string             `yaml:"summary"`
+	Description string             `yaml:"description"`
+	Parameters  []openApiParameter `yaml:"parameters"`
+	RequestBody *struct {
+		Content openApiContent `yaml:"content"`
+	} `yaml:"requestBody"`
+	Responses map[string]struct {
+		Content openApiContent `yaml:"content"`
+	} `yaml:"responses"`
+	XMethod  string `yaml:"x-method"`  //签名格式接口的接口名称,设置后为签名格式
+	XSigned  bool   `yaml:"x-signed"`  //签名格式接口
+	XTimeout string `yaml:"x-timeout"` //超时时间,如 3s
+}
+
+type openApiPathItem struct {
+	Get        *openApiOperation  `yaml:"get"`
+	Put        *openApiOperation  `yaml:"put"`
+	Post       *openApiOperation  `yaml:"post"`
+	Delete     *openApiOperation  `yaml:"delete"`
+	Options    *openApiOperation  `yaml:"options"`
+	Head       *openApiOperation  `yaml:"head"`
+	Patch      *openApiOperation  `yaml:"patch"`
+	Parameters []openApiParameter `yaml:"parameters"`
+}
+
+type openApiDoc struct {
+	OpenApi    string                      `yaml:"openapi"`
+	Paths      map[string]*openApiPathItem `yaml:"paths"`
+	Components struct {
+		Schemas map[string]*openApiSchema `yaml:"schemas"`
+	} `yaml:"components"`
+}
+
+// openApiBuilder 转换过程中生成的结构
+type openApiBuilder struct {
+	doc   *openApiDoc
+	types map[string]*Type
+}
+
+// ParseOpenApi 读取 OpenAPI 3 文档(YAML或JSON)转为接口定义
+// 设置了 x-method 或 x-signed 的接口为签名格式,返回内容从 data 节点解析,其他接口为 Raw
+func ParseOpenApi(data []byte, option *OpenApiOption) (*Api, error) {
+	doc := &openApiDoc{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(doc.OpenApi, "3.") {
+		return nil, fmt.Errorf("openapi version not support:%s", doc.OpenApi)
+	}
+	builder := &openApiBuilder{doc: doc, types: map[string]*Type{}}
+	api := &Api{Package: option.Package, Name: option.Name, ConfigName: option.ConfigName}
+
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		builder.goType(doc.Components.Schemas[name], GoName(name))
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		item := doc.Paths[path]
+		for _, method := range []struct {
+			name string
+			op   *openApiOperation
+		}{
+			{http.MethodGet, item.Get}, {http.MethodPost, item.Post}, {http.MethodPut, item.Put},
+			{http.MethodPatch, item.Patch}, {http.MethodDelete, item.Delete},
+			{http.MethodHead, item.Head}, {http.MethodOptions, item.Options},
+		} {
+			if method.op == nil {
+				continue
+			}
+			op, err := builder.operation(path, method.name, method.op, item.Parameters, option)
+			if err != nil {
+				return nil, err
+			}
+			api.Operations = append(api.Operations, op)
+		}
+	}
+	for _, typ := range builder.types {
+		api.Types = append(api.Types, typ)
+	}
+	return api, nil
+}
+
+// operation 转换单个接口
+func (builder *openApiBuilder) operation(path, method string, src *openApiOperation, shared []openApiParameter, option *OpenApiOption) (*Operation, error) {
+	name := src.OperationId
+	if len(name) == 0 {
+		name = strings.ToLower(method) + " " + path
+	}
+	op := &Operation{
+		Name:       GoName(name),
+		Doc:        src.Summary,
+		Path:       path,
+		HttpMethod: method,
+		Method:     src.XMethod,
+		Timeout:    option.Timeout,
+		Raw:        len(src.XMethod) == 0 && !src.XSigned,
+	}
+	if len(op.Doc) == 0 {
+		op.Doc = src.Description
+	}
+	if len(src.XTimeout) > 0 {
+		timeout, err := time.ParseDuration(src.XTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s x-timeout error:%w", method, path, err)
+		}
+		op.Timeout = timeout
+	}
+
+	params := append(append([]openApiParameter{}, shared...), src.Parameters...)
+	if src.RequestBody != nil {
+		if schema := src.RequestBody.Content.jsonSchema(); schema != nil {
+			op.Param = builder.goType(schema, op.Name+"Param")
+			builder.describe(op.Param, op.Doc+" 参数")
+		}
+	} else if len(params) > 0 {
+		if op.Raw && (method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions) {
+			//Raw 的GET请求参数需要为 QueryParams 才能编码到URL上
+			op.Param = "rest_client.QueryParams"
+		} else {
+			typ := &Type{Name: op.Name + "Param", Doc: op.Doc + " 参数"}
+			for _, param := range params {
+				if param.In != "query" && param.In != "path" {
+					continue
+				}
+				typ.Fields = append(typ.Fields, &Field{
+					Name:     GoName(param.Name),
+					Type:     builder.goType(param.Schema, op.Name+GoName(param.Name)),
+					Json:     param.Name,
+					Doc:      param.Description,
+					Required: param.Required,
+				})
+			}
+			if len(typ.Fields) > 0 {
+				builder.types[typ.Name] = typ
+				op.Param = "*" + typ.Name
+			}
+		}
+	}
+
+	codes := make([]string, 0, len(src.Responses))
+	for code := range src.Responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	if len(codes) > 0 {
+		if schema := src.Responses[codes[0]].Content.jsonSchema(); schema != nil {
+			response := builder.goType(schema, op.Name+"Response")
+			builder.describe(response, op.Doc+" 返回")
+			switch {
+			case strings.HasPrefix(response, "*"):
+				op.Response = response[1:]
+			case strings.HasPrefix(response, "[]"), strings.HasPrefix(response, "map["):
+				op.Response = response
+			}
+		}
+	}
+	return op, nil
+}
+
+// describe 未设置说明的结构使用接口说明
+func (builder *openApiBuilder) describe(goType, doc string) {
+	if typ, ok := builder.types[strings.TrimPrefix(goType, "*")]; ok && len(typ.Doc) == 0 {
+		typ.Doc = strings.TrimSpace(doc)
+	}
+}
+
+// goType 结构对应的Go类型,对象生成名为 name 的结构并返回其指针类型
+func (builder *openApiBuilder) goType(schema *openApiSchema, name string) string {
+	if schema == nil {
+		return "interface{}"
+	}
+	if len(schema.Ref) > 0 {
+		refName := schema.Ref[strings.LastIndex(schema.Ref, "/")+1:]
+		ref, ok := builder.doc.Components.Schemas[refName]
+		if !ok {
+			return "interface{}"
+		}
+		return builder.goType(ref, GoName(refName))
+	}
+	switch schema.Type {
+	case "string":
+		return "string"
+	case "integer":
+		if schema.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + builder.goType(schema.Items, name+"Item")
+	case "object", "":
+		if len(schema.Properties) == 0 {
+			if schema.Type == "object" {
+				return "map[string]interface{}"
+			}
+			return "interface{}"
+		}
+	default:
+		return "interface{}"
+	}
+	if _, ok := builder.types[name]; ok {
+		return "*" + name
+	}
+	typ := &Type{Name: name, Doc: schema.Description}
+	builder.types[name] = typ
+	required := map[string]bool{}
+	for _, field := range schema.Required {
+		required[field] = true
+	}
+	fields := make([]string, 0, len(schema.Properties))
+	for field := range schema.Properties {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		prop := schema.Properties[field]
+		typ.Fields = append(typ.Fields, &Field{
+			Name:     GoName(field),
+			Type:     builder.goType(prop, name+GoName(field)),
+			Json:     field,
+			Doc:      prop.Description,
+			Required: required[field],
+		})
+	}
+	return "*" + name
+}
@@ -0,0 +1,250 @@
+// Package restgen 按接口定义生成 RestApi 实现代码,接口定义可从 OpenAPI 文档或YAML定义读取
+package restgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+)
+
+// Api 一个服务的接口定义
+type Api struct {
+	Package    string       //生成代码的包名
+	Name       string       //服务结构名,如 ProductApi
+	ConfigName string       //服务配置名
+	Token      bool         //服务需要TOKEN,生成的结构实现 RestTokenApi
+	Operations []*Operation //接口,按顺序生成KEY常量
+	Types      []*Type      //参数及返回的结构
+}
+
+// Operation 单个接口
+type Operation struct {
+	Name       string        //接口常量名,如 ProductDetail
+	Doc        string        //接口说明
+	Path       string        //接口路径
+	HttpMethod string        //请求方式,默认POST
+	Method     string        //签名参数中的接口名称
+	Timeout    time.Duration //超时时间
+	Raw        bool          //不使用签名格式
+	Param      string        //参数类型,如 *ProductDetailParam,为空时为 interface{}
+	Response   string        //返回类型,如 ProductDetailResponse,为空时不生成类型化的调用
+}
+
+// Type 生成的结构
+type Type struct {
+	Name   string
+	Doc    string
+	Fields []*Field
+}
+
+// Field 结构字段
+type Field struct {
+	Name     string //字段名
+	Type     string //Go类型,如 string []int64 *Product
+	Json     string //JSON名
+	Doc      string //字段说明
+	Required bool   //必填字段不加 omitempty
+}
+
+var codeTemplate = template.Must(template.New("code").Funcs(template.FuncMap{
+	"httpMethod": httpMethodConst,
+	"duration":   durationLiteral,
+	"comment":    comment,
+}).Parse(`// Code generated by restgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+	"github.com/hsbteam/rest_client"
+	"net/http"
+{{- if .UseTime}}
+	"time"
+{{- end}}
+)
+
+// 接口KEY
+const (
+{{- range $i, $op := .Operations}}
+	{{$op.Name}}{{if eq $i 0}} = iota{{end}}{{if $op.Doc}} //{{comment $op.Doc}}{{end}}
+{{- end}}
+)
+{{range .Types}}
+// {{.Name}} {{if .Doc}}{{comment .Doc}}{{else}}结构{{end}}
+type {{.Name}} struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} ` + "`" + `json:"{{.Json}}{{if not .Required}},omitempty{{end}}"` + "`" + `{{if .Doc}} //{{comment .Doc}}{{end}}
+{{- end}}
+}
+{{end}}
+// {{.Name}} {{.ConfigName}} 服务接口
+{{- if .Token}}
+type {{.Name}} struct {
+	TokenFunc func(ctx context.Context) (string, error) //获取当前TOKEN
+}
+
+// Token 未设置 TokenFunc 时为空
+func (api *{{.Name}}) Token(ctx context.Context) (string, error) {
+	if api.TokenFunc == nil {
+		return "", nil
+	}
+	return api.TokenFunc(ctx)
+}
+{{- else}}
+type {{.Name}} struct{}
+{{- end}}
+
+func (api *{{.Name}}) ConfigBuilds(_ context.Context) (map[int]rest_client.RestBuild, error) {
+	return map[int]rest_client.RestBuild{
+{{- range .Operations}}
+		{{.Name}}: &rest_client.AppRestBuild{
+			HttpMethod: {{httpMethod .HttpMethod}},
+			Path:       {{printf "%q" .Path}},
+{{- if .Method}}
+			Method:     {{printf "%q" .Method}},
+{{- end}}
+{{- if .Timeout}}
+			Timeout:    {{duration .Timeout}},
+{{- end}}
+{{- if .Raw}}
+			Raw:        true,
+{{- end}}
+		},
+{{- end}}
+	}, nil
+}
+
+func (api *{{.Name}}) ConfigName(_ context.Context) (string, error) {
+	return {{printf "%q" .ConfigName}}, nil
+}
+
+// {{.Name}}Client {{.ConfigName}} 服务类型化调用
+type {{.Name}}Client struct {
+	Client *rest_client.RestClient
+}
+
+// New{{.Name}}Client 创建类型化调用{{if .Token}},api 为nil时不使用TOKEN{{end}}
+{{- if .Token}}
+func New{{.Name}}Client(manager *rest_client.RestClientManager, api *{{.Name}}) *{{.Name}}Client {
+	if api == nil {
+		api = &{{.Name}}{}
+	}
+	return &{{.Name}}Client{Client: manager.NewApi(api)}
+}
+{{- else}}
+func New{{.Name}}Client(manager *rest_client.RestClientManager) *{{.Name}}Client {
+	return &{{.Name}}Client{Client: manager.NewApi(&{{.Name}}{})}
+}
+{{- end}}
+{{range .Operations}}{{if .Response}}
+// {{.Name}} {{if .Doc}}{{comment .Doc}}{{else}}调用 {{.Path}}{{end}}
+func (c *{{$.Name}}Client) {{.Name}}(ctx context.Context, param {{if .Param}}{{.Param}}{{else}}interface{}{{end}}) (*{{.Response}}, error) {
+	res, err := c.Client.DoSync(ctx, {{.Name}}, param)
+	if err != nil {
+		return nil, err
+	}
+	out := &{{.Response}}{}
+	if err := res.JsonResult().GetStruct("{{if not .Raw}}data{{end}}", out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+{{end}}{{end}}`))
+
+// Generate 生成格式化后的代码
+func Generate(api *Api) ([]byte, error) {
+	if len(api.Package) == 0 || len(api.Name) == 0 || len(api.ConfigName) == 0 {
+		return nil, fmt.Errorf("package name and config name is required")
+	}
+	if len(api.Operations) == 0 {
+		return nil, fmt.Errorf("no operation in api")
+	}
+	names := map[string]bool{api.Name: true, api.Name + "Client": true, "New" + api.Name + "Client": true}
+	useTime := false
+	for _, op := range api.Operations {
+		if names[op.Name] {
+			return nil, fmt.Errorf("duplicate name:%s", op.Name)
+		}
+		names[op.Name] = true
+		useTime = useTime || op.Timeout > 0
+	}
+	for _, typ := range api.Types {
+		if names[typ.Name] {
+			return nil, fmt.Errorf("duplicate name:%s", typ.Name)
+		}
+		names[typ.Name] = true
+	}
+	sort.Slice(api.Types, func(i, j int) bool {
+		return api.Types[i].Name < api.Types[j].Name
+	})
+	var buf bytes.Buffer
+	if err := codeTemplate.Execute(&buf, struct {
+		*Api
+		UseTime bool
+	}{api, useTime}); err != nil {
+		return nil, err
+	}
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format code error:%w\n%s", err, buf.String())
+	}
+	return code, nil
+}
+
+// httpMethodConst 请求方式使用 net/http 中的常量
+func httpMethodConst(method string) string {
+	method = strings.ToUpper(method)
+	if len(method) == 0 {
+		method = "POST"
+	}
+	switch method {
+	case "GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "CONNECT", "TRACE":
+		return "http.Method" + method[:1] + strings.ToLower(method[1:])
+	}
+	return fmt.Sprintf("%q", method)
+}
+
+// durationLiteral 时间转为代码中的表示,如 2 * time.Second
+func durationLiteral(d time.Duration) string {
+	for _, unit := range []struct {
+		d    time.Duration
+		name string
+	}{{time.Minute, "Minute"}, {time.Second, "Second"}, {time.Millisecond, "Millisecond"}} {
+		if d%unit.d == 0 {
+			return fmt.Sprintf("%d * time.%s", d/unit.d, unit.name)
+		}
+	}
+	return fmt.Sprintf("%d", int64(d))
+}
+
+// comment 多行说明合并为一行
+func comment(doc string) string {
+	return strings.Join(strings.Fields(doc), " ")
+}
+
+// GoName 转为导出的Go名称,如 product_detail get-product 转为 ProductDetail GetProduct
+func GoName(name string) string {
+	var out strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		out.WriteRune(r)
+	}
+	goName := out.String()
+	if len(goName) == 0 || unicode.IsDigit([]rune(goName)[0]) {
+		goName = "X" + goName
+	}
+	return goName
+}
@@ -0,0 +1,108 @@
+package restgen
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateOpenApi(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/product.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	api, err := ParseOpenApi(data, &OpenApiOption{Package: "product", Name: "ProductApi", ConfigName: "product", Timeout: 2 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := Generate(api)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//生成内容需与 go:generate 生成的示例一致,修改生成规则后需在 internal/product 执行 go generate
+	expect, err := ioutil.ReadFile("internal/product/product_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(code) != string(expect) {
+		t.Error("generate code not match internal/product/product_gen.go")
+	}
+	for _, find := range []string{"ProductDetail = iota", `Method:     "detail"`, "3 * time.Second", `GetStruct("data", out)`, "rest_client.QueryParams"} {
+		if !strings.Contains(string(code), find) {
+			t.Error("generate code miss:" + find)
+		}
+	}
+}
+
+func TestGenerateDuplicate(t *testing.T) {
+	_, err := Generate(&Api{Package: "a", Name: "A", ConfigName: "a", Operations: []*Operation{{Name: "B"}, {Name: "B"}}})
+	if err == nil {
+		t.Error("duplicate name not error")
+	}
+}
+
+func TestGoName(t *testing.T) {
+	for name, expect := range map[string]string{
+		"product_detail": "ProductDetail",
+		"get /product":   "GetProduct",
+		"skuList":        "SkuList",
+		"1st":            "X1st",
+	} {
+		if GoName(name) != expect {
+			t.Error("go name error:" + name + " " + GoName(name))
+		}
+	}
+	if durationLiteral(1500*time.Millisecond) != "1500 * time.Millisecond" || durationLiteral(2*time.Minute) != "2 * time.Minute" {
+		t.Error("duration literal error")
+	}
+}
+
+func TestGenerateYaml(t *testing.T) {
+	data, err := ioutil.ReadFile("internal/dome/dome.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	api, err := ParseYaml(data, "dome")
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := Generate(api)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect, err := ioutil.ReadFile("internal/dome/dome_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(code) != string(expect) {
+		t.Error("generate code not match internal/dome/dome_gen.go")
+	}
+	for _, find := range []string{"Skus []*ProductSku", "SkuID int64", "100 * time.Second", "TokenFunc"} {
+		if !strings.Contains(string(code), find) {
+			t.Error("generate code miss:" + find)
+		}
+	}
+	if _, err = ParseYaml([]byte("apis:\n  - path: /a\n"), "dome"); err == nil {
+		t.Error("api without name not error")
+	}
+	if _, err = ParseYaml([]byte("timeout: 1x\n"), "dome"); err == nil {
+		t.Error("timeout error not error")
+	}
+}
+
+func TestYamlGoType(t *testing.T) {
+	types := map[string]bool{"Product": true}
+	for goType, expect := range map[string]string{
+		"Product":                 "*Product",
+		"[]Product":               "[]*Product",
+		"map[string][]Product":    "map[string][]*Product",
+		"*Product":                "*Product",
+		"rest_client.QueryParams": "rest_client.QueryParams",
+		"[]string":                "[]string",
+	} {
+		if yamlGoType(goType, types) != expect {
+			t.Error("yaml go type error:" + goType)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package restgen
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"strings"
+	"time"
+)
+
+// yamlApi YAML定义的服务
+//
+//	package: product
+//	name: ProductApi
+//	config: product
+//	token: true
+//	timeout: 2s
+//	types:
+//	  - name: Product
+//	    doc: 商品
+//	    fields:
+//	      - {name: id, type: int64, required: true}
+//	      - {name: skus, type: "[]*ProductSku"}
+//	apis:
+//	  - name: ProductDetail
+//	    doc: 商品详情
+//	    path: /product
+//	    http_method: GET
+//	    method: detail
+//	    timeout: 3s
+//	    param: ProductDetailParam
+//	    response: Product
+type yamlApi struct {
+	Package string      `yaml:"package"`
+	Name    string      `yaml:"name"`
+	Config  string      `yaml:"config"`
+	Token   bool        `yaml:"token"`
+	Timeout string      `yaml:"timeout"` //接口未设置超时时使用
+	Types   []yamlType  `yaml:"types"`
+	Apis    []yamlBuild `yaml:"apis"`
+}
+
+type yamlType struct {
+	Name   string      `yaml:"name"`
+	Doc    string      `yaml:"doc"`
+	Fields []yamlField `yaml:"fields"`
+}
+
+type yamlField struct {
+	Name     string `yaml:"name"` //JSON名
+	Go       string `yaml:"go"`   //Go字段名,为空时按JSON名转换
+	Type     string `yaml:"type"` //Go类型,定义的结构可直接使用名称,如 Product []Product
+	Doc      string `yaml:"doc"`
+	Required bool   `yaml:"required"`
+}
+
+type yamlBuild struct {
+	Name       string `yaml:"name"`
+	Doc        string `yaml:"doc"`
+	Path       string `yaml:"path"`
+	HttpMethod string `yaml:"http_method"`
+	Method     string `yaml:"method"`
+	Timeout    string `yaml:"timeout"`
+	Raw        bool   `yaml:"raw"`
+	Param      string `yaml:"param"`    //参数类型,定义的结构可直接使用名称
+	Response   string `yaml:"response"` //返回类型,设置后生成类型化的调用
+}
+
+// ParseYaml 读取YAML格式的服务定义,package 为空时使用 pkg
+func ParseYaml(data []byte, pkg string) (*Api, error) {
+	def := &yamlApi{}
+	if err := yaml.Unmarshal(data, def); err != nil {
+		return nil, err
+	}
+	api := &Api{Package: def.Package, Name: def.Name, ConfigName: def.Config, Token: def.Token}
+	if len(api.Package) == 0 {
+		api.Package = pkg
+	}
+	timeout, err := parseTimeout(def.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("timeout error:%w", err)
+	}
+	types := map[string]bool{}
+	for _, typ := range def.Types {
+		types[typ.Name] = true
+	}
+	for _, typ := range def.Types {
+		out := &Type{Name: typ.Name, Doc: typ.Doc}
+		for _, field := range typ.Fields {
+			name := field.Go
+			if len(name) == 0 {
+				name = GoName(field.Name)
+			}
+			out.Fields = append(out.Fields, &Field{
+				Name:     name,
+				Type:     yamlGoType(field.Type, types),
+				Json:     field.Name,
+				Doc:      field.Doc,
+				Required: field.Required,
+			})
+		}
+		api.Types = append(api.Types, out)
+	}
+	for _, build := range def.Apis {
+		op := &Operation{
+			Name:       build.Name,
+			Doc:        build.Doc,
+			Path:       build.Path,
+			HttpMethod: build.HttpMethod,
+			Method:     build.Method,
+			Timeout:    timeout,
+			Raw:        build.Raw,
+			Param:      yamlGoType(build.Param, types),
+			Response:   strings.TrimPrefix(build.Response, "*"),
+		}
+		if len(op.Name) == 0 {
+			return nil, fmt.Errorf("api name is required:%s", build.Path)
+		}
+		if len(build.Timeout) > 0 {
+			if op.Timeout, err = parseTimeout(build.Timeout); err != nil {
+				return nil, fmt.Errorf("%s timeout error:%w", build.Name, err)
+			}
+		}
+		api.Operations = append(api.Operations, op)
+	}
+	return api, nil
+}
+
+func parseTimeout(timeout string) (time.Duration, error) {
+	if len(timeout) == 0 {
+		return 0, nil
+	}
+	return time.ParseDuration(timeout)
+}
+
+// yamlGoType 定义的结构名转为指针类型,如 Product 转为 *Product,[]Product 转为 []*Product
+func yamlGoType(goType string, types map[string]bool) string {
+	prefix := ""
+	for {
+		switch {
+		case strings.HasPrefix(goType, "[]"):
+			prefix += "[]"
+			goType = goType[2:]
+			continue
+		case strings.HasPrefix(goType, "map[string]"):
+			prefix += "map[string]"
+			goType = goType[len("map[string]"):]
+			continue
+		}
+		break
+	}
+	if types[goType] {
+		goType = "*" + goType
+	}
+	return prefix + goType
+}
@@ -0,0 +1,244 @@
+package resttest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/hsbteam/rest_client"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// contractTimestamp 签名参数中 timestamp 在 golden 中的占位
+const contractTimestamp = "<timestamp>"
+
+// contractIgnoreHeaders 每次请求不同,由 net/http 或运行环境生成的HEADER,不比较
+var contractIgnoreHeaders = []string{"Accept-Encoding", "Content-Length", "Traceparent", "Tracestate", "User-Agent", "X-Client-Instance", "X-Client-Name", "X-Client-Version", "X-Request-Id", "X-Sign", "X-Timestamp"}
+
+// Contract 单个接口的契约用例
+type Contract struct {
+	Name     string      //用例名,golden 文件为 <Dir>/<Name>.json
+	Key      int         //接口KEY
+	Param    interface{} //请求参数
+	Response *Fixture    //录制时的返回,默认成功且 data 为空对象
+}
+
+// ContractRequest 请求形态,签名格式时 Params 不含 sign,timestamp 为占位
+type ContractRequest struct {
+	HttpMethod string            `json:"http_method"`
+	Path       string            `json:"path"`
+	Params     map[string]string `json:"params,omitempty"`    //签名参数
+	SignBase   string            `json:"sign_base,omitempty"` //签名原文,拼接密钥后MD5
+	Query      string            `json:"query,omitempty"`     //Raw 请求的查询参数
+	Body       string            `json:"body,omitempty"`      //Raw 请求内容
+	Header     map[string]string `json:"header,omitempty"`
+}
+
+// ContractResponse 录制的返回及解析结果
+type ContractResponse struct {
+	HttpCode int               `json:"http_code"`
+	Header   map[string]string `json:"header,omitempty"`
+	Body     string            `json:"body"`
+	Error    string            `json:"error,omitempty"` //JsonResult 的错误,成功时为空
+}
+
+// contractGolden golden 文件内容
+type contractGolden struct {
+	Config   string           `json:"config"`
+	Key      int              `json:"key"`
+	Request  ContractRequest  `json:"request"`
+	Response ContractResponse `json:"response"`
+}
+
+// ContractHarness 按 golden 文件检查接口请求形态,用于发布前发现签名,参数编码及返回解析的不兼容修改
+// golden 不存在或 Update 时按当前请求录制,否则比较请求形态,并使用录制的返回检查解析结果
+type ContractHarness struct {
+	Api           rest_client.RestApi
+	Config        *rest_client.AppRestConfig //服务配置,AppUrl 替换为本地地址
+	Dir           string                     //golden 目录,默认 testdata/contract
+	Update        bool                       //重新录制,也可设置环境变量 RESTTEST_UPDATE=1
+	IgnoreHeaders []string                   //不比较的HEADER
+}
+
+// Run 按用例名执行子测试
+func (harness *ContractHarness) Run(t *testing.T, contracts ...*Contract) {
+	for _, contract := range contracts {
+		contract := contract
+		t.Run(contract.Name, func(t *testing.T) {
+			if err := harness.Check(contract); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func (harness *ContractHarness) path(contract *Contract) string {
+	dir := harness.Dir
+	if len(dir) == 0 {
+		dir = filepath.Join("testdata", "contract")
+	}
+	return filepath.Join(dir, contract.Name+".json")
+}
+
+// Check 执行单个用例,请求形态或解析结果与 golden 不一致时返回错误
+func (harness *ContractHarness) Check(contract *Contract) error {
+	ctx := context.Background()
+	builds, err := harness.Api.ConfigBuilds(ctx)
+	if err != nil {
+		return err
+	}
+	build, ok := builds[contract.Key].(*rest_client.AppRestBuild)
+	if !ok {
+		return fmt.Errorf("key:%d is not AppRestBuild", contract.Key)
+	}
+	file := harness.path(contract)
+	update := harness.Update || os.Getenv("RESTTEST_UPDATE") == "1"
+	var golden *contractGolden
+	if !update {
+		if data, err := ioutil.ReadFile(file); err == nil {
+			golden = &contractGolden{}
+			if err = json.Unmarshal(data, golden); err != nil {
+				return fmt.Errorf("golden %s error:%w", file, err)
+			}
+		}
+	}
+
+	var lock sync.Mutex
+	var request *ContractRequest
+	var requestErr error
+	response := harness.recordResponse(contract, build.Raw)
+	if golden != nil {
+		response = golden.Response
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := harness.readRequest(r, build.Raw)
+		lock.Lock()
+		request, requestErr = req, err
+		lock.Unlock()
+		for key, val := range response.Header {
+			w.Header().Set(key, val)
+		}
+		w.WriteHeader(response.HttpCode)
+		_, _ = w.Write([]byte(response.Body))
+	}))
+	defer server.Close()
+
+	config := *harness.Config
+	config.AppUrl = server.URL
+	manager := rest_client.NewRestClientManager()
+	defer manager.Close()
+	manager.SetRestConfig(&config)
+	res, err := manager.NewApi(harness.Api).DoSync(ctx, contract.Key, contract.Param)
+	if err != nil {
+		return err
+	}
+	if err = res.JsonResult().Err(); err != nil {
+		response.Error = err.Error()
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if requestErr != nil {
+		return requestErr
+	}
+	if request == nil {
+		return fmt.Errorf("request not send")
+	}
+
+	configName, _ := harness.Api.ConfigName(ctx)
+	actual := &contractGolden{Config: configName, Key: contract.Key, Request: *request, Response: response}
+	if golden == nil {
+		if err = os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			return err
+		}
+		return ioutil.WriteFile(file, contractJson(actual), 0644)
+	}
+	expect, got := contractJson(golden.Request), contractJson(actual.Request)
+	if string(expect) != string(got) {
+		return fmt.Errorf("request drift from %s:\nexpect:%s\nactual:%s", file, expect, got)
+	}
+	if golden.Response.Error != response.Error {
+		return fmt.Errorf("response result drift from %s:\nexpect:%q\nactual:%q", file, golden.Response.Error, response.Error)
+	}
+	return nil
+}
+
+// contractJson golden 使用的JSON格式,不转义HTML字符便于阅读
+func contractJson(val interface{}) []byte {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(val)
+	return buf.Bytes()
+}
+
+// recordResponse 录制时返回的内容
+func (harness *ContractHarness) recordResponse(contract *Contract, raw bool) ContractResponse {
+	fixture := contract.Response
+	if fixture == nil {
+		fixture = &Fixture{Data: map[string]interface{}{}}
+	}
+	body, _ := fixture.body(raw)
+	response := ContractResponse{HttpCode: fixture.HttpCode, Body: string(body), Header: map[string]string{"Content-Type": "application/json"}}
+	if response.HttpCode == 0 {
+		response.HttpCode = http.StatusOK
+	}
+	for key := range fixture.Header {
+		response.Header[key] = fixture.Header.Get(key)
+	}
+	return response
+}
+
+// readRequest 读取请求形态,签名格式时校验签名
+func (harness *ContractHarness) readRequest(r *http.Request, raw bool) (*ContractRequest, error) {
+	req := &ContractRequest{HttpMethod: r.Method, Path: r.URL.Path, Header: map[string]string{}}
+	ignore := map[string]bool{}
+	for _, key := range append(append([]string{}, contractIgnoreHeaders...), harness.IgnoreHeaders...) {
+		ignore[http.CanonicalHeaderKey(key)] = true
+	}
+	for key := range r.Header {
+		if !ignore[key] {
+			req.Header[key] = r.Header.Get(key)
+		}
+	}
+	if raw {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Query, req.Body = r.URL.RawQuery, string(body)
+		return req, nil
+	}
+	form := readForm(r)
+	if !rest_client.AppRestCheckSign(form, harness.Config.AppSecret) {
+		return nil, fmt.Errorf("sign check fail")
+	}
+	form.Set("timestamp", contractTimestamp)
+	form.Del("sign")
+	req.Params = map[string]string{}
+	keys := make([]string, 0, len(form))
+	for key := range form {
+		keys = append(keys, key)
+		req.Params[key] = form.Get(key)
+	}
+	sort.Strings(keys)
+	base := make([]string, 0, len(keys))
+	for _, key := range keys {
+		val := url.QueryEscape(form.Get(key))
+		if key == "timestamp" {
+			val = contractTimestamp
+		}
+		base = append(base, url.QueryEscape(key)+"="+val)
+	}
+	req.SignBase = strings.Join(base, "&")
+	return req, nil
+}
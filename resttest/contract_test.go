@@ -0,0 +1,50 @@
+package resttest
+
+import (
+	"github.com/hsbteam/rest_client"
+	"strings"
+	"testing"
+)
+
+func newTestContractHarness(dir string) *ContractHarness {
+	return &ContractHarness{
+		Api:    &testApi{},
+		Config: &rest_client.AppRestConfig{Name: "product", AppKey: "app", AppSecret: "secret", Headers: map[string]string{"X-Env": "test"}},
+		Dir:    dir,
+	}
+}
+
+func TestContractHarness(t *testing.T) {
+	newTestContractHarness("").Run(t,
+		&Contract{Name: "product_detail", Key: 1, Param: map[string]string{"id": "1"}, Response: &Fixture{Data: map[string]string{"name": "phone"}}},
+		&Contract{Name: "product_add_fail", Key: 2, Param: map[string]interface{}{"name": "phone", "tags": []string{"a&b"}}, Response: &Fixture{Code: "500", State: "fail", Message: "stock error"}},
+	)
+}
+
+func TestContractHarnessDrift(t *testing.T) {
+	harness := newTestContractHarness(t.TempDir())
+	contract := &Contract{Name: "detail", Key: 1, Param: map[string]string{"id": "1"}}
+	if err := harness.Check(contract); err != nil {
+		t.Fatal(err)
+	}
+	if err := harness.Check(contract); err != nil {
+		t.Error("same request drift:", err)
+	}
+
+	harness.Config.DefaultParams = map[string]interface{}{"channel": "app"}
+	if err := harness.Check(contract); err == nil || !strings.Contains(err.Error(), "request drift") {
+		t.Error("param drift not find:", err)
+	}
+	harness.Config.DefaultParams = nil
+	harness.Config.Headers = map[string]string{"X-Env": "prod"}
+	if err := harness.Check(contract); err == nil || !strings.Contains(err.Error(), "request drift") {
+		t.Error("header drift not find:", err)
+	}
+	harness.Config.Headers = map[string]string{"X-Env": "test"}
+	if err := harness.Check(&Contract{Name: "detail", Key: 1, Param: map[string]string{"id": "2"}}); err == nil {
+		t.Error("content drift not find")
+	}
+	if err := harness.Check(&Contract{Name: "none", Key: 3}); err == nil {
+		t.Error("missing key not error")
+	}
+}
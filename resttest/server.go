@@ -0,0 +1,166 @@
+// Package resttest 提供接口测试用的模拟网关
+package resttest
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/hsbteam/rest_client"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Fixture 模拟的接口返回
+type Fixture struct {
+	HttpCode int         //HTTP状态码,默认200
+	Code     string      //result.code,默认200
+	State    string      //result.state,默认ok
+	Message  string      //result.message
+	Data     interface{} //data节点内容,已序列化的JSON使用 json.RawMessage
+	Header   http.Header //返回HEADER
+}
+
+// body 生成返回内容,Raw接口直接返回data内容
+func (fix *Fixture) body(raw bool) ([]byte, error) {
+	if raw {
+		if data, ok := fix.Data.(json.RawMessage); ok {
+			return data, nil
+		}
+		return json.Marshal(fix.Data)
+	}
+	code := fix.Code
+	if len(code) == 0 {
+		code = "200"
+	}
+	state := fix.State
+	if len(state) == 0 {
+		state = "ok"
+	}
+	return json.Marshal(map[string]interface{}{
+		"result": map[string]string{
+			"code":    code,
+			"state":   state,
+			"message": fix.Message,
+		},
+		"data": fix.Data,
+	})
+}
+
+// ApiServer 按 RestApi 的接口定义模拟网关
+// 请求按 HTTP方式,路径,接口名 匹配到接口KEY,校验签名后返回该KEY设置的 Fixture
+type ApiServer struct {
+	*httptest.Server
+	config   rest_client.AppRestConfig
+	builds   map[int]*rest_client.AppRestBuild
+	lock     sync.RWMutex
+	fixtures map[int]*Fixture
+}
+
+// NewApiServer 创建模拟网关,config 中的 AppUrl 会被替换为模拟网关地址
+func NewApiServer(ctx context.Context, api rest_client.RestApi, config *rest_client.AppRestConfig) (*ApiServer, error) {
+	builds, err := api.ConfigBuilds(ctx)
+	if err != nil {
+		return nil, err
+	}
+	server := &ApiServer{
+		builds:   map[int]*rest_client.AppRestBuild{},
+		fixtures: map[int]*Fixture{},
+	}
+	for key, build := range builds {
+		if appBuild, ok := build.(*rest_client.AppRestBuild); ok {
+			server.builds[key] = appBuild
+		}
+	}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.serve))
+	server.config = *config
+	server.config.AppUrl = server.URL
+	return server, nil
+}
+
+// Config 指向模拟网关的服务配置,用于 RestClientManager.SetRestConfig
+func (server *ApiServer) Config() *rest_client.AppRestConfig {
+	config := server.config
+	return &config
+}
+
+// SetFixture 设置接口返回
+func (server *ApiServer) SetFixture(key int, fixture *Fixture) *ApiServer {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	server.fixtures[key] = fixture
+	return server
+}
+
+func httpMethod(method string) string {
+	if len(method) == 0 {
+		return http.MethodPost
+	}
+	return strings.ToUpper(method)
+}
+
+// match 匹配请求对应的接口
+func (server *ApiServer) match(r *http.Request, form url.Values) (int, *rest_client.AppRestBuild, bool) {
+	for key, build := range server.builds {
+		if httpMethod(build.HttpMethod) != r.Method || build.Path != r.URL.Path {
+			continue
+		}
+		if !build.Raw && build.Method != form.Get("method") {
+			continue
+		}
+		return key, build, true
+	}
+	return 0, nil, false
+}
+
+// readForm 读取签名参数,请求方式不限(DELETE等请求Go不解析内容)
+func readForm(r *http.Request) url.Values {
+	form := r.URL.Query()
+	body, _ := ioutil.ReadAll(r.Body)
+	if values, err := url.ParseQuery(string(body)); err == nil {
+		for key, val := range values {
+			form[key] = val
+		}
+	}
+	return form
+}
+
+func (server *ApiServer) serve(w http.ResponseWriter, r *http.Request) {
+	form := readForm(r)
+	key, build, ok := server.match(r, form)
+	if !ok {
+		writeFixture(w, &Fixture{Code: "404", State: "fail", Message: "not find api:" + r.Method + " " + r.URL.Path}, false)
+		return
+	}
+	if !build.Raw {
+		if form.Get("app") != server.config.AppKey || !rest_client.AppRestCheckSign(form, server.config.AppSecret) {
+			writeFixture(w, &Fixture{Code: "403", State: "fail", Message: "sign error"}, false)
+			return
+		}
+	}
+	server.lock.RLock()
+	fixture, ok := server.fixtures[key]
+	server.lock.RUnlock()
+	if !ok {
+		fixture = &Fixture{Data: map[string]interface{}{}}
+	}
+	writeFixture(w, fixture, build.Raw)
+}
+
+func writeFixture(w http.ResponseWriter, fixture *Fixture, raw bool) {
+	body, err := fixture.body(raw)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	for key, val := range fixture.Header {
+		w.Header()[key] = val
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if fixture.HttpCode > 0 {
+		w.WriteHeader(fixture.HttpCode)
+	}
+	_, _ = w.Write(body)
+}
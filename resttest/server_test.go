@@ -0,0 +1,50 @@
+package resttest
+
+import (
+	"context"
+	"github.com/hsbteam/rest_client"
+	"net/http"
+	"testing"
+)
+
+type testApi struct{}
+
+func (api *testApi) ConfigBuilds(_ context.Context) (map[int]rest_client.RestBuild, error) {
+	return map[int]rest_client.RestBuild{
+		1: &rest_client.AppRestBuild{HttpMethod: http.MethodGet, Path: "/product", Method: "detail"},
+		2: &rest_client.AppRestBuild{HttpMethod: http.MethodPost, Path: "/product", Method: "add"},
+	}, nil
+}
+
+func (api *testApi) ConfigName(_ context.Context) (string, error) {
+	return "product", nil
+}
+
+func TestApiServer(t *testing.T) {
+	config := &rest_client.AppRestConfig{Name: "product", AppKey: "app", AppSecret: "secret"}
+	server, err := NewApiServer(context.Background(), &testApi{}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	server.SetFixture(1, &Fixture{Data: map[string]string{"name": "phone"}})
+	server.SetFixture(2, &Fixture{Code: "500", State: "fail", Message: "stock error"})
+
+	manager := rest_client.NewRestClientManager()
+	manager.SetRestConfig(server.Config())
+	client := manager.NewApi(&testApi{})
+	res := (<-client.Do(context.Background(), 1, map[string]string{"id": "1"})).JsonResult()
+	if res.MustString("data.name") != "phone" {
+		t.Error("fixture data error")
+	}
+	if (<-client.Do(context.Background(), 2, nil)).JsonResult().Err() == nil {
+		t.Error("fixture fail error")
+	}
+
+	wrong := server.Config()
+	wrong.AppSecret = "wrong"
+	manager.SetRestConfig(wrong)
+	if (<-client.Do(context.Background(), 1, nil)).JsonResult().Err() == nil {
+		t.Error("sign check error")
+	}
+}
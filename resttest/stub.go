@@ -0,0 +1,144 @@
+package resttest
+
+import (
+	"github.com/hsbteam/rest_client"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// StubCall 模拟网关收到的请求
+type StubCall struct {
+	HttpMethod string
+	Path       string
+	Method     string      //签名参数中的接口名称
+	Content    string      //签名参数中的 content
+	Form       url.Values  //全部签名参数
+	Header     http.Header //请求HEADER
+	Error      string      //校验失败的原因,通过时为空
+	Time       time.Time
+}
+
+// StubServer 按签名参数中的接口名称返回设置内容的模拟网关,不依赖 RestApi 定义
+// 校验 app sign timestamp 及接口名称,并记录全部收到的请求用于断言
+type StubServer struct {
+	*httptest.Server
+	AppKey    string
+	AppSecret string
+	MaxSkew   time.Duration //timestamp 与当前时间允许的最大差值,默认5分钟
+	lock      sync.Mutex
+	handlers  map[string]func(call *StubCall) *Fixture
+	calls     []*StubCall
+}
+
+// NewStubServer 创建模拟网关,使用 Config 获取指向该网关的服务配置
+func NewStubServer(appKey, appSecret string) *StubServer {
+	stub := &StubServer{
+		AppKey:    appKey,
+		AppSecret: appSecret,
+		MaxSkew:   5 * time.Minute,
+		handlers:  map[string]func(call *StubCall) *Fixture{},
+	}
+	stub.Server = httptest.NewServer(http.HandlerFunc(stub.serve))
+	return stub
+}
+
+// Config 指向模拟网关的服务配置
+func (stub *StubServer) Config(name string) *rest_client.AppRestConfig {
+	return &rest_client.AppRestConfig{Name: name, AppKey: stub.AppKey, AppSecret: stub.AppSecret, AppUrl: stub.URL}
+}
+
+// Handle 设置接口名称的返回
+func (stub *StubServer) Handle(method string, fixture *Fixture) *StubServer {
+	return stub.HandleFunc(method, func(_ *StubCall) *Fixture {
+		return fixture
+	})
+}
+
+// HandleFunc 按请求生成接口名称的返回
+func (stub *StubServer) HandleFunc(method string, handler func(call *StubCall) *Fixture) *StubServer {
+	stub.lock.Lock()
+	defer stub.lock.Unlock()
+	stub.handlers[method] = handler
+	return stub
+}
+
+// Calls 收到的请求,传入接口名称时仅返回这些接口的请求
+func (stub *StubServer) Calls(methods ...string) []*StubCall {
+	stub.lock.Lock()
+	defer stub.lock.Unlock()
+	if len(methods) == 0 {
+		return append([]*StubCall{}, stub.calls...)
+	}
+	var out []*StubCall
+	for _, call := range stub.calls {
+		for _, method := range methods {
+			if call.Method == method {
+				out = append(out, call)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// Reset 清空记录的请求
+func (stub *StubServer) Reset() {
+	stub.lock.Lock()
+	defer stub.lock.Unlock()
+	stub.calls = nil
+}
+
+// check 校验签名参数,返回失败原因
+func (stub *StubServer) check(form url.Values) string {
+	if form.Get("app") != stub.AppKey {
+		return "app error"
+	}
+	timestamp, err := time.ParseInLocation("2006-01-02 15:04:05", form.Get("timestamp"), time.Local)
+	if err != nil {
+		return "timestamp error"
+	}
+	if skew := time.Since(timestamp); stub.MaxSkew > 0 && (skew > stub.MaxSkew || skew < -stub.MaxSkew) {
+		return "timestamp expired"
+	}
+	if !rest_client.AppRestCheckSign(form, stub.AppSecret) {
+		return "sign error"
+	}
+	return ""
+}
+
+func (stub *StubServer) serve(w http.ResponseWriter, r *http.Request) {
+	form := readForm(r)
+	call := &StubCall{
+		HttpMethod: r.Method,
+		Path:       r.URL.Path,
+		Method:     form.Get("method"),
+		Content:    form.Get("content"),
+		Form:       form,
+		Header:     r.Header.Clone(),
+		Time:       time.Now(),
+	}
+	call.Error = stub.check(form)
+	stub.lock.Lock()
+	handler, ok := stub.handlers[call.Method]
+	notFound := len(call.Error) == 0 && !ok
+	if notFound {
+		call.Error = "not find method:" + call.Method
+	}
+	stub.calls = append(stub.calls, call)
+	stub.lock.Unlock()
+	switch {
+	case notFound:
+		writeFixture(w, &Fixture{Code: "404", State: "fail", Message: call.Error}, false)
+	case len(call.Error) > 0:
+		writeFixture(w, &Fixture{Code: "403", State: "fail", Message: call.Error}, false)
+	default:
+		fixture := handler(call)
+		if fixture == nil {
+			fixture = &Fixture{Data: map[string]interface{}{}}
+		}
+		writeFixture(w, fixture, false)
+	}
+}
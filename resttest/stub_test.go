@@ -0,0 +1,57 @@
+package resttest
+
+import (
+	"context"
+	"github.com/hsbteam/rest_client"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestStubServer(t *testing.T) {
+	stub := NewStubServer("app", "secret")
+	defer stub.Close()
+	stub.Handle("detail", &Fixture{Data: map[string]string{"name": "phone"}})
+	stub.HandleFunc("add", func(call *StubCall) *Fixture {
+		return &Fixture{Data: map[string]string{"content": call.Content}}
+	})
+
+	manager := rest_client.NewRestClientManager()
+	manager.SetRestConfig(stub.Config("product"))
+	client := manager.NewApi(&testApi{})
+	res := (<-client.Do(context.Background(), 1, map[string]string{"id": "1"})).JsonResult()
+	if res.MustString("data.name") != "phone" {
+		t.Error("stub fixture error")
+	}
+	res = (<-client.Do(context.Background(), 2, map[string]string{"name": "a"})).JsonResult()
+	if res.MustString("data.content") != `{"name":"a"}` {
+		t.Error("stub handler error")
+	}
+	calls := stub.Calls("add")
+	if len(calls) != 1 || calls[0].HttpMethod != "POST" || calls[0].Path != "/product" || len(calls[0].Error) > 0 {
+		t.Error("stub calls error")
+	}
+	if len(stub.Calls()) != 2 {
+		t.Error("stub all calls error")
+	}
+
+	stub.Reset()
+	wrong := stub.Config("product")
+	wrong.AppSecret = "wrong"
+	manager.SetRestConfig(wrong)
+	if (<-client.Do(context.Background(), 1, nil)).JsonResult().Err() == nil {
+		t.Error("stub sign check error")
+	}
+	if calls = stub.Calls(); len(calls) != 1 || calls[0].Error != "sign error" {
+		t.Error("stub sign error not record")
+	}
+
+	form := url.Values{"app": {"app"}, "timestamp": {time.Now().Add(-time.Hour).Format("2006-01-02 15:04:05")}}
+	if stub.check(form) != "timestamp expired" {
+		t.Error("stub timestamp check error")
+	}
+	form.Set("timestamp", "now")
+	if stub.check(form) != "timestamp error" {
+		t.Error("stub timestamp format error")
+	}
+}
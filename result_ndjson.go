@@ -0,0 +1,70 @@
+package rest_client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/tidwall/gjson"
+	"io"
+)
+
+// NdjsonResult 按行读取换行分隔的JSON(NDJSON),每行解析后发送到通道,不缓存整个返回内容
+// 空行忽略,某行不是合法JSON或读取出错时发送带错误的结果后关闭通道
+// ctx结束时停止读取,读取未完成时需取消ctx以释放连接
+func (res *RestResult) NdjsonResult(ctx context.Context) <-chan *JsonResult {
+	out := make(chan *JsonResult)
+	go func() {
+		defer close(out)
+		send := func(item *JsonResult) bool {
+			select {
+			case out <- item:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		if res == nil {
+			send(NewJsonResultFromError(errResultClosed()))
+			return
+		}
+		if res.err != nil {
+			send(NewJsonResultFromError(res.err))
+			return
+		}
+		var err error
+		defer func() {
+			if res.response != nil {
+				_ = res.response.Body.Close()
+			}
+			if res.event != nil {
+				res.event.ResponseCheck(err)
+			}
+		}()
+		reader := bufio.NewReader(res)
+		for num := 1; ; num++ {
+			line, rErr := reader.ReadBytes('\n')
+			line = bytes.TrimSpace(line)
+			if len(line) > 0 {
+				if !gjson.ValidBytes(line) {
+					err = NewRestClientError(ErrJsonValid, fmt.Sprintf("ndjson line %d is not valid json", num))
+					send(NewJsonResultFromError(err))
+					return
+				}
+				if !send(NewJsonResult(string(line), "")) {
+					err = cancelError(ctx.Err())
+					return
+				}
+			}
+			if rErr == io.EOF {
+				return
+			}
+			if rErr != nil {
+				err = rErr
+				send(NewJsonResultFromError(err))
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,53 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRestResultNdjson(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte("{\"id\":1}\n\n{\"id\":2}\r\n"))
+		w.(http.Flusher).Flush()
+		if r.URL.Path == "/bad" {
+			_, _ = w.Write([]byte("{\"id\":\n"))
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"id":3}`))
+	}))
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true},
+		2: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true, Path: "/bad"},
+	})
+	var ids []int64
+	for line := range (<-client.Do(context.Background(), 1, nil)).NdjsonResult(context.Background()) {
+		if line.Err() != nil {
+			t.Fatal(line.Err())
+		}
+		ids = append(ids, line.MustInt("id"))
+	}
+	if len(ids) != 3 || ids[0] != 1 || ids[2] != 3 {
+		t.Error("ndjson lines wrong", ids)
+	}
+	var lastErr error
+	count := 0
+	for line := range (<-client.Do(context.Background(), 2, nil)).NdjsonResult(context.Background()) {
+		count++
+		lastErr = line.Err()
+	}
+	if count != 3 || ErrorCode(lastErr) != ErrJsonValid {
+		t.Error("ndjson invalid line not return error")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	lines := (<-client.Do(context.Background(), 1, nil)).NdjsonResult(ctx)
+	<-lines
+	cancel()
+	for range lines {
+	}
+}
@@ -0,0 +1,79 @@
+package rest_client
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// RestProxyOption 返回内容透传配置
+type RestProxyOption struct {
+	Headers    []string                 //需要复制的返回HEADER,为空时复制 Content-Type
+	Rewrite    func(header http.Header) //写入HEADER前修改,可增加或改写HEADER
+	StatusCode int                      //为0时使用下游的HTTP状态码
+}
+
+// ProxyTo 将返回内容边读边写透传到 w,用于BFF服务直接转发下游返回
+// 写入的同时保留一份内容用于 CheckJsonResult 检测,检测在写入完成后执行,
+// 此时内容已发送,检测失败只能通过返回错误告知调用方(如记录日志)
+// 请求失败时返回 502 状态码
+func (res *RestResult) ProxyTo(w http.ResponseWriter, option *RestProxyOption) error {
+	if option == nil {
+		option = &RestProxyOption{}
+	}
+	if res == nil {
+		err := errResultClosed()
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return err
+	}
+	if res.err != nil {
+		http.Error(w, res.err.Error(), http.StatusBadGateway)
+		return res.err
+	}
+	header := w.Header()
+	statusCode := http.StatusOK
+	if res.response != nil {
+		statusCode = res.response.StatusCode
+		names := option.Headers
+		if len(names) == 0 {
+			names = []string{"Content-Type"}
+		}
+		for _, name := range names {
+			if val, ok := res.response.Header[http.CanonicalHeaderKey(name)]; ok {
+				header[http.CanonicalHeaderKey(name)] = val
+			}
+		}
+	}
+	if option.Rewrite != nil {
+		option.Rewrite(header)
+	}
+	if option.StatusCode > 0 {
+		statusCode = option.StatusCode
+	}
+	w.WriteHeader(statusCode)
+
+	needCheck := res.needCheck()
+	var writer io.Writer = w
+	var body *bytes.Buffer
+	if needCheck {
+		body = getBuffer()
+		defer putBuffer(body)
+		writer = io.MultiWriter(w, body)
+	}
+	if _, err := io.Copy(writer, res); err != nil {
+		if res.err == nil {
+			res.err = err
+		}
+		return err
+	}
+	if needCheck {
+		var checkBody string
+		if checkBody, res.err = res.transform(res.sanitize(body.String())); res.err == nil {
+			res.err = res.checkResult(checkBody)
+		}
+	}
+	if res.event != nil {
+		res.event.ResponseCheck(res.err)
+	}
+	return res.err
+}
@@ -0,0 +1,39 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRestResultProxyTo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Internal", "1")
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":{"id":1}}`))
+	}))
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodPost},
+	})
+	w := httptest.NewRecorder()
+	err := (<-client.Do(context.Background(), 1, nil)).ProxyTo(w, &RestProxyOption{
+		Rewrite: func(header http.Header) {
+			header.Set("X-Proxy", "bff")
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w.Header().Get("Content-Type") != "application/json" || w.Header().Get("X-Internal") != "" || w.Header().Get("X-Proxy") != "bff" {
+		t.Error("proxy header error")
+	}
+	if w.Body.String() != `{"result":{"code":"200","state":"ok"},"data":{"id":1}}` {
+		t.Error("proxy body error")
+	}
+	w = httptest.NewRecorder()
+	if err = NewRestResultFromError(NewRestClientError("1", "fail"), nil).ProxyTo(w, nil); err == nil || w.Code != http.StatusBadGateway {
+		t.Error("proxy error result wrong")
+	}
+}
@@ -0,0 +1,147 @@
+package rest_client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/tidwall/gjson"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// RestSink 结果写入目标,如数据库批量写入
+// WriteBatch 返回前不会继续读取返回内容,写入慢时自然形成背压
+type RestSink interface {
+	WriteBatch(ctx context.Context, items []*JsonData) error
+}
+
+// RestSinkFunc 函数形式的写入目标
+type RestSinkFunc func(ctx context.Context, items []*JsonData) error
+
+func (fn RestSinkFunc) WriteBatch(ctx context.Context, items []*JsonData) error {
+	return fn(ctx, items)
+}
+
+// RestChanSink 写入通道的目标,通道满时阻塞
+type RestChanSink struct {
+	ch chan<- *JsonData
+}
+
+// NewRestChanSink 创建写入通道的目标,通道由调用方关闭
+func NewRestChanSink(ch chan<- *JsonData) *RestChanSink {
+	return &RestChanSink{ch: ch}
+}
+
+func (sink *RestChanSink) WriteBatch(ctx context.Context, items []*JsonData) error {
+	for _, item := range items {
+		select {
+		case sink.ch <- item:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// jsonStreamSeek 流式定位到对象路径,路径为 . 分隔的KEY
+func jsonStreamSeek(dec *json.Decoder, path string) error {
+	if len(path) == 0 {
+		return nil
+	}
+	for _, key := range strings.Split(path, ".") {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		if tok != json.Delim('{') {
+			return NewRestClientError(ErrPathNotExists, "path not exists:"+path)
+		}
+		find := false
+		for dec.More() {
+			tok, err = dec.Token()
+			if err != nil {
+				return err
+			}
+			if name, ok := tok.(string); ok && name == key {
+				find = true
+				break
+			}
+			var skip json.RawMessage
+			if err = dec.Decode(&skip); err != nil {
+				return err
+			}
+		}
+		if !find {
+			return NewRestClientError(ErrPathNotExists, "path not exists:"+path)
+		}
+	}
+	return nil
+}
+
+// SinkTo 流式解析返回内容中 path 节点数组的每个元素,按批写入目标,不缓存完整返回内容
+// 流式读取时不做返回内容的结构检测(如 CheckJsonResult),仅检测HTTP状态
+// @param path . 分隔的对象路径,传空表示返回内容为数组
+// @param batchSize 每批数量,小于1时为1
+// @return 写入的元素数量
+func (res *RestResult) SinkTo(ctx context.Context, path string, sink RestSink, batchSize int) (int, error) {
+	if res == nil {
+		return 0, errResultClosed()
+	}
+	if res.err != nil {
+		return 0, res.err
+	}
+	if res.response != nil && (res.response.StatusCode < 200 || res.response.StatusCode >= 300) {
+		res.err = NewRestClientError(ErrServerHttp, fmt.Sprintf("server http code:%d", res.response.StatusCode))
+		return 0, res.err
+	}
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	dec := json.NewDecoder(res)
+	if err := jsonStreamSeek(dec, path); err != nil {
+		return 0, err
+	}
+	tok, err := dec.Token()
+	if err != nil {
+		return 0, err
+	}
+	if tok != json.Delim('[') {
+		return 0, NewRestClientError(ErrTypeMismatch, fmt.Sprintf("path:%s not array", path))
+	}
+	total := 0
+	batch := make([]*JsonData, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if err := sink.WriteBatch(ctx, batch); err != nil {
+			return err
+		}
+		total += len(batch)
+		batch = make([]*JsonData, 0, batchSize)
+		return nil
+	}
+	for dec.More() {
+		if err = ctx.Err(); err != nil {
+			return total, err
+		}
+		var raw json.RawMessage
+		if err = dec.Decode(&raw); err != nil {
+			return total, err
+		}
+		item := gjson.ParseBytes(raw)
+		batch = append(batch, NewJsonData(&item))
+		if len(batch) >= batchSize {
+			if err = flush(); err != nil {
+				return total, err
+			}
+		}
+	}
+	if err = flush(); err != nil {
+		return total, err
+	}
+	//读取剩余内容以便复用连接
+	_, _ = io.Copy(ioutil.Discard, res)
+	return total, nil
+}
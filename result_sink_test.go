@@ -0,0 +1,54 @@
+package rest_client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRestResultSinkTo(t *testing.T) {
+	response := &http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(strings.NewReader(`{"result":{"code":"200"},"data":{"total":3,"items":[{"id":1},{"id":2},{"id":3}]}}`)),
+	}
+	res := NewRestResult(nil, response, NewRestEventNoop())
+	var ids []int64
+	batches := 0
+	total, err := res.SinkTo(context.Background(), "data.items", RestSinkFunc(func(_ context.Context, items []*JsonData) error {
+		batches++
+		for _, item := range items {
+			ids = append(ids, item.Get("id").Int())
+		}
+		return nil
+	}), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 3 || batches != 2 || len(ids) != 3 || ids[2] != 3 {
+		t.Error("sink data error")
+	}
+	res = NewRestBodyResult(nil, `{"data":{}}`, nil, nil)
+	if _, err = res.SinkTo(context.Background(), "data.items", RestSinkFunc(func(_ context.Context, _ []*JsonData) error {
+		return nil
+	}), 2); err == nil {
+		t.Error("sink not exists path error")
+	}
+}
+
+func TestRestChanSink(t *testing.T) {
+	ch := make(chan *JsonData, 3)
+	res := NewRestBodyResult(nil, `[1,2,3]`, nil, nil)
+	if _, err := res.SinkTo(context.Background(), "", NewRestChanSink(ch), 10); err != nil {
+		t.Fatal(err)
+	}
+	close(ch)
+	sum := int64(0)
+	for item := range ch {
+		sum += item.Int()
+	}
+	if sum != 6 {
+		t.Error("chan sink data error")
+	}
+}
@@ -0,0 +1,56 @@
+package rest_client
+
+import (
+	"io"
+	"strings"
+)
+
+// Tee 将返回内容复制为多个结果,供缓存,日志,解析等多方分别读取
+// 返回内容只读取一次到一个缓冲,所有结果共享同一份内容,不再额外复制
+// 原结果在调用后已读取完毕,应使用返回的结果,n 小于等于0时返回错误且不读取
+func (res *RestResult) Tee(n int) ([]*RestResult, error) {
+	if res == nil {
+		return nil, errResultClosed()
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+	if n <= 0 {
+		return nil, NewRestClientError(ErrInvalidArg, "tee count must be greater than 0")
+	}
+	//strings.Builder 生成字符串时不复制内容
+	var body strings.Builder
+	if res.response != nil && res.response.ContentLength > 0 && res.response.ContentLength < maxPoolBuffer {
+		body.Grow(int(res.response.ContentLength))
+	}
+	if _, err := io.Copy(&body, res); err != nil {
+		return nil, err
+	}
+	bodyStr := body.String()
+	out := make([]*RestResult, n)
+	for i := range out {
+		out[i] = &RestResult{
+			build:          res.build,
+			response:       res.response,
+			body:           bodyStr,
+			bodyReadOffset: 0,
+		}
+	}
+	return out, nil
+}
+
+// TeeTo 将返回内容同时写入多个 io.Writer,边读边写不缓存内容
+// 任一写入失败时停止读取并返回错误
+func (res *RestResult) TeeTo(writers ...io.Writer) (int64, error) {
+	if res == nil {
+		return 0, errResultClosed()
+	}
+	if res.err != nil {
+		return 0, res.err
+	}
+	n, err := io.Copy(io.MultiWriter(writers...), res)
+	if err != nil && res.err == nil {
+		res.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,47 @@
+package rest_client
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRestResultTee(t *testing.T) {
+	response := &http.Response{
+		StatusCode: 200,
+		Body:       ioutil.NopCloser(strings.NewReader(`{"A":"11"}`)),
+	}
+	list, err := NewRestResult(nil, response, NewRestEventNoop()).Tee(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache, _ := ioutil.ReadAll(list[0])
+	if string(cache) != `{"A":"11"}` {
+		t.Error("tee read error")
+	}
+	if list[1].JsonResult().GetData("A").String() != "11" {
+		t.Error("tee json error")
+	}
+	for _, n := range []int{0, -1} {
+		res := NewRestBodyResult(nil, "body", nil, nil)
+		if _, err := res.Tee(n); ErrorCode(err) != ErrInvalidArg {
+			t.Error("tee count must check", n, err)
+		}
+		if data, _ := ioutil.ReadAll(res); string(data) != "body" {
+			t.Error("tee with wrong count must not read body", n)
+		}
+	}
+}
+
+func TestRestResultTeeTo(t *testing.T) {
+	var log, parse bytes.Buffer
+	res := NewRestBodyResult(nil, "body", nil, nil)
+	if n, err := res.TeeTo(&log, &parse); err != nil || n != 4 {
+		t.Error("tee to error")
+	}
+	if log.String() != "body" || parse.String() != "body" {
+		t.Error("tee to data error")
+	}
+}
@@ -0,0 +1,142 @@
+package rest_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RetryBudget 重试预算,按令牌桶限制每秒的重试次数,同一服务配置的所有调用共享,
+// 下游异常时避免每个调用方同时重试造成重试风暴
+type RetryBudget struct {
+	Rate      float64 //每秒允许的重试次数
+	Burst     int     //允许突发的重试次数
+	once      sync.Once
+	limiter   *RestRateLimiter
+	retries   int64
+	exhausted int64
+}
+
+// NewRetryBudget 创建重试预算
+func NewRetryBudget(rate float64, burst int) *RetryBudget {
+	return &RetryBudget{Rate: rate, Burst: burst}
+}
+
+// RetryBudgetStats 重试预算统计
+type RetryBudgetStats struct {
+	Retries   int64 //已执行的重试次数
+	Exhausted int64 //因预算不足放弃的重试次数
+}
+
+// allow 获取一次重试机会,不等待
+func (budget *RetryBudget) allow() bool {
+	budget.once.Do(func() {
+		budget.limiter = NewRestRateLimiter(budget.Rate, budget.Burst)
+	})
+	if budget.limiter.allow() {
+		atomic.AddInt64(&budget.retries, 1)
+		return true
+	}
+	atomic.AddInt64(&budget.exhausted, 1)
+	return false
+}
+
+// Stats 获取重试统计
+func (budget *RetryBudget) Stats() RetryBudgetStats {
+	return RetryBudgetStats{
+		Retries:   atomic.LoadInt64(&budget.retries),
+		Exhausted: atomic.LoadInt64(&budget.exhausted),
+	}
+}
+
+// AppRestRetry 请求失败重试配置,网络错误及 408 429 5xx 状态码时重试,参数为 io.Reader 时不重试
+// POST PATCH 请求可能已被服务端执行,仅在请求未发送时重试,接口设置 Idempotent 后按上述规则重试
+type AppRestRetry struct {
+	Max    int           //最多重试次数
+	Wait   time.Duration //重试等待时间,按重试次数递增
+	Budget *RetryBudget  //重试预算,为nil时不限制,预算不足放弃重试时返回 ErrRetryBudget 错误
+}
+
+// shouldRetry 结果是否需要重试,非幂等的请求仅在未发送时重试
+func shouldRetry(result *RestResult, idempotent bool) bool {
+	if result.err != nil {
+		if !idempotent && !retryNotSent(result.err) {
+			return false
+		}
+		return ClassifyError(result.err) == ResultRetryable
+	}
+	return idempotent && result.response != nil && ClassifyHttpCode(result.response.StatusCode) == ResultRetryable
+}
+
+// retryNotSent 请求是否未发送到服务端,如建立连接失败及排队已满,此时重试不会重复执行
+func retryNotSent(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && (opErr.Op == "dial" || opErr.Op == "proxyconnect") {
+		return true
+	}
+	var rErr *RestClientError
+	return errors.As(err, &rErr) && (rErr.Code == ErrQueueFull || rErr.Code == ErrFaultDrop)
+}
+
+// idempotent 请求是否可以重复执行
+func (clt *AppRestBuild) idempotent() bool {
+	if clt.Idempotent {
+		return true
+	}
+	switch appHttpMethod(clt.HttpMethod) {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodTrace:
+		return true
+	}
+	return false
+}
+
+// withRetry 按服务配置的重试执行请求
+func (clt *AppRestBuild) withRetry(ctx context.Context, client *RestClient, param interface{}, call func() *RestResult) *RestResult {
+	result := call()
+	if _, ok := param.(io.Reader); ok {
+		return result
+	}
+	tConfig, err := client.GetConfig(ctx)
+	if err != nil {
+		return result
+	}
+	config, ok := tConfig.(*AppRestConfig)
+	if !ok || config.Retry == nil {
+		return result
+	}
+	retry := config.Retry
+	idempotent := clt.idempotent()
+	for i := 1; i <= retry.Max && shouldRetry(result, idempotent); i++ {
+		if retry.Budget != nil && !retry.Budget.allow() {
+			//HTTP状态码需重试的结果也返回错误,与未配置重试时的结果区分
+			cause := result.err
+			if cause == nil {
+				cause = NewRestClientError(ErrServerHttp, fmt.Sprintf("server http code:%d", result.response.StatusCode))
+				_ = result.Close()
+			}
+			result.err = &RestClientError{Code: ErrRetryBudget, Msg: "retry budget exhausted:" + cause.Error(), err: cause}
+			return result
+		}
+		if retry.Wait > 0 {
+			timer := time.NewTimer(retry.Wait * time.Duration(i))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return result
+			case <-timer.C:
+			}
+		}
+		if ctx.Err() != nil {
+			return result
+		}
+		_ = result.Close()
+		result = call()
+	}
+	return result
+}
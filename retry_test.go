@@ -0,0 +1,91 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAppRestRetry(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&count, 1)%3 != 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"a":1}`))
+	}))
+	defer server.Close()
+	budget := NewRetryBudget(0.001, 2)
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:   "test",
+		AppUrl: server.URL,
+		Retry:  &AppRestRetry{Max: 3, Wait: time.Millisecond, Budget: budget},
+	})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodPost, Raw: true, Idempotent: true},
+	}})
+	res := (<-client.Do(context.Background(), 1, nil)).JsonResult()
+	if res.Err() != nil || atomic.LoadInt32(&count) != 3 {
+		t.Error("retry error", res.Err(), count)
+	}
+	_ = (<-client.Do(context.Background(), 1, strings.NewReader("{}"))).JsonResult()
+	if atomic.LoadInt32(&count) != 4 {
+		t.Error("reader param retried")
+	}
+	//重试预算已用完
+	result := <-client.Do(context.Background(), 1, nil)
+	if result.response.StatusCode != http.StatusServiceUnavailable || atomic.LoadInt32(&count) != 5 {
+		t.Error("retry budget not limit", count)
+	}
+	if err := result.JsonResult().Err(); ErrorCode(err) != ErrRetryBudget || !strings.Contains(err.Error(), "503") {
+		t.Error("retry budget exhausted on http code must return error", err)
+	}
+	_ = result.Close()
+	if stats := budget.Stats(); stats.Retries != 2 || stats.Exhausted != 1 {
+		t.Error("retry budget stats error", stats)
+	}
+
+	server.Close()
+	if err := (<-client.Do(context.Background(), 1, nil)).Err(); ErrorCode(err) != ErrRetryBudget {
+		t.Error("retry budget error code", err)
+	}
+}
+
+func TestAppRestRetryNotIdempotent(t *testing.T) {
+	var count int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&count, 1)
+		if r.URL.Path == "/slow" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{Name: "test", AppUrl: server.URL, Retry: &AppRestRetry{Max: 2}})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{Path: "/slow", Raw: true, ResponseHeaderTimeout: 10 * time.Millisecond},
+		2: &AppRestBuild{Path: "/fail", Raw: true, HttpMethod: http.MethodPatch},
+		3: &AppRestBuild{Path: "/fail", Raw: true, HttpMethod: http.MethodGet},
+	}})
+	//POST 超时时服务端可能已执行,不重试
+	if err := (<-client.Do(context.Background(), 1, nil)).Err(); ErrorCode(err) != ErrTimeout || atomic.LoadInt32(&count) != 1 {
+		t.Error("post timeout must not retry", err, count)
+	}
+	atomic.StoreInt32(&count, 0)
+	_ = (<-client.Do(context.Background(), 2, nil)).Close()
+	if n := atomic.LoadInt32(&count); n != 1 {
+		t.Error("patch must not retry", n)
+	}
+	atomic.StoreInt32(&count, 0)
+	_ = (<-client.Do(context.Background(), 3, nil)).Close()
+	if n := atomic.LoadInt32(&count); n != 3 {
+		t.Error("get should retry", n)
+	}
+}
@@ -0,0 +1,57 @@
+package rest_client
+
+import (
+	"strings"
+)
+
+// RestBodySanitizer 实现该接口的 RestBuild 在检测及解析JSON前处理返回内容
+type RestBodySanitizer interface {
+	SanitizeBody(body string) string
+}
+
+// RestSanitizer 返回内容处理函数
+type RestSanitizer func(body string) string
+
+// xssiPrefixes 常见的防JSON劫持前缀
+var xssiPrefixes = []string{")]}',", ")]}'", "while(1);", "for(;;);"}
+
+// SanitizeJsonBody 去掉UTF-8 BOM,防JSON劫持前缀及无效的控制字符(保留 \t \n \r)
+func SanitizeJsonBody(body string) string {
+	body = strings.TrimPrefix(body, "\uFEFF")
+	trimmed := strings.TrimLeft(body, " \t\r\n")
+	for _, prefix := range xssiPrefixes {
+		if strings.HasPrefix(trimmed, prefix) {
+			body = strings.TrimLeft(trimmed[len(prefix):], " \t\r\n")
+			break
+		}
+	}
+	if strings.IndexFunc(body, isInvalidControl) < 0 {
+		return body
+	}
+	return strings.Map(func(r rune) rune {
+		if isInvalidControl(r) {
+			return -1
+		}
+		return r
+	}, body)
+}
+
+func isInvalidControl(r rune) bool {
+	return (r < 0x20 && r != '\t' && r != '\n' && r != '\r') || r == 0x7f
+}
+
+// SanitizeBody 设置了 Sanitizer 时处理返回内容
+func (clt *AppRestBuild) SanitizeBody(body string) string {
+	if clt.Sanitizer == nil {
+		return body
+	}
+	return clt.Sanitizer(body)
+}
+
+// sanitize 按接口配置处理返回内容
+func (res *RestResult) sanitize(body string) string {
+	if sanitizer, ok := res.build.(RestBodySanitizer); ok {
+		return sanitizer.SanitizeBody(body)
+	}
+	return body
+}
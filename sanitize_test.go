@@ -0,0 +1,39 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSanitizeJsonBody(t *testing.T) {
+	cases := map[string]string{
+		"\uFEFF{\"a\":1}":          `{"a":1}`,
+		")]}',\n{\"a\":1}":         `{"a":1}`,
+		"{\"a\":\"b\x00\x08c\"}\n": "{\"a\":\"bc\"}\n",
+		`{"a":"回收宝"}`:              `{"a":"回收宝"}`,
+	}
+	for in, out := range cases {
+		if get := SanitizeJsonBody(in); get != out {
+			t.Errorf("sanitize wrong:%q %q", in, get)
+		}
+	}
+}
+
+func TestAppRestSanitizer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("\uFEFF)]}'\n{\"result\":{\"code\":\"200\",\"state\":\"ok\"},\"data\":{\"name\":\"a\x01\"}}"))
+	}))
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{Sanitizer: SanitizeJsonBody},
+		2: &AppRestBuild{},
+	})
+	if res := (<-client.Do(context.Background(), 1, nil)).JsonResult(); res.Err() != nil || res.MustString("data.name") != "a" {
+		t.Error("sanitize result wrong", res.Err())
+	}
+	if res := (<-client.Do(context.Background(), 2, nil)).JsonResult(); res.MustString("data.name") == "a" {
+		t.Error("result sanitized without sanitizer")
+	}
+}
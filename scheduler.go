@@ -0,0 +1,130 @@
+package rest_client
+
+import (
+	"context"
+	"sync"
+)
+
+// 请求优先级,数值越小越优先
+const (
+	PriorityHigh   = 0 //用户请求等对延迟敏感的请求
+	PriorityNormal = 1 //默认
+	PriorityLow    = 2 //批量任务,WithBackground 标记的请求默认为此级别
+)
+
+type priorityKey struct{}
+
+// WithPriority 设置请求优先级,服务配置了 Scheduler 时排队按此优先
+func WithPriority(ctx context.Context, priority int) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// PriorityFrom 获取请求优先级
+func PriorityFrom(ctx context.Context) int {
+	if priority, ok := ctx.Value(priorityKey{}).(int); ok {
+		if priority < PriorityHigh {
+			return PriorityHigh
+		}
+		if priority > PriorityLow {
+			return PriorityLow
+		}
+		return priority
+	}
+	if IsBackground(ctx) {
+		return PriorityLow
+	}
+	return PriorityNormal
+}
+
+// AppRestScheduler 服务的请求调度,限制同时进行的请求数
+// 超过时按优先级排队,队列满时直接返回 ErrQueueFull 错误,保护下游并保证交互请求的延迟
+// 请求在返回内容读取完成或关闭时释放
+type AppRestScheduler struct {
+	Concurrency int //同时进行的请求数,小于等于0不限制
+	QueueSize   int //最大排队数,0不排队
+	lock        sync.Mutex
+	running     int
+	queued      int
+	queues      [PriorityLow + 1][]chan struct{}
+}
+
+// acquire 获取请求位置,返回释放函数
+func (sch *AppRestScheduler) acquire(ctx context.Context) (func(), error) {
+	if sch.Concurrency <= 0 {
+		return func() {}, nil
+	}
+	sch.lock.Lock()
+	if sch.running < sch.Concurrency {
+		sch.running++
+		sch.lock.Unlock()
+		return sch.releaseOnce(), nil
+	}
+	if sch.queued >= sch.QueueSize {
+		sch.lock.Unlock()
+		return nil, NewRestClientError(ErrQueueFull, "rest request queue is full")
+	}
+	priority := PriorityFrom(ctx)
+	wait := make(chan struct{})
+	sch.queues[priority] = append(sch.queues[priority], wait)
+	sch.queued++
+	sch.lock.Unlock()
+	select {
+	case <-wait:
+		return sch.releaseOnce(), nil
+	case <-ctx.Done():
+		sch.lock.Lock()
+		for i, item := range sch.queues[priority] {
+			if item == wait {
+				sch.queues[priority] = append(sch.queues[priority][:i], sch.queues[priority][i+1:]...)
+				sch.queued--
+				sch.lock.Unlock()
+				return nil, ctx.Err()
+			}
+		}
+		sch.lock.Unlock()
+		//已分配到位置,归还
+		sch.release()
+		return nil, ctx.Err()
+	}
+}
+
+func (sch *AppRestScheduler) releaseOnce() func() {
+	var once sync.Once
+	return func() {
+		once.Do(sch.release)
+	}
+}
+
+// release 释放位置,有排队请求时直接转交给优先级最高的请求
+func (sch *AppRestScheduler) release() {
+	sch.lock.Lock()
+	defer sch.lock.Unlock()
+	for priority := range sch.queues {
+		if len(sch.queues[priority]) > 0 {
+			wait := sch.queues[priority][0]
+			sch.queues[priority] = sch.queues[priority][1:]
+			sch.queued--
+			close(wait)
+			return
+		}
+	}
+	sch.running--
+}
+
+// Stats 当前进行中及排队的请求数
+func (sch *AppRestScheduler) Stats() (running, queued int) {
+	sch.lock.Lock()
+	defer sch.lock.Unlock()
+	return sch.running, sch.queued
+}
+
+// joinRelease 合并释放函数
+func joinRelease(first, second func()) func() {
+	if first == nil {
+		return second
+	}
+	return func() {
+		second()
+		first()
+	}
+}
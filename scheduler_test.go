@@ -0,0 +1,72 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAppRestScheduler(t *testing.T) {
+	block := make(chan struct{})
+	var lock sync.Mutex
+	var order []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/block" {
+			<-block
+		}
+		lock.Lock()
+		order = append(order, r.URL.Path)
+		lock.Unlock()
+	}))
+	defer server.Close()
+	scheduler := &AppRestScheduler{Concurrency: 1, QueueSize: 2}
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{Name: "test", AppUrl: server.URL, Scheduler: scheduler})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{Path: "/block", Raw: true},
+		2: &AppRestBuild{Path: "/low", Raw: true},
+		3: &AppRestBuild{Path: "/high", Raw: true},
+	}})
+	first := client.DoFuture(context.Background(), 1, nil)
+	waitQueued := func(n int) {
+		for i := 0; i < 100; i++ {
+			if running, queued := scheduler.Stats(); running == 1 && queued == n {
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Fatal("request not queued")
+	}
+	waitQueued(0)
+	low := client.DoFuture(WithBackground(context.Background()), 2, nil)
+	waitQueued(1)
+	high := client.DoFuture(WithPriority(context.Background(), PriorityHigh), 3, nil)
+	waitQueued(2)
+	if err := (<-client.Do(context.Background(), 3, nil)).Err(); ErrorCode(err) != ErrQueueFull {
+		t.Error("queue full not return error")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	scheduler.QueueSize = 3
+	if err := (<-client.Do(ctx, 3, nil)).Err(); ErrorCode(err) != ErrCanceled {
+		t.Error("queue wait not canceled")
+	}
+	close(block)
+	//返回内容读取完成后才释放位置
+	for _, future := range []*RestFuture{first, high, low} {
+		res, err := future.Get(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = res.JsonResult()
+	}
+	if len(order) != 3 || order[1] != "/high" || order[2] != "/low" {
+		t.Error("priority order wrong", order)
+	}
+	if running, queued := scheduler.Stats(); running != 0 || queued != 0 {
+		t.Error("scheduler not release", running, queued)
+	}
+}
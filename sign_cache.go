@@ -0,0 +1,91 @@
+package rest_client
+
+import (
+	"strings"
+	"sync"
+)
+
+// AppRestSignCache 签名参数缓存,签名时间精确到秒,同一秒内相同的参数签名结果相同,
+// 重试或对冲请求时直接复用,避免重复排序编码及计算摘要,进入新的一秒时清空
+// 网关拒绝后重新签名的请求不使用缓存,并删除被拒绝的签名参数
+type AppRestSignCache struct {
+	MaxEntries int //最多缓存条数,默认1024,超过时不再缓存
+	lock       sync.Mutex
+	timestamp  string
+	data       map[string]string
+}
+
+// NewAppRestSignCache 创建签名缓存
+func NewAppRestSignCache(maxEntries int) *AppRestSignCache {
+	return &AppRestSignCache{MaxEntries: maxEntries}
+}
+
+// appSignCacheKey 签名结果相关的内容,时间单独比较
+func appSignCacheKey(config *AppRestConfig, version, method, jsonParam string, token *string) string {
+	var key strings.Builder
+	size := len(config.AppKey) + len(config.AppSecret) + len(version) + len(method) + len(jsonParam) + 6
+	if token != nil {
+		size += len(*token)
+	}
+	key.Grow(size)
+	key.WriteString(config.AppKey)
+	key.WriteByte(0)
+	key.WriteString(config.AppSecret)
+	key.WriteByte(0)
+	key.WriteString(version)
+	key.WriteByte(0)
+	key.WriteString(method)
+	key.WriteByte(0)
+	if token != nil {
+		key.WriteByte(1)
+		key.WriteString(*token)
+	}
+	key.WriteByte(0)
+	key.WriteString(jsonParam)
+	return key.String()
+}
+
+// load 获取缓存的签名参数,不存在时生成并缓存
+func (cache *AppRestSignCache) load(config *AppRestConfig, version, method, timestamp, jsonParam string, token *string,
+	build func(config *AppRestConfig, version, method, timestamp, jsonParam string, token *string) string) string {
+	key := appSignCacheKey(config, version, method, jsonParam, token)
+	cache.lock.Lock()
+	if cache.timestamp == timestamp {
+		if params, ok := cache.data[key]; ok {
+			cache.lock.Unlock()
+			return params
+		}
+	}
+	cache.lock.Unlock()
+
+	params := build(config, version, method, timestamp, jsonParam, token)
+
+	maxEntries := cache.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = 1024
+	}
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	if cache.timestamp != timestamp {
+		//时间只会前进,旧时间的请求不替换当前缓存
+		if timestamp < cache.timestamp {
+			return params
+		}
+		cache.timestamp = timestamp
+		cache.data = map[string]string{}
+	}
+	if len(cache.data) < maxEntries {
+		cache.data[key] = params
+	}
+	return params
+}
+
+// refresh 重新签名时使用,删除缓存的签名参数并重新生成,结果不缓存
+func (cache *AppRestSignCache) refresh(config *AppRestConfig, version, method, timestamp, jsonParam string, token *string,
+	build func(config *AppRestConfig, version, method, timestamp, jsonParam string, token *string) string) string {
+	key := appSignCacheKey(config, version, method, jsonParam, token)
+	cache.lock.Lock()
+	delete(cache.data, key)
+	cache.lock.Unlock()
+	return build(config, version, method, timestamp, jsonParam, token)
+}
@@ -0,0 +1,79 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAppRestSignCache(t *testing.T) {
+	cache := NewAppRestSignCache(2)
+	config := &AppRestConfig{AppKey: "dome1", AppSecret: "secret"}
+	calls := 0
+	build := func(config *AppRestConfig, version, method, timestamp, jsonParam string, token *string) string {
+		calls++
+		return appSignedParams(config, version, method, timestamp, jsonParam, token)
+	}
+	first := cache.load(config, "1.0", "a.b", "2023-01-01 00:00:00", `{"a":1}`, nil, build)
+	if cache.load(config, "1.0", "a.b", "2023-01-01 00:00:00", `{"a":1}`, nil, build) != first || calls != 1 {
+		t.Error("sign cache not use")
+	}
+	token := ""
+	cache.load(config, "1.0", "a.b", "2023-01-01 00:00:00", `{"a":1}`, &token, build)
+	cache.load(config, "1.0", "a.c", "2023-01-01 00:00:00", `{"a":1}`, nil, build)
+	cache.load(config, "1.0", "a.c", "2023-01-01 00:00:00", `{"a":1}`, nil, build)
+	if calls != 4 {
+		t.Error("sign cache key or max entries error", calls)
+	}
+	if cache.load(config, "1.0", "a.b", "2023-01-01 00:00:01", `{"a":1}`, nil, build) == first || calls != 5 || len(cache.data) != 1 {
+		t.Error("sign cache not reset on next second")
+	}
+	cache.load(config, "1.0", "a.b", "2023-01-01 00:00:00", `{"a":1}`, nil, build)
+	if cache.timestamp != "2023-01-01 00:00:01" {
+		t.Error("sign cache replace by old timestamp")
+	}
+	if cache.load(config, "2.0", "a.b", "2023-01-01 00:00:01", `{"a":1}`, nil, build) == first || calls != 7 {
+		t.Error("sign cache key should contain version", calls)
+	}
+	//重新签名时不使用缓存并删除被拒绝的签名参数
+	cache.refresh(config, "1.0", "a.b", "2023-01-01 00:00:01", `{"a":1}`, nil, build)
+	if _, ok := cache.data[appSignCacheKey(config, "1.0", "a.b", `{"a":1}`, nil)]; ok || calls != 8 {
+		t.Error("sign cache not bypass on resign", calls)
+	}
+	cache.load(config, "1.0", "a.b", "2023-01-01 00:00:01", `{"a":1}`, nil, build)
+	if calls != 9 {
+		t.Error("sign cache entry not removed on resign", calls)
+	}
+}
+
+func TestAppRestSignCacheRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if !AppRestCheckSign(r.Form, "dome111111") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":{}}`))
+	}))
+	defer server.Close()
+	for _, lowAlloc := range []bool{false, true} {
+		manager := NewRestClientManager()
+		manager.SetRestConfig(&AppRestConfig{
+			Name:      "test",
+			AppKey:    "dome1",
+			AppSecret: "dome111111",
+			AppUrl:    server.URL,
+			LowAlloc:  lowAlloc,
+			SignCache: NewAppRestSignCache(0),
+		})
+		client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+			1: &AppRestBuild{HttpMethod: http.MethodPost, Method: "a.b"},
+		}})
+		for i := 0; i < 3; i++ {
+			if err := (<-client.Do(context.Background(), 1, map[string]int{"a": 1})).JsonResult().Err(); err != nil {
+				t.Error("sign cache request error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package rest_client
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"net/url"
+	"strings"
+)
+
+// AppRestCanonicalizer 生成签名串,签名为签名串的MD5,用于对接签名规则略有不同的合作方
+type AppRestCanonicalizer interface {
+	//fields 为参与签名的字段,已按名称排序: app content method timestamp token version,method token 未设置时不包含
+	Canonical(fields [][2]string, appSecret string) string
+}
+
+// AppSecretPlace 密钥在签名串中的位置
+type AppSecretPlace int
+
+const (
+	AppSecretAppend  AppSecretPlace = iota //拼接在末尾,默认
+	AppSecretPrepend                       //拼接在开头
+	AppSecretWrap                          //开头及末尾都拼接
+	AppSecretField                         //作为最后一个字段,如 &key=密钥
+)
+
+// AppRestCanonical 可配置的签名串规则,NewAppRestCanonical 创建的规则与默认签名一致
+// 零值时字段名与值直接拼接,无分隔及编码
+type AppRestCanonical struct {
+	Fields      []string            //参与签名的字段,为空时为全部
+	Encode      func(string) string //字段值编码,为nil时不编码
+	Pair        string              //字段名与值的分隔,如 =
+	Separator   string              //字段间的分隔,如 &
+	SecretPlace AppSecretPlace      //密钥位置
+	SecretKey   string              //AppSecretField 时的字段名,默认 key
+}
+
+// NewAppRestCanonical 创建与默认签名一致的规则: 按名称排序后 url 编码,密钥拼接在末尾
+func NewAppRestCanonical() *AppRestCanonical {
+	return &AppRestCanonical{
+		Encode:    url.QueryEscape,
+		Pair:      "=",
+		Separator: "&",
+	}
+}
+
+// include 字段是否参与签名
+func (canonical *AppRestCanonical) include(name string) bool {
+	if len(canonical.Fields) == 0 {
+		return true
+	}
+	for _, field := range canonical.Fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (canonical *AppRestCanonical) Canonical(fields [][2]string, appSecret string) string {
+	var buf strings.Builder
+	if canonical.SecretPlace == AppSecretPrepend || canonical.SecretPlace == AppSecretWrap {
+		buf.WriteString(appSecret)
+	}
+	n := 0
+	for _, field := range fields {
+		if !canonical.include(field[0]) {
+			continue
+		}
+		if n > 0 {
+			buf.WriteString(canonical.Separator)
+		}
+		n++
+		val := field[1]
+		if canonical.Encode != nil {
+			val = canonical.Encode(val)
+		}
+		buf.WriteString(field[0])
+		buf.WriteString(canonical.Pair)
+		buf.WriteString(val)
+	}
+	switch canonical.SecretPlace {
+	case AppSecretAppend, AppSecretWrap:
+		buf.WriteString(appSecret)
+	case AppSecretField:
+		secretKey := canonical.SecretKey
+		if len(secretKey) == 0 {
+			secretKey = "key"
+		}
+		if n > 0 {
+			buf.WriteString(canonical.Separator)
+		}
+		buf.WriteString(secretKey)
+		buf.WriteString(canonical.Pair)
+		buf.WriteString(appSecret)
+	}
+	return buf.String()
+}
+
+// AppRestSignWith 按指定规则生成签名,canonical 为nil时同 AppRestParamSign,用于模拟合作方校验签名
+func AppRestSignWith(canonical AppRestCanonicalizer, version, appKey, method, timestamp, content, appSecret string, token *string) string {
+	if canonical == nil {
+		return AppRestParamSign(version, appKey, method, timestamp, content, appSecret, token)
+	}
+	fields := make([][2]string, 0, 6)
+	fields = append(fields, [2]string{"app", appKey}, [2]string{"content", content})
+	if len(method) > 0 {
+		fields = append(fields, [2]string{"method", method})
+	}
+	fields = append(fields, [2]string{"timestamp", timestamp})
+	if token != nil {
+		fields = append(fields, [2]string{"token", *token})
+	}
+	fields = append(fields, [2]string{"version", version})
+	sum := md5.Sum([]byte(canonical.Canonical(fields, appSecret)))
+	return hex.EncodeToString(sum[:])
+}
+
+// appConfigSign 按服务配置的签名规则生成签名
+func appConfigSign(config *AppRestConfig, version, method, timestamp, content string, token *string) string {
+	if config.Canonical != nil {
+		return AppRestSignWith(config.Canonical, version, config.AppKey, method, timestamp, content, config.AppSecret, token)
+	}
+	if config.LowAlloc {
+		return appParamSignLowAlloc(version, config.AppKey, method, timestamp, content, config.AppSecret, token)
+	}
+	return AppRestParamSign(version, config.AppKey, method, timestamp, content, config.AppSecret, token)
+}
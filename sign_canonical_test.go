@@ -0,0 +1,66 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAppRestCanonical(t *testing.T) {
+	token := "tk"
+	for _, tk := range []*string{nil, &token} {
+		sign := AppRestParamSign("1.0", "dome1", "a.b", "2023-01-01 00:00:00", `{"a":"b c+&"}`, "secret", tk)
+		if AppRestSignWith(NewAppRestCanonical(), "1.0", "dome1", "a.b", "2023-01-01 00:00:00", `{"a":"b c+&"}`, "secret", tk) != sign {
+			t.Error("default canonical should equal AppRestParamSign")
+		}
+	}
+	fields := [][2]string{{"app", "dome1"}, {"content", "a b"}, {"timestamp", "t"}, {"version", "1.0"}}
+	canonical := &AppRestCanonical{Fields: []string{"app", "content"}, SecretPlace: AppSecretWrap}
+	if res := canonical.Canonical(fields, "s"); res != "sappdome1contenta bs" {
+		t.Error("canonical fields error", res)
+	}
+	canonical = &AppRestCanonical{Pair: ":", Separator: "|", SecretPlace: AppSecretField}
+	if res := canonical.Canonical(fields[:2], "s"); res != "app:dome1|content:a b|key:s" {
+		t.Error("canonical secret field error", res)
+	}
+	canonical = &AppRestCanonical{Separator: ",", SecretPlace: AppSecretPrepend, Encode: strings.ToUpper}
+	if res := canonical.Canonical(fields[:2], "s"); res != "sappDOME1,contentA B" {
+		t.Error("canonical prepend error", res)
+	}
+}
+
+func TestAppRestCanonicalRequest(t *testing.T) {
+	canonical := NewAppRestCanonical()
+	canonical.Fields = []string{"app", "content", "timestamp"}
+	canonical.SecretPlace = AppSecretField
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		code := "200"
+		sign := AppRestSignWith(canonical, r.Form.Get("version"), r.Form.Get("app"), r.Form.Get("method"), r.Form.Get("timestamp"), r.Form.Get("content"), "dome111111", nil)
+		if sign != r.Form.Get("sign") {
+			code = "500"
+		}
+		_, _ = w.Write([]byte(`{"result":{"code":"` + code + `","state":"ok"},"data":{}}`))
+	}))
+	defer server.Close()
+	for _, lowAlloc := range []bool{false, true} {
+		manager := NewRestClientManager()
+		manager.SetRestConfig(&AppRestConfig{
+			Name:      "test",
+			AppKey:    "dome1",
+			AppSecret: "dome111111",
+			AppUrl:    server.URL,
+			LowAlloc:  lowAlloc,
+			Canonical: canonical,
+		})
+		client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{1: &AppRestBuild{Method: "a.b"}}})
+		if err := (<-client.Do(context.Background(), 1, map[string]string{"a": "b"})).JsonResult().Err(); err != nil {
+			t.Error("canonical request error", err)
+		}
+		if err := (<-client.Do(context.Background(), 1, strings.NewReader(`{"a":"c"}`))).JsonResult().Err(); err != nil {
+			t.Error("canonical stream request error", err)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+)
+
+// AppSignMode 签名参数的发送方式
+type AppSignMode int
+
+const (
+	AppSignInherit AppSignMode = iota //接口上未设置时使用服务配置,服务配置未设置时为 AppSignForm
+	AppSignForm                       //签名参数与 content 一起以表单发送
+	//网关v2: app timestamp sign token 等通过 X-App X-Timestamp X-Sign X-Token 等HEADER发送,请求内容仅为业务参数
+	//GET等请求的业务参数在URL的 content 参数上,此模式不使用签名缓存
+	AppSignHeader
+)
+
+// signMode 接口使用的签名发送方式
+func (clt *AppRestBuild) signMode(config *AppRestConfig) AppSignMode {
+	if clt.SignMode != AppSignInherit {
+		return clt.SignMode
+	}
+	if config.SignMode != AppSignInherit {
+		return config.SignMode
+	}
+	return AppSignForm
+}
+
+// signHeader HEADER签名模式生成业务参数及签名HEADER
+func (clt *AppRestBuild) signHeader(ctx context.Context, client *RestClient, config *AppRestConfig, param interface{}, hasBody bool) (string, http.Header, error) {
+	param, err := readStreamParam(param)
+	if err != nil {
+		return "", nil, err
+	}
+	encoder := clt.paramEncoder()
+	if hasBody && clt.ParamEncoder == nil && clt.Codec != nil {
+		//内容中发送时不需要base64
+		encoder = &codecParamEncoder{codec: clt.Codec}
+	}
+	content, err := encodeParam(param, encoder, config.ParamEncoder)
+	if err != nil {
+		return "", nil, err
+	}
+	if content, err = clt.encryptContent(content); err != nil {
+		return "", nil, err
+	}
+	token, err := appToken(ctx, client, config)
+	if err != nil {
+		return "", nil, err
+	}
+	version := clt.appVersion(config)
+	timestamp := appTimestamp(config)
+	sign := appConfigSign(config, version, clt.Method, timestamp, content, token)
+	header := make(http.Header, 6)
+	header.Set("X-App", config.AppKey)
+	if len(clt.Method) > 0 {
+		header.Set("X-Method", clt.Method)
+	}
+	header.Set("X-Version", version)
+	header.Set("X-Timestamp", timestamp)
+	if token != nil {
+		header.Set("X-Token", *token)
+	}
+	header.Set("X-Sign", sign)
+	return content, header, nil
+}
+
+// AppRestCheckHeaderSign 校验HEADER签名模式的签名,content 为请求内容或GET等请求URL上的 content 参数
+func AppRestCheckHeaderSign(header http.Header, content string, appSecret string) bool {
+	var token *string
+	if values, ok := header["X-Token"]; ok && len(values) > 0 {
+		token = &values[0]
+	}
+	sign := AppRestParamSign(header.Get("X-Version"), header.Get("X-App"), header.Get("X-Method"), header.Get("X-Timestamp"), content, appSecret, token)
+	return len(header.Get("X-Sign")) > 0 && sign == header.Get("X-Sign")
+}
@@ -0,0 +1,68 @@
+package rest_client
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testSignHeaderApi struct {
+	testBuildApi
+}
+
+func (res *testSignHeaderApi) Token(_ context.Context) (string, error) {
+	return "tk1", nil
+}
+
+func TestAppSignHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content := r.URL.Query().Get("content")
+		if r.Method != http.MethodGet {
+			body, _ := ioutil.ReadAll(r.Body)
+			content = string(body)
+		}
+		code := "200"
+		if !AppRestCheckHeaderSign(r.Header, content, "dome111111") || r.Header.Get("X-Token") != "tk1" {
+			code = "500"
+		}
+		_, _ = w.Write([]byte(`{"result":{"code":"` + code + `","state":"ok"},"data":{"content":` + content +
+			`,"type":"` + r.Header.Get("Content-Type") + `"}}`))
+	}))
+	defer server.Close()
+	for _, lowAlloc := range []bool{false, true} {
+		manager := NewRestClientManager()
+		manager.SetRestConfig(&AppRestConfig{
+			Name:      "test",
+			AppKey:    "dome1",
+			AppSecret: "dome111111",
+			AppUrl:    server.URL,
+			SignMode:  AppSignHeader,
+			LowAlloc:  lowAlloc,
+		})
+		client := manager.NewApi(&testSignHeaderApi{testBuildApi{name: "test", builds: map[int]RestBuild{
+			1: &AppRestBuild{Method: "a.b", Version: "2.0"},
+			2: &AppRestBuild{Method: "a.c", HttpMethod: http.MethodGet},
+		}}})
+		res := (<-client.Do(context.Background(), 1, map[string]string{"a": "b"})).JsonResult()
+		if res.Err() != nil || res.MustString("data.content.a") != "b" || res.MustString("data.type") != "application/json" {
+			t.Error("sign header post error", res.Err())
+		}
+		res = (<-client.Do(context.Background(), 2, map[string]string{"a": "c"})).JsonResult()
+		if res.Err() != nil || res.MustString("data.content.a") != "c" {
+			t.Error("sign header get error", res.Err())
+		}
+	}
+}
+
+func TestAppSignMode(t *testing.T) {
+	config := &AppRestConfig{}
+	if (&AppRestBuild{}).signMode(config) != AppSignForm {
+		t.Error("default sign mode error")
+	}
+	config.SignMode = AppSignHeader
+	if (&AppRestBuild{}).signMode(config) != AppSignHeader || (&AppRestBuild{SignMode: AppSignForm}).signMode(config) != AppSignForm {
+		t.Error("sign mode override error")
+	}
+}
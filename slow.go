@@ -0,0 +1,131 @@
+package rest_client
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// RestSlowCall 慢请求信息
+type RestSlowCall struct {
+	ConfigName string
+	Key        int
+	Threshold  time.Duration   //触发的阈值
+	Timings    RestTimings     //各阶段耗时,读取完返回内容前触发时 Total 为到返回HEADER的耗时
+	Caller     *RestCallerInfo //发起请求的调用方
+	Err        error           //请求错误,成功时为nil
+}
+
+// RestSlowEvent 可选事件接口,请求耗时超过慢请求阈值时回调,每个请求最多一次
+type RestSlowEvent interface {
+	SlowCall(call *RestSlowCall)
+}
+
+// AppRestSlow 慢请求检测,请求(含重试)总耗时超过阈值时回调并单独计数,用于下游变慢时自动告警
+type AppRestSlow struct {
+	Threshold time.Duration //慢请求阈值,接口设置了 SlowThreshold 时使用接口的值
+	//慢请求回调,如发送告警,可以为nil
+	OnSlow func(ctx context.Context, call *RestSlowCall)
+	calls  int64
+	slow   int64
+}
+
+// AppRestSlowStats 慢请求统计
+type AppRestSlowStats struct {
+	Calls int64 //已完成检测的请求数
+	Slow  int64 //慢请求数
+}
+
+// Stats 获取慢请求统计
+func (slow *AppRestSlow) Stats() AppRestSlowStats {
+	return AppRestSlowStats{
+		Calls: atomic.LoadInt64(&slow.calls),
+		Slow:  atomic.LoadInt64(&slow.slow),
+	}
+}
+
+// restSlowCheck 请求的慢请求检测,附加在请求耗时记录上
+type restSlowCheck struct {
+	ctx        context.Context
+	config     *AppRestSlow //可以为nil,仅接口设置了阈值时
+	event      RestSlowEvent
+	configName string
+	key        int
+	threshold  time.Duration
+	counted    bool
+	fired      bool
+}
+
+// slowThreshold 接口使用的慢请求阈值,0为不检测
+func (clt *AppRestBuild) slowThreshold(config *AppRestConfig) time.Duration {
+	if clt.SlowThreshold > 0 {
+		return clt.SlowThreshold
+	}
+	if config.Slow != nil {
+		return config.Slow.Threshold
+	}
+	return 0
+}
+
+// withSlow 设置本次请求的慢请求检测,重试时使用最后一次请求的事件
+func (timing *restTiming) withSlow(ctx context.Context, config *AppRestConfig, threshold time.Duration, event RestEvent) {
+	if threshold <= 0 {
+		return
+	}
+	check := &restSlowCheck{
+		ctx:        ctx,
+		config:     config.Slow,
+		configName: config.Name,
+		threshold:  threshold,
+	}
+	check.event, _ = event.(RestSlowEvent)
+	_, check.key, _ = CallInfo(ctx)
+	timing.lock.Lock()
+	if timing.slow != nil {
+		check.counted, check.fired = timing.slow.counted, timing.slow.fired
+	}
+	timing.slow = check
+	timing.lock.Unlock()
+}
+
+// checkSlow 在记录总耗时后检测是否为慢请求,每个请求只计数及回调一次
+func (timing *restTiming) checkSlow(err error) {
+	if timing == nil {
+		return
+	}
+	timing.lock.Lock()
+	check := timing.slow
+	if check == nil || check.fired {
+		timing.lock.Unlock()
+		return
+	}
+	counted := check.counted
+	check.counted = true
+	slow := timing.timings.Total >= check.threshold
+	check.fired = slow
+	timings := timing.timings
+	timing.lock.Unlock()
+	if check.config != nil && !counted {
+		atomic.AddInt64(&check.config.calls, 1)
+	}
+	if !slow {
+		return
+	}
+	call := &RestSlowCall{
+		ConfigName: check.configName,
+		Key:        check.key,
+		Threshold:  check.threshold,
+		Timings:    timings,
+		Caller:     Caller(check.ctx),
+		Err:        err,
+	}
+	if check.config != nil {
+		atomic.AddInt64(&check.config.slow, 1)
+		if check.config.OnSlow != nil {
+			check.config.OnSlow(check.ctx, call)
+		}
+	}
+	if check.event != nil {
+		check.event.SlowCall(call)
+	}
+}
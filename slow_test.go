@@ -0,0 +1,65 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type testSlowEvent struct {
+	RestEventNoop
+	calls []*RestSlowCall
+}
+
+func (event *testSlowEvent) SlowCall(call *RestSlowCall) {
+	event.calls = append(event.calls, call)
+}
+
+func TestAppRestSlow(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond)
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":{}}`))
+	}))
+	defer server.Close()
+	event := &testSlowEvent{}
+	var alerts []*RestSlowCall
+	slow := &AppRestSlow{
+		Threshold: 10 * time.Millisecond,
+		OnSlow: func(_ context.Context, call *RestSlowCall) {
+			alerts = append(alerts, call)
+		},
+	}
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:      "test",
+		AppKey:    "dome1",
+		AppSecret: "dome111111",
+		AppUrl:    server.URL,
+		Slow:      slow,
+		EventCreate: func(_ context.Context) RestEvent {
+			return event
+		},
+	})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{},
+		2: &AppRestBuild{SlowThreshold: time.Hour},
+	}})
+	for _, key := range []int{1, 2} {
+		if err := (<-client.Do(context.Background(), key, nil)).JsonResult().Err(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(alerts) != 1 || len(event.calls) != 1 || alerts[0] != event.calls[0] {
+		t.Fatal("slow callback wrong", len(alerts), len(event.calls))
+	}
+	call := alerts[0]
+	if call.ConfigName != "test" || call.Key != 1 || call.Threshold != 10*time.Millisecond ||
+		call.Timings.Total < 30*time.Millisecond || call.Caller == nil || call.Err != nil {
+		t.Error("slow call wrong", call)
+	}
+	if stats := slow.Stats(); stats.Calls != 2 || stats.Slow != 1 {
+		t.Error("slow stats wrong", stats)
+	}
+}
@@ -0,0 +1,389 @@
+package rest_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SoapVersion SOAP协议版本
+type SoapVersion string
+
+const (
+	Soap11 SoapVersion = "1.1"
+	Soap12 SoapVersion = "1.2"
+)
+
+const (
+	soap11Namespace = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12Namespace = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// SoapRestConfig SOAP服务配置,用于对接使用SOAP的外部服务
+// 服务地址为 AppUrl,接口 Path 拼接在其后,事件 HEADER 连接池 审计 慢请求 时钟偏差 后台请求等使用 AppRestConfig 的配置
+// 签名 默认参数 重试 负载均衡 镜像等网关相关的配置不使用
+type SoapRestConfig struct {
+	AppRestConfig
+	Version    SoapVersion //默认协议版本,为空时为1.1,接口上配置的优先
+	Namespace  string      //默认的操作命名空间,接口上配置的优先
+	SoapHeader interface{} //Envelope 中 Header 的内容,如认证信息,string及[]byte为XML原文,其他按 encoding/xml 编码
+}
+
+// SoapRestBuild SOAP接口配置,参数放入 Envelope 的 Body 中以POST发送,返回使用 RestResult.XmlResult 解析
+// 参数为 string 及 []byte 时为XML原文,map 时按KEY排序编码为 Operation 的子元素,其他按 encoding/xml 编码
+type SoapRestBuild struct {
+	Path      string      //接口路径
+	Action    string      //SOAPAction
+	Operation string      //Body 中的元素名,为空时使用参数本身的XML名称
+	Namespace string      //Operation 的命名空间,为空时使用服务配置
+	Version   SoapVersion //协议版本,为空时使用服务配置
+	//等待返回HEADER超时,0时使用连接配置
+	Timeout time.Duration
+	//整个请求的超时,包含读取返回内容,0不限制
+	TotalTimeout time.Duration
+	//慢请求阈值,大于0时替换服务配置 Slow 的阈值,事件实现 RestSlowEvent 时回调
+	SlowThreshold time.Duration
+}
+
+// soapVersion 接口使用的协议版本
+func (clt *SoapRestBuild) soapVersion(config *SoapRestConfig) SoapVersion {
+	if len(clt.Version) > 0 {
+		return clt.Version
+	}
+	if len(config.Version) > 0 {
+		return config.Version
+	}
+	return Soap11
+}
+
+// BuildRequest 执行请求
+func (clt *SoapRestBuild) BuildRequest(ctx context.Context, client *RestClient, key int, param interface{}, _ *RestCallerInfo) *RestResult {
+	timing := newRestTiming()
+	result := clt.buildRequest(ctx, client, key, param, timing)
+	timing.finish()
+	result.timing = timing
+	result.err = timing.timeoutError(result.err)
+	timing.checkSlow(result.err)
+	return result
+}
+
+func (clt *SoapRestBuild) buildRequest(ctx context.Context, client *RestClient, key int, param interface{}, timing *restTiming) *RestResult {
+	tConfig, err := client.GetConfig(ctx)
+	if err != nil {
+		return NewRestResultFromError(err, &RestEventNoop{})
+	}
+	config, ok := tConfig.(*SoapRestConfig)
+	if !ok {
+		return NewRestResultFromError(NewRestClientError(ErrConfigType, "build config is wrong"), &RestEventNoop{})
+	}
+	ctx = withCallInfo(ctx, config.Name, key)
+	event := contextEvent(ctx)
+	if event == nil {
+		create := eventCreate(config.EventCreate, config.EventCreateV2, config.EventCreates)
+		if create != nil && config.EventSample != nil {
+			event = config.EventSample.event(ctx, create)
+		} else if create != nil {
+			event = create(ctx)
+		} else {
+			event = &RestEventNoop{}
+		}
+	}
+	slowThreshold := clt.SlowThreshold
+	if slowThreshold <= 0 && config.Slow != nil {
+		slowThreshold = config.Slow.Threshold
+	}
+	timing.withSlow(ctx, &config.AppRestConfig, slowThreshold, event)
+	if config.Audit != nil {
+		event = config.Audit.event(ctx, event)
+	}
+	if config.ClockSkew != nil {
+		event = config.ClockSkew.event(event)
+	}
+
+	if err := checkDeadlineBudget(ctx, config.MinBudget); err != nil {
+		return NewRestResultFromError(err, event)
+	}
+	headerTimeout := clt.Timeout
+	if config.Background != nil && IsBackground(ctx) {
+		queued := timing.queue()
+		err := config.Background.Wait(ctx)
+		queued()
+		if err != nil {
+			return NewRestResultFromError(cancelError(err), event)
+		}
+		if config.Background.Timeout > 0 {
+			headerTimeout = config.Background.Timeout
+		}
+	}
+
+	var roundTripper http.RoundTripper = client.GetTransport()
+	if config.Transport != nil && client.manager != nil {
+		roundTripper = client.manager.transports.get(config.Name, config.Transport).roundTripper
+	}
+	apiUrl := config.AppUrl
+	override := contextOverride(ctx)
+	if override != nil && len(override.AppUrl) > 0 {
+		apiUrl = override.AppUrl
+	}
+	apiUrl += clt.Path
+
+	version := clt.soapVersion(config)
+	namespace := clt.Namespace
+	if len(namespace) == 0 {
+		namespace = config.Namespace
+	}
+	body, err := soapEnvelope(version, config.SoapHeader, xml.Name{Space: namespace, Local: clt.Operation}, param)
+	if err != nil {
+		return NewRestResultFromError(err, event)
+	}
+
+	event.RequestStart(http.MethodPost, apiUrl)
+	totalTimeout := clt.TotalTimeout
+	if override != nil && override.Timeout > 0 {
+		totalTimeout = override.Timeout
+	}
+	reqCtx, timeout := newRestTimeout(timing.withTrace(ctx), 0, headerTimeout, totalTimeout)
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, apiUrl, NewRestRequestReader(bytes.NewReader(body), event))
+	if err != nil {
+		timeout.release()
+		return NewRestResultFromError(err, event)
+	}
+	setIdentityHeader(config.Identity, req.Header)
+	for key, val := range config.Headers {
+		req.Header.Set(key, val)
+	}
+	for key, val := range contextHeader(ctx) {
+		req.Header[key] = val
+	}
+	if override != nil {
+		for key, val := range override.Headers {
+			req.Header.Set(key, val)
+		}
+	}
+	if client.manager != nil {
+		injectTrace(ctx, client.manager.propagators, req.Header)
+	}
+	setDeadlineHeader(reqCtx, req.Header, config.DeadlineHeader)
+	if version == Soap12 {
+		contentType := "application/soap+xml; charset=utf-8"
+		if len(clt.Action) > 0 {
+			contentType += fmt.Sprintf("; action=%q", clt.Action)
+		}
+		req.Header.Set("Content-Type", contentType)
+	} else {
+		req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+		req.Header.Set("SOAPAction", fmt.Sprintf("%q", clt.Action))
+	}
+
+	httpClient := &http.Client{
+		Transport: client.wrapRoundTripper(roundTripper),
+	}
+	timeout.start()
+	res, err := httpClient.Do(req)
+	timeout.gotHeader()
+	if err != nil {
+		timeout.release()
+		return NewRestResultFromError(timeout.error(err), event)
+	}
+	res.Body = newFenceBody(res.Body, timeout.release)
+	charsetBody(res, "")
+	return NewRestResult(clt, res, event)
+}
+
+// soapEnvelope 生成请求的 Envelope
+func soapEnvelope(version SoapVersion, header interface{}, operation xml.Name, param interface{}) ([]byte, error) {
+	namespace := soap11Namespace
+	if version == Soap12 {
+		namespace = soap12Namespace
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<soap:Envelope xmlns:soap="` + namespace + `">`)
+	if header != nil {
+		buf.WriteString("<soap:Header>")
+		if err := soapEncode(&buf, xml.Name{}, header); err != nil {
+			return nil, err
+		}
+		buf.WriteString("</soap:Header>")
+	}
+	buf.WriteString("<soap:Body>")
+	if err := soapEncode(&buf, operation, param); err != nil {
+		return nil, err
+	}
+	buf.WriteString("</soap:Body></soap:Envelope>")
+	return buf.Bytes(), nil
+}
+
+// soapEncode 编码 Header 或 Body 的内容
+func soapEncode(buf *bytes.Buffer, name xml.Name, param interface{}) error {
+	switch val := param.(type) {
+	case nil:
+		if len(name.Local) > 0 {
+			return soapEncodeValue(buf, name, nil)
+		}
+		return nil
+	case string:
+		buf.WriteString(val)
+		return nil
+	case []byte:
+		buf.Write(val)
+		return nil
+	}
+	return soapEncodeValue(buf, name, param)
+}
+
+func soapEncodeValue(buf *bytes.Buffer, name xml.Name, param interface{}) error {
+	enc := xml.NewEncoder(buf)
+	var err error
+	if rv := reflect.ValueOf(param); rv.Kind() == reflect.Map || param == nil {
+		if len(name.Local) == 0 {
+			return NewRestClientError(ErrXmlValid, "soap map param need operation")
+		}
+		err = soapEncodeMap(enc, xml.StartElement{Name: name}, rv)
+	} else if len(name.Local) > 0 {
+		err = enc.EncodeElement(param, xml.StartElement{Name: name})
+	} else {
+		err = enc.Encode(param)
+	}
+	if err == nil {
+		err = enc.Flush()
+	}
+	if err != nil {
+		return &RestClientError{Code: ErrXmlValid, Msg: "soap param encode fail:" + err.Error(), err: err}
+	}
+	return nil
+}
+
+// soapEncodeMap 按KEY排序编码 map,值为 map 时嵌套,为切片时重复元素
+func soapEncodeMap(enc *xml.Encoder, start xml.StartElement, rv reflect.Value) error {
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if rv.IsValid() {
+		keys := make([]string, 0, rv.Len())
+		values := make(map[string]reflect.Value, rv.Len())
+		for _, key := range rv.MapKeys() {
+			name := fmt.Sprint(key.Interface())
+			keys = append(keys, name)
+			values[name] = rv.MapIndex(key)
+		}
+		sort.Strings(keys)
+		for _, name := range keys {
+			if err := soapEncodeItem(enc, xml.StartElement{Name: xml.Name{Local: name}}, values[name]); err != nil {
+				return err
+			}
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+func soapEncodeItem(enc *xml.Encoder, start xml.StartElement, val reflect.Value) error {
+	for val.Kind() == reflect.Interface && !val.IsNil() {
+		val = val.Elem()
+	}
+	switch val.Kind() {
+	case reflect.Map:
+		return soapEncodeMap(enc, start, val)
+	case reflect.Slice, reflect.Array:
+		if val.Type().Elem().Kind() == reflect.Uint8 {
+			break
+		}
+		for i := 0; i < val.Len(); i++ {
+			if err := soapEncodeItem(enc, start, val.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Interface, reflect.Invalid:
+		return enc.EncodeElement("", start)
+	}
+	return enc.EncodeElement(val.Interface(), start)
+}
+
+// SoapFault 服务端返回的 Fault,兼容1.1及1.2
+type SoapFault struct {
+	Version  SoapVersion
+	HttpCode int
+	Code     string //去除前缀的错误码,1.1 为 Client Server 等,1.2 为 Sender Receiver 等
+	Subcode  string //1.2 Subcode 的值,保留前缀
+	Reason   string //1.1 faultstring,1.2 Reason 中的第一个 Text
+	Actor    string //1.1 faultactor,1.2 Role
+	Node     string //1.2 Node
+	Detail   string //detail 中的XML原文,可通过 DecodeDetail 解析
+}
+
+func (fault *SoapFault) Error() string {
+	return fmt.Sprintf("soap fault:%s [%s]", fault.Reason, fault.Code)
+}
+
+// IsClient 是否为调用方的错误,如参数错误,此类错误重试无效
+func (fault *SoapFault) IsClient() bool {
+	return fault.Code == "Client" || fault.Code == "Sender"
+}
+
+// DecodeDetail 解析 detail 中的内容
+func (fault *SoapFault) DecodeDetail(v interface{}) error {
+	return xml.Unmarshal([]byte(fault.Detail), v)
+}
+
+// soapFaultXml 1.1及1.2的 Fault 格式,按元素本地名匹配
+type soapFaultXml struct {
+	XMLName     xml.Name
+	FaultCode   string       `xml:"faultcode"`
+	FaultString string       `xml:"faultstring"`
+	FaultActor  string       `xml:"faultactor"`
+	FaultDetail soapInnerXml `xml:"detail"`
+	Code        struct {
+		Value   string `xml:"Value"`
+		Subcode struct {
+			Value string `xml:"Value"`
+		} `xml:"Subcode"`
+	} `xml:"Code"`
+	Reason struct {
+		Text []string `xml:"Text"`
+	} `xml:"Reason"`
+	Node   string       `xml:"Node"`
+	Role   string       `xml:"Role"`
+	Detail soapInnerXml `xml:"Detail"`
+}
+
+type soapInnerXml struct {
+	Content string `xml:",innerxml"`
+}
+
+// soapFault 解析 Body 中的 Fault,非 Fault 时返回nil
+func soapFault(version SoapVersion, content string) *SoapFault {
+	var data soapFaultXml
+	if err := xml.Unmarshal([]byte(content), &data); err != nil || data.XMLName.Local != "Fault" {
+		return nil
+	}
+	fault := &SoapFault{Version: version}
+	if version == Soap12 {
+		fault.Code = data.Code.Value
+		fault.Subcode = strings.TrimSpace(data.Code.Subcode.Value)
+		if len(data.Reason.Text) > 0 {
+			fault.Reason = data.Reason.Text[0]
+		}
+		fault.Actor = data.Role
+		fault.Node = data.Node
+		fault.Detail = data.Detail.Content
+	} else {
+		fault.Code = data.FaultCode
+		fault.Reason = data.FaultString
+		fault.Actor = data.FaultActor
+		fault.Detail = data.FaultDetail.Content
+	}
+	fault.Code = strings.TrimSpace(fault.Code)
+	if i := strings.LastIndex(fault.Code, ":"); i >= 0 {
+		fault.Code = fault.Code[i+1:]
+	}
+	fault.Reason = strings.TrimSpace(fault.Reason)
+	fault.Detail = strings.TrimSpace(fault.Detail)
+	return fault
+}
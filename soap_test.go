@@ -0,0 +1,145 @@
+package rest_client
+
+import (
+	"context"
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testSoapGoods struct {
+	Id   int    `xml:"Id"`
+	Name string `xml:"Name"`
+}
+
+func TestSoapRestBuild(t *testing.T) {
+	var body, action, contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := ioutil.ReadAll(r.Body)
+		body, action, contentType = string(data), r.Header.Get("SOAPAction"), r.Header.Get("Content-Type")
+		switch r.URL.Path {
+		case "/fault11":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault>` +
+				`<faultcode>soap:Client</faultcode><faultstring>goods not found</faultstring><detail><Error><Code>404</Code></Error></detail>` +
+				`</soap:Fault></soap:Body></soap:Envelope>`))
+		case "/fault12":
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte(`<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body><env:Fault>` +
+				`<env:Code><env:Value>env:Receiver</env:Value><env:Subcode><env:Value>m:Busy</env:Value></env:Subcode></env:Code>` +
+				`<env:Reason><env:Text xml:lang="en">server busy</env:Text></env:Reason><env:Role>http://example.com/role</env:Role>` +
+				`</env:Fault></env:Body></env:Envelope>`))
+		case "/error":
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = w.Write([]byte(`<html>bad gateway</html>`))
+		default:
+			_, _ = w.Write([]byte(`<?xml version="1.0"?><soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body>` +
+				`<GetGoodsResponse xmlns="http://example.com/goods"><Id>1</Id><Name>goods</Name></GetGoodsResponse></soap:Body></soap:Envelope>`))
+		}
+	}))
+	defer server.Close()
+	manager := NewRestClientManager()
+	defer manager.Close()
+	manager.SetRestConfig(&SoapRestConfig{AppRestConfig: AppRestConfig{Name: "soap", AppUrl: server.URL}, Namespace: "http://example.com/goods", SoapHeader: `<Auth>key</Auth>`})
+	client := manager.NewApi(&testBuildApi{name: "soap", builds: map[int]RestBuild{
+		1: &SoapRestBuild{Path: "/goods", Action: "http://example.com/goods/GetGoods", Operation: "GetGoods"},
+		2: &SoapRestBuild{Path: "/fault11", Action: "GetGoods", Operation: "GetGoods"},
+		3: &SoapRestBuild{Path: "/fault12", Action: "GetGoods", Operation: "GetGoods", Version: Soap12},
+		4: &SoapRestBuild{Path: "/error", Operation: "GetGoods"},
+	}})
+
+	res, err := client.DoSync(context.Background(), 1, map[string]interface{}{"Id": 1, "Tags": []string{"a", "b"}, "Shop": map[string]interface{}{"Name": "x&y"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := res.XmlResult()
+	var goods testSoapGoods
+	if err = result.Decode(&goods); err != nil || goods.Id != 1 || goods.Name != "goods" {
+		t.Error("soap result decode error", err)
+	}
+	expect := `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Header><Auth>key</Auth></soap:Header><soap:Body>` +
+		`<GetGoods xmlns="http://example.com/goods"><Id>1</Id><Shop><Name>x&amp;y</Name></Shop><Tags>a</Tags><Tags>b</Tags></GetGoods></soap:Body></soap:Envelope>`
+	if body != xml.Header+expect {
+		t.Error("soap envelope error", body)
+	}
+	if action != `"http://example.com/goods/GetGoods"` || contentType != "text/xml; charset=utf-8" {
+		t.Error("soap 1.1 header error", action, contentType)
+	}
+
+	res, _ = client.DoSync(context.Background(), 1, &testSoapGoods{Id: 2, Name: "goods"})
+	if !strings.Contains(body, `<GetGoods xmlns="http://example.com/goods"><Id>2</Id><Name>goods</Name></GetGoods>`) {
+		t.Error("soap struct param error", body)
+	}
+
+	res, _ = client.DoSync(context.Background(), 2, nil)
+	result = res.XmlResult()
+	fault := result.Fault()
+	if fault == nil || fault.Code != "Client" || !fault.IsClient() || fault.Reason != "goods not found" || fault.HttpCode != http.StatusInternalServerError {
+		t.Fatal("soap 1.1 fault error", result.Err())
+	}
+	var detail struct {
+		Code int `xml:"Code"`
+	}
+	if err = fault.DecodeDetail(&detail); err != nil || detail.Code != 404 {
+		t.Error("soap fault detail error", err)
+	}
+	if res.Err() != result.Err() {
+		t.Error("rest result should keep soap fault")
+	}
+
+	res, _ = client.DoSync(context.Background(), 3, nil)
+	fault = res.XmlResult().Fault()
+	if fault == nil || fault.Code != "Receiver" || fault.Subcode != "m:Busy" || fault.Reason != "server busy" || fault.IsClient() {
+		t.Fatal("soap 1.2 fault error", fault)
+	}
+	if action != "" || contentType != `application/soap+xml; charset=utf-8; action="GetGoods"` {
+		t.Error("soap 1.2 header error", action, contentType)
+	}
+
+	res, _ = client.DoSync(context.Background(), 4, nil)
+	if err = res.XmlResult().Err(); ErrorCode(err) != ErrServerHttp {
+		t.Error("soap http error should be ErrServerHttp", err)
+	}
+}
+
+func TestSoapEnvelope(t *testing.T) {
+	body, err := soapEnvelope(Soap12, nil, xml.Name{Local: "Ping"}, nil)
+	if err != nil || string(body) != xml.Header+`<soap:Envelope xmlns:soap="http://www.w3.org/2003/05/soap-envelope"><soap:Body><Ping></Ping></soap:Body></soap:Envelope>` {
+		t.Error("soap empty param error", string(body), err)
+	}
+	if _, err = soapEnvelope(Soap11, nil, xml.Name{}, map[string]interface{}{"id": 1}); ErrorCode(err) != ErrXmlValid {
+		t.Error("soap map param without operation should fail")
+	}
+}
+
+func TestSoapRestConfigShared(t *testing.T) {
+	var deadline string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline = r.Header.Get("X-Timeout-Ms")
+		_, _ = w.Write([]byte(`<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><PingResponse/></soap:Body></soap:Envelope>`))
+	}))
+	defer server.Close()
+	slow := &AppRestSlow{Threshold: time.Nanosecond}
+	manager := NewRestClientManager()
+	defer manager.Close()
+	manager.SetRestConfig(&SoapRestConfig{AppRestConfig: AppRestConfig{Name: "soap", AppUrl: server.URL, Slow: slow, DeadlineHeader: "X-Timeout-Ms"}})
+	client := manager.NewApi(&testBuildApi{name: "soap", builds: map[int]RestBuild{
+		1: &SoapRestBuild{Operation: "Ping"},
+	}})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	res, err := client.DoSync(ctx, 1, nil)
+	if err != nil || res.XmlResult().Err() != nil {
+		t.Fatal("soap request fail", err)
+	}
+	if stats := slow.Stats(); stats.Calls != 1 || stats.Slow != 1 {
+		t.Error("soap slow check not work", stats)
+	}
+	if len(deadline) == 0 {
+		t.Error("soap deadline header not set")
+	}
+}
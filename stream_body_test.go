@@ -0,0 +1,65 @@
+package rest_client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+type testStreamApi struct {
+	testBuildApi
+}
+
+func (res *testStreamApi) Token(_ context.Context) (string, error) {
+	return "tk&1", nil
+}
+
+func TestAppStreamBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if !AppRestCheckSign(r.Form, "dome111111") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":{"len":` + strconv.Itoa(len(r.Form.Get("content"))) +
+			`,"chunked":` + strconv.FormatBool(r.ContentLength < 0) + `}}`))
+	}))
+	defer server.Close()
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:      "test",
+		AppKey:    "dome 1",
+		AppSecret: "dome111111",
+		AppUrl:    server.URL,
+	})
+	client := manager.NewApi(&testStreamApi{testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodPost, Method: "a.b"},
+		2: &AppRestBuild{HttpMethod: http.MethodGet},
+	}}})
+
+	content := `{"list":[` + strings.Repeat(`"中文&+ ",`, 20000) + `""]}`
+	reader, writer := io.Pipe()
+	go func() {
+		for i := 0; i < len(content); i += 1000 {
+			end := i + 1000
+			if end > len(content) {
+				end = len(content)
+			}
+			_, _ = writer.Write([]byte(content[i:end]))
+		}
+		_ = writer.Close()
+	}()
+	res := (<-client.Do(context.Background(), 1, reader)).JsonResult()
+	if res.Err() != nil || res.MustInt("data.len") != int64(len(content)) || !res.MustBool("data.chunked") {
+		t.Error("stream body error", res.Err())
+	}
+
+	res = (<-client.Do(context.Background(), 2, strings.NewReader(`{"a": 1}`))).JsonResult()
+	if res.Err() != nil || res.MustInt("data.len") != int64(len(`{"a":1}`)) {
+		t.Error("stream param in url error", res.Err())
+	}
+}
@@ -0,0 +1,71 @@
+package rest_client
+
+import (
+	"context"
+)
+
+type tenantKey struct{}
+
+// WithTenant 设置本次请求的租户,服务配置设置了 Tenants 时按租户获取密钥及地址
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFrom 获取请求的租户,未设置时返回空
+func TenantFrom(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// RestTenantCredential 租户的密钥及地址,为空的字段使用服务配置中的值
+type RestTenantCredential struct {
+	AppKey    string
+	AppSecret string
+	AppUrl    string //设置后替换服务配置的地址及负载均衡
+	//租户的TOKEN,如租户各自的 RestTokenCache.Token,为nil时使用 RestTokenApi 的TOKEN
+	Token func(ctx context.Context) (string, error)
+}
+
+// TenantCredentialProvider 按服务配置名及租户获取密钥,未找到时返回nil
+// 每次请求都会调用,需要时由实现方缓存
+type TenantCredentialProvider interface {
+	TenantCredential(ctx context.Context, configName, tenant string) (*RestTenantCredential, error)
+}
+
+// StaticTenantCredentials 固定的租户密钥,KEY为租户
+type StaticTenantCredentials map[string]*RestTenantCredential
+
+func (creds StaticTenantCredentials) TenantCredential(_ context.Context, _, tenant string) (*RestTenantCredential, error) {
+	return creds[tenant], nil
+}
+
+// forTenant 按请求的租户替换密钥及地址,未设置租户时返回原配置
+func (clf *AppRestConfig) forTenant(ctx context.Context) (*AppRestConfig, error) {
+	if clf.Tenants == nil {
+		return clf, nil
+	}
+	tenant := TenantFrom(ctx)
+	if len(tenant) == 0 {
+		return clf, nil
+	}
+	cred, err := clf.Tenants.TenantCredential(ctx, clf.Name, tenant)
+	if err != nil {
+		return nil, err
+	}
+	if cred == nil {
+		return nil, NewRestClientError(ErrTenantMissing, "tenant credential not found:"+tenant)
+	}
+	config := *clf
+	if len(cred.AppKey) > 0 {
+		config.AppKey = cred.AppKey
+	}
+	if len(cred.AppSecret) > 0 {
+		config.AppSecret = cred.AppSecret
+	}
+	if len(cred.AppUrl) > 0 {
+		config.AppUrl = cred.AppUrl
+		config.tenantUrl = true
+	}
+	config.tenantToken = cred.Token
+	return &config, nil
+}
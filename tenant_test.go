@@ -0,0 +1,81 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAppRestTenant(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		secret := map[string]string{"dome1": "dome111111", "tenant_a": "secret_a"}[r.Form.Get("app")]
+		if !AppRestCheckSign(r.Form, secret) {
+			_, _ = w.Write([]byte(`{"result":{"code":"403","state":"sign"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":"` + r.Form.Get("app") + `"}`))
+	}))
+	defer server.Close()
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:      "test",
+		AppKey:    "dome1",
+		AppSecret: "dome111111",
+		AppUrl:    server.URL,
+		Tenants: StaticTenantCredentials{
+			"a": {AppKey: "tenant_a", AppSecret: "secret_a"},
+		},
+	})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{1: &AppRestBuild{}}})
+	for tenant, app := range map[string]string{"": "dome1", "a": "tenant_a"} {
+		res := (<-client.Do(WithTenant(context.Background(), tenant), 1, nil)).JsonResult()
+		if res.Err() != nil || res.MustString("data") != app {
+			t.Errorf("tenant %s error:%v", tenant, res.Err())
+		}
+	}
+	if err := (<-client.Do(WithTenant(context.Background(), "b"), 1, nil)).JsonResult().Err(); ErrorCode(err) != ErrTenantMissing {
+		t.Error("missing tenant not return error")
+	}
+}
+
+type testTenantTokenApi struct {
+	testBuildApi
+}
+
+func (api *testTenantTokenApi) Token(_ context.Context) (string, error) {
+	return "shared", nil
+}
+
+func TestAppRestTenantUrl(t *testing.T) {
+	handler := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = r.ParseForm()
+			_, _ = w.Write([]byte(`{"result":{"code":"200","state":"ok"},"data":{"server":"` + name + `","token":"` + r.Form.Get("token") + `"}}`))
+		}))
+	}
+	shared, tenant := handler("shared"), handler("tenant")
+	defer shared.Close()
+	defer tenant.Close()
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{
+		Name:      "test",
+		AppKey:    "dome1",
+		AppSecret: "dome111111",
+		AppUrl:    shared.URL,
+		Balancer:  NewEwmaBalancer(shared.URL),
+		Tenants: StaticTenantCredentials{
+			"a": {AppUrl: tenant.URL, Token: func(_ context.Context) (string, error) {
+				return "token_a", nil
+			}},
+		},
+	})
+	client := manager.NewApi(&testTenantTokenApi{testBuildApi{name: "test", builds: map[int]RestBuild{1: &AppRestBuild{}}}})
+	for name, want := range map[string][2]string{"": {"shared", "shared"}, "a": {"tenant", "token_a"}} {
+		res := (<-client.Do(WithTenant(context.Background(), name), 1, nil)).JsonResult("data")
+		if res.Err() != nil || res.MustString("server") != want[0] || res.MustString("token") != want[1] {
+			t.Errorf("tenant %s url or token wrong:%v %s", name, res.Err(), res.MustString("server"))
+		}
+	}
+}
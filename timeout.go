@@ -0,0 +1,75 @@
+package rest_client
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+type dialTimeoutKey struct{}
+
+// timeoutDialContext 按ctx中接口设置的时间限制建立连接,未设置时使用连接配置
+func timeoutDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if timeout, ok := ctx.Value(dialTimeoutKey{}).(time.Duration); ok && timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		return dial(ctx, network, addr)
+	}
+}
+
+// restTimeout 单次请求的超时控制,通过ctx实现,不修改共享的 Transport
+type restTimeout struct {
+	cancel context.CancelFunc
+	header time.Duration
+	timer  *time.Timer
+	fired  int32 //等待HEADER超时已触发
+}
+
+// newRestTimeout 创建请求使用的ctx,total 从创建时开始计时,包含排队时间
+func newRestTimeout(ctx context.Context, dial, header, total time.Duration) (context.Context, *restTimeout) {
+	if dial > 0 {
+		ctx = context.WithValue(ctx, dialTimeoutKey{}, dial)
+	}
+	timeout := &restTimeout{header: header}
+	if total > 0 {
+		ctx, timeout.cancel = context.WithTimeout(ctx, total)
+	} else {
+		ctx, timeout.cancel = context.WithCancel(ctx)
+	}
+	return ctx, timeout
+}
+
+// start 发送请求前开始等待HEADER计时
+func (timeout *restTimeout) start() {
+	if timeout.header > 0 {
+		timeout.timer = time.AfterFunc(timeout.header, func() {
+			atomic.StoreInt32(&timeout.fired, 1)
+			timeout.cancel()
+		})
+	}
+}
+
+// gotHeader 收到返回HEADER后停止计时
+func (timeout *restTimeout) gotHeader() {
+	if timeout.timer != nil {
+		timeout.timer.Stop()
+	}
+}
+
+// release 请求结束时释放
+func (timeout *restTimeout) release() {
+	timeout.gotHeader()
+	timeout.cancel()
+}
+
+// error 等待HEADER超时转为超时错误,其他ctx错误转为统一错误码
+func (timeout *restTimeout) error(err error) error {
+	if atomic.LoadInt32(&timeout.fired) == 1 {
+		return &RestClientError{Code: ErrTimeout, Msg: "timeout awaiting response headers:" + err.Error(), err: err}
+	}
+	return cancelError(err)
+}
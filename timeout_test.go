@@ -0,0 +1,69 @@
+package rest_client
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAppRestBuildTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/header":
+			time.Sleep(100 * time.Millisecond)
+		case "/body":
+			w.(http.Flusher).Flush()
+			time.Sleep(100 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte(`{"a":1}`))
+	}))
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true, Path: "/header", ResponseHeaderTimeout: 20 * time.Millisecond},
+		2: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true, Path: "/header", Timeout: 20 * time.Millisecond},
+		3: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true, Path: "/body", ResponseHeaderTimeout: 50 * time.Millisecond},
+		4: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true, Path: "/body", TotalTimeout: 50 * time.Millisecond},
+		5: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true, Path: "/header", TotalTimeout: 20 * time.Millisecond},
+	})
+	for _, key := range []int{1, 2} {
+		if err := (<-client.Do(context.Background(), key, nil)).Err(); ErrorCode(err) != ErrTimeout {
+			t.Error("header timeout error", key, err)
+		}
+	}
+	//收到HEADER后不再受等待HEADER超时限制
+	if err := (<-client.Do(context.Background(), 3, nil)).JsonResult().Err(); err != nil {
+		t.Error("header timeout on body read", err)
+	}
+	res := <-client.Do(context.Background(), 4, nil)
+	if res.Err() != nil {
+		t.Fatal(res.Err())
+	}
+	if _, err := ioutil.ReadAll(res); !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("total timeout on body read error", err)
+	}
+	if err := (<-client.Do(context.Background(), 5, nil)).Err(); ErrorCode(err) != ErrCanceled || !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("total timeout error", err)
+	}
+}
+
+func TestTimeoutDialContext(t *testing.T) {
+	var deadline time.Time
+	dial := timeoutDialContext(func(ctx context.Context, _, _ string) (net.Conn, error) {
+		deadline, _ = ctx.Deadline()
+		return nil, errors.New("dial")
+	})
+	_, _ = dial(context.Background(), "tcp", "")
+	if !deadline.IsZero() {
+		t.Error("dial deadline set without timeout")
+	}
+	ctx, _ := newRestTimeout(context.Background(), time.Second, 0, 0)
+	_, _ = dial(ctx, "tcp", "")
+	if time.Until(deadline) <= 0 || time.Until(deadline) > time.Second {
+		t.Error("dial timeout not use", deadline)
+	}
+}
@@ -0,0 +1,149 @@
+package rest_client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RestTimings 单次请求各阶段耗时,未经过的阶段为0,如复用连接时无 DNS Connect TLS
+type RestTimings struct {
+	Queued    time.Duration //排队等待时间,包含后台限流,调度及并发隔离
+	DNS       time.Duration //域名解析
+	Connect   time.Duration //建立TCP连接
+	TLS       time.Duration //TLS握手
+	FirstByte time.Duration //请求发送完成到收到返回首字节
+	Total     time.Duration //开始到内容读取完成,未读取完时为到返回HEADER
+}
+
+func (t RestTimings) String() string {
+	var out strings.Builder
+	for i, item := range []struct {
+		name string
+		val  time.Duration
+	}{
+		{"queued", t.Queued}, {"dns", t.DNS}, {"connect", t.Connect},
+		{"tls", t.TLS}, {"first_byte", t.FirstByte}, {"total", t.Total},
+	} {
+		if i > 0 {
+			out.WriteByte(' ')
+		}
+		out.WriteString(item.name)
+		out.WriteByte('=')
+		out.WriteString(item.val.String())
+	}
+	return out.String()
+}
+
+// restTiming 记录请求过程的时间点
+type restTiming struct {
+	lock      sync.Mutex
+	start     time.Time
+	dnsStart  time.Time
+	connStart time.Time
+	tlsStart  time.Time
+	wrote     time.Time
+	timings   RestTimings
+	slow      *restSlowCheck //慢请求检测,为nil时不检测
+}
+
+func newRestTiming() *restTiming {
+	return &restTiming{start: time.Now()}
+}
+
+// queue 开始排队,返回结束排队的函数
+func (timing *restTiming) queue() func() {
+	start := time.Now()
+	return func() {
+		timing.lock.Lock()
+		timing.timings.Queued += time.Since(start)
+		timing.lock.Unlock()
+	}
+}
+
+// since 记录从开始时间点到当前的耗时
+func (timing *restTiming) since(start *time.Time, val *time.Duration) {
+	timing.lock.Lock()
+	if !start.IsZero() {
+		*val = time.Since(*start)
+	}
+	timing.lock.Unlock()
+}
+
+// mark 记录时间点
+func (timing *restTiming) mark(at *time.Time) {
+	timing.lock.Lock()
+	*at = time.Now()
+	timing.lock.Unlock()
+}
+
+// withTrace 在ctx上附加 httptrace 记录连接各阶段
+func (timing *restTiming) withTrace(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(_ httptrace.DNSStartInfo) { timing.mark(&timing.dnsStart) },
+		DNSDone:  func(_ httptrace.DNSDoneInfo) { timing.since(&timing.dnsStart, &timing.timings.DNS) },
+		ConnectStart: func(_, _ string) {
+			timing.mark(&timing.connStart)
+		},
+		ConnectDone: func(_, _ string, _ error) {
+			timing.since(&timing.connStart, &timing.timings.Connect)
+		},
+		TLSHandshakeStart: func() { timing.mark(&timing.tlsStart) },
+		TLSHandshakeDone: func(_ tls.ConnectionState, _ error) {
+			timing.since(&timing.tlsStart, &timing.timings.TLS)
+		},
+		WroteRequest:         func(_ httptrace.WroteRequestInfo) { timing.mark(&timing.wrote) },
+		GotFirstResponseByte: func() { timing.since(&timing.wrote, &timing.timings.FirstByte) },
+	})
+}
+
+// finish 记录总耗时,内容读取完成时再次调用更新
+func (timing *restTiming) finish() {
+	timing.lock.Lock()
+	timing.timings.Total = time.Since(timing.start)
+	timing.lock.Unlock()
+}
+
+// get 返回当前记录的耗时
+func (timing *restTiming) get() RestTimings {
+	timing.lock.Lock()
+	defer timing.lock.Unlock()
+	return timing.timings
+}
+
+// isTimeout 是否为超时错误
+func isTimeout(err error) bool {
+	if clientErr, ok := err.(*RestClientError); ok && clientErr.Code == ErrTimeout {
+		return true
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// timeoutError 超时错误附加各阶段耗时,便于定位慢在哪个阶段
+func (timing *restTiming) timeoutError(err error) error {
+	if timing == nil || !isTimeout(err) {
+		return err
+	}
+	msg := " (" + timing.get().String() + ")"
+	if clientErr, ok := err.(*RestClientError); ok {
+		return &RestClientError{Code: clientErr.Code, Msg: clientErr.Msg + msg, err: clientErr.err}
+	}
+	return &RestClientError{Code: ErrTimeout, Msg: "request timeout:" + err.Error() + msg, err: err}
+}
+
+// Timings 返回请求各阶段耗时,非 AppRestBuild 创建的结果返回0值
+func (res *RestResult) Timings() RestTimings {
+	if res == nil || res.timing == nil {
+		return RestTimings{}
+	}
+	return res.timing.get()
+}
@@ -0,0 +1,53 @@
+package rest_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRestResultTimings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		} else {
+			time.Sleep(20 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte(`{"a":1}`))
+	}))
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true},
+		2: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true, Path: "/slow", Timeout: 50 * time.Millisecond},
+	})
+	res := <-client.Do(context.Background(), 1, nil)
+	if err := res.JsonResult().Err(); err != nil {
+		t.Fatal(err)
+	}
+	timings := res.Timings()
+	if timings.Connect <= 0 || timings.FirstByte < 20*time.Millisecond || timings.Total < timings.FirstByte {
+		t.Error("timings error", timings)
+	}
+	if (&RestResult{}).Timings() != (RestTimings{}) {
+		t.Error("timings not zero")
+	}
+
+	res = <-client.Do(context.Background(), 2, nil)
+	//新版本Go等待HEADER超时的错误可通过 errors.Is 判断为 DeadlineExceeded
+	if code := ErrorCode(res.Err()); (code != ErrTimeout && code != ErrCanceled) || !strings.Contains(res.Err().Error(), "total=") {
+		t.Error("timeout error not include timings", res.Err())
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	res = <-client.Do(ctx, 1, nil)
+	_ = res.JsonResult()
+	res = <-client.Do(ctx, 2, nil)
+	err := res.Err()
+	if ErrorCode(err) != ErrCanceled || !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "total=") {
+		t.Error("ctx timeout error not include timings", err)
+	}
+}
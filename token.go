@@ -0,0 +1,258 @@
+package rest_client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// TOKEN生命周期事件类型
+const (
+	TokenAcquire    = "acquire"    //首次获取
+	TokenRefresh    = "refresh"    //到期前刷新
+	TokenExpire     = "expire"     //使用时已过期
+	TokenInvalidate = "invalidate" //主动失效,如服务端返回TOKEN错误
+)
+
+// RestTokenEvent TOKEN生命周期事件,不包含TOKEN原文
+type RestTokenEvent struct {
+	Type        string
+	Name        string    //TOKEN名称,用于区分多个TOKEN
+	Fingerprint string    //TOKEN指纹,用于跨服务追踪同一个TOKEN
+	ExpireAt    time.Time //过期时间
+	Reason      string    //失效原因
+	Err         error     //获取失败时的错误
+	Time        time.Time
+}
+
+// TokenFingerprint 生成TOKEN指纹,不可还原TOKEN
+func TokenFingerprint(token string) string {
+	if len(token) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(token))
+	return "sha256:" + hex.EncodeToString(sum[:6])
+}
+
+// RestTokenStore 多实例共享的TOKEN存储,如 RestRedisTokenStore
+type RestTokenStore interface {
+	Load(ctx context.Context, name string) (token string, expireAt time.Time, err error) //不存在时返回空TOKEN
+	Save(ctx context.Context, name, token string, expireAt time.Time) error
+	Delete(ctx context.Context, name, token string) error //仅删除与 token 相同的TOKEN,避免删除其他实例已刷新的TOKEN
+	//获取刷新锁,已被其他实例获取时 unlock 为nil
+	Lock(ctx context.Context, name string, ttl time.Duration) (unlock func(), err error)
+}
+
+// RestTokenCache 缓存TOKEN并在过期前刷新,可在 RestTokenApi.Token 中使用
+// 设置 Store 时多个实例共享TOKEN,获取到刷新锁的实例刷新,其他实例等待并使用刷新后的TOKEN
+type RestTokenCache struct {
+	Name          string
+	Fetch         func(ctx context.Context) (token string, expire time.Duration, err error) //获取TOKEN及有效时间
+	RefreshBefore time.Duration                                                             //提前刷新时间
+	Listener      func(event *RestTokenEvent)                                               //生命周期事件回调
+	Store         RestTokenStore                                                            //共享存储,为nil时仅进程内缓存
+	LockWait      time.Duration                                                             //等待其他实例刷新的最长时间,也是刷新锁的有效时间,默认5秒
+	lock          sync.Mutex
+	token         string
+	expireAt      time.Time
+	refresh       chan struct{} //正在获取时不为nil,获取完成后关闭,获取及存储读写时不持有锁
+}
+
+// NewRestTokenCache 创建TOKEN缓存,默认提前30秒刷新
+func NewRestTokenCache(name string, fetch func(ctx context.Context) (string, time.Duration, error)) *RestTokenCache {
+	return &RestTokenCache{
+		Name:          name,
+		Fetch:         fetch,
+		RefreshBefore: 30 * time.Second,
+	}
+}
+
+// event 创建生命周期事件,在持有锁时创建以记录当时的状态,未设置 Listener 时为nil
+func (cache *RestTokenCache) event(eventType string, token string, expireAt time.Time, reason string, err error) *RestTokenEvent {
+	if cache.Listener == nil {
+		return nil
+	}
+	return &RestTokenEvent{
+		Type:        eventType,
+		Name:        cache.Name,
+		Fingerprint: TokenFingerprint(token),
+		ExpireAt:    expireAt,
+		Reason:      reason,
+		Err:         err,
+		Time:        time.Now(),
+	}
+}
+
+// emit 回调生命周期事件,需在释放锁后调用,Listener 中可以调用 Token 及 Invalidate
+func (cache *RestTokenCache) emit(event *RestTokenEvent) {
+	if event != nil {
+		cache.Listener(event)
+	}
+}
+
+// Token 获取TOKEN,未获取或即将过期时重新获取,同时只有一个调用获取,其他调用等待获取完成
+func (cache *RestTokenCache) Token(ctx context.Context) (string, error) {
+	cache.lock.Lock()
+	for {
+		now := time.Now()
+		if len(cache.token) > 0 && now.Add(cache.RefreshBefore).Before(cache.expireAt) {
+			token := cache.token
+			cache.lock.Unlock()
+			return token, nil
+		}
+		if cache.refresh == nil {
+			break
+		}
+		//其他调用正在获取,未过期的TOKEN继续使用
+		if len(cache.token) > 0 && now.Before(cache.expireAt) {
+			token := cache.token
+			cache.lock.Unlock()
+			return token, nil
+		}
+		refresh := cache.refresh
+		cache.lock.Unlock()
+		select {
+		case <-ctx.Done():
+			return "", cancelError(ctx.Err())
+		case <-refresh:
+		}
+		cache.lock.Lock()
+	}
+	now := time.Now()
+	eventType := TokenAcquire
+	var expired *RestTokenEvent
+	if len(cache.token) > 0 {
+		eventType = TokenRefresh
+		if !now.Before(cache.expireAt) {
+			expired = cache.event(TokenExpire, cache.token, cache.expireAt, "", nil)
+			cache.token = ""
+			eventType = TokenAcquire
+		}
+	}
+	refresh := make(chan struct{})
+	cache.refresh = refresh
+	cache.lock.Unlock()
+	cache.emit(expired)
+
+	token, expireAt, fetched, err := cache.fetch(ctx, now)
+
+	cache.lock.Lock()
+	cache.refresh = nil
+	close(refresh)
+	if err != nil {
+		event := cache.event(eventType, "", time.Time{}, "", err)
+		token = ""
+		if len(cache.token) > 0 && time.Now().Before(cache.expireAt) {
+			//刷新失败时未过期的TOKEN继续使用
+			token, err = cache.token, nil
+		}
+		cache.lock.Unlock()
+		cache.emit(event)
+		return token, err
+	}
+	cache.token = token
+	cache.expireAt = expireAt
+	var event *RestTokenEvent
+	if fetched {
+		event = cache.event(eventType, token, cache.expireAt, "", nil)
+	}
+	cache.lock.Unlock()
+	cache.emit(event)
+	return token, nil
+}
+
+// fresh TOKEN是否有效且不需要刷新
+func (cache *RestTokenCache) fresh(token string, expireAt time.Time) bool {
+	return len(token) > 0 && time.Now().Add(cache.RefreshBefore).Before(expireAt)
+}
+
+// fetch 获取TOKEN,设置 Store 时优先使用共享的TOKEN,fetched 为是否调用了 Fetch
+func (cache *RestTokenCache) fetch(ctx context.Context, now time.Time) (token string, expireAt time.Time, fetched bool, err error) {
+	if cache.Store != nil {
+		token, expireAt, unlock := cache.shared(ctx)
+		if len(token) > 0 {
+			return token, expireAt, false, nil
+		}
+		if unlock != nil {
+			defer unlock()
+		}
+	}
+	token, expire, err := cache.Fetch(ctx)
+	if err != nil {
+		return "", time.Time{}, true, err
+	}
+	expireAt = now.Add(expire)
+	if cache.Store != nil {
+		_ = cache.Store.Save(ctx, cache.Name, token, expireAt)
+	}
+	return token, expireAt, true, nil
+}
+
+// shared 从共享存储获取TOKEN,需刷新时仅获取到锁的实例刷新,其他实例等待
+// 返回空TOKEN时由本实例获取,unlock 不为nil时已获取到刷新锁,存储不可用或等待超时时为nil
+func (cache *RestTokenCache) shared(ctx context.Context) (string, time.Time, func()) {
+	wait := cache.LockWait
+	if wait <= 0 {
+		wait = 5 * time.Second
+	}
+	deadline := time.Now().Add(wait)
+	for {
+		token, expireAt, err := cache.Store.Load(ctx, cache.Name)
+		if err != nil {
+			return "", time.Time{}, nil
+		}
+		if cache.fresh(token, expireAt) {
+			return token, expireAt, nil
+		}
+		unlock, err := cache.Store.Lock(ctx, cache.Name, wait)
+		if err != nil {
+			return "", time.Time{}, nil
+		}
+		if unlock != nil {
+			//获取锁前其他实例可能已刷新
+			token, expireAt, err = cache.Store.Load(ctx, cache.Name)
+			if err == nil && cache.fresh(token, expireAt) {
+				unlock()
+				return token, expireAt, nil
+			}
+			return "", time.Time{}, unlock
+		}
+		if !time.Now().Before(deadline) {
+			//等待超时,未过期的TOKEN继续使用
+			if len(token) > 0 && time.Now().Before(expireAt) {
+				return token, expireAt, nil
+			}
+			return "", time.Time{}, nil
+		}
+		timer := time.NewTimer(tokenLockPoll)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return "", time.Time{}, nil
+		case <-timer.C:
+		}
+	}
+}
+
+// tokenLockPoll 等待其他实例刷新时查询共享存储的间隔
+const tokenLockPoll = 20 * time.Millisecond
+
+// Invalidate 使当前TOKEN失效,下次使用时重新获取,ctx 用于删除共享存储中的TOKEN
+func (cache *RestTokenCache) Invalidate(ctx context.Context, reason string) {
+	cache.lock.Lock()
+	token := cache.token
+	if len(token) == 0 {
+		cache.lock.Unlock()
+		return
+	}
+	event := cache.event(TokenInvalidate, token, cache.expireAt, reason, nil)
+	cache.token = ""
+	cache.expireAt = time.Time{}
+	cache.lock.Unlock()
+	cache.emit(event)
+	if cache.Store != nil {
+		_ = cache.Store.Delete(ctx, cache.Name, token)
+	}
+}
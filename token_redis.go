@@ -0,0 +1,105 @@
+package rest_client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// RestRedisLockClient 共享TOKEN使用的 Redis 命令,可适配 go-redis 等客户端
+type RestRedisLockClient interface {
+	RestRedisClient
+	SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	Del(ctx context.Context, key string) error
+}
+
+// RestRedisTokenStore Redis 共享TOKEN存储,TOKEN KEY为 Namespace:名称,刷新锁为 Namespace:名称:lock
+type RestRedisTokenStore struct {
+	Client    RestRedisLockClient
+	Namespace string        //KEY前缀,默认 rest_client:token
+	Timeout   time.Duration //单次 Redis 操作超时,默认100ms
+}
+
+// NewRestRedisTokenStore 创建 Redis 共享TOKEN存储
+func NewRestRedisTokenStore(client RestRedisLockClient) *RestRedisTokenStore {
+	return &RestRedisTokenStore{
+		Client:    client,
+		Namespace: "rest_client:token",
+		Timeout:   100 * time.Millisecond,
+	}
+}
+
+// redisToken 保存的TOKEN内容
+type redisToken struct {
+	Token    string    `json:"token"`
+	ExpireAt time.Time `json:"expire_at"`
+}
+
+func (store *RestRedisTokenStore) redisContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if store.Timeout > 0 {
+		return context.WithTimeout(ctx, store.Timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+func (store *RestRedisTokenStore) Load(ctx context.Context, name string) (string, time.Time, error) {
+	ctx, cancel := store.redisContext(ctx)
+	defer cancel()
+	data, err := store.Client.Get(ctx, store.Namespace+":"+name)
+	if err != nil || data == nil {
+		return "", time.Time{}, err
+	}
+	var token redisToken
+	if err = json.Unmarshal(data, &token); err != nil {
+		return "", time.Time{}, err
+	}
+	return token.Token, token.ExpireAt, nil
+}
+
+func (store *RestRedisTokenStore) Save(ctx context.Context, name, token string, expireAt time.Time) error {
+	ttl := time.Until(expireAt)
+	if ttl <= 0 {
+		return nil
+	}
+	data, err := json.Marshal(&redisToken{Token: token, ExpireAt: expireAt})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := store.redisContext(ctx)
+	defer cancel()
+	return store.Client.Set(ctx, store.Namespace+":"+name, data, ttl)
+}
+
+func (store *RestRedisTokenStore) Delete(ctx context.Context, name, token string) error {
+	current, _, err := store.Load(ctx, name)
+	if err != nil || current != token {
+		return err
+	}
+	ctx, cancel := store.redisContext(ctx)
+	defer cancel()
+	return store.Client.Del(ctx, store.Namespace+":"+name)
+}
+
+func (store *RestRedisTokenStore) Lock(ctx context.Context, name string, ttl time.Duration) (func(), error) {
+	key := store.Namespace + ":" + name + ":lock"
+	value := make([]byte, 16)
+	_, _ = rand.Read(value)
+	value = []byte(hex.EncodeToString(value))
+	lockCtx, cancel := store.redisContext(ctx)
+	defer cancel()
+	ok, err := store.Client.SetNX(lockCtx, key, value, ttl)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return func() {
+		//仅释放自己持有的锁,超时后锁可能已被其他实例获取
+		unlockCtx, cancel := store.redisContext(context.Background())
+		defer cancel()
+		if current, err := store.Client.Get(unlockCtx, key); err == nil && bytes.Equal(current, value) {
+			_ = store.Client.Del(unlockCtx, key)
+		}
+	}, nil
+}
@@ -0,0 +1,77 @@
+package rest_client
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRestRedisTokenStore(t *testing.T) {
+	redis := newTestRedisClient()
+	store := NewRestRedisTokenStore(redis)
+	var fetch int64
+	newCache := func() *RestTokenCache {
+		cache := NewRestTokenCache("auth", func(_ context.Context) (string, time.Duration, error) {
+			n := atomic.AddInt64(&fetch, 1)
+			time.Sleep(30 * time.Millisecond)
+			return "token" + strconv.FormatInt(n, 10), time.Minute, nil
+		})
+		cache.Store = store
+		return cache
+	}
+	//多个实例同时获取时只有一个实例刷新
+	caches := []*RestTokenCache{newCache(), newCache(), newCache()}
+	var wg sync.WaitGroup
+	tokens := make([]string, 9)
+	for i := range tokens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			tokens[i], _ = caches[i%len(caches)].Token(context.Background())
+		}(i)
+	}
+	wg.Wait()
+	for _, token := range tokens {
+		if token != "token1" {
+			t.Fatal("shared token error", tokens)
+		}
+	}
+	if atomic.LoadInt64(&fetch) != 1 {
+		t.Error("shared token should fetch once", fetch)
+	}
+	if _, ok := redis.data["rest_client:token:auth:lock"]; ok {
+		t.Error("refresh lock should release")
+	}
+	if ttl := redis.ttl["rest_client:token:auth"]; ttl <= 0 || ttl > time.Minute {
+		t.Error("shared token ttl error", ttl)
+	}
+
+	//失效时删除共享的TOKEN,其他实例重新获取
+	caches[0].Invalidate(context.Background(), "auth fail")
+	token, _ := caches[0].Token(context.Background())
+	if token != "token2" {
+		t.Error("invalidate shared token error", token)
+	}
+	if err := store.Delete(context.Background(), "auth", "token1"); err != nil {
+		t.Fatal(err)
+	}
+	if token, _, _ = store.Load(context.Background(), "auth"); token != "token2" {
+		t.Error("delete should not remove refreshed token", token)
+	}
+
+	//其他实例持有锁超时后自行获取
+	unlock, _ := store.Lock(context.Background(), "other", time.Minute)
+	cache := newCache()
+	cache.Name = "other"
+	cache.LockWait = 50 * time.Millisecond
+	if token, err := cache.Token(context.Background()); err != nil || token != "token3" {
+		t.Error("lock wait timeout should fetch", token, err)
+	}
+	unlock()
+	if locked, _ := store.Lock(context.Background(), "other", time.Minute); locked == nil {
+		t.Error("unlock error")
+	}
+}
@@ -0,0 +1,112 @@
+package rest_client
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRestTokenCache(t *testing.T) {
+	fetch := 0
+	var events []*RestTokenEvent
+	cache := NewRestTokenCache("auth", func(_ context.Context) (string, time.Duration, error) {
+		fetch++
+		return "token" + strconv.Itoa(fetch), 50 * time.Millisecond, nil
+	})
+	cache.RefreshBefore = 0
+	cache.Listener = func(event *RestTokenEvent) {
+		events = append(events, event)
+	}
+	token, _ := cache.Token(context.Background())
+	token2, _ := cache.Token(context.Background())
+	if token != "token1" || token2 != "token1" {
+		t.Error("token cache error")
+	}
+	time.Sleep(60 * time.Millisecond)
+	token, _ = cache.Token(context.Background())
+	if token != "token2" {
+		t.Error("token expire refresh error")
+	}
+	cache.Invalidate(context.Background(), "auth fail")
+	token, _ = cache.Token(context.Background())
+	if token != "token3" {
+		t.Error("token invalidate error")
+	}
+	types := ""
+	for _, event := range events {
+		types += event.Type + ","
+		if event.Fingerprint == "" || event.Fingerprint == "token1" {
+			t.Error("token fingerprint error")
+		}
+	}
+	if types != "acquire,expire,acquire,invalidate,acquire," {
+		t.Error("token events error:" + types)
+	}
+}
+
+func TestRestTokenCacheUnlockFetch(t *testing.T) {
+	release := make(chan struct{})
+	var fetch int32
+	cache := NewRestTokenCache("auth", func(_ context.Context) (string, time.Duration, error) {
+		atomic.AddInt32(&fetch, 1)
+		<-release
+		return "token", time.Minute, nil
+	})
+	done := make(chan string)
+	go func() {
+		token, _ := cache.Token(context.Background())
+		done <- token
+	}()
+	for atomic.LoadInt32(&fetch) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	invalidated := make(chan struct{})
+	go func() {
+		cache.Invalidate(context.Background(), "auth fail")
+		close(invalidated)
+	}()
+	select {
+	case <-invalidated:
+	case <-time.After(time.Second):
+		t.Fatal("invalidate blocked by fetch")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := cache.Token(ctx); ErrorCode(err) != ErrCanceled {
+		t.Error("waiting token must end with ctx", err)
+	}
+	close(release)
+	if token := <-done; token != "token" || atomic.LoadInt32(&fetch) != 1 {
+		t.Error("token fetch wrong", token, fetch)
+	}
+}
+
+func TestRestTokenCacheListenerUnlocked(t *testing.T) {
+	cache := NewRestTokenCache("auth", func(_ context.Context) (string, time.Duration, error) {
+		return "token", time.Minute, nil
+	})
+	var types []string
+	cache.Listener = func(event *RestTokenEvent) {
+		types = append(types, event.Type)
+		//回调时不持有锁,可以再次获取TOKEN
+		if token, err := cache.Token(context.Background()); event.Type == TokenAcquire && (err != nil || token != "token") {
+			t.Error("token in listener wrong", token, err)
+		}
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = cache.Token(context.Background())
+		cache.Invalidate(context.Background(), "auth fail")
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("listener called with lock held")
+	}
+	if len(types) < 2 || types[0] != TokenAcquire || types[1] != TokenInvalidate {
+		t.Error("token events wrong", types)
+	}
+}
@@ -0,0 +1,148 @@
+package rest_client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// RestTraceContext 调用链上下文
+type RestTraceContext struct {
+	TraceId    string //32位十六进制
+	SpanId     string //16位十六进制,当前服务的SPAN
+	Sampled    bool
+	TraceState string //W3C tracestate
+}
+
+type traceKey struct{}
+
+// WithTraceContext 设置调用链上下文,对外请求时通过 RestPropagator 注入HEADER
+func WithTraceContext(ctx context.Context, trace *RestTraceContext) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+// TraceContextFrom 获取调用链上下文,不存在时返回nil
+func TraceContextFrom(ctx context.Context) *RestTraceContext {
+	trace, _ := ctx.Value(traceKey{}).(*RestTraceContext)
+	return trace
+}
+
+func isHex(val string, size int) bool {
+	if len(val) != size {
+		return false
+	}
+	_, err := hex.DecodeString(val)
+	return err == nil && strings.Trim(val, "0") != ""
+}
+
+// ExtractTraceContext 从收到的请求HEADER中解析调用链,支持 traceparent 及 B3,不存在时返回nil
+func ExtractTraceContext(header http.Header) *RestTraceContext {
+	if parent := header.Get("traceparent"); len(parent) > 0 {
+		parts := strings.Split(strings.TrimSpace(parent), "-")
+		if len(parts) >= 4 && len(parts[0]) == 2 && isHex(parts[1], 32) && isHex(parts[2], 16) && len(parts[3]) == 2 {
+			flags, err := hex.DecodeString(parts[3])
+			if err == nil {
+				return &RestTraceContext{
+					TraceId:    strings.ToLower(parts[1]),
+					SpanId:     strings.ToLower(parts[2]),
+					Sampled:    flags[0]&1 == 1,
+					TraceState: header.Get("tracestate"),
+				}
+			}
+		}
+	}
+	if single := header.Get("b3"); len(single) > 0 {
+		parts := strings.Split(single, "-")
+		if len(parts) >= 2 {
+			trace := &RestTraceContext{TraceId: b3TraceId(parts[0]), SpanId: parts[1]}
+			if len(parts) >= 3 {
+				trace.Sampled = parts[2] == "1" || parts[2] == "d"
+			}
+			if isHex(trace.TraceId, 32) && isHex(trace.SpanId, 16) {
+				return trace
+			}
+		}
+	}
+	traceId := b3TraceId(header.Get("X-B3-TraceId"))
+	spanId := header.Get("X-B3-SpanId")
+	if isHex(traceId, 32) && isHex(spanId, 16) {
+		return &RestTraceContext{
+			TraceId: traceId,
+			SpanId:  spanId,
+			Sampled: header.Get("X-B3-Sampled") == "1" || header.Get("X-B3-Flags") == "1",
+		}
+	}
+	return nil
+}
+
+// b3TraceId B3允许64位TRACE ID,补齐为128位
+func b3TraceId(traceId string) string {
+	if len(traceId) == 16 {
+		return "0000000000000000" + traceId
+	}
+	return traceId
+}
+
+// newSpanId 生成对外请求的SPAN ID
+func newSpanId() string {
+	buf := make([]byte, 8)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// RestPropagator 调用链HEADER注入,可自定义实现其他格式
+// spanId 为本次对外请求新生成的SPAN
+type RestPropagator interface {
+	Inject(trace *RestTraceContext, spanId string, header http.Header)
+}
+
+// W3CPropagator 注入 traceparent 及 tracestate
+type W3CPropagator struct{}
+
+func (prop *W3CPropagator) Inject(trace *RestTraceContext, spanId string, header http.Header) {
+	flags := "00"
+	if trace.Sampled {
+		flags = "01"
+	}
+	header.Set("traceparent", "00-"+trace.TraceId+"-"+spanId+"-"+flags)
+	if len(trace.TraceState) > 0 {
+		header.Set("tracestate", trace.TraceState)
+	}
+}
+
+// B3Propagator 注入B3格式HEADER
+type B3Propagator struct {
+	Single bool //使用单个 b3 HEADER
+}
+
+func (prop *B3Propagator) Inject(trace *RestTraceContext, spanId string, header http.Header) {
+	sampled := "0"
+	if trace.Sampled {
+		sampled = "1"
+	}
+	if prop.Single {
+		header.Set("b3", trace.TraceId+"-"+spanId+"-"+sampled+"-"+trace.SpanId)
+		return
+	}
+	header.Set("X-B3-TraceId", trace.TraceId)
+	header.Set("X-B3-SpanId", spanId)
+	header.Set("X-B3-ParentSpanId", trace.SpanId)
+	header.Set("X-B3-Sampled", sampled)
+}
+
+// injectTrace 将上下文中的调用链注入请求HEADER
+func injectTrace(ctx context.Context, propagators []RestPropagator, header http.Header) {
+	if len(propagators) == 0 {
+		return
+	}
+	trace := TraceContextFrom(ctx)
+	if trace == nil || !isHex(trace.TraceId, 32) {
+		return
+	}
+	spanId := newSpanId()
+	for _, prop := range propagators {
+		prop.Inject(trace, spanId, header)
+	}
+}
@@ -0,0 +1,53 @@
+package rest_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExtractTraceContext(t *testing.T) {
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	trace := ExtractTraceContext(header)
+	if trace == nil || trace.TraceId != "4bf92f3577b34da6a3ce929d0e0e4736" || !trace.Sampled {
+		t.Fatal("traceparent parse error")
+	}
+	header = http.Header{}
+	header.Set("X-B3-TraceId", "a3ce929d0e0e4736")
+	header.Set("X-B3-SpanId", "00f067aa0ba902b7")
+	trace = ExtractTraceContext(header)
+	if trace == nil || trace.TraceId != "0000000000000000a3ce929d0e0e4736" {
+		t.Error("b3 parse error")
+	}
+	if ExtractTraceContext(http.Header{}) != nil {
+		t.Error("empty trace parse error")
+	}
+}
+
+func TestTracePropagate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"parent":"` + r.Header.Get("traceparent") + `","b3":"` + r.Header.Get("b3") + `"}`))
+	}))
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true},
+	})
+	ctx := WithTraceContext(context.Background(), &RestTraceContext{
+		TraceId: "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanId:  "00f067aa0ba902b7",
+		Sampled: true,
+	})
+	res := (<-client.Do(ctx, 1, nil)).JsonResult()
+	parent := res.MustString("parent")
+	if !strings.HasPrefix(parent, "00-4bf92f3577b34da6a3ce929d0e0e4736-") || strings.Contains(parent, "00f067aa0ba902b7") {
+		t.Error("traceparent inject error:" + parent)
+	}
+	client.manager.SetPropagators(&B3Propagator{Single: true})
+	res = (<-client.Do(ctx, 1, nil)).JsonResult()
+	if res.MustString("parent") != "" || !strings.HasSuffix(res.MustString("b3"), "-1-00f067aa0ba902b7") {
+		t.Error("b3 inject error")
+	}
+}
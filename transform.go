@@ -0,0 +1,199 @@
+package rest_client
+
+import (
+	"encoding/json"
+	"github.com/tidwall/gjson"
+	"sort"
+	"strings"
+)
+
+// RestBodyTransformer 实现该接口的 RestBuild 在读取返回内容后,检测返回结果前转换内容,如将旧格式转为统一结构
+type RestBodyTransformer interface {
+	TransformBody(body string) (string, error)
+}
+
+// RestTransformer 返回内容转换函数,返回错误时请求失败
+type RestTransformer func(body string) (string, error)
+
+// TransformBody 按顺序执行接口设置的 Transformers
+func (clt *AppRestBuild) TransformBody(body string) (string, error) {
+	var err error
+	for _, transformer := range clt.Transformers {
+		if body, err = transformer(body); err != nil {
+			return "", err
+		}
+	}
+	return body, nil
+}
+
+// transform 按接口配置转换返回内容,在 sanitize 之后执行
+func (res *RestResult) transform(body string) (string, error) {
+	transformer, ok := res.build.(RestBodyTransformer)
+	if !ok {
+		return body, nil
+	}
+	body, err := transformer.TransformBody(body)
+	if err != nil {
+		if _, ok := err.(*RestClientError); ok {
+			return "", err
+		}
+		return "", &RestClientError{Code: ErrTransform, Msg: "transform result fail:" + err.Error(), err: err}
+	}
+	return body, nil
+}
+
+// jsonSetRaw 替换路径上的节点内容,路径为空时替换全部内容
+func jsonSetRaw(body, path, raw string) (string, error) {
+	if len(path) == 0 {
+		return raw, nil
+	}
+	data := gjson.Get(body, path)
+	if data.Index <= 0 {
+		return "", NewRestClientError(ErrTransform, "path not support transform:"+path)
+	}
+	return body[:data.Index] + raw + body[data.Index+len(data.Raw):], nil
+}
+
+// jsonSplitPath 拆分为上级路径及字段名
+func jsonSplitPath(path string) (string, string) {
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i] == '.' && (i == 0 || path[i-1] != '\\') {
+			return path[:i], strings.ReplaceAll(path[i+1:], "\\.", ".")
+		}
+	}
+	return "", strings.ReplaceAll(path, "\\.", ".")
+}
+
+// jsonObjectField 按字段名获取对象的字段,字段名不按路径语法解析
+func jsonObjectField(obj gjson.Result, key string) gjson.Result {
+	var field gjson.Result
+	obj.ForEach(func(name, value gjson.Result) bool {
+		if name.Str == key {
+			field = value
+			return false
+		}
+		return true
+	})
+	return field
+}
+
+// jsonObjectBuilder 按原有顺序重建对象
+type jsonObjectBuilder struct {
+	buf  strings.Builder
+	keys map[string]bool
+}
+
+func (builder *jsonObjectBuilder) add(key string, raw string) {
+	if builder.keys[key] {
+		return
+	}
+	if builder.keys == nil {
+		builder.keys = map[string]bool{}
+		builder.buf.WriteByte('{')
+	} else {
+		builder.buf.WriteByte(',')
+	}
+	builder.keys[key] = true
+	name, _ := json.Marshal(key)
+	builder.buf.Write(name)
+	builder.buf.WriteByte(':')
+	builder.buf.WriteString(raw)
+}
+
+func (builder *jsonObjectBuilder) String() string {
+	if builder.keys == nil {
+		return "{}"
+	}
+	return builder.buf.String() + "}"
+}
+
+// UnwrapJsonString 节点为JSON编码后的字符串时替换为解码后的JSON,如 {"data":"{\"id\":1}"},
+// 路径为空时处理整个返回内容,不是字符串或内容不是JSON的节点保持不变
+func UnwrapJsonString(paths ...string) RestTransformer {
+	if len(paths) == 0 {
+		paths = []string{""}
+	}
+	return func(body string) (string, error) {
+		for _, path := range paths {
+			data := gjson.Parse(body)
+			if len(path) > 0 {
+				data = gjson.Get(body, path)
+			}
+			if data.Type != gjson.String || !gjson.Valid(data.Str) {
+				continue
+			}
+			var err error
+			if body, err = jsonSetRaw(body, path, strings.TrimSpace(data.Str)); err != nil {
+				return "", err
+			}
+		}
+		return body, nil
+	}
+}
+
+// RenameJsonField 重命名旧字段,KEY为字段路径,值为新字段名,如 {"result.errcode":"code"},
+// 字段不存在或同级已有新字段时不处理
+func RenameJsonField(renames map[string]string) RestTransformer {
+	paths := make([]string, 0, len(renames))
+	for path := range renames {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return func(body string) (string, error) {
+		for _, path := range paths {
+			parentPath, key := jsonSplitPath(path)
+			parent := gjson.Parse(body)
+			if len(parentPath) > 0 {
+				parent = gjson.Get(body, parentPath)
+			}
+			newKey := renames[path]
+			if !parent.IsObject() || !jsonObjectField(parent, key).Exists() || jsonObjectField(parent, newKey).Exists() {
+				continue
+			}
+			var builder jsonObjectBuilder
+			parent.ForEach(func(name, value gjson.Result) bool {
+				if name.Str == key {
+					builder.add(newKey, value.Raw)
+				} else {
+					builder.add(name.Str, value.Raw)
+				}
+				return true
+			})
+			var err error
+			if body, err = jsonSetRaw(body, parentPath, builder.String()); err != nil {
+				return "", err
+			}
+		}
+		return body, nil
+	}
+}
+
+// FlattenJsonEnvelope 将对象节点的字段提升到上级对象并去掉该节点,用于去掉多余的外层包装,
+// 如 FlattenJsonEnvelope("response") 将 {"response":{"result":{},"data":{}}} 转为 {"result":{},"data":{}},
+// 上级对象已有的同名字段保持不变
+func FlattenJsonEnvelope(path string) RestTransformer {
+	parentPath, key := jsonSplitPath(path)
+	return func(body string) (string, error) {
+		parent := gjson.Parse(body)
+		if len(parentPath) > 0 {
+			parent = gjson.Get(body, parentPath)
+		}
+		envelope := jsonObjectField(parent, key)
+		if !parent.IsObject() || !envelope.IsObject() {
+			return body, nil
+		}
+		var builder jsonObjectBuilder
+		//先添加上级字段,同名时保留上级的值
+		parent.ForEach(func(name, value gjson.Result) bool {
+			if name.Str != key {
+				builder.add(name.Str, value.Raw)
+			}
+			return true
+		})
+		envelope.ForEach(func(name, value gjson.Result) bool {
+			builder.add(name.Str, value.Raw)
+			return true
+		})
+		return jsonSetRaw(body, parentPath, builder.String())
+	}
+}
@@ -0,0 +1,63 @@
+package rest_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRestTransformers(t *testing.T) {
+	unwrap := UnwrapJsonString("data", "data.extra", "miss")
+	if get, err := unwrap(`{"data":"{\"id\":1,\"extra\":\"[1,2]\",\"name\":\"a\"}"}`); err != nil || get != `{"data":{"id":1,"extra":[1,2],"name":"a"}}` {
+		t.Error(get, err)
+	}
+	if get, err := UnwrapJsonString()(`"{\"a\":1}"`); err != nil || get != `{"a":1}` {
+		t.Error(get, err)
+	}
+	if get, err := unwrap(`{"data":"plain text"}`); err != nil || get != `{"data":"plain text"}` {
+		t.Error(get, err)
+	}
+	rename := RenameJsonField(map[string]string{"result.errcode": "code", "result.errmsg": "msg", "ok": "state"})
+	if get, err := rename(`{"result":{"errcode":"200","errmsg":"ok","msg":"keep"},"data":1}`); err != nil ||
+		get != `{"result":{"code":"200","errmsg":"ok","msg":"keep"},"data":1}` {
+		t.Error(get, err)
+	}
+	flatten := FlattenJsonEnvelope("response")
+	if get, err := flatten(`{"data":0,"response":{"result":{"code":"200"},"data":{"id":1}}}`); err != nil ||
+		get != `{"data":0,"result":{"code":"200"}}` {
+		t.Error(get, err)
+	}
+	if get, err := FlattenJsonEnvelope("a.b")(`{"a":{"b":{"c":1},"d":2}}`); err != nil || get != `{"a":{"d":2,"c":1}}` {
+		t.Error(get, err)
+	}
+}
+
+func TestAppRestTransformers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"response":{"result":{"errcode":"200","state":"ok"},"data":"{\"name\":\"a\"}"}}`))
+	}))
+	defer server.Close()
+	fail := errors.New("fail")
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		1: &AppRestBuild{Transformers: []RestTransformer{
+			FlattenJsonEnvelope("response"),
+			RenameJsonField(map[string]string{"result.errcode": "code"}),
+			UnwrapJsonString("data"),
+		}},
+		2: &AppRestBuild{},
+		3: &AppRestBuild{Transformers: []RestTransformer{func(body string) (string, error) {
+			return "", fail
+		}}},
+	})
+	if res := (<-client.Do(context.Background(), 1, nil)).JsonResult("data"); res.Err() != nil || res.MustString("name") != "a" {
+		t.Error("transform result wrong", res.Err())
+	}
+	if res := (<-client.Do(context.Background(), 2, nil)).JsonResult(); res.Err() == nil {
+		t.Error("result transformed without transformer")
+	}
+	if res := (<-client.Do(context.Background(), 3, nil)).JsonResult(); ErrorCode(res.Err()) != ErrTransform || !errors.Is(res.Err(), fail) {
+		t.Error("transform error wrong", res.Err())
+	}
+}
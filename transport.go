@@ -0,0 +1,129 @@
+package rest_client
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RestTransportConfig 连接及连接池配置
+type RestTransportConfig struct {
+	DialTimeout           time.Duration //建立连接超时
+	KeepAlive             time.Duration //TCP keep-alive 间隔
+	MaxIdleConns          int           //所有地址最大空闲连接数
+	MaxIdleConnsPerHost   int           //每个地址最大空闲连接数,net/http 默认仅为2
+	MaxConnsPerHost       int           //每个地址最大连接数,0不限制
+	IdleConnTimeout       time.Duration //空闲连接保持时间
+	ResponseHeaderTimeout time.Duration //默认等待返回HEADER的时间
+	DisableKeepAlives     bool          //不复用连接
+	Http2                 bool          //HTTPS连接尝试使用HTTP/2,不支持时使用HTTP/1.1
+	H2C                   bool          //HTTP连接使用明文HTTP/2(prior knowledge),握手失败时回退到HTTP/1.1
+	Resolver              RestResolver  //自定义域名解析,如 RestStaticResolver,RestDnsCache
+	LocalAddr             string        //出口IP,多网卡部署时合作方白名单要求固定出口IP
+	Interface             string        //出口网卡名,如 eth1,使用网卡上与目标地址类型一致的IP,设置 LocalAddr 时忽略
+	//自定义建立连接,如 NewRestSocks5Dialer,NewRestSshDialer 经代理或跳板机连接,设置后 LocalAddr Interface KeepAlive 不生效
+	//同时设置 Resolver 时域名在本地解析后以IP经代理连接,需要由代理或跳板机解析内网域名时不要设置 Resolver
+	Dialer RestDialer
+	//连接使用的IP类型,如合作方IPv6地址不通时 RestAddrOnlyIPv4
+	AddrFamily RestAddrFamily
+	//Happy Eyeballs 首选IP类型未连接时尝试另一类型的等待时间,默认300ms,负数时仅在首选类型失败后尝试
+	FallbackDelay time.Duration
+	//SPKI证书固定,为nil时不校验
+	CertPins *RestCertPins
+}
+
+// DefaultRestTransportConfig 默认连接配置
+func DefaultRestTransportConfig() *RestTransportConfig {
+	return &RestTransportConfig{
+		DialTimeout:           30 * time.Second,
+		KeepAlive:             300 * time.Second,
+		MaxIdleConns:          120,
+		MaxIdleConnsPerHost:   12,
+		IdleConnTimeout:       15 * time.Second,
+		ResponseHeaderTimeout: 60 * time.Second, //默认到header的等待时间最长
+	}
+}
+
+// NewRestTransport 按配置创建 Transport
+func NewRestTransport(config *RestTransportConfig) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:       config.DialTimeout,
+		KeepAlive:     config.KeepAlive,
+		FallbackDelay: config.FallbackDelay,
+	}
+	dialContext := dialer.DialContext
+	if config.Dialer != nil {
+		dialContext = config.Dialer.DialContext
+	} else if len(config.LocalAddr) > 0 || len(config.Interface) > 0 {
+		dialContext = localDialContext(dialer, config.LocalAddr, config.Interface)
+	}
+	if config.Resolver != nil {
+		dialContext = resolveDialContext(dialContext, config.Resolver)
+	}
+	if config.AddrFamily != RestAddrAny {
+		dialContext = familyDialContext(dialContext, config.AddrFamily, config.FallbackDelay)
+	}
+	transport := &http.Transport{
+		DialContext:           timeoutDialContext(dialContext),
+		MaxIdleConns:          config.MaxIdleConns,
+		MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       config.MaxConnsPerHost,
+		IdleConnTimeout:       config.IdleConnTimeout,
+		ResponseHeaderTimeout: config.ResponseHeaderTimeout,
+		DisableKeepAlives:     config.DisableKeepAlives,
+		ForceAttemptHTTP2:     config.Http2,
+	}
+	if config.CertPins != nil {
+		transport.TLSClientConfig = config.CertPins.tlsConfig()
+	}
+	return transport
+}
+
+// newRestRoundTripper 按配置创建,开启 H2C 时返回支持明文HTTP/2的 RoundTripper
+func newRestRoundTripper(config *RestTransportConfig) (http.RoundTripper, *http.Transport) {
+	transport := NewRestTransport(config)
+	if config.H2C {
+		return newH2cTransport(transport), transport
+	}
+	return transport, transport
+}
+
+// restConfigTransport 服务配置独立的连接
+type restConfigTransport struct {
+	roundTripper http.RoundTripper
+	transport    *http.Transport //HTTP/1.1 部分
+}
+
+// restTransports 按服务配置名缓存的 Transport
+type restTransports struct {
+	lock sync.Mutex
+	data map[string]*restConfigTransport
+}
+
+func (trans *restTransports) get(name string, config *RestTransportConfig) *restConfigTransport {
+	trans.lock.Lock()
+	defer trans.lock.Unlock()
+	if trans.data == nil {
+		trans.data = map[string]*restConfigTransport{}
+	}
+	transport, ok := trans.data[name]
+	if !ok {
+		transport = &restConfigTransport{}
+		transport.roundTripper, transport.transport = newRestRoundTripper(config)
+		trans.data[name] = transport
+	}
+	return transport
+}
+
+// remove 服务配置更新时移除并关闭空闲连接
+func (trans *restTransports) remove(name string) {
+	trans.lock.Lock()
+	defer trans.lock.Unlock()
+	if transport, ok := trans.data[name]; ok {
+		if closer, ok := transport.roundTripper.(interface{ CloseIdleConnections() }); ok {
+			closer.CloseIdleConnections()
+		}
+		delete(trans.data, name)
+	}
+}
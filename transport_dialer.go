@@ -0,0 +1,137 @@
+package rest_client
+
+import (
+	"context"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/proxy"
+	"net"
+	"sync"
+	"time"
+)
+
+// RestDialer 自定义建立连接,*net.Dialer 及 golang.org/x/net/proxy 的 ContextDialer 可直接使用
+type RestDialer interface {
+	DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// RestDialerFunc 函数形式的建立连接
+type RestDialerFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+func (fn RestDialerFunc) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return fn(ctx, network, addr)
+}
+
+// NewRestSocks5Dialer 经SOCKS5代理建立连接,域名由代理解析,配置了 Resolver 时为本地解析后的IP
+// @param user 为空时不认证
+func NewRestSocks5Dialer(addr, user, password string) RestDialer {
+	var auth *proxy.Auth
+	if len(user) > 0 {
+		auth = &proxy.Auth{User: user, Password: password}
+	}
+	dialer, err := proxy.SOCKS5("tcp", addr, auth, proxy.Direct)
+	if err != nil {
+		return RestDialerFunc(func(_ context.Context, _, _ string) (net.Conn, error) {
+			return nil, err
+		})
+	}
+	return dialer.(proxy.ContextDialer)
+}
+
+// RestSshDialer 经SSH跳板机建立连接,用于只能通过跳板机访问的合作方环境,不需要另外运行端口转发
+// 所有连接复用同一个SSH连接,SSH连接断开后下次建立连接时重连
+type RestSshDialer struct {
+	Addr    string            //跳板机地址,host:port
+	Config  *ssh.ClientConfig //认证及主机密钥校验配置
+	Forward RestDialer        //连接跳板机使用,可用于多级跳板,为nil时直接连接
+	lock    sync.Mutex
+	client  *ssh.Client
+}
+
+// NewRestSshDialer 创建SSH跳板机连接
+func NewRestSshDialer(addr string, config *ssh.ClientConfig) *RestSshDialer {
+	return &RestSshDialer{
+		Addr:   addr,
+		Config: config,
+	}
+}
+
+// sshClient 获取SSH连接,未连接或已断开时重新连接
+func (dialer *RestSshDialer) sshClient(ctx context.Context) (*ssh.Client, error) {
+	dialer.lock.Lock()
+	defer dialer.lock.Unlock()
+	if dialer.client != nil {
+		return dialer.client, nil
+	}
+	var forward RestDialer = &net.Dialer{}
+	if dialer.Forward != nil {
+		forward = dialer.Forward
+	}
+	conn, err := forward.DialContext(ctx, "tcp", dialer.Addr)
+	if err != nil {
+		return nil, err
+	}
+	//握手不支持ctx,通过连接超时控制
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else if dialer.Config.Timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(dialer.Config.Timeout))
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, dialer.Addr, dialer.Config)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	client := ssh.NewClient(sshConn, chans, reqs)
+	dialer.client = client
+	go func() {
+		_ = client.Wait()
+		dialer.lock.Lock()
+		if dialer.client == client {
+			dialer.client = nil
+		}
+		dialer.lock.Unlock()
+	}()
+	return client, nil
+}
+
+// DialContext 经跳板机连接目标地址,域名由跳板机解析
+func (dialer *RestSshDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	client, err := dialer.sshClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	type dialResult struct {
+		conn net.Conn
+		err  error
+	}
+	done := make(chan dialResult, 1)
+	go func() {
+		conn, err := client.Dial(network, addr)
+		done <- dialResult{conn, err}
+	}()
+	select {
+	case res := <-done:
+		return res.conn, res.err
+	case <-ctx.Done():
+		//超时后建立的连接直接关闭
+		go func() {
+			if res := <-done; res.conn != nil {
+				_ = res.conn.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// Close 关闭SSH连接,之后建立连接时会重新连接
+func (dialer *RestSshDialer) Close() error {
+	dialer.lock.Lock()
+	client := dialer.client
+	dialer.client = nil
+	dialer.lock.Unlock()
+	if client == nil {
+		return nil
+	}
+	return client.Close()
+}
@@ -0,0 +1,210 @@
+package rest_client
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"golang.org/x/crypto/ssh"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// testPipe 双向转发连接
+func testPipe(a, b net.Conn) {
+	go func() {
+		_, _ = io.Copy(a, b)
+		_ = a.Close()
+	}()
+	_, _ = io.Copy(b, a)
+	_ = b.Close()
+}
+
+// testSocks5Server 不认证的SOCKS5代理,记录请求的目标地址
+func testSocks5Server(t *testing.T, targets chan<- string) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				head := make([]byte, 2)
+				_, _ = io.ReadFull(conn, head)
+				_, _ = io.ReadFull(conn, make([]byte, head[1]))
+				_, _ = conn.Write([]byte{5, 0})
+				req := make([]byte, 4)
+				_, _ = io.ReadFull(conn, req)
+				var host string
+				switch req[3] {
+				case 1:
+					ip := make([]byte, 4)
+					_, _ = io.ReadFull(conn, ip)
+					host = net.IP(ip).String()
+				case 3:
+					size := make([]byte, 1)
+					_, _ = io.ReadFull(conn, size)
+					name := make([]byte, size[0])
+					_, _ = io.ReadFull(conn, name)
+					host = string(name)
+				}
+				port := make([]byte, 2)
+				_, _ = io.ReadFull(conn, port)
+				addr := net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port))))
+				targets <- addr
+				target, err := net.Dial("tcp", addr)
+				if err != nil {
+					_, _ = conn.Write([]byte{5, 1, 0, 1, 0, 0, 0, 0, 0, 0})
+					_ = conn.Close()
+					return
+				}
+				_, _ = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+				testPipe(conn, target)
+			}()
+		}
+	}()
+	return listener
+}
+
+// testSshServer 只支持 direct-tcpip 转发的SSH服务
+func testSshServer(t *testing.T, signer ssh.Signer) (net.Listener, *int32) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{NoClientAuth: true}
+	config.AddHostKey(signer)
+	var conns int32
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&conns, 1)
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for newChan := range chans {
+					var forward struct {
+						Host     string
+						Port     uint32
+						OrigHost string
+						OrigPort uint32
+					}
+					if newChan.ChannelType() != "direct-tcpip" || ssh.Unmarshal(newChan.ExtraData(), &forward) != nil {
+						_ = newChan.Reject(ssh.UnknownChannelType, "not support")
+						continue
+					}
+					target, err := net.Dial("tcp", net.JoinHostPort(forward.Host, strconv.Itoa(int(forward.Port))))
+					if err != nil {
+						_ = newChan.Reject(ssh.ConnectionFailed, err.Error())
+						continue
+					}
+					channel, chReqs, err := newChan.Accept()
+					if err != nil {
+						_ = target.Close()
+						continue
+					}
+					go ssh.DiscardRequests(chReqs)
+					go func() {
+						go func() {
+							_, _ = io.Copy(channel, target)
+							_ = channel.Close()
+						}()
+						_, _ = io.Copy(target, channel)
+						_ = target.Close()
+					}()
+				}
+			}()
+		}
+	}()
+	return listener, &conns
+}
+
+func testDialerClient(url string, dialer RestDialer) *RestClient {
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{Name: "test", AppUrl: url, Transport: &RestTransportConfig{Dialer: dialer, DisableKeepAlives: true}})
+	return manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true},
+	}})
+}
+
+func TestRestSocks5Dialer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+	targets := make(chan string, 1)
+	proxy := testSocks5Server(t, targets)
+	defer proxy.Close()
+	client := testDialerClient(server.URL, NewRestSocks5Dialer(proxy.Addr().String(), "", ""))
+	res := (<-client.Do(context.Background(), 1, nil)).JsonResult()
+	if res.Err() != nil || !res.MustBool("ok") {
+		t.Fatal("socks5 request fail", res.Err())
+	}
+	if target := <-targets; target != server.Listener.Addr().String() {
+		t.Error("socks5 target wrong", target)
+	}
+}
+
+func TestRestSshDialer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bastion, conns := testSshServer(t, signer)
+	defer bastion.Close()
+	dialer := NewRestSshDialer(bastion.Addr().String(), &ssh.ClientConfig{
+		User:            "test",
+		HostKeyCallback: ssh.FixedHostKey(signer.PublicKey()),
+	})
+	defer dialer.Close()
+	client := testDialerClient(server.URL, dialer)
+	for i := 0; i < 3; i++ {
+		if i == 2 {
+			_ = dialer.Close()
+		}
+		res := (<-client.Do(context.Background(), 1, nil)).JsonResult()
+		if res.Err() != nil || !res.MustBool("ok") {
+			t.Fatal("ssh request fail", i, res.Err())
+		}
+	}
+	if n := atomic.LoadInt32(conns); n != 2 {
+		t.Error("ssh connection not reuse", n)
+	}
+
+	wrong := NewRestSshDialer(bastion.Addr().String(), &ssh.ClientConfig{
+		User:            "test",
+		HostKeyCallback: ssh.FixedHostKey(testOtherKey(t)),
+	})
+	if err := (<-testDialerClient(server.URL, wrong).Do(context.Background(), 1, nil)).Err(); err == nil {
+		t.Error("ssh host key must check")
+	}
+}
+
+func testOtherKey(t *testing.T) ssh.PublicKey {
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
@@ -0,0 +1,107 @@
+package rest_client
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+// RestAddrFamily 连接使用的IP类型
+type RestAddrFamily int
+
+const (
+	RestAddrAny        RestAddrFamily = iota //按解析结果顺序,IPv4 IPv6都可用时 Happy Eyeballs 并行尝试
+	RestAddrPreferIPv4                       //优先IPv4,失败或超过 FallbackDelay 未连接时尝试IPv6
+	RestAddrPreferIPv6                       //优先IPv6,失败或超过 FallbackDelay 未连接时尝试IPv4
+	RestAddrOnlyIPv4                         //只使用IPv4,如合作方IPv6地址不通时
+	RestAddrOnlyIPv6                         //只使用IPv6
+)
+
+// defaultFallbackDelay 跟 net.Dialer 默认值一致
+const defaultFallbackDelay = 300 * time.Millisecond
+
+// familyDialContext 按IP类型偏好建立连接,仅处理 tcp 连接
+func familyDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error), family RestAddrFamily, fallbackDelay time.Duration) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if network != "tcp" {
+			return dial(ctx, network, addr)
+		}
+		switch family {
+		case RestAddrOnlyIPv4:
+			return dial(ctx, "tcp4", addr)
+		case RestAddrOnlyIPv6:
+			return dial(ctx, "tcp6", addr)
+		case RestAddrPreferIPv4:
+			return preferDial(ctx, dial, "tcp4", "tcp6", addr, fallbackDelay)
+		case RestAddrPreferIPv6:
+			return preferDial(ctx, dial, "tcp6", "tcp4", addr, fallbackDelay)
+		}
+		return dial(ctx, network, addr)
+	}
+}
+
+// preferDial 先连接首选类型,失败或超过 fallbackDelay 未连接时同时连接另一类型,使用先连接成功的
+// fallbackDelay 为0时使用默认值,负数时仅在首选类型失败后尝试另一类型
+func preferDial(ctx context.Context, dial func(ctx context.Context, network, addr string) (net.Conn, error), primary, fallback, addr string, fallbackDelay time.Duration) (net.Conn, error) {
+	if host, _, err := net.SplitHostPort(addr); err != nil || net.ParseIP(host) != nil {
+		return dial(ctx, "tcp", addr)
+	}
+	if fallbackDelay == 0 {
+		fallbackDelay = defaultFallbackDelay
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type dialResult struct {
+		conn net.Conn
+		err  error
+	}
+	results := make(chan dialResult, 2)
+	start := func(network string) {
+		go func() {
+			conn, err := dial(ctx, network, addr)
+			results <- dialResult{conn, err}
+		}()
+	}
+	start(primary)
+	pending, fallbackStarted := 1, false
+	var timer <-chan time.Time
+	if fallbackDelay > 0 {
+		fallbackTimer := time.NewTimer(fallbackDelay)
+		defer fallbackTimer.Stop()
+		timer = fallbackTimer.C
+	}
+	var firstErr error
+	for {
+		select {
+		case <-timer:
+			if !fallbackStarted {
+				fallbackStarted = true
+				pending++
+				start(fallback)
+			}
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				//另一类型之后连接成功时关闭
+				if pending > 0 {
+					go func() {
+						if res := <-results; res.conn != nil {
+							_ = res.conn.Close()
+						}
+					}()
+				}
+				return res.conn, nil
+			}
+			if firstErr == nil {
+				firstErr = res.err
+			}
+			if !fallbackStarted {
+				fallbackStarted = true
+				pending++
+				start(fallback)
+			} else if pending == 0 {
+				return nil, firstErr
+			}
+		}
+	}
+}
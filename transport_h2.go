@@ -0,0 +1,149 @@
+package rest_client
+
+import (
+	"context"
+	"crypto/tls"
+	"golang.org/x/net/http2"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// h2cRetryAfter 回退到HTTP/1.1的地址经过该时间后重新尝试HTTP/2,如服务端升级后
+const h2cRetryAfter = 5 * time.Minute
+
+// h2cTransport 明文HTTP/2(prior knowledge)请求,服务端不支持时回退到HTTP/1.1
+// 仅在服务端返回的第一个帧不是 SETTINGS(如HTTP/1.1服务返回400或直接关闭连接)时回退,
+// 回退后该地址 h2cRetryAfter 内的请求都使用HTTP/1.1,其他HTTP/2错误直接返回
+type h2cTransport struct {
+	h2       *http2.Transport
+	h1       *http.Transport
+	lock     sync.Mutex
+	h1Hosts  map[string]time.Time //回退的时间
+	prefaces map[string]bool      //连接握手失败的地址,回退时清除
+}
+
+func newH2cTransport(h1 *http.Transport) *h2cTransport {
+	dialer := h1.DialContext
+	if dialer == nil {
+		dialer = (&net.Dialer{}).DialContext
+	}
+	trans := &h2cTransport{
+		h1:       h1,
+		h1Hosts:  map[string]time.Time{},
+		prefaces: map[string]bool{},
+	}
+	trans.h2 = &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			conn, err := dialer(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return &h2cConn{Conn: conn, trans: trans, addr: addr}, nil
+		},
+	}
+	return trans
+}
+
+// h2cAddr 与 http2.Transport 连接的地址一致,未指定端口时为80
+func h2cAddr(host string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(host, "80")
+}
+
+// useH1 地址是否已回退且未到重试时间
+func (trans *h2cTransport) useH1(addr string) bool {
+	trans.lock.Lock()
+	defer trans.lock.Unlock()
+	at, ok := trans.h1Hosts[addr]
+	if ok && time.Since(at) >= h2cRetryAfter {
+		delete(trans.h1Hosts, addr)
+		return false
+	}
+	return ok
+}
+
+// prefaceFail 记录连接握手失败
+func (trans *h2cTransport) prefaceFail(addr string) {
+	trans.lock.Lock()
+	trans.prefaces[addr] = true
+	trans.lock.Unlock()
+}
+
+// downgrade 地址有握手失败的连接时回退到HTTP/1.1
+func (trans *h2cTransport) downgrade(addr string) bool {
+	trans.lock.Lock()
+	defer trans.lock.Unlock()
+	if !trans.prefaces[addr] {
+		return false
+	}
+	delete(trans.prefaces, addr)
+	trans.h1Hosts[addr] = time.Now()
+	return true
+}
+
+func (trans *h2cTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "http" {
+		return trans.h1.RoundTrip(req)
+	}
+	addr := h2cAddr(req.URL.Host)
+	if trans.useH1(addr) {
+		return trans.h1.RoundTrip(req)
+	}
+	res, err := trans.h2.RoundTrip(req)
+	if err == nil || req.Context().Err() != nil || !trans.downgrade(addr) {
+		return res, err
+	}
+	//请求内容已读取且无法重新获取时不重试
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, err
+		}
+		body, bErr := req.GetBody()
+		if bErr != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = body
+	}
+	return trans.h1.RoundTrip(req)
+}
+
+func (trans *h2cTransport) CloseIdleConnections() {
+	trans.h2.CloseIdleConnections()
+	trans.h1.CloseIdleConnections()
+}
+
+// h2cConn 检查服务端返回的第一个帧,不是 SETTINGS 时为握手失败,服务端不支持HTTP/2
+// 只在 http2.Transport 的读取协程中读取,无需加锁
+type h2cConn struct {
+	net.Conn
+	trans   *h2cTransport
+	addr    string
+	head    []byte
+	checked bool
+}
+
+func (conn *h2cConn) Read(p []byte) (int, error) {
+	n, err := conn.Conn.Read(p)
+	if conn.checked {
+		return n, err
+	}
+	//帧头9字节,第4字节为帧类型
+	conn.head = append(conn.head, p[:n]...)
+	if len(conn.head) >= 9 {
+		conn.checked = true
+		if http2.FrameType(conn.head[3]) != http2.FrameSettings {
+			conn.trans.prefaceFail(conn.addr)
+		}
+		conn.head = nil
+	} else if err != nil {
+		conn.checked = true
+		conn.trans.prefaceFail(conn.addr)
+	}
+	return n, err
+}
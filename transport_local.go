@@ -0,0 +1,65 @@
+package rest_client
+
+import (
+	"context"
+	"net"
+)
+
+// localIPs 出口IP列表,指定网卡时每次连接获取网卡当前的地址,网卡地址变化后无需重建连接池
+func localIPs(localAddr, iface string) ([]net.IP, error) {
+	if len(localAddr) > 0 {
+		ip := net.ParseIP(localAddr)
+		if ip == nil {
+			return nil, &net.AddrError{Err: "invalid local address", Addr: localAddr}
+		}
+		return []net.IP{ip}, nil
+	}
+	inter, err := net.InterfaceByName(iface)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := inter.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	ips := make([]net.IP, 0, len(addrs))
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLinkLocalUnicast() {
+			ips = append(ips, ipNet.IP)
+		}
+	}
+	if len(ips) == 0 {
+		return nil, &net.AddrError{Err: "no address on interface", Addr: iface}
+	}
+	return ips, nil
+}
+
+// selectLocalIP 选择与目标地址类型一致的出口IP,目标为域名时按连接类型,未指定类型时优先使用IPv4
+func selectLocalIP(ips []net.IP, network, addr string) net.IP {
+	wantV4 := network != "tcp6"
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		if ip := net.ParseIP(host); ip != nil {
+			wantV4 = ip.To4() != nil
+		}
+	}
+	for _, ip := range ips {
+		if (ip.To4() != nil) == wantV4 {
+			return ip
+		}
+	}
+	return ips[0]
+}
+
+// localDialContext 绑定出口IP建立连接
+// 目标为域名时由 net.Dialer 解析并只连接与出口IP类型一致的地址
+func localDialContext(dialer *net.Dialer, localAddr, iface string) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		ips, err := localIPs(localAddr, iface)
+		if err != nil {
+			return nil, &net.OpError{Op: "dial", Net: network, Err: err}
+		}
+		bound := *dialer
+		bound.LocalAddr = &net.TCPAddr{IP: selectLocalIP(ips, network, addr)}
+		return bound.DialContext(ctx, network, addr)
+	}
+}
@@ -0,0 +1,199 @@
+package rest_client
+
+import (
+	"context"
+	"github.com/tidwall/gjson"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRestTransportConfig(t *testing.T) {
+	transport := NewRestTransport(&RestTransportConfig{MaxIdleConnsPerHost: 64, MaxConnsPerHost: 128})
+	if transport.MaxIdleConnsPerHost != 64 || transport.MaxConnsPerHost != 128 {
+		t.Error("transport config error")
+	}
+	server := newTestAppServer(func(_ string, _ gjson.Result) string {
+		return `{}`
+	})
+	defer server.Close()
+	manager := NewRestClientManager()
+	config := &AppRestConfig{Name: "test", AppUrl: server.URL, Transport: &RestTransportConfig{MaxIdleConnsPerHost: 64}}
+	manager.SetRestConfig(config)
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodPost},
+	}})
+	if err := (<-client.Do(context.Background(), 1, nil)).JsonResult().Err(); err != nil {
+		t.Fatal(err)
+	}
+	cached := manager.transports.get("test", config.Transport)
+	if cached.transport == manager.transport || cached.transport.MaxIdleConnsPerHost != 64 {
+		t.Error("config transport not use")
+	}
+	manager.SetRestConfig(config)
+	if manager.transports.get("test", config.Transport) == cached {
+		t.Error("config transport not reset")
+	}
+}
+
+func TestRestTransportH2C(t *testing.T) {
+	h2Server := httptest.NewUnstartedServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"proto":"` + r.Proto + `"}`))
+	}), &http2.Server{}))
+	h2Server.Start()
+	defer h2Server.Close()
+	h1Server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"proto":"` + r.Proto + `"}`))
+	}))
+	defer h1Server.Close()
+	for url, proto := range map[string]string{h2Server.URL: "HTTP/2.0", h1Server.URL: "HTTP/1.1"} {
+		manager := NewRestClientManager()
+		manager.SetRestConfig(&AppRestConfig{Name: "test", AppUrl: url, Transport: &RestTransportConfig{H2C: true}})
+		client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+			1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true},
+		}})
+		for i := 0; i < 2; i++ {
+			res := (<-client.Do(context.Background(), 1, nil)).JsonResult()
+			if res.Err() != nil || res.MustString("proto") != proto {
+				t.Errorf("h2c request error:%v %s", res.Err(), proto)
+			}
+		}
+	}
+}
+
+func TestRestTransportH2CDowngrade(t *testing.T) {
+	h2Server := httptest.NewUnstartedServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}), &http2.Server{}))
+	h2Server.Config.ErrorLog = log.New(ioutil.Discard, "", 0)
+	h2Server.Start()
+	defer h2Server.Close()
+	config := &RestTransportConfig{H2C: true}
+	manager := NewRestClientManager()
+	manager.SetRestConfig(&AppRestConfig{Name: "test", AppUrl: h2Server.URL, Transport: config})
+	client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+		1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true},
+	}})
+	if err := (<-client.Do(context.Background(), 1, nil)).JsonResult().Err(); err == nil {
+		t.Fatal("stream reset must fail")
+	}
+	trans := manager.transports.get("test", config).roundTripper.(*h2cTransport)
+	addr := h2cAddr(strings.TrimPrefix(h2Server.URL, "http://"))
+	if trans.useH1(addr) {
+		t.Error("stream error must not downgrade")
+	}
+	trans.h1Hosts[addr] = time.Now().Add(-h2cRetryAfter)
+	if trans.useH1(addr) {
+		t.Error("downgrade must expire")
+	}
+}
+
+func TestRestTransportLocalAddr(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, _ := net.SplitHostPort(r.RemoteAddr)
+		_, _ = w.Write([]byte(`{"ip":"` + host + `"}`))
+	}))
+	defer server.Close()
+	for _, item := range []struct {
+		config *RestTransportConfig
+		ip     string
+	}{
+		{&RestTransportConfig{LocalAddr: "127.0.0.2"}, "127.0.0.2"},
+		{&RestTransportConfig{Interface: "lo"}, "127.0.0.1"},
+		{&RestTransportConfig{LocalAddr: "bad"}, ""},
+		{&RestTransportConfig{Interface: "not_exists"}, ""},
+	} {
+		manager := NewRestClientManager()
+		manager.SetRestConfig(&AppRestConfig{Name: "test", AppUrl: server.URL, Transport: item.config})
+		client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+			1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true},
+		}})
+		res := (<-client.Do(context.Background(), 1, nil)).JsonResult()
+		if len(item.ip) == 0 {
+			if res.Err() == nil {
+				t.Error("local addr must fail", item.config)
+			}
+		} else if res.Err() != nil || res.MustString("ip") != item.ip {
+			t.Error("local addr not bind", res.Err(), item.ip)
+		}
+	}
+	ips := []net.IP{net.ParseIP("::1"), net.ParseIP("127.0.0.1")}
+	if !selectLocalIP(ips, "tcp", "[::2]:80").Equal(ips[0]) || !selectLocalIP(ips, "tcp", "10.0.0.1:80").Equal(ips[1]) ||
+		!selectLocalIP(ips, "tcp", "example.com:80").Equal(ips[1]) || !selectLocalIP(ips, "tcp6", "example.com:80").Equal(ips[0]) {
+		t.Error("select local ip wrong")
+	}
+}
+
+func TestRestTransportAddrFamily(t *testing.T) {
+	var lock sync.Mutex
+	var networks []string
+	//IPv6 不通,IPv4 正常
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		lock.Lock()
+		networks = append(networks, network)
+		lock.Unlock()
+		if network == "tcp6" {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		conn, _ := net.Pipe()
+		return conn, nil
+	}
+	for _, item := range []struct {
+		family   RestAddrFamily
+		delay    time.Duration
+		networks []string
+	}{
+		{RestAddrOnlyIPv4, 0, []string{"tcp4"}},
+		{RestAddrPreferIPv4, 0, []string{"tcp4"}},
+		{RestAddrPreferIPv6, 10 * time.Millisecond, []string{"tcp6", "tcp4"}},
+		{RestAddrAny, 0, []string{"tcp"}},
+	} {
+		networks = nil
+		conn, err := familyDialContext(dial, item.family, item.delay)(context.Background(), "tcp", "partner.test:443")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = conn.Close()
+		lock.Lock()
+		if strings.Join(networks, ",") != strings.Join(item.networks, ",") {
+			t.Error("family dial wrong", item.family, networks)
+		}
+		lock.Unlock()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := familyDialContext(dial, RestAddrPreferIPv6, -1)(ctx, "tcp", "partner.test:443"); err == nil {
+		t.Error("negative fallback delay must wait primary")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+	url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	for family, ok := range map[RestAddrFamily]bool{RestAddrOnlyIPv4: true, RestAddrOnlyIPv6: false} {
+		manager := NewRestClientManager()
+		manager.SetRestConfig(&AppRestConfig{Name: "test", AppUrl: url, Transport: &RestTransportConfig{
+			AddrFamily: family,
+			Resolver:   &RestStaticResolver{Hosts: map[string][]string{"localhost": {"127.0.0.1"}}},
+		}})
+		client := manager.NewApi(&testBuildApi{name: "test", builds: map[int]RestBuild{
+			1: &AppRestBuild{HttpMethod: http.MethodGet, Raw: true},
+		}})
+		if err := (<-client.Do(context.Background(), 1, nil)).JsonResult().Err(); (err == nil) != ok {
+			t.Error("family transport wrong", family, err)
+		}
+	}
+}
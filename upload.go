@@ -0,0 +1,226 @@
+package rest_client
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AppUpload 分片上传,通过网关 初始化/上传分片/完成/取消 接口完成大文件上传
+// 分片内容以base64放入参数 data 字段,经过正常签名流程发送
+type AppUpload struct {
+	Client       *RestClient
+	InitKey      int           //初始化接口,返回上传ID
+	PartKey      int           //上传分片接口,返回分片标识
+	CompleteKey  int           //完成上传接口
+	AbortKey     int           //取消上传接口,小于0时不调用
+	PartSize     int64         //分片大小,默认5M
+	Parallel     int           //并发上传分片数,默认3
+	Retry        int           //单个分片失败重试次数,默认2
+	RetryWait    time.Duration //重试等待时间,按重试次数递增,默认200ms
+	UploadIdPath string        //初始化接口返回上传ID的路径,默认 data.upload_id
+	PartTagPath  string        //分片接口返回分片标识的路径,默认 data.etag
+	//整体上传进度回调,uploaded 为已成功上传分片的长度之和,分片重试不重复计算,可以为nil
+	Progress func(uploaded, total int64)
+}
+
+// AppUploadPart 已上传分片信息
+type AppUploadPart struct {
+	PartNumber int    `json:"part_number"`
+	Etag       string `json:"etag"`
+}
+
+// NewAppUpload 创建分片上传,不调用取消接口
+func NewAppUpload(client *RestClient, initKey, partKey, completeKey int) *AppUpload {
+	return &AppUpload{
+		Client:       client,
+		InitKey:      initKey,
+		PartKey:      partKey,
+		CompleteKey:  completeKey,
+		AbortKey:     -1,
+		PartSize:     5 * 1024 * 1024,
+		Parallel:     3,
+		Retry:        2,
+		RetryWait:    200 * time.Millisecond,
+		UploadIdPath: "data.upload_id",
+		PartTagPath:  "data.etag",
+	}
+}
+
+func uploadParam(param map[string]interface{}, set map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(param)+len(set))
+	for key, val := range param {
+		out[key] = val
+	}
+	for key, val := range set {
+		out[key] = val
+	}
+	return out
+}
+
+func (up *AppUpload) call(ctx context.Context, key int, param interface{}) *JsonResult {
+	return (<-up.Client.Do(ctx, key, param)).JsonResult()
+}
+
+// uploadPart 上传单个分片,失败时按配置重试
+func (up *AppUpload) uploadPart(ctx context.Context, uploadId string, partNumber int, data []byte, param map[string]interface{}) (*AppUploadPart, error) {
+	partParam := uploadParam(param, map[string]interface{}{
+		"upload_id":   uploadId,
+		"part_number": partNumber,
+		"data":        base64.StdEncoding.EncodeToString(data),
+	})
+	var err error
+	for i := 0; i <= up.Retry; i++ {
+		if i > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(up.RetryWait * time.Duration(i)):
+			}
+		}
+		res := up.call(ctx, up.PartKey, partParam)
+		var etag string
+		etag, err = res.GetString(up.PartTagPath)
+		if err == nil {
+			return &AppUploadPart{PartNumber: partNumber, Etag: etag}, nil
+		}
+	}
+	return nil, err
+}
+
+// Upload 执行分片上传
+// @param reader 上传内容
+// @param size 内容总长度
+// @param param 每个接口都会附带的业务参数,如文件名
+func (up *AppUpload) Upload(ctx context.Context, reader io.ReaderAt, size int64, param map[string]interface{}) (*JsonResult, error) {
+	if up.PartSize <= 0 {
+		return nil, NewRestClientError(ErrUpload, "upload part size is wrong")
+	}
+	initRes := up.call(ctx, up.InitKey, uploadParam(param, map[string]interface{}{
+		"size": size,
+	}))
+	uploadId, err := initRes.GetString(up.UploadIdPath)
+	if err != nil {
+		return nil, err
+	}
+
+	parts, err := up.uploadParts(ctx, uploadId, reader, size, param)
+	if err != nil {
+		if up.AbortKey >= 0 {
+			up.call(context.Background(), up.AbortKey, uploadParam(param, map[string]interface{}{
+				"upload_id": uploadId,
+			}))
+		}
+		return nil, err
+	}
+	res := up.call(ctx, up.CompleteKey, uploadParam(param, map[string]interface{}{
+		"upload_id": uploadId,
+		"parts":     parts,
+	}))
+	if err = res.Err(); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// UploadFile 分片上传文件
+// @param param 每个接口都会附带的业务参数,如文件名
+func (up *AppUpload) UploadFile(ctx context.Context, path string, param map[string]interface{}) (*JsonResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, NewRestClientError(ErrUpload, "open upload file fail:"+err.Error())
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return nil, NewRestClientError(ErrUpload, "stat upload file fail:"+err.Error())
+	}
+	return up.Upload(ctx, file, info.Size(), param)
+}
+
+func (up *AppUpload) uploadParts(ctx context.Context, uploadId string, reader io.ReaderAt, size int64, param map[string]interface{}) ([]*AppUploadPart, error) {
+	partCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	parallel := up.Parallel
+	if parallel <= 0 {
+		parallel = 1
+	}
+	type partJob struct {
+		number int
+		offset int64
+		length int64
+	}
+	jobs := make(chan partJob)
+	var (
+		lock     sync.Mutex
+		wait     sync.WaitGroup
+		parts    []*AppUploadPart
+		uploaded int64
+		firstErr error
+	)
+	setErr := func(err error) {
+		lock.Lock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+		lock.Unlock()
+	}
+	for i := 0; i < parallel; i++ {
+		wait.Add(1)
+		go func() {
+			defer wait.Done()
+			for job := range jobs {
+				data := make([]byte, job.length)
+				if _, err := reader.ReadAt(data, job.offset); err != nil && err != io.EOF {
+					setErr(err)
+					continue
+				}
+				part, err := up.uploadPart(partCtx, uploadId, job.number, data, param)
+				if err != nil {
+					setErr(NewRestClientError(ErrUpload, fmt.Sprintf("upload part %d fail:%s", job.number, err.Error())))
+					continue
+				}
+				lock.Lock()
+				parts = append(parts, part)
+				uploaded += job.length
+				if up.Progress != nil {
+					up.Progress(uploaded, size)
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+	number := 1
+	for offset := int64(0); offset < size; offset += up.PartSize {
+		length := up.PartSize
+		if offset+length > size {
+			length = size - offset
+		}
+		select {
+		case jobs <- partJob{number: number, offset: offset, length: length}:
+		case <-partCtx.Done():
+		}
+		if partCtx.Err() != nil {
+			break
+		}
+		number++
+	}
+	close(jobs)
+	wait.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(parts, func(i, j int) bool {
+		return parts[i].PartNumber < parts[j].PartNumber
+	})
+	return parts, nil
+}
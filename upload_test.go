@@ -0,0 +1,109 @@
+package rest_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"github.com/tidwall/gjson"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestAppUpload(t *testing.T) {
+	var lock sync.Mutex
+	received := map[int64]string{}
+	server := newTestAppServer(func(method string, content gjson.Result) string {
+		switch method {
+		case "init":
+			return `{"upload_id":"u1"}`
+		case "part":
+			data, _ := base64.StdEncoding.DecodeString(content.Get("data").String())
+			lock.Lock()
+			received[content.Get("part_number").Int()] = string(data)
+			lock.Unlock()
+			return `{"etag":"e` + content.Get("part_number").String() + `"}`
+		case "complete":
+			return `{"parts":` + strconv.Itoa(len(content.Get("parts").Array())) + `}`
+		}
+		return `{}`
+	})
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		0: &AppRestBuild{HttpMethod: http.MethodPost, Method: "init"},
+		1: &AppRestBuild{HttpMethod: http.MethodPost, Method: "part"},
+		2: &AppRestBuild{HttpMethod: http.MethodPost, Method: "complete"},
+	})
+	up := NewAppUpload(client, 0, 1, 2)
+	up.PartSize = 4
+	body := []byte("0123456789")
+	res, err := up.Upload(context.Background(), bytes.NewReader(body), int64(len(body)), map[string]interface{}{
+		"name": "a.txt",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.MustInt("data.parts") != 3 {
+		t.Error("upload parts error")
+	}
+	if received[1]+received[2]+received[3] != string(body) {
+		t.Error("upload data error")
+	}
+}
+
+func TestAppUploadFile(t *testing.T) {
+	var lock sync.Mutex
+	failed := false
+	server := newTestAppServer(func(method string, content gjson.Result) string {
+		switch method {
+		case "init":
+			return `{"upload_id":"u1"}`
+		case "part":
+			//第2个分片第一次失败
+			lock.Lock()
+			defer lock.Unlock()
+			if content.Get("part_number").Int() == 2 && !failed {
+				failed = true
+				return `{}`
+			}
+			return `{"etag":"e` + content.Get("part_number").String() + `"}`
+		case "complete":
+			return `{"parts":` + strconv.Itoa(len(content.Get("parts").Array())) + `}`
+		}
+		return `{}`
+	})
+	defer server.Close()
+	client := newTestAppClient(server.URL, map[int]RestBuild{
+		0: &AppRestBuild{Method: "init"},
+		1: &AppRestBuild{Method: "part"},
+		2: &AppRestBuild{Method: "complete"},
+	})
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := ioutil.WriteFile(path, []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	up := NewAppUpload(client, 0, 1, 2)
+	up.PartSize = 4
+	up.RetryWait = 0
+	var progress []int64
+	up.Progress = func(uploaded, total int64) {
+		if total != 10 {
+			t.Error("upload progress total error", total)
+		}
+		progress = append(progress, uploaded)
+	}
+	res, err := up.UploadFile(context.Background(), path, map[string]interface{}{"name": "a.txt"})
+	if err != nil || res.MustInt("data.parts") != 3 || !failed {
+		t.Fatal("upload file error", err)
+	}
+	if len(progress) != 3 || progress[2] != 10 || progress[0] >= progress[1] {
+		t.Error("upload progress error", progress)
+	}
+	if _, err = up.UploadFile(context.Background(), filepath.Join(os.TempDir(), "rest_client_not_exists"), nil); err == nil {
+		t.Error("upload not exists file should fail")
+	}
+}
@@ -1,15 +1,26 @@
 package rest_client
 
 import (
+	"reflect"
 	"runtime"
 	"strconv"
 	"strings"
 )
 
+// RestCallerInfo 发起请求的调用方信息,通过 Caller ErrorCaller 获取
 type RestCallerInfo struct {
 	FileName string
 	FileLine string
 	FuncName string
+	Tags     map[string]string //WithCallerTag 设置的标签,不可修改
+}
+
+// String 调用方位置,格式为 文件:行 函数
+func (caller *RestCallerInfo) String() string {
+	if caller == nil || len(caller.FileName) == 0 {
+		return ""
+	}
+	return caller.FileName + ":" + caller.FileLine + " " + caller.FuncName
 }
 
 func pathCreate(basePath, path string) string {
@@ -22,33 +33,42 @@ func pathCreate(basePath, path string) string {
 	return basePath + "." + path
 }
 
-// callerFileInfo 获取调用方文件信息
-func callerFileInfo(lastSkipMatch string, startLoop int, maxLoop int) *RestCallerInfo {
-	fileName := ""
-	fileLine := ""
-	funcName := ""
-	find := false
-	for i := startLoop; i < maxLoop; i++ {
-		pc, _fileName, _fileLine, ok := runtime.Caller(i)
-		if pc == 0 {
+// restPackage 本包路径,用于跳过包内的调用帧
+var restPackage = reflect.TypeOf(RestCallerInfo{}).PkgPath() + "."
+
+// internalFrame 是否为本包或运行时的调用帧,包内的测试文件视为调用方
+func internalFrame(frame runtime.Frame) bool {
+	if strings.HasPrefix(frame.Function, "runtime.") {
+		return true
+	}
+	return strings.HasPrefix(frame.Function, restPackage) && !strings.HasSuffix(frame.File, "_test.go")
+}
+
+// callerFileInfo 获取调用方文件信息,跳过本包内的全部调用帧,如 DoFuture DoSync 等封装
+// 在包内协程中发起的请求(如持久化任务 合并请求)没有包外调用帧,使用最外层的包内调用帧
+func callerFileInfo(startLoop int, maxLoop int) *RestCallerInfo {
+	pcs := make([]uintptr, maxLoop)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(startLoop+1, pcs)])
+	var last runtime.Frame
+	for {
+		frame, more := frames.Next()
+		if len(frame.File) > 0 && !internalFrame(frame) {
+			last = frame
 			break
 		}
-		if strings.Contains(_fileName, lastSkipMatch) {
-			find = true
-		} else {
-			if find {
-				fileName = _fileName
-				fileLine = strconv.Itoa(_fileLine)
-				if ok {
-					funcName = runtime.FuncForPC(pc).Name()
-				}
-				break
-			}
+		if strings.HasPrefix(frame.Function, restPackage) {
+			last = frame
 		}
+		if !more {
+			break
+		}
+	}
+	if len(last.File) == 0 {
+		return &RestCallerInfo{}
 	}
 	return &RestCallerInfo{
-		FileName: fileName,
-		FileLine: fileLine,
-		FuncName: funcName,
+		FileName: last.File,
+		FileLine: strconv.Itoa(last.Line),
+		FuncName: last.Function,
 	}
 }
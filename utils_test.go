@@ -18,7 +18,7 @@ func TestPathCreate(t *testing.T) {
 }
 
 func TestCallerFileInfo(t *testing.T) {
-	data := callerFileInfo("utils.go", 0, 12)
+	data := callerFileInfo(0, 12)
 	if !strings.Contains(data.FuncName, "TestCallerFileInfo") {
 		t.Error("not find self function name")
 	}
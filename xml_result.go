@@ -0,0 +1,123 @@
+package rest_client
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// XmlResult XML结果集,SOAP返回时为 Body 中的内容
+type XmlResult struct {
+	body    string
+	content string
+	err     error
+}
+
+// soapEnvelopeXml 返回的 Envelope,按元素本地名匹配,兼容1.1及1.2
+type soapEnvelopeXml struct {
+	XMLName xml.Name
+	Body    soapInnerXml `xml:"Body"`
+}
+
+// NewXmlResult 解析XML内容,为SOAP Envelope 时取 Body 中的内容,Body 中为 Fault 时返回 *SoapFault 错误
+func NewXmlResult(body string) *XmlResult {
+	var envelope soapEnvelopeXml
+	if err := xml.Unmarshal([]byte(body), &envelope); err != nil {
+		return &XmlResult{body: body, err: &RestClientError{Code: ErrXmlValid, Msg: "xml parse fail:" + err.Error(), err: err}}
+	}
+	res := &XmlResult{body: body, content: body}
+	var version SoapVersion
+	switch envelope.XMLName.Space {
+	case soap11Namespace:
+		version = Soap11
+	case soap12Namespace:
+		version = Soap12
+	default:
+		return res
+	}
+	res.content = envelope.Body.Content
+	if fault := soapFault(version, res.content); fault != nil {
+		res.err = fault
+	}
+	return res
+}
+
+// NewXmlResultFromError 创建一个错误XML结果
+func NewXmlResultFromError(err error) *XmlResult {
+	return &XmlResult{err: err}
+}
+
+// Err XML结果是否错误,SOAP Fault 时为 *SoapFault
+func (res *XmlResult) Err() error {
+	return res.err
+}
+
+// Fault 返回的 SOAP Fault,非 Fault 时返回nil
+func (res *XmlResult) Fault() *SoapFault {
+	var fault *SoapFault
+	if errors.As(res.err, &fault) {
+		return fault
+	}
+	return nil
+}
+
+// Body 返回的原始内容
+func (res *XmlResult) Body() string {
+	return res.body
+}
+
+// Content SOAP Body 中的XML原文,非SOAP返回时为原始内容
+func (res *XmlResult) Content() string {
+	return res.content
+}
+
+// Decode 将内容解析到结构,SOAP返回时解析 Body 中的第一个元素
+func (res *XmlResult) Decode(v interface{}) error {
+	if res.err != nil {
+		return res.err
+	}
+	if err := xml.Unmarshal([]byte(res.content), v); err != nil {
+		return &RestClientError{Code: ErrXmlValid, Msg: "xml decode fail:" + err.Error(), err: err}
+	}
+	return nil
+}
+
+// XmlResult 将结果解析为XML,用于 SoapRestBuild 等返回XML的接口
+// 返回SOAP Fault 时错误为 *SoapFault,其他HTTP状态码异常时错误码为 ErrServerHttp
+func (res *RestResult) XmlResult() *XmlResult {
+	if res == nil {
+		return NewXmlResultFromError(errResultClosed())
+	}
+	defer func() {
+		if res.event != nil {
+			res.event.ResponseCheck(res.err)
+		}
+	}()
+	if res.err != nil {
+		return NewXmlResultFromError(res.err)
+	}
+	buf := getBuffer()
+	if res.response != nil && res.response.ContentLength > 0 && res.response.ContentLength < maxPoolBuffer {
+		buf.Grow(int(res.response.ContentLength) + bytes.MinRead)
+	}
+	_, err := buf.ReadFrom(res)
+	if err != nil {
+		putBuffer(buf)
+		return NewXmlResultFromError(res.err)
+	}
+	result := NewXmlResult(res.sanitize(buf.String()))
+	putBuffer(buf)
+	httpCode := http.StatusOK
+	if res.response != nil {
+		httpCode = res.response.StatusCode
+	}
+	if fault := result.Fault(); fault != nil {
+		fault.HttpCode = httpCode
+	} else if httpCode >= http.StatusBadRequest {
+		result.err = NewRestClientError(ErrServerHttp, fmt.Sprintf("server http code:%d", httpCode))
+	}
+	res.err = result.err
+	return result
+}
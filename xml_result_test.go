@@ -0,0 +1,26 @@
+package rest_client
+
+import (
+	"testing"
+)
+
+func TestNewXmlResult(t *testing.T) {
+	res := NewXmlResult(`<Goods><Id>1</Id></Goods>`)
+	var goods struct {
+		Id int `xml:"Id"`
+	}
+	if err := res.Decode(&goods); err != nil || goods.Id != 1 {
+		t.Error("plain xml decode error", err)
+	}
+	if res.Content() != res.Body() || res.Fault() != nil {
+		t.Error("plain xml content should be body")
+	}
+	res = NewXmlResult(`{"id":1}`)
+	if ErrorCode(res.Err()) != ErrXmlValid {
+		t.Error("invalid xml should return ErrXmlValid", res.Err())
+	}
+	res = NewXmlResult(`<s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body><Pong/></s:Body></s:Envelope>`)
+	if res.Err() != nil || res.Content() != "<Pong/>" {
+		t.Error("soap content error", res.Content())
+	}
+}